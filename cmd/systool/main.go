@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/bryanCE/sysadmin/internal/cli"
@@ -12,6 +14,11 @@ import (
 var version = "dev" // Will be set by ldflags during build
 
 func main() {
+	var (
+		verbose    bool
+		configPath string
+	)
+
 	rootCmd := &cobra.Command{
 		Use:   "systool",
 		Short: "DNS & SSL Swiss Army Knife - Advanced DNS and SSL analysis tool",
@@ -19,16 +26,46 @@ func main() {
 Features include DNS querying, propagation checking, DNS inconsistency detection,
 and SSL certificate validation and analysis.`,
 		Version: version,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Debug events from the resolver and scanner are dropped unless --verbose is set
+			if verbose {
+				handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+				slog.SetDefault(slog.New(handler))
+			}
+
+			// Config file values become flag defaults; flags given on the command line win
+			required := configPath != ""
+			if !required {
+				configPath = cli.DefaultConfigPath()
+			}
+			if configPath == "" {
+				return nil
+			}
+			config, err := cli.LoadConfig(configPath, required)
+			if err != nil {
+				return err
+			}
+			return config.Apply(cmd)
+		},
 	}
 
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Enable debug logging of DNS exchanges and network probes to stderr")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "debug", false, "Alias for --verbose")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file with flag defaults (default ~/"+cli.DefaultConfigFile+")")
+
 	// Add DNS subcommands
 	rootCmd.AddCommand(cli.NewQueryCommand())
+	rootCmd.AddCommand(cli.NewRecordsCommand())
 	rootCmd.AddCommand(cli.NewPropagationCommand())
 	rootCmd.AddCommand(cli.NewConsistencyCommand())
+	rootCmd.AddCommand(cli.NewDiffCommand())
+	rootCmd.AddCommand(cli.NewReverseDNSCommand())
+	rootCmd.AddCommand(cli.NewSRVCommand())
 	rootCmd.AddCommand(cli.NewBulkCommand())
 
 	// Add SSL subcommands
 	rootCmd.AddCommand(cli.NewSSLCheckCommand())
+	rootCmd.AddCommand(cli.NewSSLCompareCommand())
 
 	// Add DNSSEC subcommands
 	rootCmd.AddCommand(cli.NewDNSSECVerifyCommand())
@@ -36,8 +73,21 @@ and SSL certificate validation and analysis.`,
 	// Add Network subcommands
 	rootCmd.AddCommand(cli.NewNetworkCommand())
 
+	// Add WHOIS subcommands
+	rootCmd.AddCommand(cli.NewWhoisCommand())
+
+	// Re-format results saved as JSON
+	rootCmd.AddCommand(cli.NewFormatCommand())
+
+	// Complete --format, --providers and record-type values in every subcommand
+	cli.RegisterCompletions(rootCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var exitErr *cli.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -6,7 +6,13 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,20 +27,50 @@ func NewQueryCommand() *cobra.Command {
 	var (
 		nameserverFlag string
 		formatFlag     string
+		watchFlag      bool
+		intervalFlag   string
+		ecsFlag        string
+		sortFlag       string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "query [domain] [record-type]",
 		Short: "Query DNS records for a domain",
 		Long: `Perform DNS queries for a specific domain and record type.
-Supports all common record types (A, AAAA, CNAME, MX, NS, TXT, SOA, PTR, SRV).`,
-		Args: cobra.MinimumNArgs(1),
+Supports all common record types (A, AAAA, CNAME, MX, NS, TXT, SOA, PTR, SRV).
+Give a comma-separated list (e.g. A,AAAA,MX,TXT) to query several types at once;
+the answers are shown one type after another.
+
+With --watch, the query is repeated every --interval and a timestamped line
+is printed only when the answer set changes (Ctrl+C to stop).
+
+With --ecs, an EDNS Client Subnet option is sent so CDN-hosted names return
+the answer a client in that network would see.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeRecordTypeArg(1, cobra.ShellCompDirectiveNoFileComp),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			domain := args[0]
+			if err := output.CheckSortKey(sortFlag, output.RecordSortKeys); err != nil {
+				return err
+			}
+			domain, err := dns.ToASCII(args[0])
+			if err != nil {
+				return err
+			}
 			recordType := dns.RecordTypeA // Default to A record
 
+			// A comma-separated list queries each type and shows the answers together
+			var recordTypes []dns.DNSRecordType
 			if len(args) > 1 {
-				recordType = dns.DNSRecordType(strings.ToUpper(args[1]))
+				if strings.Contains(args[1], ",") {
+					var err error
+					recordTypes, err = dns.ParseRecordTypes(args[1])
+					if err != nil {
+						return err
+					}
+					recordType = recordTypes[0]
+				} else {
+					recordType = dns.DNSRecordType(strings.ToUpper(args[1]))
+				}
 			}
 
 			// Get nameserver
@@ -47,12 +83,58 @@ Supports all common record types (A, AAAA, CNAME, MX, NS, TXT, SOA, PTR, SRV).`,
 			}
 
 			// Create resolver
-			resolver := dns.NewResolver()
+			opts := dns.DefaultQueryOptions()
+			if ecsFlag != "" {
+				subnet, err := dns.ParseClientSubnet(ecsFlag)
+				if err != nil {
+					return err
+				}
+				opts.ClientSubnet = subnet
+			}
+			resolver := dns.NewResolverWithOptions(opts)
+
+			if watchFlag {
+				if len(recordTypes) > 1 {
+					return fmt.Errorf("--watch follows a single record type")
+				}
+				interval, err := time.ParseDuration(intervalFlag)
+				if err != nil || interval <= 0 {
+					return fmt.Errorf("invalid interval format: %s", intervalFlag)
+				}
+				return watchQuery(resolver, domain, recordType, ns, interval)
+			}
 
 			// Create context with timeout
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
 
+			// Format and display results
+			var format output.OutputFormat
+			switch strings.ToLower(formatFlag) {
+			case "json":
+				format = output.FormatJSON
+			case "csv":
+				format = output.FormatCSV
+			case "xml":
+				format = output.FormatXML
+			default:
+				format = output.FormatTable
+			}
+			formatter := output.NewFormatter(format)
+			formatter.SetSort(sortFlag)
+
+			if len(recordTypes) > 1 {
+				// Perform one query per type; failures are shown alongside the answers
+				multi := resolver.QueryTypes(ctx, domain, recordTypes, ns)
+				if err := formatter.FormatMultiQueryResult(multi, os.Stdout); err != nil {
+					return err
+				}
+				if failed := multi.Failed(); failed > 0 {
+					return fmt.Errorf("%d of %d record type queries failed", failed, len(recordTypes))
+				}
+				return nil
+			}
+
 			// Perform query
 			result, err := resolver.Query(ctx, domain, recordType, ns)
 			if err != nil {
@@ -60,6 +142,68 @@ Supports all common record types (A, AAAA, CNAME, MX, NS, TXT, SOA, PTR, SRV).`,
 				return err
 			}
 
+			return formatter.FormatQueryResult(result, os.Stdout)
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to query (IP address, or an https:// DNS-over-HTTPS URL)")
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().BoolVarP(&watchFlag, "watch", "w", false, "Re-query on an interval and print only when the answer changes")
+	cmd.Flags().StringVarP(&intervalFlag, "interval", "i", "30s", "Watch interval (e.g., 30s, 1m)")
+	cmd.Flags().StringVar(&ecsFlag, "ecs", "", "EDNS Client Subnet to send, in CIDR notation (e.g., 203.0.113.0/24 or 2001:db8::/56)")
+	cmd.Flags().StringVar(&sortFlag, "sort", "", "Order records by ttl, type, value or priority")
+
+	return cmd
+}
+
+// NewRecordsCommand creates the records subcommand
+func NewRecordsCommand() *cobra.Command {
+	var (
+		nameserverFlag  string
+		formatFlag      string
+		concurrencyFlag int
+		sortFlag        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "records [domain]",
+		Short: "Fetch all common records for a domain",
+		Long: `Fetch the A, AAAA, CNAME, MX, NS, TXT, SOA and CAA records of a domain and show
+them in one report. Most servers refuse ANY queries, so each type is queried
+separately, --concurrency at a time.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.CheckSortKey(sortFlag, output.RecordSortKeys); err != nil {
+				return err
+			}
+			domain, err := dns.ToASCII(args[0])
+			if err != nil {
+				return err
+			}
+
+			// Get nameserver
+			var ns string
+			if nameserverFlag != "" {
+				ns = nameserverFlag
+			} else {
+				defaultNS := nameservers.GetDefaultNameservers()[0]
+				ns = defaultNS.IP.String()
+			}
+
+			// Create resolver
+			opts := dns.DefaultQueryOptions()
+			opts.Concurrency = concurrencyFlag
+			resolver := dns.NewResolverWithOptions(opts)
+
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			// Query every common type
+			result := resolver.QueryTypes(ctx, domain, dns.CommonRecordTypes, ns)
+
 			// Format and display results
 			var format output.OutputFormat
 			switch strings.ToLower(formatFlag) {
@@ -74,32 +218,171 @@ Supports all common record types (A, AAAA, CNAME, MX, NS, TXT, SOA, PTR, SRV).`,
 			}
 
 			formatter := output.NewFormatter(format)
-			return formatter.FormatQueryResult(result, os.Stdout)
+			formatter.SetSort(sortFlag)
+			if err := formatter.FormatRecordsReport(result, os.Stdout); err != nil {
+				return err
+			}
+			if failed := result.Failed(); failed == len(result.Results) {
+				return fmt.Errorf("all %d record type queries failed", failed)
+			}
+			return nil
 		},
 	}
 
 	// Add flags
-	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to query (IP address)")
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to query (IP address, or an https:// DNS-over-HTTPS URL)")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().IntVarP(&concurrencyFlag, "concurrency", "c", 4, "Number of record types queried at once")
+	cmd.Flags().StringVar(&sortFlag, "sort", "", "Order records within each type by ttl, type, value or priority")
 
 	return cmd
 }
 
+// watchQuery polls a record until interrupted, printing a line whenever the answer set changes
+func watchQuery(resolver *dns.Resolver, domain string, recordType dns.DNSRecordType, ns string, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("👀 Watching %s %s via %s every %v (Ctrl+C to stop)\n\n", domain, recordType, ns, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var previous []string
+	var previousErr string
+	first := true
+
+	for {
+		queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		result, err := resolver.Query(queryCtx, domain, recordType, ns)
+		cancel()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		timestamp := time.Now().Format("2006-01-02 15:04:05")
+		switch {
+		case err != nil:
+			if err.Error() != previousErr {
+				fmt.Printf("[%s] ❌ query failed: %v\n", timestamp, err)
+				previousErr = err.Error()
+			}
+		default:
+			current := recordValueSet(result.Records)
+			if first {
+				fmt.Printf("[%s] 📋 initial answer: %s\n", timestamp, formatValueSet(current))
+			} else if slices.Equal(current, previous) {
+				if previousErr != "" {
+					fmt.Printf("[%s] ✅ query recovered, answer unchanged: %s\n", timestamp, formatValueSet(current))
+				}
+			} else {
+				added, removed := diffValueSets(previous, current)
+				fmt.Printf("[%s] 🔄 answer changed: %s → %s", timestamp, formatValueSet(previous), formatValueSet(current))
+				if len(added) > 0 {
+					fmt.Printf(" (+%s)", strings.Join(added, ", +"))
+				}
+				if len(removed) > 0 {
+					fmt.Printf(" (-%s)", strings.Join(removed, ", -"))
+				}
+				fmt.Printf("\n")
+			}
+			previous = current
+			previousErr = ""
+			first = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordValueSet returns the sorted, de-duplicated answer values (MX prefixed with priority)
+func recordValueSet(records []dns.DNSRecord) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, record := range records {
+		value := record.Value
+		if record.Type == dns.RecordTypeMX {
+			value = fmt.Sprintf("%d %s", record.Priority, record.Value)
+		}
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+func formatValueSet(values []string) string {
+	if len(values) == 0 {
+		return "(no records)"
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// diffValueSets returns the values only in current (added) and only in previous (removed)
+func diffValueSets(previous, current []string) (added, removed []string) {
+	for _, value := range current {
+		if !slices.Contains(previous, value) {
+			added = append(added, value)
+		}
+	}
+	for _, value := range previous {
+		if !slices.Contains(current, value) {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed
+}
+
 // NewPropagationCommand creates the propagation subcommand
 func NewPropagationCommand() *cobra.Command {
 	var (
-		providerFlag string
-		formatFlag   string
+		providerFlag     string
+		nsFileFlag       string
+		authFlag         bool
+		transportFlag    string
+		formatFlag       string
+		concurrencyFlag  int
+		queryTimeoutFlag time.Duration
 	)
 
 	cmd := &cobra.Command{
 		Use:   "propagation [domain] [record-type]",
 		Short: "Check DNS propagation across servers",
 		Long: `Check DNS propagation status for a domain across multiple nameservers.
-Useful for verifying that DNS changes have propagated correctly.`,
-		Args: cobra.MinimumNArgs(1),
+Useful for verifying that DNS changes have propagated correctly.
+
+At most --concurrency nameservers are queried at once, and each one is
+given --query-timeout (including retries) before it is counted as failed.
+
+With --nameserver-file, the servers come from a file of address[,location[,provider]]
+lines and the results are grouped by location, e.g.:
+
+  8.8.8.8,US-East,Google
+  192.0.2.53:5353,EU
+
+With --auth, the domain's own authoritative nameservers are queried instead:
+the zone's NS records are looked up, each NS host is resolved to its
+addresses, and the results are grouped by NS host. Disagreement between
+them means a zone transfer or deployment has not reached every server.
+
+Examples:
+  systool propagation example.com A
+  systool propagation example.com MX --providers google,cloudflare
+  systool propagation www.example.com A --auth`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeRecordTypeArg(1, cobra.ShellCompDirectiveNoFileComp),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			domain := args[0]
+			domain, err := dns.ToASCII(args[0])
+			if err != nil {
+				return err
+			}
 			recordType := dns.RecordTypeA // Default to A record
 
 			if len(args) > 1 {
@@ -107,39 +390,83 @@ Useful for verifying that DNS changes have propagated correctly.`,
 			}
 
 			// Get nameservers
+			transport, err := parseTransport(transportFlag)
+			if err != nil {
+				return err
+			}
+			if transport == transportDoH && (authFlag || nsFileFlag != "") {
+				return fmt.Errorf("--transport doh only applies to catalog providers, not --auth or --nameserver-file")
+			}
 			var ns []string
-			if providerFlag != "" {
-				if strings.TrimSpace(strings.ToLower(providerFlag)) == "all" {
-					// Use all available nameservers
-					allServers := nameservers.GetAllNameservers()
-					for _, server := range allServers {
-						ns = append(ns, server.IP.String())
+			var locations map[string]string
+			if authFlag {
+				if providerFlag != "" || nsFileFlag != "" {
+					return fmt.Errorf("--auth cannot be combined with --providers or --nameserver-file")
+				}
+				lookupCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+				zone, servers, err := dns.NewResolver().AuthoritativeNameservers(lookupCtx, domain, nameservers.GetDefaultNameservers()[0].IP.String())
+				cancel()
+				if err != nil {
+					return fmt.Errorf("failed to find the authoritative nameservers: %w", err)
+				}
+				// Each address is labelled with its NS host, so the table groups by server
+				locations = make(map[string]string)
+				for _, server := range servers {
+					if len(server.Addresses) == 0 {
+						fmt.Fprintf(os.Stderr, "⚠️  Nameserver %s has no address and was skipped\n", server.Name)
+						continue
 					}
-				} else {
-					providers := strings.Split(providerFlag, ",")
-					for _, provider := range providers {
-						provider = strings.TrimSpace(provider)
-						servers := nameservers.GetProviderNameservers(provider)
-						for _, server := range servers {
-							ns = append(ns, server.IP.String())
-						}
+					for _, address := range server.Addresses {
+						address = net.JoinHostPort(address, "53")
+						ns = append(ns, address)
+						locations[address] = strings.TrimSuffix(server.Name, ".")
+					}
+				}
+				if len(ns) == 0 {
+					return fmt.Errorf("no nameserver of %s resolves to an address", zone)
+				}
+				fmt.Fprintf(os.Stderr, "🏛️  Querying %d addresses of the authoritative nameservers of %s\n", len(ns), zone)
+			} else if nsFileFlag != "" {
+				if providerFlag != "" {
+					return fmt.Errorf("--nameserver-file and --providers cannot be combined")
+				}
+				servers, err := nameservers.LoadFile(nsFileFlag)
+				if err != nil {
+					return err
+				}
+				locations = make(map[string]string)
+				for _, server := range servers {
+					ns = append(ns, server.Address())
+					if server.Location != "" {
+						locations[server.Address()] = server.Location
 					}
 				}
+			} else if ns, err = providerNameservers(providerFlag, transport); err != nil {
+				return err
 			}
 
 			if len(ns) == 0 {
 				// Use default nameservers
-				defaultServers := nameservers.GetDefaultNameservers()
-				for _, server := range defaultServers {
-					ns = append(ns, server.IP.String())
-				}
+				ns = catalogNameservers(nameservers.GetDefaultNameservers(), transport)
 			}
 
 			// Create resolver
-			resolver := dns.NewResolver()
+			opts := dns.DefaultQueryOptions()
+			opts.Concurrency = concurrencyFlag
+			opts.QueryTimeout = queryTimeoutFlag
+			resolver := dns.NewResolverWithOptions(opts)
+
+			// Allow enough overall time for every batch of servers to hit its deadline
+			overall := 30 * time.Second
+			if concurrencyFlag > 0 && queryTimeoutFlag > 0 {
+				batches := (len(ns) + concurrencyFlag - 1) / concurrencyFlag
+				if needed := time.Duration(batches) * queryTimeoutFlag; needed > overall {
+					overall = needed
+				}
+			}
 
 			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			ctx, cancel := context.WithTimeout(context.Background(), overall)
 			defer cancel()
 
 			// Check propagation
@@ -148,6 +475,9 @@ Useful for verifying that DNS changes have propagated correctly.`,
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				return err
 			}
+			if len(locations) > 0 {
+				result.Locations = locations
+			}
 
 			// Format and display results
 			var format output.OutputFormat
@@ -169,7 +499,13 @@ Useful for verifying that DNS changes have propagated correctly.`,
 
 	// Add flags
 	cmd.Flags().StringVarP(&providerFlag, "providers", "p", "", "DNS providers to check (comma-separated: google,cloudflare,quad9,opendns) or 'all' for all providers")
+	cmd.Flags().StringVar(&nsFileFlag, "nameserver-file", "", "Query the nameservers listed in this file (address[,location[,provider]] per line) and group results by location")
+	cmd.Flags().BoolVar(&authFlag, "auth", false, "Query the domain's authoritative nameservers, found through its NS records")
+	cmd.Flags().BoolVar(&authFlag, "nameservers-from-domain", false, "Alias for --auth")
+	cmd.Flags().StringVar(&transportFlag, "transport", transportUDP, "Query providers over udp or doh (DNS-over-HTTPS, providers with a DoH endpoint only)")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().IntVarP(&concurrencyFlag, "concurrency", "c", 10, "Maximum number of nameservers queried in parallel")
+	cmd.Flags().DurationVar(&queryTimeoutFlag, "query-timeout", 10*time.Second, "Per-nameserver deadline, including retries (e.g., 3s)")
 
 	return cmd
 }
@@ -177,58 +513,83 @@ Useful for verifying that DNS changes have propagated correctly.`,
 // NewConsistencyCommand creates the consistency subcommand
 func NewConsistencyCommand() *cobra.Command {
 	var (
-		providerFlag string
-		formatFlag   string
+		providerFlag  string
+		transportFlag string
+		formatFlag    string
+		checkPTRFlag  bool
+		ttlMinFlag    time.Duration
+		ttlMaxFlag    time.Duration
+		allowPrivate  bool
+		dkimFlag      string
+		checksFlag    string
+		skipFlag      string
+		failOnFlag    string
+		minSevFlag    string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "consistency [domain]",
 		Short: "Check DNS consistency issues",
 		Long: `Perform comprehensive DNS consistency checks for a domain.
-Identifies misconfigurations, inconsistencies, and potential problems.`,
+Identifies misconfigurations, inconsistencies, and potential problems.
+
+Use --checks or --skip-checks to choose which checks run, and --fail-on to
+exit with status 2 when issues at or above a severity are found. --min-severity
+hides lower-severity issues from the output without affecting --fail-on.
+Opt-in checks run only when named, e.g. --checks all,www.
+
+Available checks:
+` + consistencyCheckHelp(),
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			domain := args[0]
+			domain, err := dns.ToASCII(args[0])
+			if err != nil {
+				return err
+			}
+
+			failOn := strings.ToLower(strings.TrimSpace(failOnFlag))
+			if failOn != "" && dns.SeverityRank(failOn) < 1 {
+				return fmt.Errorf("invalid --fail-on severity %q (use high, medium or low)", failOnFlag)
+			}
+			minSeverity := strings.ToLower(strings.TrimSpace(minSevFlag))
+			if dns.SeverityRank(minSeverity) < 0 {
+				return fmt.Errorf("invalid --min-severity %q (use high, medium, low or info)", minSevFlag)
+			}
 
 			// Get nameservers
-			var ns []string
-			if providerFlag != "" {
-				if strings.TrimSpace(strings.ToLower(providerFlag)) == "all" {
-					// Use all available nameservers
-					allServers := nameservers.GetAllNameservers()
-					for _, server := range allServers {
-						ns = append(ns, server.IP.String())
-					}
-				} else {
-					providers := strings.Split(providerFlag, ",")
-					for _, provider := range providers {
-						provider = strings.TrimSpace(provider)
-						servers := nameservers.GetProviderNameservers(provider)
-						for _, server := range servers {
-							ns = append(ns, server.IP.String())
-						}
-					}
-				}
+			transport, err := parseTransport(transportFlag)
+			if err != nil {
+				return err
+			}
+			ns, err := providerNameservers(providerFlag, transport)
+			if err != nil {
+				return err
 			}
 
 			if len(ns) == 0 {
 				// Use all nameservers for comprehensive check
-				allServers := nameservers.GetAllNameservers()
-				for _, server := range allServers {
-					ns = append(ns, server.IP.String())
-				}
+				ns = catalogNameservers(nameservers.GetAllNameservers(), transport)
 			}
 
 			// Create resolver and checker
 			resolver := dns.NewResolver()
 			checker := dns.NewConsistencyChecker(resolver)
+			checker.SetCheckMXReverseDNS(checkPTRFlag)
+			checker.SetTTLThresholds(ttlMinFlag, ttlMaxFlag)
+			checker.SetAllowPrivateIPs(allowPrivate)
+			if dkimFlag != "" {
+				checker.SetDKIMSelectors(strings.Split(dkimFlag, ","))
+			}
+			if err := checker.SetChecks(splitList(checksFlag), splitList(skipFlag)); err != nil {
+				return err
+			}
 
 			// Create context with timeout
 			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 			defer cancel()
 
 			// Check consistency
-			issues, err := checker.CheckConsistency(ctx, domain, ns)
+			report, err := checker.CheckConsistencyReport(ctx, domain, ns)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				return err
@@ -247,99 +608,200 @@ Identifies misconfigurations, inconsistencies, and potential problems.`,
 				format = output.FormatTable
 			}
 
+			// Gate on every issue found, even those hidden by --min-severity
+			allIssues := report.Issues
+			report.Issues = dns.FilterBySeverity(report.Issues, minSeverity)
+
 			formatter := output.NewFormatter(format)
-			return formatter.FormatConsistencyIssues(issues, os.Stdout)
+			if err := formatter.FormatConsistencyReport(report, os.Stdout); err != nil {
+				return err
+			}
+
+			return failOnIssues(cmd, allIssues, failOn)
 		},
 	}
 
 	// Add flags
 	cmd.Flags().StringVarP(&providerFlag, "providers", "p", "", "DNS providers to check (comma-separated: google,cloudflare,quad9,opendns) or 'all' for all providers")
+	cmd.Flags().StringVar(&transportFlag, "transport", transportUDP, "Query providers over udp or doh (DNS-over-HTTPS, providers with a DoH endpoint only)")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().BoolVar(&checkPTRFlag, "check-ptr", false, "Verify that MX host addresses have PTR records resolving back to them (FCrDNS)")
+	cmd.Flags().DurationVar(&ttlMinFlag, "ttl-min", dns.DefaultMinTTL, "Flag records with a TTL below this (0 to disable)")
+	cmd.Flags().DurationVar(&ttlMaxFlag, "ttl-max", dns.DefaultMaxTTL, "Flag records with a TTL above this (0 to disable)")
+	cmd.Flags().BoolVar(&allowPrivate, "allow-private", false, "Don't flag private or reserved addresses (split-horizon zones)")
+	cmd.Flags().StringVar(&checksFlag, "checks", "", "Run only these checks (comma-separated, e.g. spf,dmarc,soa; 'all' for every default check)")
+	cmd.Flags().StringVar(&skipFlag, "skip-checks", "", "Skip these checks (comma-separated)")
+	cmd.Flags().StringVar(&failOnFlag, "fail-on", "", "Exit with status 2 if issues at or above this severity are found (high, medium, low)")
+	cmd.Flags().StringVar(&minSevFlag, "min-severity", "info", "Only show issues at or above this severity (high, medium, low, info)")
+	cmd.Flags().StringVar(&dkimFlag, "dkim-selectors", "", "Additional DKIM selectors to probe (comma-separated), beyond the built-in common ones")
 
 	return cmd
 }
 
-// NewBulkCommand creates the bulk subcommand
-func NewBulkCommand() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "bulk",
-		Short: "Perform bulk DNS operations",
-		Long: `Execute DNS operations on multiple domains from a file.
-The file should contain one domain per line.`,
+// consistencyCheckHelp lists the available checks for the consistency command's help text
+func consistencyCheckHelp() string {
+	descriptions := dns.ConsistencyCheckDescriptions()
+	var b strings.Builder
+	for _, name := range dns.ConsistencyCheckNames() {
+		fmt.Fprintf(&b, "  %-15s %s\n", name, descriptions[name])
 	}
+	return strings.TrimRight(b.String(), "\n")
+}
 
-	// Add subcommands
-	cmd.AddCommand(NewBulkQueryCommand())
-	cmd.AddCommand(NewBulkPropagationCommand())
-	cmd.AddCommand(NewBulkConsistencyCommand())
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
 
-	return cmd
+// Nameserver transports accepted by --transport
+const (
+	transportUDP = "udp"
+	transportDoH = "doh"
+)
+
+// parseTransport validates a --transport value
+func parseTransport(value string) (string, error) {
+	switch transport := strings.ToLower(strings.TrimSpace(value)); transport {
+	case transportUDP, transportDoH:
+		return transport, nil
+	}
+	return "", fmt.Errorf("invalid --transport %q (use udp or doh)", value)
 }
 
-// NewBulkQueryCommand creates the bulk query subcommand
-func NewBulkQueryCommand() *cobra.Command {
+// providerNameservers returns the servers to query for --providers: every catalog server of
+// the comma-separated providers, or of all providers for "all", as catalogNameservers maps
+// them for transport. Named providers without a DoH endpoint are skipped with a warning under
+// doh. It returns nil when providerFlag is empty.
+func providerNameservers(providerFlag, transport string) ([]string, error) {
+	if strings.TrimSpace(providerFlag) == "" {
+		return nil, nil
+	}
+
+	var servers []nameservers.Nameserver
+	if strings.TrimSpace(strings.ToLower(providerFlag)) == "all" {
+		servers = nameservers.GetAllNameservers()
+	} else {
+		for _, provider := range splitList(providerFlag) {
+			providerServers := nameservers.GetProviderNameservers(provider)
+			if transport == transportDoH && len(providerServers) > 0 && len(catalogNameservers(providerServers, transport)) == 0 {
+				fmt.Fprintf(os.Stderr, "⚠️  %s has no DoH endpoint and was skipped\n", provider)
+			}
+			servers = append(servers, providerServers...)
+		}
+	}
+
+	ns := catalogNameservers(servers, transport)
+	if len(ns) == 0 && transport == transportDoH {
+		return nil, fmt.Errorf("none of the selected providers has a DoH endpoint (providers with one: %s)", strings.Join(dohProviders(), ", "))
+	}
+	return ns, nil
+}
+
+// catalogNameservers maps catalog servers to what the resolver queries: the IP over udp, or
+// the DoH URL over doh, where servers without one are left out and shared URLs appear once
+func catalogNameservers(servers []nameservers.Nameserver, transport string) []string {
+	var ns []string
+	seen := make(map[string]bool)
+	for _, server := range servers {
+		target := server.IP.String()
+		if transport == transportDoH {
+			if !server.HasDoH() {
+				continue
+			}
+			target = server.DoHURL
+		}
+		if !seen[target] {
+			seen[target] = true
+			ns = append(ns, target)
+		}
+	}
+	return ns
+}
+
+// dohProviders returns the providers whose servers have a DoH endpoint, sorted by name
+func dohProviders() []string {
+	var names []string
+	for provider, servers := range nameservers.CommonNameservers {
+		if len(catalogNameservers(servers, transportDoH)) > 0 {
+			names = append(names, provider)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// failOnIssues returns an ExitError when any issue is at or above the threshold severity.
+// Usage and cobra's own error line are silenced because the command itself ran successfully.
+func failOnIssues(cmd *cobra.Command, issues []dns.ConsistencyIssue, threshold string) error {
+	if threshold == "" {
+		return nil
+	}
+
+	count := 0
+	for _, issue := range issues {
+		if dns.SeverityRank(issue.Severity) >= dns.SeverityRank(threshold) {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return &ExitError{
+		Code: ExitIssuesFound,
+		Err:  fmt.Errorf("%d issue(s) at or above %s severity", count, threshold),
+	}
+}
+
+// NewDiffCommand creates the diff subcommand
+func NewDiffCommand() *cobra.Command {
 	var (
-		nameserverFlag  string
-		formatFlag      string
-		concurrencyFlag int
+		serverAFlag string
+		serverBFlag string
+		typesFlag   string
+		formatFlag  string
 	)
 
 	cmd := &cobra.Command{
-		Use:   "query [file] [record-type]",
-		Short: "Perform bulk DNS queries",
-		Long: `Query DNS records for multiple domains from a file.
-The file should contain one domain per line.`,
-		Args: cobra.MinimumNArgs(1),
+		Use:   "diff [domain]",
+		Short: "Diff DNS records between two nameservers",
+		Long: `Query the same domain on two nameservers and show which records were
+added, removed or changed on server B relative to server A.
+Useful for verifying a DNS provider migration before cutover.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			filename := args[0]
-			recordType := dns.RecordTypeA // Default to A record
-
-			if len(args) > 1 {
-				recordType = dns.DNSRecordType(strings.ToUpper(args[1]))
-			}
-
-			// Read domains from file
-			domains, err := dns.ReadDomainsFromFile(filename)
+			domain, err := dns.ToASCII(args[0])
 			if err != nil {
-				return fmt.Errorf("failed to read domains: %w", err)
+				return err
 			}
 
-			// Get nameserver
-			var ns []string
-			if nameserverFlag != "" {
-				ns = []string{nameserverFlag}
-			} else {
-				defaultNS := nameservers.GetDefaultNameservers()[0]
-				ns = []string{defaultNS.IP.String()}
+			var recordTypes []dns.DNSRecordType
+			for _, recordType := range strings.Split(typesFlag, ",") {
+				if recordType = strings.TrimSpace(recordType); recordType != "" {
+					recordTypes = append(recordTypes, dns.DNSRecordType(strings.ToUpper(recordType)))
+				}
+			}
+			if len(recordTypes) == 0 {
+				recordTypes = dns.DefaultDiffRecordTypes
 			}
 
-			// Create resolver and bulk processor
+			// Create resolver
 			resolver := dns.NewResolver()
-			processor := dns.NewBulkProcessor(resolver, concurrencyFlag)
-
-			// Set progress callback
-			processor.SetProgressCallback(func(current, total int, domain string, success bool) {
-				status := "✓"
-				if !success {
-					status = "✗"
-				}
-				fmt.Printf("\r[%d/%d] %s %s", current, total, domain, status)
-				if current == total {
-					fmt.Println() // New line after completion
-				}
-			})
 
 			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 
-			fmt.Printf("Processing %d domains...\n", len(domains))
-
-			// Process bulk query
-			summary, err := processor.ProcessQuery(ctx, domains, recordType, ns)
-			if err != nil {
-				return fmt.Errorf("bulk query failed: %w", err)
-			}
+			// Diff both servers
+			result := resolver.DiffServers(ctx, domain, recordTypes, serverAFlag, serverBFlag)
 
 			// Format and display results
 			var format output.OutputFormat
@@ -355,104 +817,141 @@ The file should contain one domain per line.`,
 			}
 
 			formatter := output.NewFormatter(format)
-			return formatter.FormatBulkSummary(summary, os.Stdout)
+			return formatter.FormatDiffResult(result, os.Stdout)
 		},
 	}
 
 	// Add flags
-	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to query (IP address)")
+	cmd.Flags().StringVar(&serverAFlag, "server-a", "", "Reference nameserver (IP address or hostname)")
+	cmd.Flags().StringVar(&serverBFlag, "server-b", "", "Nameserver to compare against server A (IP address or hostname)")
+	cmd.Flags().StringVarP(&typesFlag, "types", "t", "A,AAAA,MX,NS,TXT,CNAME", "Record types to compare (comma-separated)")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
-	cmd.Flags().IntVarP(&concurrencyFlag, "concurrency", "c", 5, "Number of concurrent queries")
+	cmd.MarkFlagRequired("server-a")
+	cmd.MarkFlagRequired("server-b")
 
 	return cmd
 }
 
-// NewBulkPropagationCommand creates the bulk propagation subcommand
-func NewBulkPropagationCommand() *cobra.Command {
+// NewReverseDNSCommand creates the rdns-check subcommand
+func NewReverseDNSCommand() *cobra.Command {
 	var (
-		providerFlag    string
-		formatFlag      string
-		concurrencyFlag int
+		nameserverFlag string
+		formatFlag     string
 	)
 
 	cmd := &cobra.Command{
-		Use:   "propagation [file] [record-type]",
-		Short: "Check DNS propagation for multiple domains",
-		Long: `Check DNS propagation status for multiple domains from a file.
-The file should contain one domain per line.`,
-		Args: cobra.MinimumNArgs(1),
+		Use:   "rdns-check [ip]",
+		Short: "Verify forward-confirmed reverse DNS for an IP",
+		Long: `Look up the PTR records of an IP address and forward-resolve each name,
+reporting whether the A/AAAA records lead back to the same IP (FCrDNS).
+Mail servers commonly reject connections from addresses that fail this check.`,
+		Example: `  systool rdns-check 192.0.2.25
+  systool rdns-check 2001:db8::25 --nameserver 1.1.1.1`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			filename := args[0]
-			recordType := dns.RecordTypeA // Default to A record
+			ip := args[0]
 
-			if len(args) > 1 {
-				recordType = dns.DNSRecordType(strings.ToUpper(args[1]))
+			// Get nameserver
+			var ns string
+			if nameserverFlag != "" {
+				ns = nameserverFlag
+			} else {
+				defaultNS := nameservers.GetDefaultNameservers()[0]
+				ns = defaultNS.IP.String()
 			}
 
-			// Read domains from file
-			domains, err := dns.ReadDomainsFromFile(filename)
-			if err != nil {
-				return fmt.Errorf("failed to read domains: %w", err)
-			}
+			resolver := dns.NewResolver()
 
-			// Get nameservers
-			var ns []string
-			if providerFlag != "" {
-				if strings.TrimSpace(strings.ToLower(providerFlag)) == "all" {
-					// Use all available nameservers
-					allServers := nameservers.GetAllNameservers()
-					for _, server := range allServers {
-						ns = append(ns, server.IP.String())
-					}
-				} else {
-					providers := strings.Split(providerFlag, ",")
-					for _, provider := range providers {
-						provider = strings.TrimSpace(provider)
-						servers := nameservers.GetProviderNameservers(provider)
-						for _, server := range servers {
-							ns = append(ns, server.IP.String())
-						}
-					}
-				}
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			result, err := resolver.CheckReverseDNS(ctx, ip, ns)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return err
 			}
 
-			if len(ns) == 0 {
-				// Use default nameservers
-				defaultServers := nameservers.GetDefaultNameservers()
-				for _, server := range defaultServers {
-					ns = append(ns, server.IP.String())
-				}
+			var format output.OutputFormat
+			switch strings.ToLower(formatFlag) {
+			case "json":
+				format = output.FormatJSON
+			case "csv":
+				format = output.FormatCSV
+			case "xml":
+				format = output.FormatXML
+			default:
+				format = output.FormatTable
 			}
 
-			// Create resolver and bulk processor
-			resolver := dns.NewResolver()
-			processor := dns.NewBulkProcessor(resolver, concurrencyFlag)
+			formatter := output.NewFormatter(format)
+			if err := formatter.FormatReverseDNSResult(result, os.Stdout); err != nil {
+				return err
+			}
 
-			// Set progress callback
-			processor.SetProgressCallback(func(current, total int, domain string, success bool) {
-				status := "✓"
-				if !success {
-					status = "✗"
-				}
-				fmt.Printf("\r[%d/%d] %s %s", current, total, domain, status)
-				if current == total {
-					fmt.Println() // New line after completion
-				}
-			})
+			// Structured formats carry the issues in the result; tables list them below
+			if len(result.Issues) > 0 && format == output.FormatTable {
+				fmt.Fprintln(os.Stdout)
+				return formatter.FormatConsistencyIssues(result.Issues, os.Stdout)
+			}
+			return nil
+		},
+	}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-			defer cancel()
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to query (IP address)")
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+
+	return cmd
+}
 
-			fmt.Printf("Processing %d domains...\n", len(domains))
+// NewSRVCommand creates the srv subcommand
+func NewSRVCommand() *cobra.Command {
+	var (
+		nameserverFlag string
+		formatFlag     string
+	)
 
-			// Process bulk propagation
-			summary, err := processor.ProcessPropagation(ctx, domains, recordType, ns)
+	cmd := &cobra.Command{
+		Use:   "srv [_service._proto] [domain]",
+		Short: "Resolve a service's SRV targets in client selection order",
+		Long: `Query the SRV records of _service._proto.domain and list the targets in the
+order RFC 2782 clients try them: lowest priority first, and within a priority
+in a weighted random order, so heavier targets tend to come first. The
+service and domain may also be given as one name, such as _sip._tcp.example.com.
+A lone "." target is reported as the service being deliberately not offered.`,
+		Example: `  systool srv _sip._tcp example.com
+  systool srv _imaps._tcp.example.com --nameserver 1.1.1.1
+  systool srv _ldap._tcp corp.example.com --format json`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service, proto, domain, err := parseSRVName(args)
+			if err != nil {
+				return err
+			}
+			domain, err = dns.ToASCII(domain)
 			if err != nil {
-				return fmt.Errorf("bulk propagation check failed: %w", err)
+				return err
+			}
+
+			// Get nameserver
+			var ns string
+			if nameserverFlag != "" {
+				ns = nameserverFlag
+			} else {
+				defaultNS := nameservers.GetDefaultNameservers()[0]
+				ns = defaultNS.IP.String()
+			}
+
+			resolver := dns.NewResolver()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			result, err := resolver.ResolveSRV(ctx, service, proto, domain, ns)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return err
 			}
 
-			// Format and display results
 			var format output.OutputFormat
 			switch strings.ToLower(formatFlag) {
 			case "json":
@@ -466,14 +965,542 @@ The file should contain one domain per line.`,
 			}
 
 			formatter := output.NewFormatter(format)
-			return formatter.FormatBulkSummary(summary, os.Stdout)
+			return formatter.FormatSRVResult(result, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to query (IP address)")
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+
+	return cmd
+}
+
+// parseSRVName splits the srv arguments into service, protocol and domain: either
+// "_service._proto" and a domain, or a single "_service._proto.domain" name
+func parseSRVName(args []string) (string, string, string, error) {
+	labels := strings.SplitN(args[0], ".", 3)
+	switch {
+	case len(args) == 2 && len(labels) == 2:
+		return labels[0], labels[1], args[1], nil
+	case len(args) == 1 && len(labels) == 3 && strings.HasPrefix(labels[0], "_") && strings.HasPrefix(labels[1], "_"):
+		return labels[0], labels[1], labels[2], nil
+	}
+	return "", "", "", fmt.Errorf("expected _service._proto and a domain, or _service._proto.domain (e.g., _sip._tcp example.com), got %q", strings.Join(args, " "))
+}
+
+// NewBulkCommand creates the bulk subcommand
+func NewBulkCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bulk",
+		Short: "Perform bulk DNS operations",
+		Long: `Execute DNS operations on multiple domains from a file.
+The file should contain one domain per line, or CSV rows of domain,record_type[,nameserver]
+to override the defaults for that domain; use "-" to read from stdin. With --retry-from,
+omit the file to re-run only the domains that failed in a previous --format json summary.`,
+	}
+
+	// Shared by every bulk subcommand
+	cmd.PersistentFlags().StringP("output", "o", "", "Write results to this file, format from its extension (.json, .jsonl, .csv, .xml) unless --format is given")
+	cmd.PersistentFlags().Bool("force", false, "Overwrite the --output file if it exists")
+	cmd.PersistentFlags().Bool("fail-on-error", false, fmt.Sprintf("Exit with status %d if any domain failed", ExitBulkFailures))
+	cmd.PersistentFlags().BoolP("quiet", "q", false, "Don't show progress on stderr")
+	cmd.PersistentFlags().Bool("summary", false, "Show only the summary counts in table and CSV output (JSON stays full)")
+	cmd.PersistentFlags().String("sort", "", "Order results by domain, duration (slowest first) or status (failures first); streamed JSON lines keep completion order")
+	cmd.PersistentFlags().Int("max-failures", -1, fmt.Sprintf("Exit with status %d if more than this many domains failed", ExitBulkFailures))
+	cmd.PersistentFlags().String("trace-id", "", "Tag each domain's --verbose log lines and JSON result with a correlation ID: auto for a UUID, or a prefix for <prefix>-<uuid>")
+
+	// Add subcommands
+	cmd.AddCommand(NewBulkQueryCommand())
+	cmd.AddCommand(NewBulkPropagationCommand())
+	cmd.AddCommand(NewBulkConsistencyCommand())
+	cmd.AddCommand(NewBulkPTRCommand())
+
+	return cmd
+}
+
+// bulkOutputFormat resolves a bulk command's --format and --stream flags; streaming always
+// writes JSONL, so --stream only combines with the json-style formats. Without --format, an
+// --output file's extension picks the format.
+func bulkOutputFormat(cmd *cobra.Command, formatFlag string, stream bool) (output.OutputFormat, error) {
+	format := strings.ToLower(formatFlag)
+	if path, _ := cmd.Flags().GetString("output"); path != "" && !cmd.Flags().Changed("format") {
+		inferred, err := formatFromExtension(path)
+		if err != nil {
+			return "", err
+		}
+		if stream && inferred != output.FormatJSON && inferred != output.FormatJSONL {
+			return "", fmt.Errorf("--stream writes JSON lines and cannot write %s", path)
+		}
+		format = string(inferred)
+	}
+	if stream {
+		if cmd.Flags().Changed("format") && format != "json" && format != "jsonl" {
+			return "", fmt.Errorf("--stream writes JSON lines and cannot be combined with --format %s", formatFlag)
+		}
+		return output.FormatJSONL, nil
+	}
+
+	switch format {
+	case "json":
+		return output.FormatJSON, nil
+	case "jsonl":
+		return output.FormatJSONL, nil
+	case "csv":
+		return output.FormatCSV, nil
+	case "xml":
+		return output.FormatXML, nil
+	default:
+		return output.FormatTable, nil
+	}
+}
+
+// newBulkProcessor creates the processor for a bulk command from its --concurrency,
+// --per-domain-timeout, --qps, --retry and --trace-id flags. With --concurrency auto the run starts at the flag's default.
+func newBulkProcessor(cmd *cobra.Command, resolver *dns.Resolver) (*dns.BulkProcessor, error) {
+	flag := cmd.Flags().Lookup("concurrency")
+	value := flag.Value.String()
+	adaptive := strings.EqualFold(value, "auto")
+	if adaptive {
+		value = flag.DefValue
+	}
+	concurrency, err := strconv.Atoi(value)
+	if err != nil || concurrency < 1 {
+		return nil, fmt.Errorf("invalid --concurrency %q (use a positive number or auto)", flag.Value.String())
+	}
+
+	processor := dns.NewBulkProcessor(resolver, concurrency)
+	if adaptive {
+		processor.SetAdaptiveConcurrency(dns.AdaptiveMinConcurrency, dns.AdaptiveMaxConcurrency)
+	}
+
+	qps, _ := cmd.Flags().GetFloat64("qps")
+	if qps < 0 {
+		return nil, fmt.Errorf("invalid --qps %v (must not be negative)", qps)
+	}
+	processor.SetQPS(qps)
+
+	retries, _ := cmd.Flags().GetInt("retry")
+	if retries < 0 {
+		return nil, fmt.Errorf("invalid --retry %d (must not be negative)", retries)
+	}
+	processor.SetRetries(retries)
+
+	timeout, _ := cmd.Flags().GetDuration("per-domain-timeout")
+	if timeout < 0 {
+		return nil, fmt.Errorf("invalid --per-domain-timeout %v (must not be negative)", timeout)
+	}
+	processor.SetPerDomainTimeout(timeout)
+
+	traceID, _ := cmd.Flags().GetString("trace-id")
+	if strings.ContainsAny(traceID, " \t\n") {
+		return nil, fmt.Errorf("invalid --trace-id %q (must not contain whitespace)", traceID)
+	}
+	processor.SetTraceIDs(traceID)
+	return processor, nil
+}
+
+// openBulkOutput returns the --output file of a bulk command, or nil to write to stdout
+func openBulkOutput(cmd *cobra.Command) (*outputFile, error) {
+	path, _ := cmd.Flags().GetString("output")
+	if path == "" {
+		return nil, nil
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	return createOutputFile(path, force)
+}
+
+// writeBulkSummary writes the formatted summary to stdout or, with --output, to the file,
+// leaving only the summary counts on stdout
+func writeBulkSummary(formatter *output.Formatter, summary *dns.BulkSummary, out *outputFile) error {
+	if out == nil {
+		return formatter.FormatBulkSummary(summary, os.Stdout)
+	}
+
+	if err := formatter.FormatBulkSummary(summary, out); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	if err := out.Commit(); err != nil {
+		return err
+	}
+	output.WriteBulkSummaryHeader(summary, os.Stdout)
+	fmt.Fprintf(os.Stdout, "💾 Results written to %s\n", out.path)
+	return nil
+}
+
+// failOnBulkFailures returns an ExitError when the run had failures and --fail-on-error is
+// set, or more failures than --max-failures allows. Like failOnIssues it is returned after
+// the summary has been written.
+func failOnBulkFailures(cmd *cobra.Command, summary *dns.BulkSummary) error {
+	failOnError, _ := cmd.Flags().GetBool("fail-on-error")
+	maxFailures, _ := cmd.Flags().GetInt("max-failures")
+
+	var err error
+	switch {
+	case maxFailures >= 0 && summary.Failed > maxFailures:
+		err = fmt.Errorf("%d of %d domain(s) failed, more than --max-failures %d", summary.Failed, summary.TotalDomains, maxFailures)
+	case failOnError && summary.Failed > 0:
+		err = fmt.Errorf("%d of %d domain(s) failed", summary.Failed, summary.TotalDomains)
+	default:
+		return nil
+	}
+
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return &ExitError{Code: ExitBulkFailures, Err: err}
+}
+
+// bulkFilter builds the result filter for --only-failures and --min-severity, or nil when
+// neither is set
+func bulkFilter(onlyFailures bool, minSeverity string) (*dns.BulkFilter, error) {
+	if minSeverity != "" && dns.SeverityRank(minSeverity) < 0 {
+		return nil, fmt.Errorf("invalid --min-severity %q (use high, medium, low or info)", minSeverity)
+	}
+	if !onlyFailures && minSeverity == "" {
+		return nil, nil
+	}
+	return &dns.BulkFilter{OnlyFailures: onlyFailures, MinSeverity: minSeverity}, nil
+}
+
+// readBulkTargets loads a bulk command's targets from the input file named by args[0],
+// expanded with the prefixes in expandFile if set. With retryFrom set, the targets are instead
+// the domains that failed in that previous run's JSON summary, which is returned as well.
+func readBulkTargets(args []string, expandFile, retryFrom string, operation dns.BulkOperation) (*dns.BulkInput, *dns.BulkSummary, error) {
+	if retryFrom != "" {
+		if len(args) > 0 {
+			return nil, nil, fmt.Errorf("--retry-from takes its domains from %s; drop the input file argument", retryFrom)
+		}
+		previous, err := dns.LoadBulkSummary(retryFrom, operation)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load previous run: %w", err)
+		}
+		return &dns.BulkInput{Targets: previous.FailedTargets()}, previous, nil
+	}
+
+	if len(args) == 0 {
+		return nil, nil, fmt.Errorf("requires an input file (or --retry-from)")
+	}
+
+	// Read subdomain prefixes to expand each domain with, if any
+	var prefixes []string
+	if expandFile != "" {
+		var err error
+		prefixes, err = dns.ReadSubdomainPrefixes(expandFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read subdomain prefixes: %w", err)
+		}
+	}
+
+	// Read domains from file
+	input, err := dns.ReadBulkInputFromFile(args[0], prefixes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read domains: %w", err)
+	}
+	return input, nil, nil
+}
+
+// configureBulkOutput reports progress on stderr, unless --quiet, so it never mixes with results
+// on stdout. In JSONL format each result is also written to writer as soon as it completes instead of
+// being held for the summary, skipping those filter rejects and counting the rest in
+// filter.Shown; the returned func reports the first write error.
+func configureBulkOutput(cmd *cobra.Command, processor *dns.BulkProcessor, formatter *output.Formatter, format output.OutputFormat, filter *dns.BulkFilter, writer io.Writer) func() error {
+	if quiet, _ := cmd.Flags().GetBool("quiet"); !quiet {
+		processor.SetProgressCallback(newBulkProgress(os.Stderr).update)
+	}
+
+	var writeErr error
+	if format == output.FormatJSONL {
+		processor.SetResultCallback(func(result dns.BulkResult) {
+			if filter != nil {
+				if !filter.Matches(result) {
+					return
+				}
+				filter.Shown++
+			}
+			if writeErr == nil {
+				writeErr = formatter.FormatBulkResultLine(&result, writer)
+			}
+		})
+	}
+	return func() error { return writeErr }
+}
+
+// bulkRun describes one invocation of a bulk subcommand for runBulk
+type bulkRun struct {
+	input    *dns.BulkInput
+	previous *dns.BulkSummary // Summary being retried with --retry-from, if any
+	noun     string           // What the targets are, for progress output (e.g. "domains")
+	action   string           // Names the run in errors (e.g. "bulk query")
+	timeout  time.Duration    // Deadline for the whole run
+	process  func(ctx context.Context, processor *dns.BulkProcessor) (*dns.BulkSummary, error)
+}
+
+// runBulk runs the pipeline shared by the bulk subcommands: it sets up the processor and
+// output from the shared flags (--format, --stream, --detail, --checkpoint, --rate-limit,
+// --no-cache, --only-failures, --min-severity and the persistent bulk flags), calls
+// run.process, merges a --retry-from summary and writes the results.
+func runBulk(cmd *cobra.Command, run bulkRun) error {
+	flags := cmd.Flags()
+	formatFlag, _ := flags.GetString("format")
+	stream, _ := flags.GetBool("stream")
+	detail, _ := flags.GetBool("detail")
+	checkpoint, _ := flags.GetString("checkpoint")
+	rateLimit, _ := flags.GetString("rate-limit")
+	noCache, _ := flags.GetBool("no-cache")
+	onlyFailures, _ := flags.GetBool("only-failures")
+	minSeverity, _ := flags.GetString("min-severity")
+
+	// Create resolver and bulk processor
+	resolver := dns.NewResolver()
+	processor, err := newBulkProcessor(cmd, resolver)
+	if err != nil {
+		return err
+	}
+
+	format, err := bulkOutputFormat(cmd, formatFlag, stream)
+	if err != nil {
+		return err
+	}
+	filter, err := bulkFilter(onlyFailures, minSeverity)
+	if err != nil {
+		return err
+	}
+	formatter := output.NewFormatter(format)
+	formatter.SetDetail(detail)
+	summaryOnly, _ := flags.GetBool("summary")
+	formatter.SetSummaryOnly(summaryOnly)
+	sortKey, _ := flags.GetString("sort")
+	if err := output.CheckSortKey(sortKey, output.BulkSortKeys); err != nil {
+		return err
+	}
+	formatter.SetSort(sortKey)
+	if run.previous != nil && format == output.FormatJSONL {
+		return fmt.Errorf("--retry-from merges results into one summary and cannot be combined with --stream or --format jsonl")
+	}
+	out, err := openBulkOutput(cmd)
+	if err != nil {
+		return err
+	}
+	var writer io.Writer = os.Stdout
+	if out != nil {
+		defer out.Discard()
+		writer = out
+	}
+	streamErr := configureBulkOutput(cmd, processor, formatter, format, filter, writer)
+	if checkpoint != "" {
+		processor.SetCheckpoint(checkpoint)
+	}
+	// Starts on a new line so a progress bar being redrawn doesn't hide it
+	processor.SetWarningCallback(func(err error) {
+		fmt.Fprintf(os.Stderr, "\n⚠️  %v\n", err)
+	})
+	if !noCache {
+		processor.EnableCache()
+	}
+	if rateLimit != "" {
+		rate, err := dns.ParseRate(rateLimit)
+		if err != nil {
+			return fmt.Errorf("invalid --rate-limit: %w", err)
+		}
+		processor.SetRateLimit(rate)
+	}
+
+	// Create context with timeout; Ctrl+C also stops the run, leaving the checkpoint intact
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, run.timeout)
+	defer cancel()
+
+	if quiet, _ := flags.GetBool("quiet"); !quiet {
+		fmt.Fprintf(os.Stderr, "Processing %d %s...\n", len(run.input.Targets), run.noun)
+	}
+
+	summary, err := run.process(ctx, processor)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", run.action, err)
+	}
+
+	if err := streamErr(); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	if run.previous != nil {
+		summary = dns.MergeRetry(run.previous, summary)
+	} else {
+		summary.Duplicates = run.input.Duplicates
+		summary.Skipped = run.input.Skipped
+	}
+	if filter != nil && format != output.FormatJSONL {
+		summary.ApplyFilter(filter)
+	}
+	summary.Filter = filter
+
+	// Format and display results
+	if err := writeBulkSummary(formatter, summary, out); err != nil {
+		return err
+	}
+	return failOnBulkFailures(cmd, summary)
+}
+
+// NewBulkQueryCommand creates the bulk query subcommand
+func NewBulkQueryCommand() *cobra.Command {
+	var (
+		nameserverFlag string
+		expandFlag     string
+		retryFromFlag  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "query [file] [record-types]",
+		Short: "Perform bulk DNS queries",
+		Long: `Query DNS records for multiple domains from a file.
+The file should contain one domain per line, or CSV rows of domain,record_type[,nameserver]
+to override the defaults for that domain; use "-" to read from stdin. With --retry-from,
+omit the file to re-run only the domains that failed in a previous --format json summary.`,
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: completeRecordTypeArg(1, cobra.ShellCompDirectiveDefault),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			recordTypes := []dns.DNSRecordType{dns.RecordTypeA} // Default to A record
+
+			if len(args) > 1 {
+				var err error
+				recordTypes, err = dns.ParseRecordTypes(args[1])
+				if err != nil {
+					return err
+				}
+			}
+
+			// Read domains from file, or the failures of a previous run
+			input, previous, err := readBulkTargets(args, expandFlag, retryFromFlag, dns.BulkOperationQuery)
+			if err != nil {
+				return err
+			}
+			if previous != nil && len(previous.RecordTypes) > 0 {
+				recordTypes = previous.RecordTypes
+			}
+
+			// Get nameserver
+			var ns []string
+			if nameserverFlag != "" {
+				ns = []string{nameserverFlag}
+			} else if previous != nil && len(previous.Nameservers) > 0 {
+				ns = previous.Nameservers
+			} else {
+				defaultNS := nameservers.GetDefaultNameservers()[0]
+				ns = []string{defaultNS.IP.String()}
+			}
+
+			return runBulk(cmd, bulkRun{
+				input:    input,
+				previous: previous,
+				noun:     "domains",
+				action:   "bulk query",
+				timeout:  5 * time.Minute,
+				process: func(ctx context.Context, processor *dns.BulkProcessor) (*dns.BulkSummary, error) {
+					return processor.ProcessQuery(ctx, input.Targets, recordTypes, ns)
+				},
+			})
+		},
+	}
+
+	// Add flags
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to query (IP address)")
+	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, csv, xml, jsonl)")
+	cmd.Flags().Bool("stream", false, "Write each result as a JSON line as soon as it completes (same as --format jsonl)")
+	cmd.Flags().Bool("detail", false, "Include per-domain results (records, server agreement, issue counts) in table and CSV output")
+	cmd.Flags().String("checkpoint", "", "Save progress to this file and resume from it when rerun (e.g., state.json)")
+	cmd.Flags().StringVar(&expandFlag, "expand-subdomains", "", "Also query each domain with the subdomain prefixes listed in this file (one per line); required for *.domain rows")
+	cmd.Flags().Bool("only-failures", false, "Only show domains that failed; summary counts still cover the whole run")
+	cmd.Flags().StringVar(&retryFromFlag, "retry-from", "", "Re-run only the domains that failed in this JSON summary from a previous run, merging the results")
+	cmd.Flags().Bool("no-cache", false, "Send every query instead of reusing answers still within their TTL")
+	cmd.Flags().String("rate-limit", "", "Maximum queries per nameserver, regardless of concurrency (e.g., 20/s, 600/m)")
+	cmd.Flags().StringP("concurrency", "c", "5", fmt.Sprintf("Number of concurrent queries, or auto to adjust between %d and %d based on the error rate", dns.AdaptiveMinConcurrency, dns.AdaptiveMaxConcurrency))
+	cmd.Flags().Duration("per-domain-timeout", 0, "Give up on a domain after this long (e.g., 30s); 0 waits until the run's deadline")
+	cmd.Flags().Int("retry", 0, "Re-attempt failed domains up to this many times once the first pass is done")
+	cmd.Flags().Float64("qps", 0, "Maximum queries per second for the whole run, across all workers and nameservers (0 for no limit)")
+
+	return cmd
+}
+
+// NewBulkPropagationCommand creates the bulk propagation subcommand
+func NewBulkPropagationCommand() *cobra.Command {
+	var (
+		providerFlag  string
+		transportFlag string
+		expandFlag    string
+		retryFromFlag string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "propagation [file] [record-type]",
+		Short: "Check DNS propagation for multiple domains",
+		Long: `Check DNS propagation status for multiple domains from a file.
+The file should contain one domain per line, or CSV rows of domain,record_type[,nameserver]
+to override the defaults for that domain; use "-" to read from stdin. With --retry-from,
+omit the file to re-run only the domains that failed in a previous --format json summary.`,
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: completeRecordTypeArg(1, cobra.ShellCompDirectiveDefault),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			recordType := dns.RecordTypeA // Default to A record
+
+			if len(args) > 1 {
+				recordType = dns.DNSRecordType(strings.ToUpper(args[1]))
+			}
+
+			// Read domains from file, or the failures of a previous run
+			input, previous, err := readBulkTargets(args, expandFlag, retryFromFlag, dns.BulkOperationPropagation)
+			if err != nil {
+				return err
+			}
+			if previous != nil && len(previous.RecordTypes) > 0 {
+				recordType = previous.RecordTypes[0]
+			}
+
+			// Get nameservers
+			transport, err := parseTransport(transportFlag)
+			if err != nil {
+				return err
+			}
+			ns, err := providerNameservers(providerFlag, transport)
+			if err != nil {
+				return err
+			}
+
+			if len(ns) == 0 && previous != nil {
+				ns = previous.Nameservers
+			}
+			if len(ns) == 0 {
+				// Use default nameservers
+				ns = catalogNameservers(nameservers.GetDefaultNameservers(), transport)
+			}
+
+			return runBulk(cmd, bulkRun{
+				input:    input,
+				previous: previous,
+				noun:     "domains",
+				action:   "bulk propagation check",
+				timeout:  10 * time.Minute,
+				process: func(ctx context.Context, processor *dns.BulkProcessor) (*dns.BulkSummary, error) {
+					return processor.ProcessPropagation(ctx, input.Targets, recordType, ns)
+				},
+			})
 		},
 	}
 
 	// Add flags
 	cmd.Flags().StringVarP(&providerFlag, "providers", "p", "", "DNS providers to check (comma-separated: google,cloudflare,quad9,opendns) or 'all' for all providers")
-	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
-	cmd.Flags().IntVarP(&concurrencyFlag, "concurrency", "c", 3, "Number of concurrent checks")
+	cmd.Flags().StringVar(&transportFlag, "transport", transportUDP, "Query providers over udp or doh (DNS-over-HTTPS, providers with a DoH endpoint only)")
+	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, csv, xml, jsonl)")
+	cmd.Flags().Bool("stream", false, "Write each result as a JSON line as soon as it completes (same as --format jsonl)")
+	cmd.Flags().Bool("detail", false, "Include per-domain results (records, server agreement, issue counts) in table and CSV output")
+	cmd.Flags().String("checkpoint", "", "Save progress to this file and resume from it when rerun (e.g., state.json)")
+	cmd.Flags().StringVar(&expandFlag, "expand-subdomains", "", "Also query each domain with the subdomain prefixes listed in this file (one per line); required for *.domain rows")
+	cmd.Flags().Bool("only-failures", false, "Only show domains that failed; summary counts still cover the whole run")
+	cmd.Flags().StringVar(&retryFromFlag, "retry-from", "", "Re-run only the domains that failed in this JSON summary from a previous run, merging the results")
+	cmd.Flags().Bool("no-cache", false, "Send every query instead of reusing answers still within their TTL")
+	cmd.Flags().String("rate-limit", "", "Maximum queries per nameserver, regardless of concurrency (e.g., 20/s, 600/m)")
+	cmd.Flags().StringP("concurrency", "c", "3", fmt.Sprintf("Number of concurrent checks, or auto to adjust between %d and %d based on the error rate", dns.AdaptiveMinConcurrency, dns.AdaptiveMaxConcurrency))
+	cmd.Flags().Duration("per-domain-timeout", 0, "Give up on a domain after this long (e.g., 30s); 0 waits until the run's deadline")
+	cmd.Flags().Int("retry", 0, "Re-attempt failed domains up to this many times once the first pass is done")
+	cmd.Flags().Float64("qps", 0, "Maximum queries per second for the whole run, across all workers and nameservers (0 for no limit)")
 
 	return cmd
 }
@@ -481,105 +1508,159 @@ The file should contain one domain per line.`,
 // NewBulkConsistencyCommand creates the bulk consistency subcommand
 func NewBulkConsistencyCommand() *cobra.Command {
 	var (
-		providerFlag    string
-		formatFlag      string
-		concurrencyFlag int
+		providerFlag  string
+		transportFlag string
+		expandFlag    string
+		retryFromFlag string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "consistency [file]",
 		Short: "Check DNS consistency for multiple domains",
 		Long: `Check DNS consistency for multiple domains from a file.
-The file should contain one domain per line.`,
-		Args: cobra.ExactArgs(1),
+The file should contain one domain per line, or CSV rows of domain,record_type[,nameserver]
+to override the defaults for that domain; use "-" to read from stdin. With --retry-from,
+omit the file to re-run only the domains that failed in a previous --format json summary.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			filename := args[0]
-
-			// Read domains from file
-			domains, err := dns.ReadDomainsFromFile(filename)
+			// Read domains from file, or the failures of a previous run
+			input, previous, err := readBulkTargets(args, expandFlag, retryFromFlag, dns.BulkOperationConsistency)
 			if err != nil {
-				return fmt.Errorf("failed to read domains: %w", err)
+				return err
 			}
 
 			// Get nameservers
-			var ns []string
-			if providerFlag != "" {
-				if strings.TrimSpace(strings.ToLower(providerFlag)) == "all" {
-					// Use all available nameservers
-					allServers := nameservers.GetAllNameservers()
-					for _, server := range allServers {
-						ns = append(ns, server.IP.String())
-					}
-				} else {
-					providers := strings.Split(providerFlag, ",")
-					for _, provider := range providers {
-						provider = strings.TrimSpace(provider)
-						servers := nameservers.GetProviderNameservers(provider)
-						for _, server := range servers {
-							ns = append(ns, server.IP.String())
-						}
-					}
-				}
+			transport, err := parseTransport(transportFlag)
+			if err != nil {
+				return err
+			}
+			ns, err := providerNameservers(providerFlag, transport)
+			if err != nil {
+				return err
 			}
 
+			if len(ns) == 0 && previous != nil {
+				ns = previous.Nameservers
+			}
 			if len(ns) == 0 {
 				// Use all nameservers for comprehensive check
-				allServers := nameservers.GetAllNameservers()
-				for _, server := range allServers {
-					ns = append(ns, server.IP.String())
-				}
+				ns = catalogNameservers(nameservers.GetAllNameservers(), transport)
 			}
 
-			// Create resolver and bulk processor
-			resolver := dns.NewResolver()
-			processor := dns.NewBulkProcessor(resolver, concurrencyFlag)
-
-			// Set progress callback
-			processor.SetProgressCallback(func(current, total int, domain string, success bool) {
-				status := "✓"
-				if !success {
-					status = "✗"
-				}
-				fmt.Printf("\r[%d/%d] %s %s", current, total, domain, status)
-				if current == total {
-					fmt.Println() // New line after completion
-				}
+			return runBulk(cmd, bulkRun{
+				input:    input,
+				previous: previous,
+				noun:     "domains",
+				action:   "bulk consistency check",
+				timeout:  15 * time.Minute,
+				process: func(ctx context.Context, processor *dns.BulkProcessor) (*dns.BulkSummary, error) {
+					return processor.ProcessConsistency(ctx, input.Targets, ns)
+				},
 			})
+		},
+	}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
-			defer cancel()
+	// Add flags
+	cmd.Flags().StringVarP(&providerFlag, "providers", "p", "", "DNS providers to check (comma-separated: google,cloudflare,quad9,opendns) or 'all' for all providers")
+	cmd.Flags().StringVar(&transportFlag, "transport", transportUDP, "Query providers over udp or doh (DNS-over-HTTPS, providers with a DoH endpoint only)")
+	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, csv, xml, jsonl)")
+	cmd.Flags().Bool("stream", false, "Write each result as a JSON line as soon as it completes (same as --format jsonl)")
+	cmd.Flags().Bool("detail", false, "Include per-domain results (records, server agreement, issue counts) in table and CSV output")
+	cmd.Flags().String("checkpoint", "", "Save progress to this file and resume from it when rerun (e.g., state.json)")
+	cmd.Flags().StringVar(&expandFlag, "expand-subdomains", "", "Also query each domain with the subdomain prefixes listed in this file (one per line); required for *.domain rows")
+	cmd.Flags().String("min-severity", "", "Only show domains with an issue at or above this severity (high, medium, low, info), plus failures")
+	cmd.Flags().Bool("only-failures", false, "Only show domains that failed; summary counts still cover the whole run")
+	cmd.Flags().StringVar(&retryFromFlag, "retry-from", "", "Re-run only the domains that failed in this JSON summary from a previous run, merging the results")
+	cmd.Flags().Bool("no-cache", false, "Send every query instead of reusing answers still within their TTL")
+	cmd.Flags().String("rate-limit", "", "Maximum queries per nameserver, regardless of concurrency (e.g., 20/s, 600/m)")
+	cmd.Flags().StringP("concurrency", "c", "2", fmt.Sprintf("Number of concurrent checks, or auto to adjust between %d and %d based on the error rate", dns.AdaptiveMinConcurrency, dns.AdaptiveMaxConcurrency))
+	cmd.Flags().Duration("per-domain-timeout", 0, "Give up on a domain after this long (e.g., 30s); 0 waits until the run's deadline")
+	cmd.Flags().Int("retry", 0, "Re-attempt failed domains up to this many times once the first pass is done")
+	cmd.Flags().Float64("qps", 0, "Maximum queries per second for the whole run, across all workers and nameservers (0 for no limit)")
+
+	return cmd
+}
 
-			fmt.Printf("Processing %d domains...\n", len(domains))
+// NewBulkPTRCommand creates the bulk ptr subcommand
+func NewBulkPTRCommand() *cobra.Command {
+	var (
+		nameserverFlag string
+		retryFromFlag  string
+		yesFlag        bool
+	)
 
-			// Process bulk consistency
-			summary, err := processor.ProcessConsistency(ctx, domains, ns)
-			if err != nil {
-				return fmt.Errorf("bulk consistency check failed: %w", err)
+	cmd := &cobra.Command{
+		Use:   "ptr [file]",
+		Short: "Look up hostnames for a list of IP addresses",
+		Long: `Look up the PTR records of IPv4 and IPv6 addresses from a file.
+The file should contain one address or CIDR range per line (the first field of CSV rows is
+used); use "-" to read from stdin. Ranges are expanded into every address they contain, and
+inputs of more than 65536 addresses need --yes. Addresses without a PTR record are reported
+as failures. With --retry-from, omit the file to re-run only the addresses that failed in a
+previous --format json summary.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Read addresses from file, or the failures of a previous run
+			var input *dns.BulkInput
+			var previous *dns.BulkSummary
+			var err error
+			if retryFromFlag != "" {
+				input, previous, err = readBulkTargets(args, "", retryFromFlag, dns.BulkOperationPTR)
+				if err != nil {
+					return err
+				}
+			} else {
+				if len(args) == 0 {
+					return fmt.Errorf("requires an input file (or --retry-from)")
+				}
+				limit := dns.DefaultMaxPTRAddresses
+				if yesFlag {
+					limit = dns.MaxPTRAddresses
+				}
+				input, err = dns.ReadIPInputFromFile(args[0], limit)
+				if err != nil {
+					return fmt.Errorf("failed to read addresses: %w", err)
+				}
 			}
 
-			// Format and display results
-			var format output.OutputFormat
-			switch strings.ToLower(formatFlag) {
-			case "json":
-				format = output.FormatJSON
-			case "csv":
-				format = output.FormatCSV
-			case "xml":
-				format = output.FormatXML
-			default:
-				format = output.FormatTable
+			// Get nameserver
+			var ns []string
+			if nameserverFlag != "" {
+				ns = []string{nameserverFlag}
+			} else if previous != nil && len(previous.Nameservers) > 0 {
+				ns = previous.Nameservers
+			} else {
+				defaultNS := nameservers.GetDefaultNameservers()[0]
+				ns = []string{defaultNS.IP.String()}
 			}
 
-			formatter := output.NewFormatter(format)
-			return formatter.FormatBulkSummary(summary, os.Stdout)
+			return runBulk(cmd, bulkRun{
+				input:    input,
+				previous: previous,
+				noun:     "addresses",
+				action:   "bulk ptr lookup",
+				timeout:  5 * time.Minute,
+				process: func(ctx context.Context, processor *dns.BulkProcessor) (*dns.BulkSummary, error) {
+					return processor.ProcessPTR(ctx, input.Targets, ns)
+				},
+			})
 		},
 	}
 
 	// Add flags
-	cmd.Flags().StringVarP(&providerFlag, "providers", "p", "", "DNS providers to check (comma-separated: google,cloudflare,quad9,opendns) or 'all' for all providers")
-	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
-	cmd.Flags().IntVarP(&concurrencyFlag, "concurrency", "c", 2, "Number of concurrent checks")
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to query (IP address)")
+	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, csv, xml, jsonl)")
+	cmd.Flags().Bool("stream", false, "Write each result as a JSON line as soon as it completes (same as --format jsonl)")
+	cmd.Flags().String("checkpoint", "", "Save progress to this file and resume from it when rerun (e.g., state.json)")
+	cmd.Flags().BoolVar(&yesFlag, "yes", false, fmt.Sprintf("Allow inputs that expand to more than %d addresses (up to %d)", dns.DefaultMaxPTRAddresses, dns.MaxPTRAddresses))
+	cmd.Flags().Bool("only-failures", false, "Only show addresses that failed; summary counts still cover the whole run")
+	cmd.Flags().StringVar(&retryFromFlag, "retry-from", "", "Re-run only the addresses that failed in this JSON summary from a previous run, merging the results")
+	cmd.Flags().Bool("no-cache", false, "Send every query instead of reusing answers still within their TTL")
+	cmd.Flags().String("rate-limit", "", "Maximum queries per nameserver, regardless of concurrency (e.g., 20/s, 600/m)")
+	cmd.Flags().StringP("concurrency", "c", "10", fmt.Sprintf("Number of concurrent lookups, or auto to adjust between %d and %d based on the error rate", dns.AdaptiveMinConcurrency, dns.AdaptiveMaxConcurrency))
+	cmd.Flags().Duration("per-domain-timeout", 0, "Give up on an address after this long (e.g., 30s); 0 waits until the run's deadline")
+	cmd.Flags().Int("retry", 0, "Re-attempt failed addresses up to this many times once the first pass is done")
+	cmd.Flags().Float64("qps", 0, "Maximum queries per second for the whole run, across all workers and nameservers (0 for no limit)")
 
 	return cmd
 }
@@ -0,0 +1,105 @@
+// =============================================================================
+// internal/cli/completion.go - Shell completion for flag values and arguments
+// =============================================================================
+package cli
+
+import (
+	"strings"
+
+	"github.com/bryanCE/sysadmin/internal/dns"
+	"github.com/bryanCE/sysadmin/pkg/nameservers"
+	"github.com/spf13/cobra"
+)
+
+// outputFormats are the values accepted by every --format flag
+var outputFormats = []string{"table", "json", "csv", "xml"}
+
+// completableRecordTypes are offered when completing a record-type argument
+var completableRecordTypes = []dns.DNSRecordType{
+	dns.RecordTypeA, dns.RecordTypeAAAA, dns.RecordTypeCNAME, dns.RecordTypeMX, dns.RecordTypeNS,
+	dns.RecordTypeTXT, dns.RecordTypeSOA, dns.RecordTypePTR, dns.RecordTypeSRV, dns.RecordTypeCAA,
+}
+
+// RegisterCompletions walks the command tree and attaches value completion to the
+// --format, --providers, check-selection and severity flags wherever they are defined
+func RegisterCompletions(cmd *cobra.Command) {
+	if cmd.LocalFlags().Lookup("format") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(outputFormats, cobra.ShellCompDirectiveNoFileComp))
+	}
+	if cmd.LocalFlags().Lookup("providers") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("providers", completeProviders)
+	}
+	if cmd.LocalFlags().Lookup("transport") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("transport", cobra.FixedCompletions([]string{"udp", "doh"}, cobra.ShellCompDirectiveNoFileComp))
+	}
+	if cmd.LocalFlags().Lookup("checks") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("checks", completeList(func() []string {
+			return append([]string{"all"}, dns.ConsistencyCheckNames()...)
+		}))
+	}
+	if cmd.LocalFlags().Lookup("skip-checks") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("skip-checks", completeList(dns.ConsistencyCheckNames))
+	}
+	if cmd.LocalFlags().Lookup("fail-on") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("fail-on", cobra.FixedCompletions([]string{"high", "medium", "low"}, cobra.ShellCompDirectiveNoFileComp))
+	}
+	if cmd.LocalFlags().Lookup("min-severity") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("min-severity", cobra.FixedCompletions([]string{"high", "medium", "low", "info"}, cobra.ShellCompDirectiveNoFileComp))
+	}
+
+	for _, child := range cmd.Commands() {
+		RegisterCompletions(child)
+	}
+}
+
+// completeProviders completes the last entry of a comma-separated provider list
+func completeProviders(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions, directive := completeList(nameservers.ProviderNames)(cmd, args, toComplete)
+	if !strings.Contains(toComplete, ",") {
+		completions = append([]string{"all"}, completions...)
+	}
+	return completions, directive
+}
+
+// completeList completes the last entry of a comma-separated list drawn from names,
+// leaving out entries already given
+func completeList(names func() []string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		prefix := ""
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix = toComplete[:i+1]
+		}
+
+		chosen := make(map[string]bool)
+		for _, name := range strings.Split(prefix, ",") {
+			chosen[strings.TrimSpace(name)] = true
+		}
+
+		var completions []string
+		for _, name := range names() {
+			if !chosen[name] {
+				completions = append(completions, prefix+name)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// completeRecordTypeArg completes the record type when it is the argument at position index;
+// earlier arguments get the given directive (file completion for input files, none for domains)
+func completeRecordTypeArg(index int, before cobra.ShellCompDirective) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch {
+		case len(args) < index:
+			return nil, before
+		case len(args) == index:
+			completions := make([]string, len(completableRecordTypes))
+			for i, recordType := range completableRecordTypes {
+				completions[i] = string(recordType)
+			}
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+}
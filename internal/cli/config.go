@@ -0,0 +1,117 @@
+// =============================================================================
+// internal/cli/config.go - Config file defaults for command flags
+// =============================================================================
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// DefaultConfigFile is looked up in the user's home directory when --config is not given
+const DefaultConfigFile = ".systool.yaml"
+
+// configKeys are the settings a config file may provide, mapped to the flag they default
+var configKeys = map[string]string{
+	"format":      "format",
+	"nameserver":  "nameserver",
+	"providers":   "providers",
+	"timeout":     "timeout",
+	"concurrency": "concurrency",
+}
+
+// Config holds flag defaults read from a config file, keyed by flag name
+type Config map[string]string
+
+// DefaultConfigPath returns ~/.systool.yaml, or "" if the home directory is unknown
+func DefaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, DefaultConfigFile)
+}
+
+// LoadConfig reads a config file. A missing file yields an empty config unless required is set.
+func LoadConfig(path string, required bool) (Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	config, err := ParseConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ParseConfig parses flat "key: value" YAML. Comments, blank lines and quoted values are
+// supported; nested structures are not.
+func ParseConfig(r io.Reader) (Config, error) {
+	config := Config{}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNumber)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		flag, known := configKeys[key]
+		if !known {
+			return nil, fmt.Errorf("line %d: unknown setting %q", lineNumber, key)
+		}
+
+		value = strings.TrimSpace(value)
+		if comment := strings.Index(value, " #"); comment >= 0 {
+			value = strings.TrimSpace(value[:comment])
+		}
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		config[flag] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Apply sets each configured value on cmd's flags that exist and were not given on the
+// command line, so explicit flags always win over the config file
+func (c Config) Apply(cmd *cobra.Command) error {
+	var applyErr error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		value, ok := c[flag.Name]
+		if !ok || flag.Changed || applyErr != nil {
+			return
+		}
+		if err := flag.Value.Set(value); err != nil {
+			applyErr = fmt.Errorf("invalid %s %q in config file: %w", flag.Name, value, err)
+		}
+	})
+	return applyErr
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/bryanCE/sysadmin/internal/dns"
 	"github.com/bryanCE/sysadmin/internal/dnssec"
 	"github.com/bryanCE/sysadmin/internal/output"
 	"github.com/spf13/cobra"
@@ -18,16 +19,21 @@ func NewDNSSECVerifyCommand() *cobra.Command {
 	var (
 		nameserverFlag string
 		formatFlag     string
+		fullChainFlag  bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "dnssec [domain]",
 		Short: "Verify DNSSEC configuration",
 		Long: `Perform comprehensive DNSSEC validation for a domain.
-Checks DS records, DNSKEY records, and validates the chain of trust.`,
+Checks DS records, DNSKEY records, and validates the chain of trust.
+With --full-chain every delegation from the root down to the domain is validated.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			domain := args[0]
+			domain, err := dns.ToASCII(args[0])
+			if err != nil {
+				return err
+			}
 
 			// Use default nameserver if not specified
 			if nameserverFlag == "" {
@@ -35,7 +41,11 @@ Checks DS records, DNSKEY records, and validates the chain of trust.`,
 			}
 
 			// Verify DNSSEC
-			result, err := dnssec.VerifyDNSSEC(domain, nameserverFlag)
+			verify := dnssec.VerifyDNSSEC
+			if fullChainFlag {
+				verify = dnssec.VerifyDNSSECChain
+			}
+			result, err := verify(domain, nameserverFlag)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				return err
@@ -62,6 +72,7 @@ Checks DS records, DNSKEY records, and validates the chain of trust.`,
 	// Add flags
 	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to query (IP address)")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().BoolVar(&fullChainFlag, "full-chain", false, "Validate every zone from the root down to the domain")
 
 	return cmd
 }
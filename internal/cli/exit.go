@@ -0,0 +1,26 @@
+// =============================================================================
+// internal/cli/exit.go - Exit codes for commands that fail on their findings
+// =============================================================================
+package cli
+
+// Exit codes beyond the generic failure (1) that cobra errors produce
+const (
+	ExitIssuesFound  = 2 // --fail-on threshold reached
+	ExitBulkFailures = 3 // --fail-on-error or --max-failures threshold reached by a bulk run
+	ExitScanChanges  = 4 // network diff found hosts or ports that changed between the scans
+)
+
+// ExitError asks main to exit with Code after printing Err. Commands return it once their
+// output has been written, so it reports a finding rather than a failure to run.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
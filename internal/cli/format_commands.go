@@ -0,0 +1,79 @@
+// =============================================================================
+// internal/cli/format_commands.go - Re-formatting saved results
+// =============================================================================
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bryanCE/sysadmin/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// NewFormatCommand creates the format subcommand
+func NewFormatCommand() *cobra.Command {
+	var (
+		typeFlag   string
+		fromFlag   string
+		formatFlag string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "format",
+		Short: "Re-format a saved JSON result",
+		Long: `Read a result saved earlier with --format json and print it in another format,
+without repeating the lookups or scans. --type names the command that produced it:
+` + strings.Join(output.SavedResultTypes(), ", ") + `.
+
+Use multiquery for query results with several record types, ssl-all-ips for
+ssl-check --all-ips and hostresult for a single-host portscan. For bulk, the
+file may also be a --stream JSONL file; its results have no start times.`,
+		Example: `  systool format --type scanresult --from result.json -f csv
+  systool format --type bulk --from run.jsonl
+  systool query example.com MX -f json | systool format --type query -f xml`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				data []byte
+				err  error
+			)
+			if fromFlag == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(fromFlag)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read saved result: %w", err)
+			}
+
+			var format output.OutputFormat
+			switch strings.ToLower(formatFlag) {
+			case "json":
+				format = output.FormatJSON
+			case "jsonl":
+				format = output.FormatJSONL
+			case "csv":
+				format = output.FormatCSV
+			case "xml":
+				format = output.FormatXML
+			default:
+				format = output.FormatTable
+			}
+
+			formatter := output.NewFormatter(format)
+			return formatter.FormatSaved(typeFlag, data, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVar(&typeFlag, "type", "", "Type of the saved result ("+strings.Join(output.SavedResultTypes(), ", ")+")")
+	cmd.Flags().StringVar(&fromFlag, "from", "-", "File holding the saved JSON result (- for stdin)")
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml, jsonl)")
+	_ = cmd.MarkFlagRequired("type")
+	_ = cmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(output.SavedResultTypes(), cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
@@ -4,14 +4,27 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
 	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/bryanCE/sysadmin/internal/dns"
 	"github.com/bryanCE/sysadmin/internal/network"
 	"github.com/bryanCE/sysadmin/internal/output"
+	"github.com/bryanCE/sysadmin/pkg/nameservers"
 	"github.com/spf13/cobra"
 )
 
@@ -30,6 +43,9 @@ Includes ping sweeps, port scanning, and network discovery functionality.`,
 	cmd.AddCommand(NewDiscoveryCommand())
 	cmd.AddCommand(NewWorkerPoolDiscoveryCommand())
 	cmd.AddCommand(NewMonitorCommand())
+	cmd.AddCommand(NewScanDiffCommand())
+	cmd.AddCommand(NewScanHistoryCommand())
+	cmd.AddCommand(NewTracerouteCommand())
 
 	return cmd
 }
@@ -40,17 +56,64 @@ func NewPingSweepCommand() *cobra.Command {
 		formatFlag      string
 		timeoutFlag     string
 		concurrencyFlag int
+		pingMethodFlag  string
+		maxHostsFlag    int
+		countFlag       int
+		excludeFlag     string
+		excludeFileFlag string
+		summaryFlag     bool
+		sortFlag        string
+		rateFlag        int
+		dbFlag          string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "ping [network]",
 		Short: "Perform ping sweep to discover live hosts",
-		Long: `Discover live hosts on a network using TCP ping sweep.
-Uses multiple common ports for faster and more reliable host discovery.
+		Long: `Discover live hosts on a network using ICMP echo and TCP connects.
+
+--ping-method selects the probe:
+  icmp  ICMP echo only (raw socket as root/CAP_NET_RAW, otherwise an
+        unprivileged ICMP socket where the OS allows it)
+  tcp   TCP connects to common ports (22, 80, 443, ...)
+  arp   ARP for addresses on a local subnet, which finds firewalled hosts
+        that ignore ICMP and TCP; other addresses are probed with TCP
+  auto  ICMP and TCP, plus ARP on local subnets, reporting hosts that
+        answered only one of them; falls back to TCP when no ICMP socket
+        can be opened (default)
+
+ICMP echo and ARP reach IPv4 addresses only. ARP needs no privileges: each
+local address is sent a UDP datagram and the OS neighbor table is read
+afterwards. Hosts on a local subnet are listed with their MAC address and
+the vendor it was assigned to.
+
+With --count above 1, each live host is probed that many times (using ICMP
+when it answered ICMP) and the results show min/avg/max latency, jitter and
+loss, which helps spot flaky hosts and links.
+
+The network may be a CIDR prefix (IPv4 or IPv6), a range such as
+192.168.1.10-192.168.1.50, a single address, or a comma-separated list of
+these. IPv4 prefixes skip their network and broadcast addresses. Targets
+larger than --max-hosts addresses are refused.
+
+--exclude and --exclude-file skip addresses, ranges or CIDR prefixes inside
+the network, such as gateways or hosts you are not allowed to probe.
+
+--rate caps the ICMP, TCP and ARP probes sent per second across all hosts,
+to stay under IDS thresholds or spare fragile devices; the achieved rate is
+shown with the results.
 
 Examples:
   systool network ping 192.168.1.0/24
-  systool network ping 10.0.0.0/24 --timeout 5s`,
+  systool network ping 10.0.0.0/24 --timeout 5s
+  systool network ping 10.0.0.0/24 --format json
+  systool network ping 10.0.0.0/24 --ping-method tcp
+  systool network ping 192.168.1.0/24 --ping-method arp
+  systool network ping 10.0.0.0/24 --count 5
+  systool network ping 192.168.1.10-192.168.1.50,192.168.2.0/28
+  systool network ping 2001:db8::/112 --ping-method tcp
+  systool network ping 10.0.0.0/24 --exclude 10.0.0.1,10.0.0.254
+  systool network ping 10.0.0.0/24 --rate 100`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			networkCIDR := args[0]
@@ -65,40 +128,89 @@ Examples:
 				}
 			}
 
+			if countFlag < 1 {
+				return fmt.Errorf("--count must be at least 1")
+			}
+			if err := output.CheckSortKey(sortFlag, output.PingSortKeys); err != nil {
+				return err
+			}
+
+			switch pingMethodFlag {
+			case network.PingMethodAuto, network.PingMethodICMP, network.PingMethodTCP, network.PingMethodARP:
+			default:
+				return fmt.Errorf("invalid ping method %q (use icmp, tcp, arp or auto)", pingMethodFlag)
+			}
+
+			if rateFlag < 0 {
+				return fmt.Errorf("--rate must not be negative")
+			}
+
 			// Create scanner with optimized settings
 			scanner := network.NewScanner()
 			scanner.SetTimeout(timeout)
+			scanner.SetRateLimit(rateFlag)
+			scanner.SetMaxHosts(maxHostsFlag)
+			exclude, err := loadExcludes(excludeFlag, excludeFileFlag)
+			if err != nil {
+				return err
+			}
+			scanner.SetExclude(exclude)
+			scanner.SetPingMethod(pingMethodFlag)
+			scanner.SetPingCount(countFlag)
 			if concurrencyFlag > 0 {
 				scanner.SetConcurrency(concurrencyFlag, 5000)
 			}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			history, err := openScanHistory(dbFlag)
+			if err != nil {
+				return err
+			}
+			if history != nil {
+				defer history.Close()
+			}
+
+			// Create context with timeout; Ctrl+C also stops the scan, keeping what was found so far
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 			defer cancel()
 
-			fmt.Printf("🔍 Ping sweep on network: %s\n", networkCIDR)
+			// Progress goes to stderr so it never mixes with JSON/CSV/XML on stdout
+			fmt.Fprintf(os.Stderr, "🔍 Ping sweep on network: %s\n", networkCIDR)
+			scanner.SetProgressCallback(printScanProgress("hosts"))
 
 			// Perform ping sweep
 			result, err := scanner.PingSweep(ctx, networkCIDR)
-			if err != nil {
+			if result == nil {
 				return fmt.Errorf("ping sweep failed: %w", err)
 			}
 
-			// Display results
-			fmt.Printf("\n✅ Batch scan completed in %v\n", result.Duration)
-
-			for _, host := range result.Hosts {
-				fmt.Printf("🟢 %-15s (%.2fms)\n", host.IP, float64(host.Latency.Nanoseconds())/1000000)
+			formatter := output.NewFormatter(output.OutputFormat(formatFlag))
+			formatter.SetSummaryOnly(summaryFlag)
+			formatter.SetSort(sortFlag)
+			if err := formatter.FormatScanResult(result, os.Stdout); err != nil {
+				return err
 			}
-
-			return nil
+			if err := recordScanHistory(history, "ping", result); err != nil {
+				return err
+			}
+			return scanInterrupted(cmd, err)
 		},
 	}
 
 	// Add flags
+	cmd.Flags().BoolVar(&summaryFlag, "summary", false, "Show only the summary counts in table and CSV output (JSON stays full)")
+	cmd.Flags().StringVar(&sortFlag, "sort", "", "Order hosts by ip, hostname or latency")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().StringVar(&dbFlag, "db", "", "Also record the live hosts and open ports in this SQLite scan history (e.g., scans.db)")
 	cmd.Flags().StringVarP(&timeoutFlag, "timeout", "t", "1s", "Connection timeout (e.g., 1s, 500ms)")
+	cmd.Flags().IntVar(&rateFlag, "rate", 0, "Maximum probes per second across all hosts (0 for no limit)")
 	cmd.Flags().IntVarP(&concurrencyFlag, "concurrency", "c", 500, "Number of concurrent hosts to scan")
+	cmd.Flags().StringVar(&pingMethodFlag, "ping-method", network.PingMethodAuto, "Host detection method (icmp, tcp, arp, auto)")
+	cmd.Flags().IntVar(&countFlag, "count", 1, "Probes per live host; above 1 adds min/avg/max/jitter statistics")
+	cmd.Flags().IntVar(&maxHostsFlag, "max-hosts", network.DefaultMaxHosts, "Maximum number of addresses the network may expand to")
+	cmd.Flags().StringVar(&excludeFlag, "exclude", "", "Comma-separated addresses, ranges or CIDR prefixes to skip")
+	cmd.Flags().StringVar(&excludeFileFlag, "exclude-file", "", "File of addresses, ranges or CIDR prefixes to skip, one per line")
 
 	return cmd
 }
@@ -106,31 +218,102 @@ Examples:
 // NewPortScanCommand creates the port scan subcommand
 func NewPortScanCommand() *cobra.Command {
 	var (
-		formatFlag      string
-		timeoutFlag     string
-		concurrencyFlag int
+		formatFlag       string
+		timeoutFlag      string
+		concurrencyFlag  int
+		udpFlag          bool
+		targetsFileFlag  string
+		showClosedFlag   bool
+		showFilteredFlag bool
+		summaryFlag      bool
+		sortFlag         string
+		rateFlag         int
+		adaptiveFlag     bool
+		ipv4Flag         bool
+		ipv6Flag         bool
+		nameserverFlag   string
 	)
 
 	cmd := &cobra.Command{
-		Use:   "portscan [host] [ports]",
-		Short: "Scan ports on a specific host",
-		Long: `Scan specific ports on a target host to identify open services.
-Supports port ranges and comma-separated lists.
+		Use:   "portscan [hosts] [ports]",
+		Short: "Scan ports on one or more hosts",
+		Long: `Scan specific ports on target hosts to identify open services.
+Ports are a comma-separated list of single ports, ranges and the presets
+top100 and top1000 (the most frequently open TCP ports) or all, for example
+top100,8443,9000-9100.
+
+Hosts may be one hostname or IP address, a comma-separated list, or a file
+given with --targets-file (one per line; pass only the ports). Hostnames are
+resolved first and every A and AAAA address is scanned, so an outage behind
+a round-robin name shows up; --ipv4 or --ipv6 keeps one address family, and
+--nameserver resolves through a given DNS server instead of the system
+resolver. Several hosts are scanned in parallel and reported together in the
+format chosen with --format, one entry per address.
+
+With --udp, UDP ports are probed instead of TCP. DNS, NTP, SNMP and NetBIOS
+get real protocol payloads; other ports get an empty datagram. Ports that
+answer are open, ports that return ICMP port-unreachable are closed, and
+silent ports are reported as open|filtered.
+
+Only open ports are listed by default. For TCP, --show-closed adds ports that
+refused the connection and --show-filtered adds ports that never answered
+(usually dropped by a firewall) and ports whose probe failed with an error,
+which helps tell a stopped service from a blocked one.
+
+--rate caps the probes sent per second across all hosts and ports, to stay
+under IDS thresholds or spare fragile devices; the achieved rate is shown
+with the results. --adaptive-timeout starts with a short connect timeout and
+retunes it to three times the 95th percentile of measured round-trip times,
+so closed and filtered ports on a fast network don't each cost --timeout,
+which remains the upper bound.
 
 Examples:
   systool network portscan 192.168.1.1 22,80,443
   systool network portscan example.com 1-1000
-  systool network portscan 10.0.0.1 80,443,8080,8443`,
-		Args: cobra.ExactArgs(2),
+  systool network portscan example.com top1000
+  systool network portscan 10.0.0.1 80,443,8080,8443
+  systool network portscan 10.0.0.1 53,123,161 --udp
+  systool network portscan web1.example.com,web2.example.com,10.0.0.5 22,443
+  systool network portscan www.example.com 443 --ipv4 --nameserver 1.1.1.1
+  systool network portscan --targets-file hosts.txt 22,80,443 --format csv
+  systool network portscan 10.0.0.1 22,80,443,3389 --show-closed --show-filtered
+  systool network portscan 10.0.0.1 top1000 --rate 200 --adaptive-timeout`,
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			host := args[0]
-			portRange := args[1]
+			portRange := args[len(args)-1]
 
 			// Parse ports
 			ports, err := network.ParsePortRange(portRange)
 			if err != nil {
 				return fmt.Errorf("invalid port range: %w", err)
 			}
+			if err := output.CheckSortKey(sortFlag, output.ScanSortKeys); err != nil {
+				return err
+			}
+
+			// Collect targets from the file or the comma-separated argument
+			var targets []string
+			if targetsFileFlag != "" {
+				if len(args) != 1 {
+					return fmt.Errorf("with --targets-file, give only the ports to scan")
+				}
+				targets, err = network.ReadTargetsFromFile(targetsFileFlag)
+				if err != nil {
+					return err
+				}
+			} else {
+				if len(args) != 2 {
+					return fmt.Errorf("requires hosts and ports, or --targets-file and ports")
+				}
+				for _, target := range strings.Split(args[0], ",") {
+					if target = strings.TrimSpace(target); target != "" {
+						targets = append(targets, target)
+					}
+				}
+				if len(targets) == 0 {
+					return fmt.Errorf("no hosts given")
+				}
+			}
 
 			// Parse timeout - using optimized default
 			timeout := 1 * time.Second
@@ -141,46 +324,117 @@ Examples:
 				}
 			}
 
+			if rateFlag < 0 {
+				return fmt.Errorf("--rate must not be negative")
+			}
+
 			// Create scanner with optimized settings
 			scanner := network.NewScanner()
 			scanner.SetTimeout(timeout)
+			scanner.SetRateLimit(rateFlag)
+			scanner.SetAdaptiveTimeout(adaptiveFlag)
+			scanner.SetShowPortStates(showClosedFlag, showFilteredFlag)
+			if err := setTargetResolution(scanner, ipv4Flag, ipv6Flag, nameserverFlag); err != nil {
+				return err
+			}
 			if concurrencyFlag > 0 {
 				scanner.SetConcurrency(500, concurrencyFlag)
 			}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			// Create context with timeout; Ctrl+C also stops the scan, keeping what was found so far
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 			defer cancel()
 
-			// Perform port scan
-			result, err := scanner.ScanPorts(ctx, host, ports)
-			if err != nil {
-				return fmt.Errorf("port scan failed: %w", err)
+			protocol := network.ProtocolTCP
+			scan := scanner.ScanPorts
+			if udpFlag {
+				protocol = network.ProtocolUDP
+				scan = scanner.ScanUDPPorts
+			}
+
+			// A single hostname with several addresses is scanned like a list of hosts
+			var ips []string
+			if len(targets) == 1 {
+				ips, err = scanner.ResolveTargets(ctx, targets[0])
+				if err != nil {
+					return err
+				}
 			}
 
-			// Display results
-			fmt.Printf("\n📊 Found %d open ports:\n\n", len(result.Ports))
+			if len(ips) != 1 {
+				// Progress goes to stderr so it never mixes with JSON/CSV/XML on stdout
+				if len(targets) == 1 {
+					fmt.Fprintf(os.Stderr, "🔍 Scanning %s (%d addresses) for %d %s ports...\n", targets[0], len(ips), len(ports), strings.ToUpper(protocol))
+				} else {
+					fmt.Fprintf(os.Stderr, "🔍 Scanning %d hosts for %d %s ports...\n", len(targets), len(ports), strings.ToUpper(protocol))
+				}
+				scanner.SetProgressCallback(printTargetProgress)
 
-			for _, port := range result.Ports {
-				service := port.Service
-				if service == "" {
-					service = "Unknown"
+				result, err := scanner.ScanHosts(ctx, targets, ports, protocol)
+				if result == nil {
+					return fmt.Errorf("port scan failed: %w", err)
+				}
+				if targetsFileFlag != "" {
+					result.Network = targetsFileFlag
 				}
-				fmt.Printf("🟢 Port %-5d %-12s", port.Port, service)
-				if port.Banner != "" {
-					fmt.Printf(" - %s", port.Banner)
+
+				formatter := output.NewFormatter(output.OutputFormat(formatFlag))
+				formatter.SetSummaryOnly(summaryFlag)
+				formatter.SetSort(sortFlag)
+				if err := formatter.FormatScanResult(result, os.Stdout); err != nil {
+					return err
 				}
-				fmt.Println()
+				return scanInterrupted(cmd, err)
 			}
 
-			return nil
+			host, ip := targets[0], ips[0]
+			label := host
+			if ip != host {
+				label = fmt.Sprintf("%s (%s)", host, ip)
+			}
+
+			// Progress goes to stderr so it never mixes with JSON/CSV/XML on stdout
+			fmt.Fprintf(os.Stderr, "🔍 Scanning %s for %d %s ports...\n", label, len(ports), strings.ToUpper(protocol))
+			scanner.SetProgressCallback(printScanProgress("open ports"))
+
+			// Perform port scan
+			start := time.Now()
+			result, err := scan(ctx, ip, ports)
+			if result == nil {
+				return fmt.Errorf("port scan failed: %w", err)
+			}
+			elapsed := time.Since(start)
+			fmt.Fprintf(os.Stderr, "✅ Scan completed in %v\n", elapsed)
+			output.WriteScanPacing(scanner.Pacing(elapsed), os.Stderr)
+			fmt.Fprintln(os.Stderr)
+
+			formatter := output.NewFormatter(output.OutputFormat(formatFlag))
+			formatter.SetSummaryOnly(summaryFlag)
+			formatter.SetSort(sortFlag)
+			if err := formatter.FormatHostResult(result, os.Stdout); err != nil {
+				return err
+			}
+			return scanInterrupted(cmd, err)
 		},
 	}
 
 	// Add flags
+	cmd.Flags().BoolVar(&summaryFlag, "summary", false, "Show only the summary counts in table and CSV output (JSON stays full)")
+	cmd.Flags().StringVar(&sortFlag, "sort", "", "Order hosts by ip, hostname, open-ports (most first) or latency, or the ports of each host by port, service or state")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
 	cmd.Flags().StringVarP(&timeoutFlag, "timeout", "t", "1s", "Connection timeout (e.g., 1s, 500ms)")
+	cmd.Flags().IntVar(&rateFlag, "rate", 0, "Maximum probes per second across all hosts and ports (0 for no limit)")
+	cmd.Flags().BoolVar(&adaptiveFlag, "adaptive-timeout", false, "Tune the connect timeout from measured round-trip times, up to --timeout")
 	cmd.Flags().IntVarP(&concurrencyFlag, "concurrency", "c", 5000, "Number of concurrent ports to scan")
+	cmd.Flags().BoolVar(&udpFlag, "udp", false, "Scan UDP ports instead of TCP")
+	cmd.Flags().BoolVar(&ipv4Flag, "ipv4", false, "Scan only the IPv4 addresses of hostname targets")
+	cmd.Flags().BoolVar(&ipv6Flag, "ipv6", false, "Scan only the IPv6 addresses of hostname targets")
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to resolve hostname targets with (default: system resolver)")
+	cmd.Flags().StringVar(&targetsFileFlag, "targets-file", "", "File of hostnames or IP addresses to scan, one per line (- for stdin)")
+	cmd.Flags().BoolVar(&showClosedFlag, "show-closed", false, "Also list closed ports (connection refused)")
+	cmd.Flags().BoolVar(&showFilteredFlag, "show-filtered", false, "Also list filtered ports (no answer) and ports that failed with an error")
 
 	return cmd
 }
@@ -191,6 +445,16 @@ func NewDiscoveryCommand() *cobra.Command {
 		formatFlag      string
 		timeoutFlag     string
 		concurrencyFlag int
+		maxHostsFlag    int
+		excludeFlag     string
+		excludeFileFlag string
+		resolveFlag     bool
+		nameserverFlag  string
+		summaryFlag     bool
+		sortFlag        string
+		rateFlag        int
+		adaptiveFlag    bool
+		dbFlag          string
 	)
 
 	cmd := &cobra.Command{
@@ -199,10 +463,43 @@ func NewDiscoveryCommand() *cobra.Command {
 		Long: `Discover live hosts on a network and scan specified ports.
 Combines host discovery with port scanning for comprehensive network mapping.
 
+Ports accept single ports, ranges and the presets top100, top1000 and all,
+mixed freely (e.g. top100,8443).
+
+The network may be a CIDR prefix (IPv4 or IPv6), a range such as
+192.168.1.10-192.168.1.50, a single address, or a comma-separated list of
+these. IPv4 prefixes skip their network and broadcast addresses. Targets
+larger than --max-hosts addresses are refused.
+
+--exclude and --exclude-file skip addresses, ranges or CIDR prefixes inside
+the network, such as gateways or hosts you are not allowed to probe.
+
+With --resolve, each live host gets a PTR lookup and, when port 443 or 8443
+is open, the names on its TLS certificate, so the report shows what the host
+claims to be. These lookups add at most a few seconds per host. Public
+resolvers rarely know PTR names for private ranges, so point --nameserver at
+your internal DNS server for those.
+
+--rate caps the probes sent per second across all hosts and ports, to stay
+under IDS thresholds or spare fragile devices; the achieved rate is shown
+with the results. --adaptive-timeout starts with a short connect timeout and
+retunes it to three times the 95th percentile of measured round-trip times,
+so closed and filtered ports on a fast network don't each cost --timeout,
+which remains the upper bound.
+
+--db also records the live hosts and open ports in a SQLite scan history,
+which network history can query across runs.
+
 Examples:
   systool network discovery 192.168.1.0/24 22,80,443
   systool network discovery 10.0.0.0/24 1-1000
-  systool network discovery 172.16.0.0/24 80,443,8080,3389,22`,
+  systool network discovery 10.0.0.0/24 top100,8443
+  systool network discovery 172.16.0.0/24 80,443,8080,3389,22
+  systool network discovery 10.0.0.0/16 22 --max-hosts 70000
+  systool network discovery 10.0.0.0/24 22 --exclude 10.0.0.128/26 --exclude-file skip.txt
+  systool network discovery 10.0.0.0/24 22,443 --resolve --nameserver 10.0.0.2
+  systool network discovery 10.0.0.0/24 22,443 --rate 500 --adaptive-timeout
+  systool network discovery 10.0.0.0/24 top100 --db scans.db`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			networkCIDR := args[0]
@@ -213,6 +510,9 @@ Examples:
 			if err != nil {
 				return fmt.Errorf("invalid port range: %w", err)
 			}
+			if err := output.CheckSortKey(sortFlag, output.ScanSortKeys); err != nil {
+				return err
+			}
 
 			// Parse timeout - using optimized default
 			timeout := 1 * time.Second
@@ -223,60 +523,84 @@ Examples:
 				}
 			}
 
+			if rateFlag < 0 {
+				return fmt.Errorf("--rate must not be negative")
+			}
+
 			// Create scanner with optimized settings
 			scanner := network.NewScanner()
 			scanner.SetTimeout(timeout)
+			scanner.SetRateLimit(rateFlag)
+			scanner.SetAdaptiveTimeout(adaptiveFlag)
+			scanner.SetMaxHosts(maxHostsFlag)
+			exclude, err := loadExcludes(excludeFlag, excludeFileFlag)
+			if err != nil {
+				return err
+			}
+			scanner.SetExclude(exclude)
+			if resolveFlag {
+				ns := nameserverFlag
+				if ns == "" {
+					ns = nameservers.GetDefaultNameservers()[0].IP.String()
+				}
+				scanner.SetResolver(dns.NewResolver(), ns)
+			}
 			if concurrencyFlag > 0 {
 				scanner.SetConcurrency(concurrencyFlag, 5000)
 			}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+			history, err := openScanHistory(dbFlag)
+			if err != nil {
+				return err
+			}
+			if history != nil {
+				defer history.Close()
+			}
+
+			// Create context with timeout; Ctrl+C also stops the scan, keeping what was found so far
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			ctx, cancel := context.WithTimeout(ctx, 15*time.Minute)
 			defer cancel()
 
+			// Progress goes to stderr so it never mixes with JSON/CSV/XML on stdout
+			fmt.Fprintf(os.Stderr, "🔍 Network discovery on %s\n", networkCIDR)
+			scanner.SetProgressCallback(printScanProgress("hosts"))
+
 			// Perform network discovery
-			// Suppress progress output for structured formats to avoid corrupting JSON/CSV/XML
-			suppressProgress := formatFlag == "json" || formatFlag == "csv" || formatFlag == "xml"
-			result, err := scanner.NetworkDiscovery(ctx, networkCIDR, ports, suppressProgress)
-			if err != nil {
+			result, err := scanner.NetworkDiscovery(ctx, networkCIDR, ports)
+			if result == nil {
 				return fmt.Errorf("network discovery failed: %w", err)
 			}
 
 			// Format and display results using the formatter
 			formatter := output.NewFormatter(output.OutputFormat(formatFlag))
+			formatter.SetSummaryOnly(summaryFlag)
+			formatter.SetSort(sortFlag)
 			if err := formatter.FormatScanResult(result, os.Stdout); err != nil {
-				// If formatter fails, fall back to basic output
-				fmt.Printf("📊 Found %d live hosts out of %d scanned:\n\n", result.Summary.LiveHosts, result.Summary.TotalHosts)
-
-				for _, host := range result.Hosts {
-					fmt.Printf("🖥️  %s\n", host.IP)
-					if len(host.Ports) > 0 {
-						for _, port := range host.Ports {
-							service := port.Service
-							if service == "" {
-								service = "Unknown"
-							}
-							fmt.Printf("   🟢 %-5d %-12s", port.Port, service)
-							if port.Banner != "" {
-								fmt.Printf(" - %s", port.Banner)
-							}
-							fmt.Println()
-						}
-					} else {
-						fmt.Printf("   📝 Host alive but no open ports found in scanned range\n")
-					}
-					fmt.Println()
-				}
+				return err
 			}
-
-			return nil
+			if err := recordScanHistory(history, "discovery", result); err != nil {
+				return err
+			}
+			return scanInterrupted(cmd, err)
 		},
 	}
 
 	// Add flags
+	cmd.Flags().BoolVar(&summaryFlag, "summary", false, "Show only the summary counts in table and CSV output (JSON stays full)")
+	cmd.Flags().StringVar(&sortFlag, "sort", "", "Order hosts by ip, hostname, open-ports (most first) or latency, or the ports of each host by port, service or state")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().StringVar(&dbFlag, "db", "", "Also record the live hosts and open ports in this SQLite scan history (e.g., scans.db)")
 	cmd.Flags().StringVarP(&timeoutFlag, "timeout", "t", "1s", "Connection timeout (e.g., 1s, 500ms)")
+	cmd.Flags().IntVar(&rateFlag, "rate", 0, "Maximum probes per second across all hosts and ports (0 for no limit)")
+	cmd.Flags().BoolVar(&adaptiveFlag, "adaptive-timeout", false, "Tune the connect timeout from measured round-trip times, up to --timeout")
 	cmd.Flags().IntVarP(&concurrencyFlag, "concurrency", "c", 500, "Number of concurrent hosts to scan")
+	cmd.Flags().IntVar(&maxHostsFlag, "max-hosts", network.DefaultMaxHosts, "Maximum number of addresses the network may expand to")
+	cmd.Flags().StringVar(&excludeFlag, "exclude", "", "Comma-separated addresses, ranges or CIDR prefixes to skip")
+	cmd.Flags().StringVar(&excludeFileFlag, "exclude-file", "", "File of addresses, ranges or CIDR prefixes to skip, one per line")
+	cmd.Flags().BoolVar(&resolveFlag, "resolve", false, "Look up PTR names and TLS certificate names of live hosts")
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver for --resolve PTR lookups (default: first built-in resolver)")
 
 	return cmd
 }
@@ -284,8 +608,16 @@ Examples:
 // NewWorkerPoolDiscoveryCommand creates the worker pool discovery subcommand for maximum performance
 func NewWorkerPoolDiscoveryCommand() *cobra.Command {
 	var (
-		formatFlag  string
-		timeoutFlag string
+		formatFlag      string
+		timeoutFlag     string
+		maxHostsFlag    int
+		excludeFlag     string
+		excludeFileFlag string
+		summaryFlag     bool
+		sortFlag        string
+		rateFlag        int
+		adaptiveFlag    bool
+		dbFlag          string
 	)
 
 	cmd := &cobra.Command{
@@ -294,15 +626,40 @@ func NewWorkerPoolDiscoveryCommand() *cobra.Command {
 		Long: `Discover live hosts on a network and scan specified ports using worker pools.
 This is the fastest scanning method available, optimized for maximum performance.
 
+Ports accept single ports, ranges and the presets top100, top1000 and all,
+mixed freely (e.g. top100,8443).
+
+The network may be a CIDR prefix (IPv4 or IPv6), a range such as
+192.168.1.10-192.168.1.50, a single address, or a comma-separated list of
+these. IPv4 prefixes skip their network and broadcast addresses. Targets
+larger than --max-hosts addresses are refused.
+
+--exclude and --exclude-file skip addresses, ranges or CIDR prefixes inside
+the network, such as gateways or hosts you are not allowed to probe.
+
+--rate caps the probes sent per second across all hosts and ports, to stay
+under IDS thresholds or spare fragile devices; the achieved rate is shown
+with the results. --adaptive-timeout starts with a short connect timeout and
+retunes it to three times the 95th percentile of measured round-trip times,
+so closed and filtered ports on a fast network don't each cost --timeout,
+which remains the upper bound.
+
 Examples:
   systool network discovery-fast 192.168.1.0/24 22,80,443
   systool network discovery-fast 10.0.0.0/24 1-1000
-  systool network discovery-fast 172.16.0.0/24 80,443,8080,3389,22`,
+  systool network discovery-fast 172.16.0.0/24 80,443,8080,3389,22
+  systool network discovery-fast 10.0.0.0/24 22 --exclude 10.0.0.1
+  systool network discovery-fast 10.0.0.0/24 top100 --format json
+  systool network discovery-fast 10.0.0.0/24 top100 --rate 1000`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			networkCIDR := args[0]
 			portRange := args[1]
 
+			if err := output.CheckSortKey(sortFlag, output.ScanSortKeys); err != nil {
+				return err
+			}
+
 			// Parse ports
 			ports, err := network.ParsePortRange(portRange)
 			if err != nil {
@@ -318,51 +675,69 @@ Examples:
 				}
 			}
 
+			if rateFlag < 0 {
+				return fmt.Errorf("--rate must not be negative")
+			}
+
 			// Create scanner with optimized settings
 			scanner := network.NewScanner()
 			scanner.SetTimeout(timeout)
+			scanner.SetRateLimit(rateFlag)
+			scanner.SetAdaptiveTimeout(adaptiveFlag)
+			scanner.SetMaxHosts(maxHostsFlag)
+			exclude, err := loadExcludes(excludeFlag, excludeFileFlag)
+			if err != nil {
+				return err
+			}
+			scanner.SetExclude(exclude)
+
+			history, err := openScanHistory(dbFlag)
+			if err != nil {
+				return err
+			}
+			if history != nil {
+				defer history.Close()
+			}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+			// Create context with timeout; Ctrl+C also stops the scan, keeping what was found so far
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			ctx, cancel := context.WithTimeout(ctx, 15*time.Minute)
 			defer cancel()
 
+			// Progress goes to stderr so it never mixes with JSON/CSV/XML on stdout
+			fmt.Fprintf(os.Stderr, "🔍 Network discovery on %s (Worker Pool)\n", networkCIDR)
+
 			// Perform worker pool network discovery
 			result, err := scanner.NetworkDiscoveryWorkerPool(ctx, networkCIDR, ports)
-			if err != nil {
+			if result == nil {
 				return fmt.Errorf("network discovery failed: %w", err)
 			}
 
-			// Display results
-			fmt.Printf("\n✅ Discovery completed in %v\n", result.Duration)
-			fmt.Printf("📊 Found %d live hosts out of %d scanned:\n\n", result.Summary.LiveHosts, result.Summary.TotalHosts)
-
-			for _, host := range result.Hosts {
-				fmt.Printf("🖥️  %s\n", host.IP)
-				if len(host.Ports) > 0 {
-					for _, port := range host.Ports {
-						service := port.Service
-						if service == "" {
-							service = "Unknown"
-						}
-						fmt.Printf("   🟢 %-5d %-12s", port.Port, service)
-						if port.Banner != "" {
-							fmt.Printf(" - %s", port.Banner)
-						}
-						fmt.Println()
-					}
-				} else {
-					fmt.Printf("   📝 Host alive but no open ports found in scanned range\n")
-				}
-				fmt.Println()
+			formatter := output.NewFormatter(output.OutputFormat(formatFlag))
+			formatter.SetSummaryOnly(summaryFlag)
+			formatter.SetSort(sortFlag)
+			if err := formatter.FormatScanResult(result, os.Stdout); err != nil {
+				return err
 			}
-
-			return nil
+			if err := recordScanHistory(history, "discovery-fast", result); err != nil {
+				return err
+			}
+			return scanInterrupted(cmd, err)
 		},
 	}
 
 	// Add flags
+	cmd.Flags().BoolVar(&summaryFlag, "summary", false, "Show only the summary counts in table and CSV output (JSON stays full)")
+	cmd.Flags().StringVar(&sortFlag, "sort", "", "Order hosts by ip, hostname, open-ports (most first) or latency, or the ports of each host by port, service or state")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().StringVar(&dbFlag, "db", "", "Also record the live hosts and open ports in this SQLite scan history (e.g., scans.db)")
 	cmd.Flags().StringVarP(&timeoutFlag, "timeout", "t", "1s", "Connection timeout (e.g., 1s, 500ms)")
+	cmd.Flags().IntVar(&rateFlag, "rate", 0, "Maximum probes per second across all hosts and ports (0 for no limit)")
+	cmd.Flags().BoolVar(&adaptiveFlag, "adaptive-timeout", false, "Tune the connect timeout from measured round-trip times, up to --timeout")
+	cmd.Flags().IntVar(&maxHostsFlag, "max-hosts", network.DefaultMaxHosts, "Maximum number of addresses the network may expand to")
+	cmd.Flags().StringVar(&excludeFlag, "exclude", "", "Comma-separated addresses, ranges or CIDR prefixes to skip")
+	cmd.Flags().StringVar(&excludeFileFlag, "exclude-file", "", "File of addresses, ranges or CIDR prefixes to skip, one per line")
 
 	return cmd
 }
@@ -370,20 +745,54 @@ Examples:
 // NewMonitorCommand creates the port monitoring subcommand
 func NewMonitorCommand() *cobra.Command {
 	var (
-		formatFlag   string
-		intervalFlag string
+		formatFlag     string
+		intervalFlag   string
+		onChangeFlag   string
+		webhookFlag    string
+		logFileFlag    string
+		ipv4Flag       bool
+		ipv6Flag       bool
+		nameserverFlag string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "monitor [hosts] [ports]",
 		Short: "Monitor specific ports on hosts continuously",
 		Long: `Continuously monitor specific ports on target hosts.
-Useful for monitoring service availability and detecting changes.
+The first check prints the current state of every host; after that only
+ports that change between open and closed are reported, with a timestamp.
+
+--on-change (or --alert-command) runs a shell command for every change, with
+the details in SYSTOOL_HOST, SYSTOOL_PORT, SYSTOOL_OLD_STATE, SYSTOOL_NEW_STATE
+and SYSTOOL_TIME, e.g. to page someone. The host, port, old and new state are
+also passed as arguments, $1 to $4 in the command.
+
+--webhook POSTs each change to a URL as a JSON event with host, port,
+old_state, new_state and time. --log-file appends the same events to a file,
+one JSON object per line. The first check only records the baseline, so
+alerts fire on changes after it.
+
+Hostnames are resolved again on every check and each A and AAAA address is
+monitored on its own, so DNS-based failover shows up: an address the name
+stops or starts resolving to is reported, with the open ports of a new one.
+--ipv4 or --ipv6 keeps one address family, and --nameserver resolves through
+a given DNS server instead of the system resolver.
+
+--format json prints every check result as a JSON event, one per line, for
+piping into other tools: a "status" event per address for the first check, a
+"change" event per port change, an "address" event when a hostname gains or
+loses an address and an "error" event when a host cannot be resolved or
+scanned. The banner goes to stderr.
 
 Examples:
   systool network monitor 192.168.1.1,192.168.1.2 80,443
   systool network monitor example.com,google.com 80,443,22
-  systool network monitor 10.0.0.1 3389,22,80 --interval 60s`,
+  systool network monitor 10.0.0.1 3389,22,80 --interval 60s
+  systool network monitor 10.0.0.1 443 --on-change ./page-oncall.sh
+  systool network monitor 10.0.0.1 443 --alert-command 'logger "port $2 on $1 is $4"'
+  systool network monitor 10.0.0.1 22,443 --webhook https://hooks.example.com/ports --log-file events.jsonl
+  systool network monitor 10.0.0.1 22,443 --format json | jq .
+  systool network monitor www.example.com 443 --ipv4 --interval 1m`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			hostList := args[0]
@@ -410,21 +819,91 @@ Examples:
 				}
 			}
 
+			var jsonEvents bool
+			switch formatFlag {
+			case string(output.FormatTable):
+			case string(output.FormatJSON):
+				jsonEvents = true
+			default:
+				return fmt.Errorf("invalid format %q for monitor (use table or json)", formatFlag)
+			}
+
+			if webhookFlag != "" {
+				if parsed, err := url.Parse(webhookFlag); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+					return fmt.Errorf("invalid webhook URL %q (use http:// or https://)", webhookFlag)
+				}
+			}
+
+			// Events are appended, so one file can collect several monitoring sessions
+			var eventLog *os.File
+			if logFileFlag != "" {
+				eventLog, err = os.OpenFile(logFileFlag, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+				if err != nil {
+					return fmt.Errorf("failed to open log file: %w", err)
+				}
+				defer eventLog.Close()
+			}
+
 			// Create scanner with optimized settings
 			scanner := network.NewScanner()
+			if err := setTargetResolution(scanner, ipv4Flag, ipv6Flag, nameserverFlag); err != nil {
+				return err
+			}
 
-			fmt.Printf("👀 Monitoring %d hosts on %d ports (Ctrl+C to stop)\n", len(hosts), len(ports))
-			fmt.Printf("⏰ Checking every %v...\n\n", interval)
+			// JSON events keep stdout to one object per line
+			banner := os.Stdout
+			if jsonEvents {
+				banner = os.Stderr
+			}
+			fmt.Fprintf(banner, "👀 Monitoring %d hosts on %d ports (Ctrl+C to stop)\n", len(hosts), len(ports))
+			fmt.Fprintf(banner, "⏰ Checking every %v...\n\n", interval)
 
 			ticker := time.NewTicker(interval)
 			defer ticker.Stop()
 
-			// Initial check
-			checkHosts(scanner, hosts, ports, formatFlag)
+			// Initial check establishes the baseline
+			previous := checkHosts(scanner, hosts, ports, jsonEvents)
+			printHostStatus(previous, hosts, jsonEvents)
 
+			highlight := isTerminal(os.Stdout)
 			for range ticker.C {
-				fmt.Printf("\n⏰ %s - Checking status...\n", time.Now().Format("15:04:05"))
-				checkHosts(scanner, hosts, ports, formatFlag)
+				current := checkHosts(scanner, hosts, ports, jsonEvents)
+				for _, change := range diffAddresses(previous, current, hosts) {
+					if jsonEvents {
+						printMonitorEvent(change)
+					} else {
+						printAddressChange(change)
+					}
+				}
+				for _, change := range diffPortStates(previous, current, hosts, ports) {
+					if jsonEvents {
+						printMonitorEvent(change)
+					} else {
+						printPortChange(change, highlight)
+					}
+					if eventLog != nil {
+						if err := json.NewEncoder(eventLog).Encode(change); err != nil {
+							fmt.Fprintf(os.Stderr, "⚠️  failed to write to log file: %v\n", err)
+						}
+					}
+					if onChangeFlag != "" {
+						runChangeHook(onChangeFlag, change)
+					}
+					if webhookFlag != "" {
+						postChangeWebhook(webhookFlag, change)
+					}
+				}
+				// Hosts that failed to resolve or scan keep their last known
+				// state; the others replace their addresses with the current ones
+				resolved := current.hosts()
+				for target := range previous {
+					if resolved[target.Host] {
+						delete(previous, target)
+					}
+				}
+				for target, state := range current {
+					previous[target] = state
+				}
 			}
 
 			return nil
@@ -432,36 +911,673 @@ Examples:
 	}
 
 	// Add flags
-	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, or json for one event per line)")
 	cmd.Flags().StringVarP(&intervalFlag, "interval", "i", "30s", "Check interval (e.g., 30s, 1m)")
+	cmd.Flags().StringVar(&onChangeFlag, "on-change", "", "Shell command to run when a port changes state")
+	cmd.Flags().StringVar(&onChangeFlag, "alert-command", "", "Alias for --on-change")
+	cmd.Flags().StringVar(&webhookFlag, "webhook", "", "URL to POST a JSON event to when a port changes state")
+	cmd.Flags().StringVar(&logFileFlag, "log-file", "", "Append each change to this file as a JSON line")
+	cmd.Flags().BoolVar(&ipv4Flag, "ipv4", false, "Monitor only the IPv4 addresses of hostnames")
+	cmd.Flags().BoolVar(&ipv6Flag, "ipv6", false, "Monitor only the IPv6 addresses of hostnames")
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver to resolve hostnames with (default: system resolver)")
 
 	return cmd
 }
 
-// checkHosts performs a check on all hosts and ports
-func checkHosts(scanner *network.Scanner, hosts []string, ports []int, formatFlag string) {
+// NewScanDiffCommand creates the scan diff subcommand
+func NewScanDiffCommand() *cobra.Command {
+	var formatFlag string
+
+	cmd := &cobra.Command{
+		Use:   "diff [old.json] [new.json]",
+		Short: "Compare two saved scans",
+		Long: fmt.Sprintf(`Compare two ping, discovery or portscan results saved with --format json and
+report new hosts, hosts that disappeared, and ports that opened or closed on each
+host. Hosts are matched by IP address and ports by port and protocol. Ports of
+new or disappeared hosts are listed as opened or closed too.
+
+The command exits with status %d when anything changed, so a cron job can alert
+on it, and 0 when the scans match.
+
+Examples:
+  systool network discovery 10.0.0.0/24 top100 --format json > today.json
+  systool network diff yesterday.json today.json
+  systool network diff yesterday.json today.json --format csv`, ExitScanChanges),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldScan, err := network.ReadScanResult(args[0])
+			if err != nil {
+				return err
+			}
+			newScan, err := network.ReadScanResult(args[1])
+			if err != nil {
+				return err
+			}
+
+			diff := network.DiffScans(oldScan, newScan)
+
+			formatter := output.NewFormatter(output.OutputFormat(formatFlag))
+			if err := formatter.FormatScanDiff(diff, os.Stdout); err != nil {
+				return err
+			}
+			if !diff.Changed() {
+				return nil
+			}
+
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return &ExitError{
+				Code: ExitScanChanges,
+				Err:  fmt.Errorf("%d change(s) between the scans", len(diff.Changes)),
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+
+	return cmd
+}
+
+// NewScanHistoryCommand creates the network history subcommand
+func NewScanHistoryCommand() *cobra.Command {
+	var (
+		dbFlag        string
+		firstSeenFlag string
+		notSeenFlag   int
+		formatFlag    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Query the scan history recorded with --db",
+		Long: `Answer questions about the scans that ping, discovery and discovery-fast
+recorded with --db. The history keeps the live hosts and open ports of every
+scan, so it shows trends that a single scan or diff cannot.
+
+--first-seen reports when an address:port was first and last seen open, and in
+how many scans. Given only an address, it reports the host and every port it
+has had open.
+
+--not-seen lists the hosts no scan has seen in that many days, longest gone
+first. A host also stops being seen when later scans no longer cover its
+network, so keep the scanned ranges stable.
+
+Examples:
+  systool network discovery 10.0.0.0/16 top100 --db scans.db
+  systool network history --db scans.db --first-seen 10.0.3.7:3389
+  systool network history --db scans.db --first-seen 10.0.3.7
+  systool network history --db scans.db --not-seen 30 --format csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (firstSeenFlag == "") == (notSeenFlag == 0) {
+				return fmt.Errorf("use exactly one of --first-seen or --not-seen")
+			}
+			if notSeenFlag < 0 {
+				return fmt.Errorf("--not-seen must be a positive number of days")
+			}
+			// Querying must not leave an empty database behind for a mistyped path
+			if _, err := os.Stat(dbFlag); err != nil {
+				return fmt.Errorf("scan history %s not found: record scans with --db first", dbFlag)
+			}
+
+			history, err := network.OpenScanHistory(dbFlag)
+			if err != nil {
+				return err
+			}
+			defer history.Close()
+
+			var report *network.ScanHistoryReport
+			if firstSeenFlag != "" {
+				ip, port, err := parseHistoryTarget(firstSeenFlag)
+				if err != nil {
+					return err
+				}
+				report, err = history.FirstSeen(ip, port)
+				if err != nil {
+					return err
+				}
+			} else {
+				cutoff := time.Now().AddDate(0, 0, -notSeenFlag)
+				report, err = history.NotSeenSince(cutoff, notSeenFlag)
+				if err != nil {
+					return err
+				}
+			}
+
+			formatter := output.NewFormatter(output.OutputFormat(formatFlag))
+			return formatter.FormatScanHistory(report, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVar(&dbFlag, "db", "", "SQLite scan history written by --db (e.g., scans.db)")
+	cmd.Flags().StringVar(&firstSeenFlag, "first-seen", "", "Report when an address or address:port was first seen (e.g., 10.0.3.7:3389)")
+	cmd.Flags().IntVar(&notSeenFlag, "not-seen", 0, "List hosts not seen in this many days")
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	_ = cmd.MarkFlagRequired("db")
+
+	return cmd
+}
+
+// NewTracerouteCommand creates the network traceroute subcommand
+func NewTracerouteCommand() *cobra.Command {
+	var (
+		formatFlag     string
+		timeoutFlag    string
+		maxHopsFlag    int
+		queriesFlag    int
+		portFlag       int
+		tcpFlag        bool
+		noResolveFlag  bool
+		nameserverFlag string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "traceroute [host]",
+		Short: "Trace the route to a host with per-hop latency",
+		Long: fmt.Sprintf(`Send probes with increasing TTLs towards a host and list each hop on the way
+with its address, PTR name and the round-trip time of every probe. Hops that
+never answer are shown as * rows and the trace carries on, up to --max-hops.
+
+Probes are UDP datagrams to port %d by default, or TCP connects (a SYN) to
+port %d with --tcp; --port picks another one, such as 443 to follow the path
+web traffic takes through a firewall. With root or CAP_NET_RAW, routers are
+identified from their ICMP time-exceeded replies. Without those privileges,
+TCP connects are timed per TTL instead: this still finds the hop count and
+the latency to the destination, but intermediate routers show as ? when they
+answered and * when they didn't. Only IPv4 is supported.
+
+Examples:
+  systool network traceroute example.com
+  sudo systool network traceroute 8.8.8.8 --max-hops 20
+  systool network traceroute www.example.com --tcp --port 443
+  systool network traceroute 10.0.0.1 --no-resolve --format csv`, network.DefaultTraceUDPPort, network.DefaultTraceTCPPort),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			timeout, err := time.ParseDuration(timeoutFlag)
+			if err != nil {
+				return fmt.Errorf("invalid timeout format: %w", err)
+			}
+			if maxHopsFlag < 1 || maxHopsFlag > 255 {
+				return fmt.Errorf("--max-hops must be between 1 and 255")
+			}
+			if queriesFlag < 1 {
+				return fmt.Errorf("--queries must be at least 1")
+			}
+			if portFlag < 0 || portFlag > 65535 {
+				return fmt.Errorf("invalid --port %d", portFlag)
+			}
+
+			scanner := network.NewScanner()
+			scanner.SetTimeout(timeout)
+			if err := setTargetResolution(scanner, true, false, nameserverFlag); err != nil {
+				return err
+			}
+
+			protocol := network.ProtocolUDP
+			if tcpFlag {
+				protocol = network.ProtocolTCP
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			// Progress goes to stderr so it never mixes with JSON/CSV/XML on stdout
+			fmt.Fprintf(os.Stderr, "🛤️  Tracing route to %s...\n", args[0])
+			result, err := scanner.Traceroute(ctx, args[0], network.TracerouteOptions{
+				Protocol:    protocol,
+				Port:        portFlag,
+				MaxHops:     maxHopsFlag,
+				Queries:     queriesFlag,
+				SkipLookups: noResolveFlag,
+				OnHop:       printTracerouteHop,
+			})
+			if err != nil {
+				return err
+			}
+			if result.Method == network.TraceMethodTCPConnect && !tcpFlag {
+				fmt.Fprintf(os.Stderr, "⚠️  No raw ICMP socket (needs root or CAP_NET_RAW): timed TCP connects to port %d instead of UDP probes\n", result.Port)
+			}
+			fmt.Fprintln(os.Stderr)
+
+			formatter := output.NewFormatter(output.OutputFormat(formatFlag))
+			if err := formatter.FormatTracerouteResult(result, os.Stdout); err != nil {
+				return err
+			}
+			return scanInterrupted(cmd, ctx.Err())
+		},
+	}
+
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().StringVarP(&timeoutFlag, "timeout", "t", "2s", "How long each probe waits for an answer")
+	cmd.Flags().IntVarP(&maxHopsFlag, "max-hops", "m", network.DefaultMaxHops, "Highest TTL to probe before giving up")
+	cmd.Flags().IntVarP(&queriesFlag, "queries", "q", network.DefaultTraceQueries, "Probes sent to each hop")
+	cmd.Flags().IntVarP(&portFlag, "port", "p", 0, fmt.Sprintf("Destination port of the probes (default %d for UDP, %d for TCP)", network.DefaultTraceUDPPort, network.DefaultTraceTCPPort))
+	cmd.Flags().BoolVar(&tcpFlag, "tcp", false, "Probe with TCP connects instead of UDP datagrams")
+	cmd.Flags().BoolVar(&noResolveFlag, "no-resolve", false, "Don't look up the PTR names of hops")
+	cmd.Flags().StringVarP(&nameserverFlag, "nameserver", "n", "", "Nameserver for the target and hop names (default: system resolver)")
+
+	return cmd
+}
+
+// printTracerouteHop reports each finished hop of a traceroute on stderr
+func printTracerouteHop(hop network.TracerouteHop) {
+	address := "*"
+	var rtts []string
+	for _, probe := range hop.Probes {
+		if probe.Answered {
+			address = "?"
+			rtts = append(rtts, probe.RTT.Round(10*time.Microsecond).String())
+		} else {
+			rtts = append(rtts, "*")
+		}
+	}
+	switch {
+	case hop.Hostname != "":
+		address = fmt.Sprintf("%s (%s)", hop.Hostname, hop.IP)
+	case hop.IP != "":
+		address = hop.IP
+	}
+	fmt.Fprintf(os.Stderr, "📍 %2d  %s  %s\n", hop.TTL, address, strings.Join(rtts, "  "))
+}
+
+// openScanHistory opens the --db scan history, returning nil when none was given
+func openScanHistory(path string) (*network.ScanHistory, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return network.OpenScanHistory(path)
+}
+
+// recordScanHistory records result in history under the command that produced
+// it; it does nothing without a --db history
+func recordScanHistory(history *network.ScanHistory, kind string, result *network.ScanResult) error {
+	if history == nil {
+		return nil
+	}
+	if err := history.Record(kind, result); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "🗄️  Recorded %d live hosts in the scan history\n", result.Summary.LiveHosts)
+	return nil
+}
+
+// parseHistoryTarget splits a --first-seen value into an address and an
+// optional port, normalizing the address as scans record it
+func parseHistoryTarget(value string) (string, int, error) {
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.String(), 0, nil
+	}
+	addrPort, err := netip.ParseAddrPort(value)
+	if err != nil || addrPort.Port() == 0 {
+		return "", 0, fmt.Errorf("invalid --first-seen %q (use an address or address:port, e.g. 10.0.3.7:3389 or [2001:db8::7]:443)", value)
+	}
+	return addrPort.Addr().String(), int(addrPort.Port()), nil
+}
+
+// setTargetResolution applies --ipv4, --ipv6 and --nameserver to how the
+// scanner resolves hostname targets
+func setTargetResolution(scanner *network.Scanner, ipv4, ipv6 bool, nameserver string) error {
+	switch {
+	case ipv4 && ipv6:
+		return fmt.Errorf("--ipv4 and --ipv6 cannot be used together")
+	case ipv4:
+		scanner.SetAddressFamily(network.AddressFamilyIPv4)
+	case ipv6:
+		scanner.SetAddressFamily(network.AddressFamilyIPv6)
+	}
+	if nameserver != "" {
+		scanner.SetTargetResolver(dns.NewResolver(), nameserver)
+	}
+	return nil
+}
+
+// printTargetProgress reports each finished host of a multi-host port scan on stderr
+func printTargetProgress(event network.ProgressEvent) {
+	fmt.Fprintf(os.Stderr, "📈 %d/%d hosts scanned: %d open ports | %d total | %v elapsed\n",
+		event.Done, event.Total, event.Found, event.TotalFound, event.Elapsed.Round(time.Millisecond))
+}
+
+// scanInterrupted returns the error for a scan that stopped early, once its partial results
+// have been printed, or nil when the scan completed
+func scanInterrupted(cmd *cobra.Command, err error) error {
+	if err == nil {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	return fmt.Errorf("scan interrupted, results are partial: %w", err)
+}
+
+// loadExcludes combines the --exclude list with the entries of --exclude-file and
+// rejects malformed entries before any address is probed
+func loadExcludes(exclude, excludeFile string) ([]string, error) {
+	var entries []string
+	if exclude != "" {
+		entries = append(entries, exclude)
+	}
+	if excludeFile != "" {
+		fileEntries, err := network.ReadTargetsFromFile(excludeFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read exclude file: %w", err)
+		}
+		entries = append(entries, fileEntries...)
+	}
+	if err := network.CheckExcludes(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// printScanProgress returns a Scanner progress callback that reports each batch on stderr,
+// describing what was found as unit (e.g. "hosts")
+func printScanProgress(unit string) func(network.ProgressEvent) {
+	return func(event network.ProgressEvent) {
+		fmt.Fprintf(os.Stderr, "📈 Batch %d/%d: %d %s found | %d/%d scanned | %d total | %v elapsed\n",
+			event.Batch, event.Batches, event.Found, unit, event.Done, event.Total, event.TotalFound, event.Elapsed.Round(time.Millisecond))
+	}
+}
+
+// monitorTarget is one address of a monitored host; for hosts given as an IP
+// address, Address is the host itself
+type monitorTarget struct {
+	Host    string
+	Address string
+}
+
+// label names the target in messages, adding the address to hostnames
+func (t monitorTarget) label() string {
+	if t.Address == "" || t.Address == t.Host {
+		return t.Host
+	}
+	return fmt.Sprintf("%s (%s)", t.Host, t.Address)
+}
+
+// portStates maps each monitored address to whether each of its ports is open
+type portStates map[monitorTarget]map[int]bool
+
+// targets lists the monitored addresses in the order of hosts, then by address
+func (p portStates) targets(hosts []string) []monitorTarget {
+	var targets []monitorTarget
+	for _, host := range hosts {
+		var addresses []netip.Addr
+		for target := range p {
+			if target.Host == host {
+				if addr, err := netip.ParseAddr(target.Address); err == nil {
+					addresses = append(addresses, addr)
+				}
+			}
+		}
+		slices.SortFunc(addresses, netip.Addr.Compare)
+		for _, addr := range addresses {
+			targets = append(targets, monitorTarget{Host: host, Address: addr.String()})
+		}
+	}
+	return targets
+}
+
+// hosts reports which hosts have at least one address in the states
+func (p portStates) hosts() map[string]bool {
+	hosts := make(map[string]bool)
+	for target := range p {
+		hosts[target.Host] = true
+	}
+	return hosts
+}
+
+// Monitor event types, the "type" field of each --format json line
+const (
+	monitorEventStatus  = "status"
+	monitorEventChange  = "change"
+	monitorEventAddress = "address"
+	monitorEventError   = "error"
+)
+
+// portChange is a port that moved between open and closed between two checks. It is
+// also the JSON event sent to --webhook and written to --log-file.
+type portChange struct {
+	Type     string    `json:"type"`
+	Host     string    `json:"host"`
+	Address  string    `json:"address"`
+	Port     int       `json:"port"`
+	OldState string    `json:"old_state"`
+	NewState string    `json:"new_state"`
+	Time     time.Time `json:"time"`
+}
+
+// hostStatus is the "status" event printed for each host by the first check
+type hostStatus struct {
+	Type      string    `json:"type"`
+	Host      string    `json:"host"`
+	Address   string    `json:"address"`
+	Up        bool      `json:"up"`
+	OpenPorts []int     `json:"open_ports"`
+	Time      time.Time `json:"time"`
+}
+
+// addressChange is the "address" event printed when a monitored hostname starts
+// or stops resolving to an address, as in DNS-based failover
+type addressChange struct {
+	Type      string    `json:"type"`
+	Host      string    `json:"host"`
+	Address   string    `json:"address"`
+	Change    string    `json:"change"` // "added" or "removed"
+	OpenPorts []int     `json:"open_ports,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// hostError is the "error" event printed when a host cannot be resolved or scanned
+type hostError struct {
+	Type    string    `json:"type"`
+	Host    string    `json:"host"`
+	Address string    `json:"address,omitempty"`
+	Error   string    `json:"error"`
+	Time    time.Time `json:"time"`
+}
+
+// printMonitorEvent writes one --format json event to stdout
+func printMonitorEvent(event any) {
+	if err := json.NewEncoder(os.Stdout).Encode(event); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  failed to write event: %v\n", err)
+	}
+}
+
+// checkHosts resolves every host, scans each of its addresses and records which
+// of the ports are open. Resolving again on every check lets DNS-based failover
+// show up. Hosts or addresses that fail are reported and left out.
+func checkHosts(scanner *network.Scanner, hosts []string, ports []int, jsonEvents bool) portStates {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	states := make(portStates)
 	for _, host := range hosts {
-		fmt.Printf("🔍 %s: ", host)
-
-		result, err := scanner.ScanPorts(ctx, host, ports)
+		addresses, err := scanner.ResolveTargets(ctx, host)
 		if err != nil {
-			fmt.Printf("🔴 ERROR - %v\n", err)
+			printHostError(monitorTarget{Host: host}, err, jsonEvents)
 			continue
 		}
 
-		if len(result.Ports) > 0 {
-			var openPorts []int
-			for _, port := range result.Ports {
-				if port.Open {
-					openPorts = append(openPorts, port.Port)
-				}
+		for _, address := range addresses {
+			target := monitorTarget{Host: host, Address: address}
+			result, err := scanner.ScanPorts(ctx, address, ports)
+			if err != nil {
+				printHostError(target, err, jsonEvents)
+				continue
 			}
-			fmt.Printf("🟢 UP - Ports: %v\n", openPorts)
+			states[target] = openPortSet(result.Ports)
+		}
+	}
+	return states
+}
+
+// openPortSet collects the numbers of the open ports in a scan result
+func openPortSet(ports []network.PortResult) map[int]bool {
+	open := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		if port.Open {
+			open[port.Port] = true
+		}
+	}
+	return open
+}
+
+// sortedPorts lists the open ports of a host in ascending order
+func sortedPorts(open map[int]bool) []int {
+	openPorts := []int{}
+	for port := range open {
+		openPorts = append(openPorts, port)
+	}
+	sort.Ints(openPorts)
+	return openPorts
+}
+
+// printHostError reports a host that could not be resolved or scanned
+func printHostError(target monitorTarget, err error, jsonEvents bool) {
+	if jsonEvents {
+		printMonitorEvent(hostError{Type: monitorEventError, Host: target.Host, Address: target.Address, Error: err.Error(), Time: time.Now()})
+		return
+	}
+	fmt.Printf("🔴 %s: ERROR - %v\n", target.label(), err)
+}
+
+// printHostStatus prints the full state of every address, used for the first check
+func printHostStatus(states portStates, hosts []string, jsonEvents bool) {
+	now := time.Now()
+	for _, target := range states.targets(hosts) {
+		openPorts := sortedPorts(states[target])
+
+		if jsonEvents {
+			printMonitorEvent(hostStatus{Type: monitorEventStatus, Host: target.Host, Address: target.Address, Up: len(openPorts) > 0, OpenPorts: openPorts, Time: now})
+			continue
+		}
+		if len(openPorts) > 0 {
+			fmt.Printf("🔍 %s: 🟢 UP - Ports: %v\n", target.label(), openPorts)
 		} else {
-			fmt.Printf("🔴 DOWN or filtered\n")
+			fmt.Printf("🔍 %s: 🔴 DOWN or filtered\n", target.label())
+		}
+	}
+}
+
+// diffAddresses lists the addresses that hosts resolving in both checks gained
+// or lost between them, in host and address order
+func diffAddresses(previous, current portStates, hosts []string) []addressChange {
+	now := time.Now()
+	hostsBefore, hostsNow := previous.hosts(), current.hosts()
+
+	var changes []addressChange
+	for _, target := range previous.targets(hosts) {
+		if _, ok := current[target]; !ok && hostsNow[target.Host] {
+			changes = append(changes, addressChange{Type: monitorEventAddress, Host: target.Host, Address: target.Address, Change: "removed", Time: now})
+		}
+	}
+	for _, target := range current.targets(hosts) {
+		if _, ok := previous[target]; !ok && hostsBefore[target.Host] {
+			changes = append(changes, addressChange{Type: monitorEventAddress, Host: target.Host, Address: target.Address, Change: "added", OpenPorts: sortedPorts(current[target]), Time: now})
+		}
+	}
+	return changes
+}
+
+// printAddressChange prints an address a hostname gained or lost
+func printAddressChange(change addressChange) {
+	timestamp := change.Time.Format("2006-01-02 15:04:05")
+	if change.Change == "removed" {
+		fmt.Printf("🔀 %s %s no longer resolves to %s\n", timestamp, change.Host, change.Address)
+		return
+	}
+	fmt.Printf("🔀 %s %s now resolves to %s - Ports: %v\n", timestamp, change.Host, change.Address, change.OpenPorts)
+}
+
+// diffPortStates lists the ports whose state differs between two checks on
+// addresses seen in both, in host, address and port order
+func diffPortStates(previous, current portStates, hosts []string, ports []int) []portChange {
+	now := time.Now()
+	var changes []portChange
+	for _, target := range current.targets(hosts) {
+		before, seenBefore := previous[target]
+		after := current[target]
+		if !seenBefore {
+			continue
 		}
+		for _, port := range ports {
+			if before[port] == after[port] {
+				continue
+			}
+			change := portChange{Type: monitorEventChange, Host: target.Host, Address: target.Address, Port: port, OldState: network.PortStateClosed, NewState: network.PortStateOpen, Time: now}
+			if before[port] {
+				change.OldState, change.NewState = network.PortStateOpen, network.PortStateClosed
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// printPortChange prints a change alert, in bold red or green when writing to a terminal
+func printPortChange(change portChange, highlight bool) {
+	icon, color := "🟢", "\033[1;32m"
+	if change.NewState == network.PortStateClosed {
+		icon, color = "🔴", "\033[1;31m"
+	}
+
+	target := monitorTarget{Host: change.Host, Address: change.Address}
+	line := fmt.Sprintf("%s %s %s port %d %s → %s", icon, change.Time.Format("2006-01-02 15:04:05"), target.label(), change.Port, change.OldState, change.NewState)
+	if highlight {
+		line = color + line + "\033[0m"
+	}
+	fmt.Println(line)
+}
+
+// runChangeHook runs the --on-change command for one change, passing the details
+// in the environment and as arguments. Failures are reported but do not stop monitoring.
+func runChangeHook(command string, change portChange) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	args := []string{change.Host, strconv.Itoa(change.Port), change.OldState, change.NewState}
+	hook := exec.CommandContext(ctx, "sh", append([]string{"-c", command, "systool"}, args...)...)
+	if runtime.GOOS == "windows" {
+		hook = exec.CommandContext(ctx, "cmd", append([]string{"/C", command}, args...)...)
+	}
+	hook.Env = append(os.Environ(),
+		"SYSTOOL_HOST="+change.Host,
+		"SYSTOOL_ADDRESS="+change.Address,
+		"SYSTOOL_PORT="+strconv.Itoa(change.Port),
+		"SYSTOOL_OLD_STATE="+change.OldState,
+		"SYSTOOL_NEW_STATE="+change.NewState,
+		"SYSTOOL_TIME="+change.Time.Format(time.RFC3339),
+	)
+	hook.Stdout = os.Stdout
+	hook.Stderr = os.Stderr
+
+	if err := hook.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  --on-change command failed for %s port %d: %v\n", change.Host, change.Port, err)
+	}
+}
+
+// postChangeWebhook POSTs one change to the --webhook URL as a JSON event. Failures
+// are reported but do not stop monitoring.
+func postChangeWebhook(webhookURL string, change portChange) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	body, err := json.Marshal(change)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  --webhook failed for %s port %d: %v\n", change.Host, change.Port, err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  --webhook failed for %s port %d: %v\n", change.Host, change.Port, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  --webhook failed for %s port %d: %v\n", change.Host, change.Port, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		fmt.Fprintf(os.Stderr, "⚠️  --webhook failed for %s port %d: %s\n", change.Host, change.Port, resp.Status)
 	}
 }
@@ -0,0 +1,103 @@
+// =============================================================================
+// internal/cli/outputfile.go - Writing command results to an --output file
+// =============================================================================
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bryanCE/sysadmin/internal/output"
+)
+
+// outputFile collects a command's results in a temporary file next to the destination and
+// moves it into place only once complete and synced, so a cron job or a reader never sees
+// a truncated report
+type outputFile struct {
+	path  string
+	force bool
+	tmp   *os.File
+}
+
+// createOutputFile prepares to write path. An existing file is an error unless force is set.
+func createOutputFile(path string, force bool) (*outputFile, error) {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return nil, fmt.Errorf("%s already exists; use --force to overwrite it", path)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to check %s: %w", path, err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	// CreateTemp makes the file private; reports are meant to be shared like any other file
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return &outputFile{path: path, force: force, tmp: tmp}, nil
+}
+
+func (o *outputFile) Write(p []byte) (int, error) {
+	return o.tmp.Write(p)
+}
+
+// Commit syncs the results to disk and renames them to the destination path
+func (o *outputFile) Commit() error {
+	if err := o.tmp.Sync(); err != nil {
+		o.Discard()
+		return fmt.Errorf("failed to sync %s: %w", o.path, err)
+	}
+	if err := o.tmp.Close(); err != nil {
+		o.Discard()
+		return fmt.Errorf("failed to write %s: %w", o.path, err)
+	}
+
+	// The destination may have appeared while the command ran
+	if !o.force {
+		if _, err := os.Stat(o.path); err == nil {
+			o.Discard()
+			return fmt.Errorf("%s appeared while writing; use --force to overwrite it", o.path)
+		}
+	}
+	if err := os.Rename(o.tmp.Name(), o.path); err != nil {
+		o.Discard()
+		return fmt.Errorf("failed to write %s: %w", o.path, err)
+	}
+	o.tmp = nil
+	return nil
+}
+
+// Discard removes the temporary file; it does nothing once Commit has succeeded
+func (o *outputFile) Discard() {
+	if o.tmp == nil {
+		return
+	}
+	o.tmp.Close()
+	os.Remove(o.tmp.Name())
+	o.tmp = nil
+}
+
+// formatFromExtension infers an output format from the output file name
+func formatFromExtension(path string) (output.OutputFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return output.FormatJSON, nil
+	case ".jsonl", ".ndjson":
+		return output.FormatJSONL, nil
+	case ".csv":
+		return output.FormatCSV, nil
+	case ".xml":
+		return output.FormatXML, nil
+	default:
+		return "", fmt.Errorf("cannot infer the format of %s from its extension; pass --format", path)
+	}
+}
@@ -0,0 +1,102 @@
+// =============================================================================
+// internal/cli/progress.go - Progress display for bulk runs
+// =============================================================================
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	progressBarWidth      = 30
+	progressRedrawEvery   = 100 * time.Millisecond // Terminal redraw throttle
+	progressLogEvery      = 10 * time.Second       // Plain-text update interval when not a terminal
+	progressMinRateWindow = time.Second            // Elapsed time before a rate and ETA are shown
+)
+
+// bulkProgress renders BulkProcessor progress callbacks: a bar redrawn in place on a
+// terminal, or a plain line every few seconds when stderr is a file or pipe, so logs stay short
+type bulkProgress struct {
+	writer   io.Writer
+	terminal bool
+	start    time.Time
+	last     time.Time
+	failed   int
+}
+
+func newBulkProgress(writer *os.File) *bulkProgress {
+	return &bulkProgress{writer: writer, terminal: isTerminal(writer), start: time.Now()}
+}
+
+// update is the progress callback; the processor calls it from a single goroutine
+func (p *bulkProgress) update(current, total int, domain string, success bool) {
+	if !success {
+		p.failed++
+	}
+
+	done := current == total
+	interval := progressLogEvery
+	if p.terminal {
+		interval = progressRedrawEvery
+	}
+	if !done && time.Since(p.last) < interval {
+		return
+	}
+	p.last = time.Now()
+
+	if p.terminal {
+		fmt.Fprintf(p.writer, "\r%s %s\033[K", progressBar(current, total), p.status(current, total))
+		if done {
+			fmt.Fprintln(p.writer)
+		}
+		return
+	}
+	fmt.Fprintf(p.writer, "Progress: %s\n", p.status(current, total))
+}
+
+// status describes progress as "120/500 (24%) | 35.2/s | ETA 10s | ✗ 3"
+func (p *bulkProgress) status(current, total int) string {
+	parts := []string{fmt.Sprintf("%d/%d (%d%%)", current, total, percent(current, total))}
+
+	elapsed := time.Since(p.start)
+	if elapsed >= progressMinRateWindow && current > 0 {
+		rate := float64(current) / elapsed.Seconds()
+		parts = append(parts, fmt.Sprintf("%.1f/s", rate))
+		if current < total {
+			eta := time.Duration(float64(total-current) / rate * float64(time.Second))
+			parts = append(parts, "ETA "+eta.Round(time.Second).String())
+		}
+	}
+	if current == total {
+		parts = append(parts, "done in "+elapsed.Round(time.Millisecond).String())
+	}
+
+	parts = append(parts, fmt.Sprintf("✗ %d", p.failed))
+	return strings.Join(parts, " | ")
+}
+
+// progressBar draws a fixed-width bar for current out of total
+func progressBar(current, total int) string {
+	filled := 0
+	if total > 0 {
+		filled = current * progressBarWidth / total
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled) + "]"
+}
+
+func percent(current, total int) int {
+	if total == 0 {
+		return 100
+	}
+	return current * 100 / total
+}
+
+// isTerminal reports whether file is a character device such as a terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
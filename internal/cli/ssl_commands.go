@@ -4,35 +4,59 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"os"
+	"os/signal"
 	"strings"
+	"time"
 
+	"github.com/bryanCE/sysadmin/internal/dns"
 	"github.com/bryanCE/sysadmin/internal/output"
 	"github.com/bryanCE/sysadmin/internal/ssl"
+	"github.com/bryanCE/sysadmin/pkg/nameservers"
 	"github.com/spf13/cobra"
 )
 
 // NewSSLCheckCommand creates the ssl-check subcommand
 func NewSSLCheckCommand() *cobra.Command {
 	var (
-		portFlag   string
-		formatFlag string
+		portFlag    string
+		formatFlag  string
+		allIPsFlag  bool
+		timeoutFlag time.Duration
+		proxyFlag   string
+		ctLookup    bool
+		clientCert  string
+		clientKey   string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "ssl-check [domain]",
 		Short: "Check SSL certificate for a domain",
 		Long: `Validate SSL/TLS certificate for a given domain.
-Checks certificate validity, expiration, issuer information, and more.`,
+Checks certificate validity, expiration, issuer information, and more.
+
+With --all-ips, every A/AAAA record behind the hostname is checked
+individually (using the hostname as SNI) to catch load balancers that
+serve different certificates.
+
+Connections honor --timeout and can be routed through a SOCKS5 or HTTP
+CONNECT proxy with --proxy (or the HTTPS_PROXY/ALL_PROXY environment
+variables). Press Ctrl+C to cancel a hung check.
+
+Signed Certificate Timestamps are collected from the TLS extension, the
+certificate itself and any stapled OCSP response. With --ct-lookup, crt.sh
+is queried for log entries matching the leaf's SHA-256 fingerprint.
+
+For servers that require mutual TLS, --client-cert and --client-key present a
+client certificate. The output shows whether the server requested one and
+which CA names it advertised.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			domain := args[0]
-
-			// Check certificate
-			info, err := ssl.CheckCertificate(domain, portFlag)
+			domain, err := dns.ToASCII(args[0])
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				return err
 			}
 
@@ -50,6 +74,49 @@ Checks certificate validity, expiration, issuer information, and more.`,
 			}
 
 			formatter := output.NewFormatter(format)
+
+			// Cancel in-flight handshakes on Ctrl+C
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			dialCfg := ssl.DialConfig{
+				Timeout: timeoutFlag,
+				Proxy:   proxyFlag,
+			}
+
+			if clientCert != "" || clientKey != "" {
+				cert, err := ssl.LoadClientCertificate(clientCert, clientKey)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					return err
+				}
+				dialCfg.ClientCertificate = cert
+			}
+
+			if allIPsFlag {
+				ips, err := resolveHostIPs(domain)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					return err
+				}
+
+				result := ssl.CheckAllIPs(ctx, domain, portFlag, ips, dialCfg)
+				return formatter.FormatMultiIPCertResult(result, os.Stdout)
+			}
+
+			// Check certificate
+			info, err := ssl.CheckCertificate(ctx, domain, portFlag, dialCfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return err
+			}
+
+			if ctLookup {
+				if err := ssl.LookupCTLogEntries(ctx, info); err != nil {
+					info.Warnings = append(info.Warnings, fmt.Sprintf("CT lookup failed: %v", err))
+				}
+			}
+
 			return formatter.FormatCertInfo(info, os.Stdout)
 		},
 	}
@@ -57,6 +124,130 @@ Checks certificate validity, expiration, issuer information, and more.`,
 	// Add flags
 	cmd.Flags().StringVarP(&portFlag, "port", "p", "443", "Port to connect to (default: 443)")
 	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().BoolVar(&allIPsFlag, "all-ips", false, "Check the certificate on every A/AAAA record behind the hostname")
+	cmd.Flags().DurationVarP(&timeoutFlag, "timeout", "t", ssl.DefaultTimeout, "Connection and handshake timeout (e.g., 5s)")
+	cmd.Flags().StringVar(&proxyFlag, "proxy", "", "Proxy URL (socks5://host:port or http://host:port); defaults to HTTPS_PROXY/ALL_PROXY")
+	cmd.Flags().BoolVar(&ctLookup, "ct-lookup", false, "Query crt.sh for Certificate Transparency log entries")
+	cmd.Flags().StringVar(&clientCert, "client-cert", "", "PEM client certificate for servers requiring mutual TLS")
+	cmd.Flags().StringVar(&clientKey, "client-key", "", "PEM private key for --client-cert")
+
+	return cmd
+}
+
+// NewSSLCompareCommand creates the ssl-compare subcommand
+func NewSSLCompareCommand() *cobra.Command {
+	var (
+		formatFlag  string
+		timeoutFlag time.Duration
+		proxyFlag   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ssl-compare [hostA[:port]] [hostB[:port]]",
+		Short: "Compare the SSL certificates served by two endpoints",
+		Long: `Fetch the certificate from two endpoints and compare them field by field:
+serial number, SHA-256 and SPKI fingerprints, issuer, SANs and validity window.
+
+Useful during migrations to confirm that staging and production present the
+same certificate. The port defaults to 443. Exits with status 1 when any
+field differs.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hostA, portA, err := splitEndpoint(args[0])
+			if err != nil {
+				return err
+			}
+			hostB, portB, err := splitEndpoint(args[1])
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			dialCfg := ssl.DialConfig{
+				Timeout: timeoutFlag,
+				Proxy:   proxyFlag,
+			}
+
+			comparison, err := ssl.CompareCertificates(ctx, hostA, portA, hostB, portB, dialCfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return err
+			}
+
+			// Format and display results
+			var format output.OutputFormat
+			switch strings.ToLower(formatFlag) {
+			case "json":
+				format = output.FormatJSON
+			case "csv":
+				format = output.FormatCSV
+			case "xml":
+				format = output.FormatXML
+			default:
+				format = output.FormatTable
+			}
+
+			formatter := output.NewFormatter(format)
+			if err := formatter.FormatCertComparison(comparison, os.Stdout); err != nil {
+				return err
+			}
+
+			if !comparison.Identical {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("certificates differ in %d field(s)", len(comparison.Differences))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().DurationVarP(&timeoutFlag, "timeout", "t", ssl.DefaultTimeout, "Connection and handshake timeout (e.g., 5s)")
+	cmd.Flags().StringVar(&proxyFlag, "proxy", "", "Proxy URL (socks5://host:port or http://host:port); defaults to HTTPS_PROXY/ALL_PROXY")
 
 	return cmd
 }
+
+// splitEndpoint parses host[:port], defaulting to port 443
+func splitEndpoint(endpoint string) (string, string, error) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		// No port given; accept bare hostnames and (bracketed) IPv6 addresses
+		host = strings.TrimSuffix(strings.TrimPrefix(endpoint, "["), "]")
+		port = "443"
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("invalid endpoint: %s", endpoint)
+	}
+	return host, port, nil
+}
+
+// resolveHostIPs resolves all A and AAAA records for a hostname
+func resolveHostIPs(domain string) ([]string, error) {
+	resolver := dns.NewResolver()
+	ns := nameservers.GetDefaultNameservers()[0].IP.String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var ips []string
+	for _, recordType := range []dns.DNSRecordType{dns.RecordTypeA, dns.RecordTypeAAAA} {
+		result, err := resolver.Query(ctx, domain, recordType, ns)
+		if err != nil {
+			continue
+		}
+		for _, record := range result.Records {
+			// Skip CNAME hops included in the answer section
+			if net.ParseIP(record.Value) != nil {
+				ips = append(ips, record.Value)
+			}
+		}
+	}
+
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no A or AAAA records found for %s", domain)
+	}
+
+	return ips, nil
+}
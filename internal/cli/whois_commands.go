@@ -0,0 +1,79 @@
+// =============================================================================
+// internal/cli/whois_commands.go - WHOIS-related CLI commands
+// =============================================================================
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/bryanCE/sysadmin/internal/dns"
+	"github.com/bryanCE/sysadmin/internal/output"
+	"github.com/bryanCE/sysadmin/internal/whois"
+	"github.com/spf13/cobra"
+)
+
+// NewWhoisCommand creates the whois subcommand
+func NewWhoisCommand() *cobra.Command {
+	var (
+		serverFlag  string
+		formatFlag  string
+		timeoutFlag time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "whois [domain]",
+		Short: "Look up domain registration data via WHOIS",
+		Long: `Query WHOIS on port 43 for a domain's registration data.
+The registry server is found through whois.iana.org and any registrar referral is followed.
+Table output prints the raw response; json and csv add a parsed summary
+(registrar, creation/expiry dates, nameservers, status).`,
+		Example: `  systool whois example.com
+  systool whois example.com -f json
+  systool whois example.com --server whois.verisign-grs.com`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			domain, err := dns.ToASCII(args[0])
+			if err != nil {
+				return err
+			}
+
+			if timeoutFlag <= 0 {
+				return fmt.Errorf("timeout must be positive")
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			result, err := whois.Lookup(ctx, domain, serverFlag, timeoutFlag)
+			if err != nil {
+				return fmt.Errorf("whois lookup failed: %w", err)
+			}
+
+			var format output.OutputFormat
+			switch strings.ToLower(formatFlag) {
+			case "json":
+				format = output.FormatJSON
+			case "csv":
+				format = output.FormatCSV
+			case "xml":
+				format = output.FormatXML
+			default:
+				format = output.FormatTable
+			}
+
+			formatter := output.NewFormatter(format)
+			return formatter.FormatWhoisResult(result, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&serverFlag, "server", "s", "", "WHOIS server to query instead of following IANA referrals")
+	cmd.Flags().StringVarP(&formatFlag, "format", "f", "table", "Output format (table, json, csv, xml)")
+	cmd.Flags().DurationVarP(&timeoutFlag, "timeout", "t", whois.DefaultTimeout, "Per-server connection timeout (e.g., 5s)")
+
+	return cmd
+}
@@ -0,0 +1,85 @@
+// =============================================================================
+// internal/dns/adaptive.go - Adaptive worker concurrency for bulk runs
+// =============================================================================
+package dns
+
+import "sync"
+
+// Bounds for adaptive concurrency chosen with --concurrency auto
+const (
+	AdaptiveMinConcurrency = 1
+	AdaptiveMaxConcurrency = 50
+)
+
+const (
+	adaptiveWindow       = 20   // Outcomes observed before each adjustment
+	adaptiveBackoffRate  = 0.2  // Failure rate above which concurrency is halved
+	adaptiveIncreaseRate = 0.05 // Failure rate below which concurrency grows
+)
+
+// concurrencyController caps how many tasks run at once and tunes the cap from recent
+// outcomes: it grows by a quarter while almost everything succeeds and halves when failures
+// and timeouts pile up, staying within [min, max]
+type concurrencyController struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	active   int
+	min, max int
+	observed int
+	failed   int
+}
+
+func newConcurrencyController(start, minLimit, maxLimit int) *concurrencyController {
+	c := &concurrencyController{min: minLimit, max: maxLimit}
+	c.limit = clampInt(start, minLimit, maxLimit)
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// acquire blocks until a task may start
+func (c *concurrencyController) acquire() {
+	c.mu.Lock()
+	for c.active >= c.limit {
+		c.cond.Wait()
+	}
+	c.active++
+	c.mu.Unlock()
+}
+
+// release ends a task, recording whether it failed, and adjusts the limit once a full
+// window of outcomes has been seen
+func (c *concurrencyController) release(failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.active--
+	c.observed++
+	if failed {
+		c.failed++
+	}
+
+	if c.observed >= adaptiveWindow {
+		rate := float64(c.failed) / float64(c.observed)
+		switch {
+		case rate > adaptiveBackoffRate:
+			c.limit = clampInt(c.limit/2, c.min, c.max)
+		case rate < adaptiveIncreaseRate:
+			c.limit = clampInt(c.limit+max(1, c.limit/4), c.min, c.max)
+		}
+		c.observed, c.failed = 0, 0
+	}
+	c.cond.Broadcast()
+}
+
+// current returns the concurrency limit in effect
+func (c *concurrencyController) current() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limit
+}
+
+// clampInt bounds value to [low, high]
+func clampInt(value, low, high int) int {
+	return min(max(value, low), high)
+}
@@ -6,7 +6,12 @@ package dns
 import (
 	"bufio"
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"strings"
 	"sync"
@@ -20,25 +25,128 @@ const (
 	BulkOperationQuery       BulkOperation = "query"
 	BulkOperationPropagation BulkOperation = "propagation"
 	BulkOperationConsistency BulkOperation = "consistency"
+	BulkOperationPTR         BulkOperation = "ptr"
 )
 
 // BulkResult represents the result of a bulk operation on a single domain
+// Exactly one of Query, Propagation and Consistency is set, matching the operation that ran
+// (Query for PTR lookups).
 type BulkResult struct {
-	Domain    string
-	Success   bool
-	Error     error
-	StartTime time.Time
-	EndTime   time.Time
-	Data      interface{} // Can be QueryResult, PropagationResult, or []ConsistencyIssue
+	Domain     string        `json:"domain"`
+	RecordType DNSRecordType `json:"record_type,omitempty"` // Per-row override from the input, if any
+	Nameserver string        `json:"nameserver,omitempty"`  // Per-row override from the input, if any
+	Success    bool          `json:"success"`
+	Error      error         `json:"error,omitempty"`
+	StartTime  time.Time     `json:"start_time"`
+	EndTime    time.Time     `json:"end_time"`
+	Retried    bool          `json:"retried,omitempty"`  // Result comes from a retry pass, see MergeRetry
+	Retries    int           `json:"retries,omitempty"`  // Extra attempts made within the run, see SetRetries
+	TraceID    string        `json:"trace_id,omitempty"` // Correlation ID of the target's log lines, see SetTraceIDs
+	timedOut   bool          // Failed by the per-domain timeout
+
+	Query       *DNSResult         `json:"query,omitempty"`       // Set by ProcessQuery and ProcessPTR
+	Propagation *PropagationResult `json:"propagation,omitempty"` // Set by ProcessPropagation
+	Consistency *ConsistencyReport `json:"consistency,omitempty"` // Set by ProcessConsistency
+}
+
+// MarshalJSON encodes Error as its message, omitting it when the operation succeeded
+func (r BulkResult) MarshalJSON() ([]byte, error) {
+	type plain BulkResult
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain(r), errorString(r.Error)})
 }
 
 // BulkSummary provides a summary of bulk operations
 type BulkSummary struct {
-	TotalDomains int
-	Successful   int
-	Failed       int
-	Duration     time.Duration
-	Results      []BulkResult
+	Operation    BulkOperation                   `json:"operation"`
+	RecordTypes  []DNSRecordType                 `json:"record_types,omitempty"` // Defaults for targets without their own; unset for consistency
+	Nameservers  []string                        `json:"nameservers,omitempty"`  // Default for targets without their own
+	TotalDomains int                             `json:"total_domains"`
+	Successful   int                             `json:"successful"`
+	Failed       int                             `json:"failed"`
+	Duration     time.Duration                   `json:"duration"`
+	Duplicates   int                             `json:"duplicates"`               // Repeated input rows dropped before the run
+	Skipped      []SkippedRow                    `json:"skipped,omitempty"`        // Input rows left out of the run
+	ByRecordType map[DNSRecordType]BulkTypeCount `json:"by_record_type,omitempty"` // Set when a query covers several record types
+	Filter       *BulkFilter                     `json:"filter,omitempty"`         // Set when Results were narrowed, see ApplyFilter
+	Queries      int64                           `json:"queries"`                  // DNS exchanges sent during the run, including retries
+	QPS          float64                         `json:"qps"`                      // Effective queries per second over Duration
+	Concurrency  int                             `json:"concurrency"`              // Workers in effect at the end; varies with SetAdaptiveConcurrency
+	TimedOut     int                             `json:"timed_out"`                // Targets failed by the per-domain timeout
+	AutoRetried  int                             `json:"auto_retried"`             // Targets that needed at least one retry, see SetRetries
+	Recovered    int                             `json:"recovered"`                // Retried targets that ended up succeeding
+	Stats        *BulkStats                      `json:"stats,omitempty"`          // Percentiles, slowest targets and failure reasons over the whole run
+	Results      []BulkResult                    `json:"results"`                  // Empty when results were streamed via SetResultCallback
+}
+
+// BulkFilter narrows the results a bulk command shows. Failed domains are always shown.
+type BulkFilter struct {
+	OnlyFailures bool   `json:"only_failures,omitempty"`
+	MinSeverity  string `json:"min_severity,omitempty"` // Keep consistency results with an issue at or above this severity
+	Shown        int    `json:"shown"`                  // Results that matched
+}
+
+// Matches reports whether result passes the filter
+func (f *BulkFilter) Matches(result BulkResult) bool {
+	if !result.Success {
+		return true
+	}
+	if f.OnlyFailures {
+		return false
+	}
+	if f.MinSeverity != "" && result.Consistency != nil {
+		return len(FilterBySeverity(result.Consistency.Issues, f.MinSeverity)) > 0
+	}
+	return true
+}
+
+// ApplyFilter keeps only the results that match filter and records it in Filter. The
+// counts describe the whole run, so TotalDomains and Filter.Shown give "N of M shown".
+func (s *BulkSummary) ApplyFilter(filter *BulkFilter) {
+	kept := make([]BulkResult, 0, len(s.Results))
+	for _, result := range s.Results {
+		if filter.Matches(result) {
+			kept = append(kept, result)
+		}
+	}
+	s.Results = kept
+	filter.Shown = len(kept)
+	s.Filter = filter
+}
+
+// BulkTypeCount tallies the outcomes for one record type of a multi-type bulk query
+type BulkTypeCount struct {
+	Successful int `json:"successful"`
+	Failed     int `json:"failed"`
+}
+
+// BulkTarget is one row of bulk input; an empty RecordType or Nameserver falls back to the
+// command's defaults
+type BulkTarget struct {
+	Domain     string        `json:"domain"`
+	RecordType DNSRecordType `json:"record_type,omitempty"`
+	Nameserver string        `json:"nameserver,omitempty"`
+}
+
+// key identifies the target for deduplication and checkpoint matching
+func (t BulkTarget) key() string {
+	return t.Domain + "," + string(t.RecordType) + "," + t.Nameserver
+}
+
+// SkippedRow is an input row that was left out of a bulk run
+type SkippedRow struct {
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+	Reason string `json:"reason"`
+}
+
+// BulkInput is a parsed bulk input file
+type BulkInput struct {
+	Targets    []BulkTarget
+	Skipped    []SkippedRow
+	Duplicates int
 }
 
 // BulkProcessor handles bulk DNS operations
@@ -47,6 +155,14 @@ type BulkProcessor struct {
 	consistencyChecker *ConsistencyChecker
 	concurrency        int
 	progressCallback   func(current, total int, domain string, success bool)
+	resultCallback     func(result BulkResult)
+	warningCallback    func(err error)
+	checkpointPath     string
+	adaptiveMin        int // With adaptiveMax, bounds for adaptive concurrency; 0 means fixed
+	adaptiveMax        int
+	perDomainTimeout   time.Duration // Deadline for each target; 0 means only the run's context applies
+	retries            int           // Extra passes over failed targets, see SetRetries
+	tracePrefix        string        // Trace ID prefix, or TraceIDAuto; "" disables trace IDs
 }
 
 // NewBulkProcessor creates a new bulk processor
@@ -63,187 +179,585 @@ func (bp *BulkProcessor) SetProgressCallback(callback func(current, total int, d
 	bp.progressCallback = callback
 }
 
-// ReadDomainsFromFile reads domains from a file (one per line)
-func ReadDomainsFromFile(filename string) ([]string, error) {
+// SetResultCallback hands each result to callback as soon as it completes. Results passed
+// to the callback are not kept in BulkSummary.Results, so memory stays flat for large runs.
+// The callback is invoked from a single goroutine, in completion order.
+func (bp *BulkProcessor) SetResultCallback(callback func(result BulkResult)) {
+	bp.resultCallback = callback
+}
+
+// SetWarningCallback receives problems that don't stop the run, such as a failed checkpoint
+// write. Without a callback they are logged with slog.Warn. The callback is invoked from a
+// single goroutine.
+func (bp *BulkProcessor) SetWarningCallback(callback func(err error)) {
+	bp.warningCallback = callback
+}
+
+// warn reports a problem that doesn't stop the run, see SetWarningCallback
+func (bp *BulkProcessor) warn(err error) {
+	if bp.warningCallback != nil {
+		bp.warningCallback(err)
+		return
+	}
+	slog.Warn("bulk run", "error", err)
+}
+
+// SetCheckpoint records successful results in the file at path as the run progresses. If the
+// file already holds results from an interrupted run of the same operation, those domains are
+// skipped and their saved results are merged into the summary. Checkpointed results are kept
+// in memory for rewriting the file, even when a result callback is set.
+func (bp *BulkProcessor) SetCheckpoint(path string) {
+	bp.checkpointPath = path
+}
+
+// SetAdaptiveConcurrency lets the worker count float between minWorkers and maxWorkers, starting from the
+// concurrency given to NewBulkProcessor. Every few results the failure rate (errors and
+// timeouts) is checked: the count grows while nearly everything succeeds and halves when
+// more than a fifth fail. BulkSummary.Concurrency reports where it ended up.
+func (bp *BulkProcessor) SetAdaptiveConcurrency(minWorkers, maxWorkers int) {
+	bp.adaptiveMin = max(minWorkers, 1)
+	bp.adaptiveMax = max(maxWorkers, bp.adaptiveMin)
+}
+
+// SetPerDomainTimeout bounds the time spent on each target, so one hung domain can't use up
+// the whole run's deadline. Targets that hit it fail and are counted in BulkSummary.TimedOut.
+func (bp *BulkProcessor) SetPerDomainTimeout(timeout time.Duration) {
+	bp.perDomainTimeout = timeout
+}
+
+// EnableCache lets every operation of the run share answers through the resolver's TTL
+// cache (see Resolver.EnableCache), so overlapping checks don't repeat the same queries
+func (bp *BulkProcessor) EnableCache() {
+	bp.resolver.EnableCache()
+}
+
+// SetRetries re-attempts failed targets up to retries more times. Each retry pass starts once
+// the previous pass is done, so a target that failed early isn't retried while the network
+// trouble behind it is still going on. Results record how many retries they took.
+func (bp *BulkProcessor) SetRetries(retries int) {
+	bp.retries = max(retries, 0)
+}
+
+// SetTraceIDs gives each target a correlation ID from NewTraceID(prefix), kept across its
+// retries. The ID is carried in the target's context, so the debug log lines of every DNS
+// exchange made for it include trace_id, and it is recorded in BulkResult.TraceID. Pass
+// TraceIDAuto for bare UUIDs; "" disables trace IDs.
+func (bp *BulkProcessor) SetTraceIDs(prefix string) {
+	bp.tracePrefix = prefix
+}
+
+// SetRateLimit caps queries to any single nameserver at perSecond, however many workers are
+// running; 0 removes the limit. The limit is installed on the processor's resolver, so it
+// covers query, propagation and consistency runs alike.
+func (bp *BulkProcessor) SetRateLimit(perSecond float64) {
+	if perSecond <= 0 {
+		bp.resolver.SetRateLimiter(nil)
+		return
+	}
+	bp.resolver.SetRateLimiter(NewRateLimiter(perSecond))
+}
+
+// SetQPS caps the queries sent by the whole run at perSecond, across every worker and
+// nameserver; 0 removes the cap. Like SetRateLimit it is installed on the resolver, so each
+// query made by processSingleQuery, processSinglePropagation or processSingleConsistency
+// waits its turn.
+func (bp *BulkProcessor) SetQPS(perSecond float64) {
+	if perSecond <= 0 {
+		bp.resolver.SetQPSLimiter(nil)
+		return
+	}
+	bp.resolver.SetQPSLimiter(NewRateLimiter(perSecond))
+}
+
+// ReadBulkInputFromFile reads bulk input from a file (see ReadBulkInput); a filename of "-"
+// reads from stdin
+func ReadBulkInputFromFile(filename string, prefixes []string) (*BulkInput, error) {
+	if filename == "-" {
+		return ReadBulkInput(os.Stdin, prefixes)
+	}
+
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	var domains []string
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
+	return ReadBulkInput(file, prefixes)
+}
 
-	for scanner.Scan() {
-		lineNum++
-		domain := strings.TrimSpace(scanner.Text())
+// ReadBulkInput reads one target per line, either a bare domain or a CSV row of
+// domain,record_type[,nameserver] overriding the command defaults for that domain.
+// Domains are normalized for case and trailing dots, internationalized domains are
+// converted to punycode, and repeated rows are dropped while preserving first-seen
+// order. Rows with an unsupported record type are reported in Skipped rather than
+// failing the whole input.
+//
+// When prefixes is non-empty, every domain is also expanded into prefix.domain candidates
+// sharing the row's record type and nameserver, and wildcard rows such as *.example.com
+// expand into the candidates alone. Without prefixes a wildcard row is an error.
+func ReadBulkInput(reader io.Reader, prefixes []string) (*BulkInput, error) {
+	input := &BulkInput{}
+	seen := make(map[string]bool)
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	for {
+		fields, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading domains: %w", err)
+		}
+		lineNum, _ := csvReader.FieldPos(0)
+
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if len(fields) == 1 && fields[0] == "" {
+			continue
+		}
 
-		// Skip empty lines and comments
-		if domain == "" || strings.HasPrefix(domain, "#") {
+		if len(fields) > 3 {
+			input.Skipped = append(input.Skipped, SkippedRow{
+				Line:   lineNum,
+				Text:   strings.Join(fields, ","),
+				Reason: "expected domain[,record_type[,nameserver]]",
+			})
 			continue
 		}
 
+		target := BulkTarget{Domain: normalizeDomain(fields[0])}
+		base, wildcard := strings.CutPrefix(target.Domain, "*.")
+
 		// Basic domain validation
-		if !isValidDomain(domain) {
-			return nil, fmt.Errorf("invalid domain on line %d: %s", lineNum, domain)
+		if !isValidDomain(base) {
+			return nil, fmt.Errorf("invalid domain on line %d: %s", lineNum, target.Domain)
+		}
+		if wildcard && len(prefixes) == 0 {
+			return nil, fmt.Errorf("wildcard domain on line %d: %s cannot be queried directly; "+
+				"use --expand-subdomains to generate names under %s", lineNum, target.Domain, base)
+		}
+
+		if len(fields) > 1 && fields[1] != "" {
+			recordType, err := ParseRecordType(fields[1])
+			if err != nil {
+				input.Skipped = append(input.Skipped, SkippedRow{
+					Line:   lineNum,
+					Text:   strings.Join(fields, ","),
+					Reason: err.Error(),
+				})
+				continue
+			}
+			target.RecordType = recordType
+		}
+		if len(fields) > 2 {
+			target.Nameserver = fields[2]
+		}
+
+		for _, candidate := range expandTarget(target, base, wildcard, prefixes) {
+			if !isValidDomain(candidate.Domain) {
+				input.Skipped = append(input.Skipped, SkippedRow{
+					Line:   lineNum,
+					Text:   candidate.Domain,
+					Reason: "expanded name is not a valid domain",
+				})
+				continue
+			}
+
+			// Keep the first occurrence only
+			if seen[candidate.key()] {
+				input.Duplicates++
+				continue
+			}
+			seen[candidate.key()] = true
+
+			input.Targets = append(input.Targets, candidate)
 		}
+	}
+
+	if len(input.Targets) == 0 {
+		return nil, fmt.Errorf("no valid domains found in input")
+	}
+
+	return input, nil
+}
 
-		domains = append(domains, domain)
+// expandTarget returns the targets a row stands for: the row itself, followed by one
+// candidate per prefix under base. A wildcard row yields only the candidates.
+func expandTarget(target BulkTarget, base string, wildcard bool, prefixes []string) []BulkTarget {
+	targets := make([]BulkTarget, 0, len(prefixes)+1)
+	if !wildcard {
+		targets = append(targets, target)
+	}
+	for _, prefix := range prefixes {
+		candidate := target
+		candidate.Domain = prefix + "." + base
+		targets = append(targets, candidate)
 	}
+	return targets
+}
+
+// ReadSubdomainPrefixes reads subdomain prefixes for expanding bulk input, one per line
+// (e.g. www, mail, _dmarc). Blank lines and # comments are ignored, and repeated prefixes
+// are dropped.
+func ReadSubdomainPrefixes(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	var prefixes []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		prefix := strings.Trim(normalizeDomain(strings.TrimSpace(line)), ".")
+		if prefix == "" {
+			continue
+		}
+
+		// A prefix must form a valid name once a base domain is appended
+		if !isValidDomain(prefix + ".example.com") {
+			return nil, fmt.Errorf("invalid subdomain prefix on line %d: %s", lineNum, prefix)
+		}
 
+		if seen[prefix] {
+			continue
+		}
+		seen[prefix] = true
+		prefixes = append(prefixes, prefix)
+	}
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+		return nil, fmt.Errorf("error reading prefixes: %w", err)
 	}
 
-	if len(domains) == 0 {
-		return nil, fmt.Errorf("no valid domains found in file")
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("no subdomain prefixes found in %s", filename)
 	}
 
-	return domains, nil
+	return prefixes, nil
 }
 
-// ProcessQuery performs bulk DNS queries
-func (bp *BulkProcessor) ProcessQuery(ctx context.Context, domains []string, recordType DNSRecordType, nameservers []string) (*BulkSummary, error) {
-	startTime := time.Now()
-	results := make([]BulkResult, 0, len(domains))
+// ProcessQuery performs bulk DNS queries; recordTypes and nameservers apply to targets
+// that don't set their own. With several record types, each such target becomes one task
+// per type, every result carries its RecordType, and the summary counts outcomes per type.
+func (bp *BulkProcessor) ProcessQuery(ctx context.Context, targets []BulkTarget, recordTypes []DNSRecordType, nameservers []string) (*BulkSummary, error) {
+	if len(recordTypes) == 0 {
+		return nil, fmt.Errorf("no record types to query")
+	}
 
-	// Create a channel for domains to process
-	domainChan := make(chan string, len(domains))
-	for _, domain := range domains {
-		domainChan <- domain
+	multiType := len(recordTypes) > 1
+	if multiType {
+		targets = expandRecordTypes(targets, recordTypes)
 	}
-	close(domainChan)
 
-	// Create a channel for results
-	resultChan := make(chan BulkResult, len(domains))
+	summary, err := bp.process(ctx, BulkOperationQuery, targets, multiType, func(ctx context.Context, target BulkTarget) BulkResult {
+		return bp.processSingleQuery(ctx, target.Domain, target.recordType(recordTypes[0]), target.nameservers(nameservers))
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary.RecordTypes = recordTypes
+	summary.Nameservers = nameservers
+	return summary, nil
+}
 
-	// Create worker pool
-	var wg sync.WaitGroup
-	for i := 0; i < bp.concurrency; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for domain := range domainChan {
-				result := bp.processSingleQuery(ctx, domain, recordType, nameservers)
-				resultChan <- result
-			}
-		}()
+// ProcessPropagation performs bulk DNS propagation checks; recordType and nameservers apply
+// to targets that don't set their own
+func (bp *BulkProcessor) ProcessPropagation(ctx context.Context, targets []BulkTarget, recordType DNSRecordType, nameservers []string) (*BulkSummary, error) {
+	summary, err := bp.process(ctx, BulkOperationPropagation, targets, false, func(ctx context.Context, target BulkTarget) BulkResult {
+		return bp.processSinglePropagation(ctx, target.Domain, target.recordType(recordType), target.nameservers(nameservers))
+	})
+	if err != nil {
+		return nil, err
 	}
+	summary.RecordTypes = []DNSRecordType{recordType}
+	summary.Nameservers = nameservers
+	return summary, nil
+}
 
-	// Close result channel when all workers are done
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+// ProcessConsistency performs bulk DNS consistency checks; nameservers apply to targets that
+// don't set their own, and per-target record types are ignored
+func (bp *BulkProcessor) ProcessConsistency(ctx context.Context, targets []BulkTarget, nameservers []string) (*BulkSummary, error) {
+	summary, err := bp.process(ctx, BulkOperationConsistency, targets, false, func(ctx context.Context, target BulkTarget) BulkResult {
+		return bp.processSingleConsistency(ctx, target.Domain, target.nameservers(nameservers))
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary.Nameservers = nameservers
+	return summary, nil
+}
+
+// expandRecordTypes turns each target without its own record type into one target per
+// type, dropping pairs that repeat a target which already named that type
+func expandRecordTypes(targets []BulkTarget, recordTypes []DNSRecordType) []BulkTarget {
+	expanded := make([]BulkTarget, 0, len(targets)*len(recordTypes))
+	seen := make(map[string]bool, cap(expanded))
+	add := func(target BulkTarget) {
+		if !seen[target.key()] {
+			seen[target.key()] = true
+			expanded = append(expanded, target)
+		}
+	}
+
+	for _, target := range targets {
+		if target.RecordType != "" {
+			add(target)
+			continue
+		}
+		for _, recordType := range recordTypes {
+			typed := target
+			typed.RecordType = recordType
+			add(typed)
+		}
+	}
+	return expanded
+}
+
+// recordType returns the target's record type, or fallback when the input didn't set one
+func (t BulkTarget) recordType(fallback DNSRecordType) DNSRecordType {
+	if t.RecordType != "" {
+		return t.RecordType
+	}
+	return fallback
+}
+
+// nameservers returns the target's nameserver, or fallback when the input didn't set one
+func (t BulkTarget) nameservers(fallback []string) []string {
+	if t.Nameserver != "" {
+		return []string{t.Nameserver}
+	}
+	return fallback
+}
+
+// process runs processOne over targets on the worker pool, reporting progress and results
+// from the collecting goroutine. With countByType, outcomes are also tallied per result
+// RecordType in BulkSummary.ByRecordType.
+func (bp *BulkProcessor) process(ctx context.Context, operation BulkOperation, targets []BulkTarget, countByType bool, processOne func(ctx context.Context, target BulkTarget) BulkResult) (*BulkSummary, error) {
+	startTime := time.Now()
+	startQueries := bp.resolver.QueryCount()
+
+	var results []BulkResult
+	if bp.resultCallback == nil {
+		results = make([]BulkResult, 0, len(targets))
+	}
 
-	// Collect results and update progress
 	processed := 0
 	successful := 0
-	for result := range resultChan {
+	var byType map[DNSRecordType]BulkTypeCount
+	if countByType {
+		byType = make(map[DNSRecordType]BulkTypeCount)
+	}
+	timedOut, autoRetried, recovered := 0, 0, 0
+	stats := newBulkStatsCollector()
+	record := func(result BulkResult) {
 		processed++
-		results = append(results, result)
+		stats.add(result)
 
 		if result.Success {
 			successful++
 		}
+		if result.timedOut {
+			timedOut++
+		}
+		if result.Retries > 0 {
+			autoRetried++
+			if result.Success {
+				recovered++
+			}
+		}
+
+		if byType != nil {
+			count := byType[result.RecordType]
+			if result.Success {
+				count.Successful++
+			} else {
+				count.Failed++
+			}
+			byType[result.RecordType] = count
+		}
+
+		if bp.resultCallback != nil {
+			bp.resultCallback(result)
+		} else {
+			results = append(results, result)
+		}
 
 		if bp.progressCallback != nil {
-			bp.progressCallback(processed, len(domains), result.Domain, result.Success)
+			bp.progressCallback(processed, len(targets), result.Domain, result.Success)
 		}
 	}
 
-	return &BulkSummary{
-		TotalDomains: len(domains),
-		Successful:   successful,
-		Failed:       len(domains) - successful,
-		Duration:     time.Since(startTime),
-		Results:      results,
-	}, nil
-}
+	// Replay results saved by an interrupted run and queue only the remaining targets
+	var checkpoint *bulkCheckpoint
+	pending := targets
+	if bp.checkpointPath != "" {
+		var err error
+		checkpoint, err = loadCheckpoint(bp.checkpointPath, operation)
+		if err != nil {
+			return nil, err
+		}
 
-// ProcessPropagation performs bulk DNS propagation checks
-func (bp *BulkProcessor) ProcessPropagation(ctx context.Context, domains []string, recordType DNSRecordType, nameservers []string) (*BulkSummary, error) {
-	startTime := time.Now()
-	results := make([]BulkResult, 0, len(domains))
+		wanted := make(map[string]bool, len(targets))
+		for _, target := range targets {
+			wanted[target.key()] = true
+		}
+		saved := checkpoint.Results[:0]
+		for _, result := range checkpoint.Results {
+			key := result.target().key()
+			if wanted[key] {
+				delete(wanted, key)
+				saved = append(saved, result)
+				record(result)
+			}
+		}
+		checkpoint.Results = saved
 
-	// Create a channel for domains to process
-	domainChan := make(chan string, len(domains))
-	for _, domain := range domains {
-		domainChan <- domain
+		pending = make([]BulkTarget, 0, len(wanted))
+		for _, target := range targets {
+			if wanted[target.key()] {
+				pending = append(pending, target)
+			}
+		}
 	}
-	close(domainChan)
 
-	// Create a channel for results
-	resultChan := make(chan BulkResult, len(domains))
+	if bp.tracePrefix != "" {
+		processOne = bp.traced(operation, pending, processOne)
+	}
 
-	// Create worker pool
-	var wg sync.WaitGroup
-	for i := 0; i < bp.concurrency; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for domain := range domainChan {
-				result := bp.processSinglePropagation(ctx, domain, recordType, nameservers)
-				resultChan <- result
-			}
-		}()
+	concurrency := bp.concurrency
+	if concurrency <= 0 {
+		concurrency = 1
 	}
 
-	// Close result channel when all workers are done
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+	// In adaptive mode enough workers for the upper bound are started, and the controller
+	// decides how many of them may work at once
+	workers := concurrency
+	var controller *concurrencyController
+	if bp.adaptiveMax > 0 {
+		controller = newConcurrencyController(concurrency, bp.adaptiveMin, bp.adaptiveMax)
+		workers = bp.adaptiveMax
+	}
 
-	// Collect results and update progress
-	processed := 0
-	successful := 0
-	for result := range resultChan {
-		processed++
-		results = append(results, result)
+	// Collect results and update progress; only this goroutine touches the checkpoint.
+	// A failed checkpoint write is reported but doesn't stop the run.
+	lastSave := time.Now()
+	finish := func(result BulkResult) {
+		record(result)
+
+		if checkpoint != nil && result.Success {
+			checkpoint.Results = append(checkpoint.Results, result)
+			if time.Since(lastSave) >= checkpointInterval {
+				if err := checkpoint.save(bp.checkpointPath); err != nil {
+					bp.warn(err)
+				}
+				lastSave = time.Now()
+			}
+		}
+	}
 
-		if result.Success {
-			successful++
+	// Failures are held back while retries remain, then queued together for the next pass
+	var failed []BulkResult
+	for pass := 0; pass <= bp.retries; pass++ {
+		if pass > 0 {
+			if len(failed) == 0 || ctx.Err() != nil {
+				break
+			}
+			pending = make([]BulkTarget, len(failed))
+			for i, result := range failed {
+				pending[i] = result.target()
+			}
+			failed = failed[:0]
 		}
 
-		if bp.progressCallback != nil {
-			bp.progressCallback(processed, len(domains), result.Domain, result.Success)
+		for result := range bp.runPass(ctx, pending, workers, controller, processOne) {
+			result.Retries = pass
+			if !result.Success && pass < bp.retries {
+				failed = append(failed, result)
+				continue
+			}
+			finish(result)
+		}
+	}
+	// Left over when the run's context ended before every retry pass could run
+	for _, result := range failed {
+		finish(result)
+	}
+
+	if checkpoint != nil {
+		if err := checkpoint.save(bp.checkpointPath); err != nil {
+			bp.warn(err)
 		}
 	}
 
+	duration := time.Since(startTime)
+	queries := bp.resolver.QueryCount() - startQueries
+	var qps float64
+	if duration > 0 {
+		qps = float64(queries) / duration.Seconds()
+	}
+
+	if controller != nil {
+		concurrency = controller.current()
+	}
+
 	return &BulkSummary{
-		TotalDomains: len(domains),
+		Operation:    operation,
+		Concurrency:  concurrency,
+		TimedOut:     timedOut,
+		AutoRetried:  autoRetried,
+		Recovered:    recovered,
+		Stats:        stats.stats(),
+		TotalDomains: len(targets),
 		Successful:   successful,
-		Failed:       len(domains) - successful,
-		Duration:     time.Since(startTime),
+		Failed:       len(targets) - successful,
+		Duration:     duration,
+		Queries:      queries,
+		QPS:          qps,
+		ByRecordType: byType,
 		Results:      results,
 	}, nil
 }
 
-// ProcessConsistency performs bulk DNS consistency checks
-func (bp *BulkProcessor) ProcessConsistency(ctx context.Context, domains []string, nameservers []string) (*BulkSummary, error) {
-	startTime := time.Now()
-	results := make([]BulkResult, 0, len(domains))
-
-	// Create a channel for domains to process
-	domainChan := make(chan string, len(domains))
-	for _, domain := range domains {
-		domainChan <- domain
-	}
-	close(domainChan)
+// runPass processes targets with a pool of workers, gated by controller in adaptive mode,
+// and returns their results as they complete; the channel closes once all are done
+func (bp *BulkProcessor) runPass(ctx context.Context, targets []BulkTarget, workers int, controller *concurrencyController, processOne func(ctx context.Context, target BulkTarget) BulkResult) <-chan BulkResult {
+	// Feed targets to the workers; the small buffers keep in-flight results bounded by concurrency
+	targetChan := make(chan BulkTarget, workers)
+	go func() {
+		defer close(targetChan)
+		for _, target := range targets {
+			targetChan <- target
+		}
+	}()
 
-	// Create a channel for results
-	resultChan := make(chan BulkResult, len(domains))
+	resultChan := make(chan BulkResult, workers)
 
 	// Create worker pool
 	var wg sync.WaitGroup
-	for i := 0; i < bp.concurrency; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for domain := range domainChan {
-				result := bp.processSingleConsistency(ctx, domain, nameservers)
+			for target := range targetChan {
+				if controller != nil {
+					controller.acquire()
+				}
+
+				result := bp.runOne(ctx, target, processOne)
+
+				if controller != nil {
+					controller.release(!result.Success)
+				}
 				resultChan <- result
 			}
 		}()
@@ -255,29 +769,58 @@ func (bp *BulkProcessor) ProcessConsistency(ctx context.Context, domains []strin
 		close(resultChan)
 	}()
 
-	// Collect results and update progress
-	processed := 0
-	successful := 0
-	for result := range resultChan {
-		processed++
-		results = append(results, result)
+	return resultChan
+}
 
-		if result.Success {
-			successful++
-		}
+// runOne processes a single target under the per-domain timeout, if any
+func (bp *BulkProcessor) runOne(ctx context.Context, target BulkTarget, processOne func(ctx context.Context, target BulkTarget) BulkResult) BulkResult {
+	taskCtx := ctx
+	if bp.perDomainTimeout > 0 {
+		var cancel context.CancelFunc
+		taskCtx, cancel = context.WithTimeout(ctx, bp.perDomainTimeout)
+		defer cancel()
+	}
 
-		if bp.progressCallback != nil {
-			bp.progressCallback(processed, len(domains), result.Domain, result.Success)
+	result := processOne(taskCtx, target)
+	result.RecordType = target.RecordType
+	result.Nameserver = target.Nameserver
+
+	// Only the per-domain deadline counts; the run's own context ending is not a timeout here
+	result.timedOut = !result.Success && ctx.Err() == nil && errors.Is(taskCtx.Err(), context.DeadlineExceeded)
+	if result.timedOut {
+		result.Error = fmt.Errorf("timed out after %v: %w", bp.perDomainTimeout, result.Error)
+	}
+	return result
+}
+
+// traced wraps processOne to run each target under its trace ID, logging when the target
+// starts and finishes. IDs are assigned up front so retry passes reuse them, and the map is
+// only read once the workers start.
+func (bp *BulkProcessor) traced(operation BulkOperation, targets []BulkTarget, processOne func(ctx context.Context, target BulkTarget) BulkResult) func(ctx context.Context, target BulkTarget) BulkResult {
+	ids := make(map[string]string, len(targets))
+	for _, target := range targets {
+		ids[target.key()] = NewTraceID(bp.tracePrefix)
+	}
+
+	return func(ctx context.Context, target BulkTarget) BulkResult {
+		id := ids[target.key()]
+		ctx = WithTraceID(ctx, id)
+		slog.Debug("bulk task started", "trace_id", id, "operation", operation, "domain", target.Domain)
+
+		result := processOne(ctx, target)
+		result.TraceID = id
+		attrs := []any{"trace_id", id, "operation", operation, "domain", target.Domain, "success", result.Success, "duration", result.EndTime.Sub(result.StartTime)}
+		if result.Error != nil {
+			attrs = append(attrs, "error", result.Error)
 		}
+		slog.Debug("bulk task finished", attrs...)
+		return result
 	}
+}
 
-	return &BulkSummary{
-		TotalDomains: len(domains),
-		Successful:   successful,
-		Failed:       len(domains) - successful,
-		Duration:     time.Since(startTime),
-		Results:      results,
-	}, nil
+// target returns the input row that produced the result
+func (r BulkResult) target() BulkTarget {
+	return BulkTarget{Domain: r.Domain, RecordType: r.RecordType, Nameserver: r.Nameserver}
 }
 
 // processSingleQuery processes a single domain query
@@ -295,7 +838,7 @@ func (bp *BulkProcessor) processSingleQuery(ctx context.Context, domain string,
 		Error:     err,
 		StartTime: startTime,
 		EndTime:   time.Now(),
-		Data:      result,
+		Query:     result,
 	}
 }
 
@@ -306,12 +849,12 @@ func (bp *BulkProcessor) processSinglePropagation(ctx context.Context, domain st
 	result, err := bp.resolver.CheckPropagation(ctx, domain, recordType, nameservers)
 
 	return BulkResult{
-		Domain:    domain,
-		Success:   err == nil,
-		Error:     err,
-		StartTime: startTime,
-		EndTime:   time.Now(),
-		Data:      result,
+		Domain:      domain,
+		Success:     err == nil,
+		Error:       err,
+		StartTime:   startTime,
+		EndTime:     time.Now(),
+		Propagation: result,
 	}
 }
 
@@ -319,22 +862,31 @@ func (bp *BulkProcessor) processSinglePropagation(ctx context.Context, domain st
 func (bp *BulkProcessor) processSingleConsistency(ctx context.Context, domain string, nameservers []string) BulkResult {
 	startTime := time.Now()
 
-	issues, err := bp.consistencyChecker.CheckConsistency(ctx, domain, nameservers)
+	report, err := bp.consistencyChecker.CheckConsistencyReport(ctx, domain, nameservers)
 
 	// Consider it successful if no error occurred (even if issues were found)
 	success := err == nil
 
 	return BulkResult{
-		Domain:    domain,
-		Success:   success,
-		Error:     err,
-		StartTime: startTime,
-		EndTime:   time.Now(),
-		Data:      issues,
+		Domain:      domain,
+		Success:     success,
+		Error:       err,
+		StartTime:   startTime,
+		EndTime:     time.Now(),
+		Consistency: report,
+	}
+}
+
+// normalizeDomain lowercases a domain, strips a trailing root dot and converts
+// Unicode labels to punycode; a name IDNA rejects is left for isValidDomain to refuse
+func normalizeDomain(domain string) string {
+	if ascii, err := ToASCII(domain); err == nil {
+		domain = ascii
 	}
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
 }
 
-// isValidDomain performs basic domain validation
+// isValidDomain performs basic domain validation on a normalized (ASCII) domain
 func isValidDomain(domain string) bool {
 	// Basic validation - can be enhanced
 	if domain == "" || len(domain) > 253 {
@@ -344,7 +896,7 @@ func isValidDomain(domain string) bool {
 	// Check for valid characters
 	for _, r := range domain {
 		if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
-			(r >= '0' && r <= '9') || r == '.' || r == '-') {
+			(r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_') {
 			return false
 		}
 	}
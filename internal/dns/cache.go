@@ -0,0 +1,91 @@
+// =============================================================================
+// internal/dns/cache.go - TTL-bounded cache of query answers
+// =============================================================================
+package dns
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheSweepSize is the entry count above which a store first drops expired entries
+const cacheSweepSize = 10000
+
+// queryCache keeps successful answers until their lowest record TTL runs out. Failed and
+// empty answers are never cached. It is safe for concurrent use.
+type queryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is a cached answer and the moment it stops being valid
+type cacheEntry struct {
+	result  DNSResult
+	expires time.Time
+}
+
+func newQueryCache() *queryCache {
+	return &queryCache{entries: make(map[string]cacheEntry)}
+}
+
+// cacheKey identifies a query; names are compared case-insensitively and with or without
+// the trailing dot
+func cacheKey(domain string, recordType DNSRecordType, nameserver string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, ".")) + "|" + string(recordType) + "|" + nameserver
+}
+
+// get returns a copy of the cached answer for key, marked Cached, if it hasn't expired
+func (c *queryCache) get(key string) (*DNSResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	result := entry.result
+	result.Records = slices.Clone(entry.result.Records)
+	result.Cached = true
+	result.ResponseTime = 0
+	result.ConnectTime = 0
+	result.QueryTime = 0
+	result.Attempts = 0
+	return &result, true
+}
+
+// put caches result for the lowest TTL among its records
+func (c *queryCache) put(key string, result *DNSResult) {
+	if result.Error != nil || len(result.Records) == 0 {
+		return
+	}
+	ttl := result.Records[0].TTL
+	for _, record := range result.Records[1:] {
+		ttl = min(ttl, record.TTL)
+	}
+	if ttl == 0 {
+		return
+	}
+
+	entry := cacheEntry{result: *result, expires: time.Now().Add(time.Duration(ttl) * time.Second)}
+	entry.result.Records = slices.Clone(result.Records)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= cacheSweepSize {
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = entry
+}
@@ -6,51 +6,106 @@ package dns
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+)
+
+// Default TTL thresholds for the TTL sanity pass
+const (
+	DefaultMinTTL = 60 * time.Second   // Shorter TTLs make resolvers hammer the authoritative servers
+	DefaultMaxTTL = 7 * 24 * time.Hour // Longer TTLs make failover and renumbering painfully slow
+)
+
+// A server's TTL diverges when it exceeds the median across servers by both of these;
+// caches count TTLs down, so only unusually high values point at stale data
+const (
+	ttlDivergenceFactor   = 2
+	ttlDivergenceMinDelta = 5 * time.Minute
 )
 
 // ConsistencyChecker checks for DNS consistency issues
 type ConsistencyChecker struct {
-	resolver *Resolver
+	resolver   *Resolver
+	checkMXPTR bool
+	minTTL     time.Duration
+	maxTTL     time.Duration
+
+	allowPrivateIPs bool
+	dkimSelectors   []string
+	enabledChecks   map[string]bool // Empty means every check
+	skippedChecks   map[string]bool
 }
 
 // NewConsistencyChecker creates a new consistency checker
 func NewConsistencyChecker(resolver *Resolver) *ConsistencyChecker {
 	return &ConsistencyChecker{
 		resolver: resolver,
+		minTTL:   DefaultMinTTL,
+		maxTTL:   DefaultMaxTTL,
 	}
 }
 
+// SetCheckMXReverseDNS enables forward-confirmed reverse DNS checks on MX hosts
+func (c *ConsistencyChecker) SetCheckMXReverseDNS(enabled bool) {
+	c.checkMXPTR = enabled
+}
+
+// SetAllowPrivateIPs disables the private/reserved address check, for split-horizon zones
+func (c *ConsistencyChecker) SetAllowPrivateIPs(allow bool) {
+	c.allowPrivateIPs = allow
+}
+
+// SetDKIMSelectors adds selectors to probe for DKIM keys beyond DefaultDKIMSelectors
+func (c *ConsistencyChecker) SetDKIMSelectors(selectors []string) {
+	c.dkimSelectors = selectors
+}
+
+// SetTTLThresholds sets the TTL range outside which records are flagged
+func (c *ConsistencyChecker) SetTTLThresholds(min, max time.Duration) {
+	c.minTTL = min
+	c.maxTTL = max
+}
+
 // CheckConsistency performs comprehensive DNS consistency checks
 func (c *ConsistencyChecker) CheckConsistency(ctx context.Context, domain string, nameservers []string) ([]ConsistencyIssue, error) {
-	var issues []ConsistencyIssue
+	report, err := c.CheckConsistencyReport(ctx, domain, nameservers)
+	if err != nil {
+		return nil, err
+	}
+	return report.Issues, nil
+}
 
-	// Check common record types
-	recordTypes := []DNSRecordType{RecordTypeA, RecordTypeAAAA, RecordTypeMX, RecordTypeNS, RecordTypeTXT}
+// CheckConsistencyReport runs the selected checks (see SetChecks) and reports which ran
+// alongside the issues they found
+func (c *ConsistencyChecker) CheckConsistencyReport(ctx context.Context, domain string, nameservers []string) (*ConsistencyReport, error) {
+	report := &ConsistencyReport{
+		Domain:    domain,
+		Checks:    []string{},
+		Issues:    []ConsistencyIssue{},
+		Timestamp: time.Now(),
+	}
+	run := &consistencyRun{
+		domain:      domain,
+		nameservers: nameservers,
+		results:     make(map[DNSRecordType]*PropagationResult),
+	}
 
-	for _, recordType := range recordTypes {
-		propagation, err := c.resolver.CheckPropagation(ctx, domain, recordType, nameservers)
-		if err != nil {
+	for _, check := range consistencyChecks {
+		if !c.checkEnabled(check) {
 			continue
 		}
-
-		if propagation.Inconsistent {
-			issue := ConsistencyIssue{
-				Type:        "propagation_inconsistency",
-				Domain:      domain,
-				RecordType:  recordType,
-				Description: fmt.Sprintf("%s records are inconsistent across nameservers", recordType),
-				Severity:    c.determineSeverity(recordType),
-				Servers:     c.getInconsistentServers(propagation.Results),
-			}
-			issues = append(issues, issue)
-		}
-
-		// Check for other issues
-		issues = append(issues, c.checkSpecificIssues(propagation, recordType)...)
+		report.Checks = append(report.Checks, check.name)
+		report.Issues = append(report.Issues, check.run(c, ctx, run)...)
 	}
+	addRemediations(report.Issues)
 
-	return issues, nil
+	return report, nil
 }
 
 // determineSeverity determines the severity of an issue based on record type
@@ -74,22 +129,6 @@ func (c *ConsistencyChecker) getInconsistentServers(results map[string][]DNSReco
 	return servers
 }
 
-// checkSpecificIssues checks for specific DNS configuration issues
-func (c *ConsistencyChecker) checkSpecificIssues(propagation *PropagationResult, recordType DNSRecordType) []ConsistencyIssue {
-	var issues []ConsistencyIssue
-
-	switch recordType {
-	case RecordTypeMX:
-		issues = append(issues, c.checkMXIssues(propagation)...)
-	case RecordTypeNS:
-		issues = append(issues, c.checkNSIssues(propagation)...)
-	case RecordTypeTXT:
-		issues = append(issues, c.checkTXTIssues(propagation)...)
-	}
-
-	return issues
-}
-
 // checkMXIssues checks for MX record specific issues
 func (c *ConsistencyChecker) checkMXIssues(propagation *PropagationResult) []ConsistencyIssue {
 	var issues []ConsistencyIssue
@@ -98,7 +137,7 @@ func (c *ConsistencyChecker) checkMXIssues(propagation *PropagationResult) []Con
 		for _, record := range records {
 			if record.Priority == 0 {
 				issues = append(issues, ConsistencyIssue{
-					Type:        "mx_priority_zero",
+					Type:        IssueMXPriorityZero,
 					Domain:      propagation.Domain,
 					RecordType:  RecordTypeMX,
 					Description: "MX record has priority 0, which may cause mail delivery issues",
@@ -113,6 +152,113 @@ func (c *ConsistencyChecker) checkMXIssues(propagation *PropagationResult) []Con
 	return issues
 }
 
+// checkMXHosts resolves each distinct MX target and checks it against RFC 2181/5321 expectations:
+// no IP literals, no CNAME targets, an address record, and (optionally) matching reverse DNS
+func (c *ConsistencyChecker) checkMXHosts(ctx context.Context, propagation *PropagationResult) []ConsistencyIssue {
+	var servers []string
+	for server := range propagation.Results {
+		servers = append(servers, server)
+	}
+	if len(servers) == 0 {
+		return nil
+	}
+	sort.Strings(servers)
+	ns := servers[0]
+
+	var issues []ConsistencyIssue
+	for _, host := range mxTargets(propagation) {
+		if net.ParseIP(strings.TrimSuffix(host, ".")) != nil {
+			issues = append(issues, mxHostIssue(propagation.Domain, ns, IssueMXIPLiteral,
+				"MX record points to an IP address instead of a hostname", "high", host))
+			continue
+		}
+
+		if chain, err := c.resolver.lookupCNAMEChain(ctx, host, ns); err == nil && len(chain) > 0 {
+			issues = append(issues, mxHostIssue(propagation.Domain, ns, IssueMXTargetCNAME,
+				fmt.Sprintf("MX target is a CNAME to %s, which RFC 2181 forbids", chain[len(chain)-1].Target), "medium", host))
+		}
+
+		addresses, err := c.resolver.lookupIPs(ctx, host, ns)
+		if err != nil {
+			continue
+		}
+		if len(addresses) == 0 {
+			issues = append(issues, mxHostIssue(propagation.Domain, ns, IssueMXTargetNoAddress,
+				"MX target has no A or AAAA record, so mail cannot be delivered to it", "high", host))
+			continue
+		}
+
+		if c.checkMXPTR {
+			issues = append(issues, c.checkMXReverseDNS(ctx, propagation.Domain, host, addresses, ns)...)
+		}
+	}
+
+	return issues
+}
+
+// mxTargets returns the distinct MX hosts returned by any server, lowercased, in server order
+func mxTargets(propagation *PropagationResult) []string {
+	var servers []string
+	for server := range propagation.Results {
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, server := range servers {
+		for _, record := range propagation.Results[server] {
+			host := strings.ToLower(record.Value)
+			if !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts
+}
+
+// checkMXReverseDNS verifies forward-confirmed reverse DNS for each address of an MX host
+func (c *ConsistencyChecker) checkMXReverseDNS(ctx context.Context, domain, host string, addresses []string, ns string) []ConsistencyIssue {
+	var issues []ConsistencyIssue
+
+	for _, address := range addresses {
+		rdns, err := c.resolver.CheckReverseDNS(ctx, address, ns)
+		if err != nil {
+			continue
+		}
+		if len(rdns.PTRs) == 0 {
+			issues = append(issues, mxHostIssue(domain, ns, IssueMXMissingPTR,
+				fmt.Sprintf("MX host address %s has no PTR record", address), "medium", host))
+			continue
+		}
+
+		if !rdns.Confirmed {
+			var names []string
+			for _, ptr := range rdns.PTRs {
+				names = append(names, ptr.Name)
+			}
+			issues = append(issues, mxHostIssue(domain, ns, IssueMXPTRMismatch,
+				fmt.Sprintf("PTR for MX host address %s (%s) does not resolve back to it", address, strings.Join(names, ", ")), "medium", host))
+		}
+	}
+
+	return issues
+}
+
+// mxHostIssue builds an MX hygiene issue carrying the offending MX host in Actual
+func mxHostIssue(domain, ns, issueType, description, severity, host string) ConsistencyIssue {
+	return ConsistencyIssue{
+		Type:        issueType,
+		Domain:      domain,
+		RecordType:  RecordTypeMX,
+		Description: description,
+		Severity:    severity,
+		Servers:     []string{ns},
+		Actual:      host,
+	}
+}
+
 // checkNSIssues checks for NS record specific issues
 func (c *ConsistencyChecker) checkNSIssues(propagation *PropagationResult) []ConsistencyIssue {
 	var issues []ConsistencyIssue
@@ -120,7 +266,7 @@ func (c *ConsistencyChecker) checkNSIssues(propagation *PropagationResult) []Con
 	for server, records := range propagation.Results {
 		if len(records) < 2 {
 			issues = append(issues, ConsistencyIssue{
-				Type:        "insufficient_nameservers",
+				Type:        IssueInsufficientNameservers,
 				Domain:      propagation.Domain,
 				RecordType:  RecordTypeNS,
 				Description: "Domain has fewer than 2 nameservers, which may cause reliability issues",
@@ -134,6 +280,730 @@ func (c *ConsistencyChecker) checkNSIssues(propagation *PropagationResult) []Con
 	return issues
 }
 
+// nameserverProbeTimeout bounds the direct SOA query sent to each NS host
+const nameserverProbeTimeout = 5 * time.Second
+
+// nsProbe is the outcome of querying one NS host directly
+type nsProbe struct {
+	address string
+	issue   *ConsistencyIssue
+}
+
+// checkNameserverHealth queries every NS host directly for the zone's SOA and flags hosts that
+// time out (unreachable_nameserver) or answer without authority (lame_delegation). It also warns
+// when all NS hosts sit in the same network prefix.
+func (c *ConsistencyChecker) checkNameserverHealth(ctx context.Context, propagation *PropagationResult) []ConsistencyIssue {
+	servers := make([]string, 0, len(propagation.Results))
+	for server := range propagation.Results {
+		servers = append(servers, server)
+	}
+	if len(servers) == 0 {
+		return nil
+	}
+	sort.Strings(servers)
+	recursive := servers[0]
+
+	// NS targets seen by any server
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, server := range servers {
+		for _, record := range propagation.Results[server] {
+			host := strings.ToLower(record.Value)
+			if record.Type == RecordTypeNS && !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	sort.Strings(hosts)
+
+	probes := make([]nsProbe, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			probes[i] = c.probeNameserver(ctx, propagation.Domain, host, recursive)
+		}(i, host)
+	}
+	wg.Wait()
+
+	var issues []ConsistencyIssue
+	var addresses []string
+	for _, probe := range probes {
+		if probe.issue != nil {
+			issues = append(issues, *probe.issue)
+		}
+		if probe.address != "" {
+			addresses = append(addresses, probe.address)
+		}
+	}
+
+	if len(addresses) > 1 {
+		if prefix, shared := sharedPrefix(addresses); shared {
+			issues = append(issues, ConsistencyIssue{
+				Type:        IssueNameserversNotDiverse,
+				Domain:      propagation.Domain,
+				RecordType:  RecordTypeNS,
+				Description: fmt.Sprintf("All nameservers are in %s, so a single network outage takes the zone offline", prefix),
+				Severity:    "medium",
+				Servers:     []string{recursive},
+				Expected:    "nameservers in different networks",
+				Actual:      strings.Join(addresses, ", "),
+			})
+		}
+	}
+
+	return issues
+}
+
+// probeNameserver resolves host and sends it a direct SOA query for zone
+func (c *ConsistencyChecker) probeNameserver(ctx context.Context, zone, host, recursive string) nsProbe {
+	var probe nsProbe
+
+	addresses, err := c.resolver.lookupIPs(ctx, host, recursive)
+	if err != nil || len(addresses) == 0 {
+		probe.issue = &ConsistencyIssue{
+			Type:        IssueUnreachableNameserver,
+			Domain:      zone,
+			RecordType:  RecordTypeNS,
+			Description: fmt.Sprintf("Nameserver %s has no A or AAAA record", host),
+			Severity:    "high",
+			Servers:     []string{recursive},
+			Actual:      host,
+		}
+		return probe
+	}
+
+	// Prefer IPv4, which every resolver can reach
+	probe.address = addresses[0]
+	for _, address := range addresses {
+		if net.ParseIP(address).To4() != nil {
+			probe.address = address
+			break
+		}
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, nameserverProbeTimeout)
+	defer cancel()
+
+	server := net.JoinHostPort(probe.address, "53")
+	rcode, authoritative, err := c.resolver.probeAuthority(probeCtx, zone, server)
+	switch {
+	case err != nil:
+		probe.issue = &ConsistencyIssue{
+			Type:        IssueUnreachableNameserver,
+			Domain:      zone,
+			RecordType:  RecordTypeNS,
+			Description: fmt.Sprintf("Nameserver %s (%s) did not answer an SOA query: %v", host, probe.address, err),
+			Severity:    "high",
+			Servers:     []string{server},
+			Actual:      host,
+		}
+	case rcode != "NOERROR" || !authoritative:
+		reason := "answered without the authoritative (AA) flag"
+		if rcode != "NOERROR" {
+			reason = "responded " + rcode
+		}
+		probe.issue = &ConsistencyIssue{
+			Type:        IssueLameDelegation,
+			Domain:      zone,
+			RecordType:  RecordTypeNS,
+			Description: fmt.Sprintf("Nameserver %s (%s) %s for the zone", host, probe.address, reason),
+			Severity:    "high",
+			Servers:     []string{server},
+			Expected:    "authoritative NOERROR answer",
+			Actual:      host,
+		}
+	}
+
+	return probe
+}
+
+// sharedPrefix reports whether all addresses fall in one /24 (IPv4) or /48 (IPv6)
+func sharedPrefix(addresses []string) (string, bool) {
+	var common netip.Prefix
+	for i, address := range addresses {
+		ip, err := netip.ParseAddr(address)
+		if err != nil {
+			return "", false
+		}
+		ip = ip.Unmap()
+
+		bits := 48
+		if ip.Is4() {
+			bits = 24
+		}
+		prefix, err := ip.Prefix(bits)
+		if err != nil {
+			return "", false
+		}
+
+		if i == 0 {
+			common = prefix
+		} else if prefix != common {
+			return "", false
+		}
+	}
+	return common.String(), true
+}
+
+// RFC 1912 section 2.2 recommended SOA timer ranges, in seconds
+var soaTimerRanges = []struct {
+	name      string
+	issueType string
+	min, max  uint32
+	value     func(*SOARecord) uint32
+}{
+	{"refresh", IssueSOARefreshOutOfRange, 1200, 43200, func(soa *SOARecord) uint32 { return soa.Refresh }},
+	{"retry", IssueSOARetryOutOfRange, 120, 7200, func(soa *SOARecord) uint32 { return soa.Retry }},
+	{"expire", IssueSOAExpireOutOfRange, 1209600, 2419200, func(soa *SOARecord) uint32 { return soa.Expire }},
+	{"minimum", IssueSOAMinimumOutOfRange, 3600, 86400, func(soa *SOARecord) uint32 { return soa.MinimumTTL }},
+}
+
+// checkSOAIssues detects serial drift between servers and SOA timers outside RFC 1912 ranges
+func (c *ConsistencyChecker) checkSOAIssues(propagation *PropagationResult) []ConsistencyIssue {
+	var issues []ConsistencyIssue
+
+	soas := make(map[string]*SOARecord)
+	for server, records := range propagation.Results {
+		for _, record := range records {
+			if record.SOA != nil {
+				soas[server] = record.SOA
+				break
+			}
+		}
+	}
+	if len(soas) == 0 {
+		return nil
+	}
+
+	servers := make([]string, 0, len(soas))
+	for server := range soas {
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+
+	// Serial drift: the highest serial is what every server should eventually serve
+	var highest uint32
+	serials := make(map[uint32]bool)
+	var perServer []string
+	for _, server := range servers {
+		serial := soas[server].Serial
+		serials[serial] = true
+		if serial > highest {
+			highest = serial
+		}
+		perServer = append(perServer, fmt.Sprintf("%s=%d", server, serial))
+	}
+
+	if len(serials) > 1 {
+		var lagging []string
+		for _, server := range servers {
+			if soas[server].Serial != highest {
+				lagging = append(lagging, server)
+			}
+		}
+		issues = append(issues, ConsistencyIssue{
+			Type:        IssueSOASerialMismatch,
+			Domain:      propagation.Domain,
+			RecordType:  RecordTypeSOA,
+			Description: "SOA serial numbers differ between nameservers; some servers have not picked up the latest zone",
+			Severity:    "high",
+			Servers:     lagging,
+			Expected:    fmt.Sprintf("%d", highest),
+			Actual:      strings.Join(perServer, ", "),
+		})
+	}
+
+	// Timer ranges, reported once per distinct value
+	for _, timer := range soaTimerRanges {
+		outOfRange := make(map[uint32][]string)
+		for _, server := range servers {
+			if value := timer.value(soas[server]); value < timer.min || value > timer.max {
+				outOfRange[value] = append(outOfRange[value], server)
+			}
+		}
+
+		for value, affected := range outOfRange {
+			issues = append(issues, ConsistencyIssue{
+				Type:        timer.issueType,
+				Domain:      propagation.Domain,
+				RecordType:  RecordTypeSOA,
+				Description: fmt.Sprintf("SOA %s value is outside the RFC 1912 recommended range", timer.name),
+				Severity:    "low",
+				Servers:     affected,
+				Expected:    fmt.Sprintf("%d-%d seconds", timer.min, timer.max),
+				Actual:      fmt.Sprintf("%d seconds", value),
+			})
+		}
+	}
+
+	return issues
+}
+
+// checkDelegation compares the NS set registered at the parent zone with the zone's own NS records
+func (c *ConsistencyChecker) checkDelegation(ctx context.Context, domain string, nameservers []string) []ConsistencyIssue {
+	if len(nameservers) == 0 {
+		return nil
+	}
+
+	delegation, err := c.resolver.CheckDelegation(ctx, domain, nameservers[0])
+	if err != nil {
+		return nil
+	}
+
+	onlyParent := difference(delegation.ParentNS, delegation.ChildNS)
+	onlyChild := difference(delegation.ChildNS, delegation.ParentNS)
+
+	var issues []ConsistencyIssue
+	if len(onlyParent) > 0 {
+		issues = append(issues, ConsistencyIssue{
+			Type:        IssueDelegationMismatch,
+			Domain:      strings.TrimSuffix(delegation.Zone, "."),
+			RecordType:  RecordTypeNS,
+			Description: fmt.Sprintf("Nameservers delegated by parent zone %s are missing from the zone's own NS records", delegation.ParentZone),
+			Severity:    "high",
+			Servers:     onlyParent,
+			Expected:    strings.Join(delegation.ParentNS, ", "),
+			Actual:      strings.Join(delegation.ChildNS, ", "),
+		})
+	}
+	if len(onlyChild) > 0 {
+		issues = append(issues, ConsistencyIssue{
+			Type:        IssueDelegationMismatch,
+			Domain:      strings.TrimSuffix(delegation.Zone, "."),
+			RecordType:  RecordTypeNS,
+			Description: fmt.Sprintf("Nameservers published by the zone are not delegated by parent zone %s", delegation.ParentZone),
+			Severity:    "high",
+			Servers:     onlyChild,
+			Expected:    strings.Join(delegation.ParentNS, ", "),
+			Actual:      strings.Join(delegation.ChildNS, ", "),
+		})
+	}
+
+	return issues
+}
+
+// checkDanglingCNAMEs resolves every CNAME target for domain and flags targets that do not exist,
+// naming the hosting provider when the target belongs to a takeover-prone service
+func (c *ConsistencyChecker) checkDanglingCNAMEs(ctx context.Context, domain string, nameservers []string) []ConsistencyIssue {
+	if len(nameservers) == 0 {
+		return nil
+	}
+	ns := nameservers[0]
+
+	chain, err := c.resolver.lookupCNAMEChain(ctx, domain, ns)
+	if err != nil {
+		return nil
+	}
+
+	var issues []ConsistencyIssue
+	for _, link := range chain {
+		dangling, err := c.resolver.isNXDomain(ctx, link.Target, ns)
+		if err != nil || !dangling {
+			continue
+		}
+
+		issues = append(issues, ConsistencyIssue{
+			Type:        IssueDanglingCNAME,
+			Domain:      domain,
+			RecordType:  RecordTypeCNAME,
+			Description: fmt.Sprintf("CNAME %s points to a target that does not exist (NXDOMAIN)", link.Owner),
+			Severity:    "high",
+			Servers:     []string{ns},
+			Actual:      link.Target,
+		})
+
+		if fingerprint, ok := MatchTakeoverFingerprint(link.Target); ok {
+			issues = append(issues, ConsistencyIssue{
+				Type:        IssuePossibleSubdomainTakeover,
+				Domain:      domain,
+				RecordType:  RecordTypeCNAME,
+				Description: fmt.Sprintf("CNAME %s points to an unclaimed %s resource that anyone could register", link.Owner, fingerprint.Provider),
+				Severity:    "high",
+				Servers:     []string{ns},
+				Actual:      link.Target,
+			})
+		}
+	}
+
+	return issues
+}
+
+// reservedRanges are address blocks that should never be published in a public zone
+var reservedRanges = []struct {
+	prefix netip.Prefix
+	label  string
+}{
+	{netip.MustParsePrefix("10.0.0.0/8"), "RFC 1918 private"},
+	{netip.MustParsePrefix("172.16.0.0/12"), "RFC 1918 private"},
+	{netip.MustParsePrefix("192.168.0.0/16"), "RFC 1918 private"},
+	{netip.MustParsePrefix("100.64.0.0/10"), "CGNAT shared"},
+	{netip.MustParsePrefix("127.0.0.0/8"), "loopback"},
+	{netip.MustParsePrefix("169.254.0.0/16"), "link-local"},
+	{netip.MustParsePrefix("::1/128"), "loopback"},
+	{netip.MustParsePrefix("fe80::/10"), "link-local"},
+	{netip.MustParsePrefix("fc00::/7"), "unique local (ULA)"},
+}
+
+// reservedRange returns the label of the reserved block containing address, if any
+func reservedRange(address string) (string, bool) {
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return "", false
+	}
+	ip = ip.Unmap()
+	for _, reserved := range reservedRanges {
+		if reserved.prefix.Contains(ip) {
+			return reserved.label, true
+		}
+	}
+	return "", false
+}
+
+// checkPrivateAddresses flags A/AAAA answers that point into private or reserved address space
+func (c *ConsistencyChecker) checkPrivateAddresses(propagation *PropagationResult) []ConsistencyIssue {
+	var issues []ConsistencyIssue
+	index := make(map[string]int)
+
+	servers := make([]string, 0, len(propagation.Results))
+	for server := range propagation.Results {
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+
+	for _, server := range servers {
+		for _, record := range propagation.Results[server] {
+			if record.Type != RecordTypeA && record.Type != RecordTypeAAAA {
+				continue
+			}
+			label, reserved := reservedRange(record.Value)
+			if !reserved {
+				continue
+			}
+
+			key := record.Name + " " + record.Value
+			if i, ok := index[key]; ok {
+				issues[i].Servers = append(issues[i].Servers, server)
+				continue
+			}
+			index[key] = len(issues)
+			issues = append(issues, ConsistencyIssue{
+				Type:        IssuePrivateIPInPublicDNS,
+				Domain:      propagation.Domain,
+				RecordType:  record.Type,
+				Description: fmt.Sprintf("%s %s points to %s address %s", record.Name, record.Type, label, record.Value),
+				Severity:    "medium",
+				Servers:     []string{server},
+				Actual:      record.Value,
+			})
+		}
+	}
+
+	return issues
+}
+
+// checkTTLIssues flags RRsets whose TTL is outside the configured range, and identical records
+// served with different TTLs by authoritative servers. Recursive resolvers count cached TTLs down,
+// so the range check uses the highest TTL seen for a record and only authoritative answers
+// (AA bit set) are compared for variance.
+func (c *ConsistencyChecker) checkTTLIssues(propagation *PropagationResult) []ConsistencyIssue {
+	// record value -> server -> TTL
+	ttls := make(map[string]map[string]uint32)
+	for server, records := range propagation.Results {
+		for _, record := range records {
+			if record.Type != propagation.RecordType {
+				continue
+			}
+			value := strings.ToLower(record.Value)
+			if ttls[value] == nil {
+				ttls[value] = make(map[string]uint32)
+			}
+			ttls[value][server] = record.TTL
+		}
+	}
+
+	values := make([]string, 0, len(ttls))
+	for value := range ttls {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	var issues []ConsistencyIssue
+	for _, value := range values {
+		byServer := ttls[value]
+
+		var highest uint32
+		var servers []string
+		for server, ttl := range byServer {
+			switch {
+			case ttl > highest:
+				highest = ttl
+				servers = []string{server}
+			case ttl == highest:
+				servers = append(servers, server)
+			}
+		}
+		sort.Strings(servers)
+		observed := time.Duration(highest) * time.Second
+
+		if c.minTTL > 0 && observed < c.minTTL {
+			issues = append(issues, ConsistencyIssue{
+				Type:        IssueTTLTooLow,
+				Domain:      propagation.Domain,
+				RecordType:  propagation.RecordType,
+				Description: fmt.Sprintf("%s record %s has a TTL of %s, below the %s minimum", propagation.RecordType, value, observed, c.minTTL),
+				Severity:    "low",
+				Servers:     servers,
+				Expected:    fmt.Sprintf(">= %s", c.minTTL),
+				Actual:      fmt.Sprintf("TTL %s", observed),
+			})
+		}
+
+		if c.maxTTL > 0 && observed > c.maxTTL {
+			issues = append(issues, ConsistencyIssue{
+				Type:        IssueTTLTooHigh,
+				Domain:      propagation.Domain,
+				RecordType:  propagation.RecordType,
+				Description: fmt.Sprintf("%s record %s has a TTL of %s, above the %s maximum", propagation.RecordType, value, observed, c.maxTTL),
+				Severity:    "low",
+				Servers:     servers,
+				Expected:    fmt.Sprintf("<= %s", c.maxTTL),
+				Actual:      fmt.Sprintf("TTL %s", observed),
+			})
+		}
+
+		// Authoritative servers should all serve the same TTL for the same record
+		var authoritative []string
+		distinct := make(map[uint32]bool)
+		for _, server := range propagation.Authoritative {
+			if ttl, ok := byServer[server]; ok {
+				authoritative = append(authoritative, server)
+				distinct[ttl] = true
+			}
+		}
+		if len(distinct) > 1 {
+			sort.Strings(authoritative)
+			observedTTLs := make([]string, len(authoritative))
+			for i, server := range authoritative {
+				observedTTLs[i] = fmt.Sprintf("%s=%d", server, byServer[server])
+			}
+			issues = append(issues, ConsistencyIssue{
+				Type:        IssueTTLVariance,
+				Domain:      propagation.Domain,
+				RecordType:  propagation.RecordType,
+				Description: fmt.Sprintf("Authoritative servers return %s record %s with different TTLs, suggesting an un-synced secondary", propagation.RecordType, value),
+				Severity:    "medium",
+				Servers:     authoritative,
+				Actual:      strings.Join(observedTTLs, ", "),
+			})
+		}
+	}
+
+	return issues
+}
+
+// checkTTLDivergence flags servers serving a record with a TTL far above what the other
+// servers report, such as a cache holding a stale high TTL. Unlike ttl_variance it
+// compares every queried server, recursive or not.
+func (c *ConsistencyChecker) checkTTLDivergence(propagation *PropagationResult) []ConsistencyIssue {
+	// record value -> server -> TTL
+	ttls := make(map[string]map[string]uint32)
+	for server, records := range propagation.Results {
+		for _, record := range records {
+			if record.Type != propagation.RecordType {
+				continue
+			}
+			value := strings.ToLower(record.Value)
+			if ttls[value] == nil {
+				ttls[value] = make(map[string]uint32)
+			}
+			ttls[value][server] = record.TTL
+		}
+	}
+
+	values := make([]string, 0, len(ttls))
+	for value := range ttls {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	var issues []ConsistencyIssue
+	for _, value := range values {
+		byServer := ttls[value]
+		if len(byServer) < 3 {
+			continue // Too few servers to tell an outlier from normal cache aging
+		}
+
+		servers := make([]string, 0, len(byServer))
+		observed := make([]uint32, 0, len(byServer))
+		for server, ttl := range byServer {
+			servers = append(servers, server)
+			observed = append(observed, ttl)
+		}
+		sort.Strings(servers)
+		slices.Sort(observed)
+		median := time.Duration(observed[len(observed)/2]) * time.Second
+
+		var divergent []string
+		for _, server := range servers {
+			ttl := time.Duration(byServer[server]) * time.Second
+			if ttl > median*ttlDivergenceFactor && ttl-median >= ttlDivergenceMinDelta {
+				divergent = append(divergent, server)
+			}
+		}
+		if len(divergent) == 0 {
+			continue
+		}
+
+		perServer := make([]string, len(servers))
+		for i, server := range servers {
+			perServer[i] = fmt.Sprintf("%s=%d", server, byServer[server])
+		}
+		issues = append(issues, ConsistencyIssue{
+			Type:        IssueTTLDivergence,
+			Domain:      propagation.Domain,
+			RecordType:  propagation.RecordType,
+			Description: fmt.Sprintf("%s record %s is served with a TTL far above the %s median by %d server(s); TTLs: %s", propagation.RecordType, value, median, len(divergent), strings.Join(perServer, ", ")),
+			Severity:    "low",
+			Servers:     divergent,
+			Expected:    fmt.Sprintf("TTL near %s", median),
+			Actual:      strings.Join(perServer, ", "),
+		})
+	}
+
+	return issues
+}
+
+// conflictRecordTypes are queried at the same owner name to find records that may not coexist
+var conflictRecordTypes = []DNSRecordType{
+	RecordTypeCNAME, RecordTypeSOA, RecordTypeNS, RecordTypeA, RecordTypeAAAA, RecordTypeMX, RecordTypeTXT,
+}
+
+// checkRecordConflicts flags CNAMEs at the zone apex, CNAMEs that share their owner name with
+// other records (RFC 1034 section 3.6.2), and identical A/AAAA records repeated in one answer
+func (c *ConsistencyChecker) checkRecordConflicts(ctx context.Context, domain string, nameservers []string) []ConsistencyIssue {
+	// Records owned by domain, per server and type
+	owned := make(map[string]map[DNSRecordType][]DNSRecord)
+	for _, recordType := range conflictRecordTypes {
+		results, err := c.resolver.QueryMultipleServers(ctx, domain, recordType, nameservers)
+		if err != nil {
+			continue
+		}
+
+		for i, result := range results {
+			if result == nil || result.Error != nil {
+				continue
+			}
+			for _, record := range result.Records {
+				// A CNAME answer carries the target's records too; keep only this name's own RRset
+				if record.Type != recordType || !sameName(record.Name, domain) {
+					continue
+				}
+				if owned[nameservers[i]] == nil {
+					owned[nameservers[i]] = make(map[DNSRecordType][]DNSRecord)
+				}
+				owned[nameservers[i]][recordType] = append(owned[nameservers[i]][recordType], record)
+			}
+		}
+	}
+
+	// Report each distinct problem once, listing every server that shows it
+	var issues []ConsistencyIssue
+	index := make(map[string]int)
+	add := func(issue ConsistencyIssue, server string) {
+		key := issue.Type + "|" + string(issue.RecordType) + "|" + issue.Actual
+		if i, ok := index[key]; ok {
+			issues[i].Servers = append(issues[i].Servers, server)
+			return
+		}
+		issue.Domain = domain
+		issue.Servers = []string{server}
+		index[key] = len(issues)
+		issues = append(issues, issue)
+	}
+
+	for _, server := range nameservers {
+		records := owned[server]
+
+		if cnames := records[RecordTypeCNAME]; len(cnames) > 0 {
+			// Only the apex owns SOA and NS records
+			if len(records[RecordTypeSOA]) > 0 || len(records[RecordTypeNS]) > 0 {
+				add(ConsistencyIssue{
+					Type:        IssueCNAMEAtApex,
+					RecordType:  RecordTypeCNAME,
+					Description: "CNAME record at the zone apex; strict resolvers will fail to resolve the zone",
+					Severity:    "high",
+					Actual:      cnames[0].Value,
+				}, server)
+			}
+
+			var others []string
+			for _, recordType := range conflictRecordTypes[1:] {
+				if len(records[recordType]) > 0 {
+					others = append(others, string(recordType))
+				}
+			}
+			if len(others) > 0 {
+				add(ConsistencyIssue{
+					Type:        IssueCNAMEConflict,
+					RecordType:  RecordTypeCNAME,
+					Description: "CNAME coexists with other records at the same name, which RFC 1034 forbids",
+					Severity:    "high",
+					Expected:    "CNAME only",
+					Actual:      "CNAME, " + strings.Join(others, ", "),
+				}, server)
+			}
+		}
+
+		for _, recordType := range []DNSRecordType{RecordTypeA, RecordTypeAAAA} {
+			counts := make(map[string]int)
+			var values []string
+			for _, record := range records[recordType] {
+				if counts[record.Value] == 0 {
+					values = append(values, record.Value)
+				}
+				counts[record.Value]++
+			}
+			for _, value := range values {
+				if counts[value] > 1 {
+					add(ConsistencyIssue{
+						Type:        IssueDuplicateRecord,
+						RecordType:  recordType,
+						Description: fmt.Sprintf("%s record %s appears %d times in a single answer", recordType, value, counts[value]),
+						Severity:    "low",
+						Actual:      value,
+					}, server)
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+// sameName compares two domain names case-insensitively, ignoring a trailing dot
+func sameName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
+
+// difference returns the values in a that are not in b
+func difference(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, value := range b {
+		inB[value] = true
+	}
+
+	var only []string
+	for _, value := range a {
+		if !inB[value] {
+			only = append(only, value)
+		}
+	}
+	return only
+}
+
 // checkTXTIssues checks for TXT record specific issues
 func (c *ConsistencyChecker) checkTXTIssues(propagation *PropagationResult) []ConsistencyIssue {
 	var issues []ConsistencyIssue
@@ -142,7 +1012,7 @@ func (c *ConsistencyChecker) checkTXTIssues(propagation *PropagationResult) []Co
 		for _, record := range records {
 			if len(record.Value) > 255 {
 				issues = append(issues, ConsistencyIssue{
-					Type:        "txt_record_too_long",
+					Type:        IssueTXTRecordTooLong,
 					Domain:      propagation.Domain,
 					RecordType:  RecordTypeTXT,
 					Description: "TXT record exceeds recommended length of 255 characters",
@@ -151,35 +1021,20 @@ func (c *ConsistencyChecker) checkTXTIssues(propagation *PropagationResult) []Co
 					Actual:      fmt.Sprintf("Length: %d", len(record.Value)),
 				})
 			}
-
-			// Check for SPF records
-			if strings.HasPrefix(record.Value, "v=spf1") {
-				issues = append(issues, c.validateSPFRecord(propagation.Domain, record.Value, server)...)
-			}
-
-			// Check for DMARC records
-			if strings.HasPrefix(record.Value, "v=DMARC1") {
-				issues = append(issues, c.validateDMARCRecord(propagation.Domain, record.Value, server)...)
-			}
-
-			// Check for DKIM records
-			if strings.Contains(record.Name, "_domainkey") {
-				issues = append(issues, c.validateDKIMRecord(propagation.Domain, record.Value, server)...)
-			}
 		}
 	}
 
 	return issues
 }
 
-// validateSPFRecord validates SPF record syntax
-func (c *ConsistencyChecker) validateSPFRecord(domain, spfRecord, server string) []ConsistencyIssue {
+// validateSPFRecord validates SPF record syntax, following includes to count DNS lookups
+func (c *ConsistencyChecker) validateSPFRecord(ctx context.Context, domain, spfRecord, server string) []ConsistencyIssue {
 	var issues []ConsistencyIssue
 
 	// Check for multiple SPF records (should be avoided)
 	if strings.Count(spfRecord, "v=spf1") > 1 {
 		issues = append(issues, ConsistencyIssue{
-			Type:        "multiple_spf_records",
+			Type:        IssueMultipleSPFRecords,
 			Domain:      domain,
 			RecordType:  RecordTypeTXT,
 			Description: "Multiple SPF records detected, which can cause email delivery issues",
@@ -192,7 +1047,7 @@ func (c *ConsistencyChecker) validateSPFRecord(domain, spfRecord, server string)
 	// Check SPF record length
 	if len(spfRecord) > 255 {
 		issues = append(issues, ConsistencyIssue{
-			Type:        "spf_record_too_long",
+			Type:        IssueSPFRecordTooLong,
 			Domain:      domain,
 			RecordType:  RecordTypeTXT,
 			Description: "SPF record is too long and may be truncated",
@@ -202,59 +1057,402 @@ func (c *ConsistencyChecker) validateSPFRecord(domain, spfRecord, server string)
 		})
 	}
 
-	// Check for too many DNS lookups (SPF has a 10 lookup limit)
-	lookupCount := strings.Count(spfRecord, "include:") + 
-		strings.Count(spfRecord, "a:") + 
-		strings.Count(spfRecord, "mx:") + 
-		strings.Count(spfRecord, "exists:")
+	// Count DNS lookups across the whole include tree (SPF has a 10 lookup limit)
+	eval := c.resolver.evaluateSPF(ctx, domain, spfRecord, server)
 
-	if lookupCount > 10 {
+	if eval.Lookups > maxSPFLookups {
 		issues = append(issues, ConsistencyIssue{
-			Type:        "spf_too_many_lookups",
+			Type:        IssueSPFTooManyLookups,
 			Domain:      domain,
 			RecordType:  RecordTypeTXT,
 			Description: "SPF record exceeds the 10 DNS lookup limit",
 			Severity:    "high",
 			Servers:     []string{server},
-			Actual:      fmt.Sprintf("Lookups: %d", lookupCount),
+			Actual:      fmt.Sprintf("Lookups: %d", eval.Lookups),
 			Expected:    "10 or fewer",
 		})
 	}
 
+	for _, loop := range eval.Loops {
+		issues = append(issues, ConsistencyIssue{
+			Type:        IssueSPFIncludeLoop,
+			Domain:      domain,
+			RecordType:  RecordTypeTXT,
+			Description: "SPF include chain loops back to a domain already being evaluated",
+			Severity:    "high",
+			Servers:     []string{server},
+			Actual:      loop,
+		})
+	}
+
+	for _, target := range eval.Missing {
+		issues = append(issues, ConsistencyIssue{
+			Type:        IssueSPFIncludeMissing,
+			Domain:      domain,
+			RecordType:  RecordTypeTXT,
+			Description: fmt.Sprintf("SPF references %s, which has no SPF record (permerror)", target),
+			Severity:    "high",
+			Servers:     []string{server},
+			Actual:      target,
+		})
+	}
+
+	if eval.Truncated {
+		issues = append(issues, ConsistencyIssue{
+			Type:        IssueSPFIncludeTooDeep,
+			Domain:      domain,
+			RecordType:  RecordTypeTXT,
+			Description: fmt.Sprintf("SPF includes are nested more than %d levels deep; lookup count is incomplete", maxSPFDepth),
+			Severity:    "medium",
+			Servers:     []string{server},
+			Actual:      fmt.Sprintf("Lookups: %d+", eval.Lookups),
+		})
+	}
+
+	// Check the catch-all policy
+	if qualifier, ok := spfAllQualifier(spfRecord); ok {
+		switch qualifier {
+		case "+":
+			issues = append(issues, ConsistencyIssue{
+				Type:        IssueSPFPermissiveAll,
+				Domain:      domain,
+				RecordType:  RecordTypeTXT,
+				Description: "SPF record ends in +all, authorising every host to send mail",
+				Severity:    "high",
+				Servers:     []string{server},
+				Actual:      "+all",
+				Expected:    "-all or ~all",
+			})
+		case "?":
+			issues = append(issues, ConsistencyIssue{
+				Type:        IssueSPFNeutralAll,
+				Domain:      domain,
+				RecordType:  RecordTypeTXT,
+				Description: "SPF record ends in ?all, so unlisted senders are neither passed nor failed",
+				Severity:    "info",
+				Servers:     []string{server},
+				Actual:      "?all",
+				Expected:    "-all or ~all",
+			})
+		}
+	}
+
 	return issues
 }
 
-// validateDMARCRecord validates DMARC record syntax
-func (c *ConsistencyChecker) validateDMARCRecord(domain, dmarcRecord, server string) []ConsistencyIssue {
+// validateDMARCRecord validates the DMARC tag set published at domain (the _dmarc name),
+// including report destinations. Known hosts of the policy domain, such as its MX targets,
+// help tell whether it has subdomains for the sp= check.
+func (c *ConsistencyChecker) validateDMARCRecord(ctx context.Context, domain, dmarcRecord, server string, knownHosts []string) []ConsistencyIssue {
 	var issues []ConsistencyIssue
+	tags := parseDMARCTags(dmarcRecord)
 
-	// Check for missing required policy
-	if !strings.Contains(dmarcRecord, "p=") {
+	issue := func(issueType, description, severity, expected, actual string) {
 		issues = append(issues, ConsistencyIssue{
-			Type:        "dmarc_missing_policy",
+			Type:        issueType,
 			Domain:      domain,
 			RecordType:  RecordTypeTXT,
-			Description: "DMARC record is missing required policy (p=) tag",
-			Severity:    "high",
+			Description: description,
+			Severity:    severity,
 			Servers:     []string{server},
-			Actual:      dmarcRecord,
+			Expected:    expected,
+			Actual:      actual,
 		})
 	}
 
+	// Check for missing required policy
+	policy, hasPolicy := tags["p"]
+	if !hasPolicy {
+		issue(IssueDMARCMissingPolicy, "DMARC record is missing required policy (p=) tag", "high", "", dmarcRecord)
+	}
+
 	// Check for weak policy
-	if strings.Contains(dmarcRecord, "p=none") {
-		issues = append(issues, ConsistencyIssue{
-			Type:        "dmarc_weak_policy",
+	if strings.EqualFold(policy, "none") {
+		issue(IssueDMARCWeakPolicy, "DMARC policy is set to 'none', providing no protection", "medium",
+			"p=quarantine or p=reject", "p=none")
+	}
+
+	// A relaxed subdomain policy undoes an enforcing p= for every subdomain, if there are any
+	policyDomain := strings.TrimPrefix(strings.ToLower(strings.TrimSuffix(domain, ".")), "_dmarc.")
+	if sp, ok := tags["sp"]; ok && strings.EqualFold(sp, "none") && hasPolicy && !strings.EqualFold(policy, "none") &&
+		c.resolver.hasSubdomains(ctx, policyDomain, server, knownHosts) {
+		issue(IssueDMARCWeakSubdomainPolicy, "DMARC subdomain policy is 'none', so subdomains can be spoofed despite the domain policy", "medium",
+			"sp=quarantine or sp=reject", "sp=none")
+	}
+
+	if pct, ok := tags["pct"]; ok {
+		if n, err := strconv.Atoi(pct); err != nil || n < 0 || n > 100 {
+			issue(IssueDMARCInvalidPct, "DMARC pct= must be an integer between 0 and 100", "medium", "0-100", "pct="+pct)
+		}
+	}
+
+	// Report destinations must be mailto: URIs; external ones need authorization
+	for _, report := range []struct{ tag, invalid, unauthorized string }{
+		{"rua", IssueDMARCInvalidRUA, IssueDMARCExternalRUAUnauthorized},
+		{"ruf", IssueDMARCInvalidRUF, IssueDMARCExternalRUFUnauthorized},
+	} {
+		tag := report.tag
+		value, ok := tags[tag]
+		if !ok {
+			continue
+		}
+
+		destinations, err := parseDMARCURIs(value)
+		if err != nil {
+			issue(report.invalid, fmt.Sprintf("DMARC %s= is malformed: %v", tag, err), "medium", "mailto:address@domain", value)
+			continue
+		}
+
+		checked := make(map[string]bool)
+		for _, dest := range destinations {
+			if isSameOrSubdomain(dest, policyDomain) || checked[dest] {
+				continue
+			}
+			checked[dest] = true
+
+			authorized, err := c.resolver.dmarcReportAuthorized(ctx, policyDomain, dest, server)
+			if err != nil || authorized {
+				continue
+			}
+			issue(report.unauthorized,
+				fmt.Sprintf("%s has not authorized receiving DMARC %s reports for %s", dest, tag, policyDomain), "medium",
+				fmt.Sprintf("TXT v=DMARC1 at %s._report._dmarc.%s", policyDomain, dest), dest)
+		}
+	}
+
+	return issues
+}
+
+// checkDKIMSelectors probes common and configured DKIM selectors, validates each key found and
+// notes when a mail-receiving domain publishes none
+func (c *ConsistencyChecker) checkDKIMSelectors(ctx context.Context, domain string, nameservers []string, hasMX bool) []ConsistencyIssue {
+	if len(nameservers) == 0 {
+		return nil
+	}
+	ns := nameservers[0]
+	selectors := dkimSelectorList(c.dkimSelectors)
+
+	found := c.resolver.probeDKIMSelectors(ctx, domain, selectors, ns)
+	if len(found) == 0 {
+		if !hasMX {
+			return nil
+		}
+		return []ConsistencyIssue{{
+			Type:        IssueDKIMNoSelectorFound,
 			Domain:      domain,
 			RecordType:  RecordTypeTXT,
-			Description: "DMARC policy is set to 'none', providing no protection",
+			Description: fmt.Sprintf("No DKIM key found under %d probed selectors although the domain receives mail; outgoing mail may be unsigned", len(selectors)),
+			Severity:    "info",
+			Servers:     []string{ns},
+			Expected:    "TXT v=DKIM1 at <selector>._domainkey." + strings.TrimSuffix(domain, "."),
+		}}
+	}
+
+	var issues []ConsistencyIssue
+	var names []string
+	for _, selector := range found {
+		names = append(names, selector.Selector)
+		for _, record := range selector.Records {
+			issues = append(issues, c.validateDKIMRecord(selector.Name, record, ns)...)
+		}
+	}
+
+	issues = append(issues, ConsistencyIssue{
+		Type:        IssueDKIMSelectorsFound,
+		Domain:      domain,
+		RecordType:  RecordTypeTXT,
+		Description: fmt.Sprintf("DKIM keys published for %d selector(s)", len(found)),
+		Severity:    "info",
+		Servers:     []string{ns},
+		Actual:      strings.Join(names, ", "),
+	})
+
+	return issues
+}
+
+// checkWWW compares the apex with its www label: a www name that does not exist is a common
+// cause of "site down" reports, and disjoint address sets usually mean stale records
+func (c *ConsistencyChecker) checkWWW(ctx context.Context, domain string, nameservers []string) []ConsistencyIssue {
+	if len(nameservers) == 0 {
+		return nil
+	}
+	ns := nameservers[0]
+	domain = strings.TrimSuffix(domain, ".")
+	www := "www." + domain
+
+	missing, err := c.resolver.isNXDomain(ctx, www, ns)
+	if err != nil {
+		return nil
+	}
+	if missing {
+		return []ConsistencyIssue{{
+			Type:        IssueWWWMissing,
+			Domain:      www,
+			RecordType:  RecordTypeA,
+			Description: fmt.Sprintf("%s does not exist (NXDOMAIN), so visitors typing www get an error", www),
 			Severity:    "medium",
-			Servers:     []string{server},
-			Actual:      "p=none",
-			Expected:    "p=quarantine or p=reject",
+			Servers:     []string{ns},
+			Expected:    "A, AAAA or CNAME record",
+			Actual:      "NXDOMAIN",
+		}}
+	}
+
+	apexAddresses, err := c.resolver.lookupIPs(ctx, domain, ns)
+	if err != nil || len(apexAddresses) == 0 {
+		return nil
+	}
+	wwwAddresses, err := c.resolver.lookupIPs(ctx, www, ns)
+	if err != nil || len(wwwAddresses) == 0 {
+		return nil
+	}
+
+	for _, address := range wwwAddresses {
+		if slices.Contains(apexAddresses, address) {
+			return nil
+		}
+	}
+
+	description := fmt.Sprintf("%s and %s resolve to different addresses", domain, www)
+	if chain, err := c.resolver.lookupCNAMEChain(ctx, www, ns); err == nil && len(chain) > 0 {
+		description += fmt.Sprintf(" (www is a CNAME to %s)", strings.TrimSuffix(chain[len(chain)-1].Target, "."))
+	}
+
+	sort.Strings(apexAddresses)
+	sort.Strings(wwwAddresses)
+	return []ConsistencyIssue{{
+		Type:        IssueWWWApexMismatch,
+		Domain:      domain,
+		RecordType:  RecordTypeA,
+		Description: description,
+		Severity:    "info",
+		Servers:     []string{ns},
+		Expected:    strings.Join(apexAddresses, ", "),
+		Actual:      strings.Join(wwwAddresses, ", "),
+	}}
+}
+
+// checkMTASTS validates the _mta-sts record, the HTTPS policy file it announces (checking that
+// the policy covers every MX host) and the _smtp._tls TLS-RPT record
+func (c *ConsistencyChecker) checkMTASTS(ctx context.Context, domain string, nameservers []string, mxHosts []string) []ConsistencyIssue {
+	if len(nameservers) == 0 {
+		return nil
+	}
+	ns := nameservers[0]
+	domain = strings.TrimSuffix(domain, ".")
+
+	var issues []ConsistencyIssue
+	issue := func(issueType, name, description, expected, actual string) {
+		issues = append(issues, ConsistencyIssue{
+			Type:        issueType,
+			Domain:      name,
+			RecordType:  RecordTypeTXT,
+			Description: description,
+			Severity:    "medium",
+			Servers:     []string{ns},
+			Expected:    expected,
+			Actual:      actual,
 		})
 	}
 
+	stsName := "_mta-sts." + domain
+	if records, err := c.resolver.lookupTXT(ctx, stsName, ns); err == nil {
+		var stsRecords []string
+		for _, record := range records {
+			if strings.HasPrefix(strings.ToLower(record), "v=stsv1") {
+				stsRecords = append(stsRecords, record)
+			}
+		}
+
+		switch {
+		case len(stsRecords) == 0:
+			issue(IssueMTASTSMissing, stsName, "No MTA-STS record, so senders cannot require TLS when delivering mail",
+				"TXT v=STSv1; id=<policy id>", "")
+		case len(stsRecords) > 1:
+			issue(IssueMTASTSInvalidRecord, stsName, "Multiple MTA-STS records are published; senders will ignore them all",
+				"exactly one v=STSv1 record", strings.Join(stsRecords, " | "))
+		default:
+			if _, err := parseMTASTSRecord(stsRecords[0]); err != nil {
+				issue(IssueMTASTSInvalidRecord, stsName, fmt.Sprintf("MTA-STS record is malformed: %v", err),
+					"v=STSv1; id=<1-32 alphanumerics>", stsRecords[0])
+			}
+			issues = append(issues, c.checkMTASTSPolicy(ctx, domain, ns, mxHosts)...)
+		}
+	}
+
+	rptName := "_smtp._tls." + domain
+	if records, err := c.resolver.lookupTXT(ctx, rptName, ns); err == nil {
+		var rptRecords []string
+		for _, record := range records {
+			if strings.HasPrefix(strings.ToLower(record), "v=tlsrptv1") {
+				rptRecords = append(rptRecords, record)
+			}
+		}
+
+		switch {
+		case len(rptRecords) == 0:
+			issue(IssueTLSRPTMissing, rptName, "No TLS-RPT record, so TLS delivery failures are never reported",
+				"TXT v=TLSRPTv1; rua=mailto:<address>", "")
+		case len(rptRecords) > 1:
+			issue(IssueTLSRPTInvalid, rptName, "Multiple TLS-RPT records are published; senders will ignore them all",
+				"exactly one v=TLSRPTv1 record", strings.Join(rptRecords, " | "))
+		default:
+			if err := parseTLSRPTRecord(rptRecords[0]); err != nil {
+				issue(IssueTLSRPTInvalid, rptName, fmt.Sprintf("TLS-RPT record is malformed: %v", err),
+					"v=TLSRPTv1; rua=mailto:<address>", rptRecords[0])
+			}
+		}
+	}
+
+	return issues
+}
+
+// checkMTASTSPolicy fetches and validates the MTA-STS policy file
+func (c *ConsistencyChecker) checkMTASTSPolicy(ctx context.Context, domain, ns string, mxHosts []string) []ConsistencyIssue {
+	policyHost := "mta-sts." + domain
+	issue := func(issueType, description, expected, actual string) ConsistencyIssue {
+		return ConsistencyIssue{
+			Type:        issueType,
+			Domain:      policyHost,
+			RecordType:  RecordTypeTXT,
+			Description: description,
+			Severity:    "medium",
+			Servers:     []string{ns},
+			Expected:    expected,
+			Actual:      actual,
+		}
+	}
+
+	body, err := fetchMTASTSPolicy(ctx, domain)
+	if err != nil {
+		return []ConsistencyIssue{issue(IssueMTASTSPolicyUnreachable, fmt.Sprintf("MTA-STS policy could not be fetched: %v", err),
+			"https://"+policyHost+"/.well-known/mta-sts.txt", "")}
+	}
+
+	policy, problems := parseMTASTSPolicy(body)
+	if len(problems) > 0 {
+		return []ConsistencyIssue{issue(IssueMTASTSInvalidPolicy, "MTA-STS policy is malformed: "+strings.Join(problems, "; "),
+			"version, mode, mx and max_age fields", "")}
+	}
+
+	var issues []ConsistencyIssue
+	if policy.Mode == "none" {
+		return issues
+	}
+	for _, host := range mxHosts {
+		covered := false
+		for _, pattern := range policy.MX {
+			if mtaSTSMatches(pattern, host) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			issues = append(issues, issue(IssueMTASTSMXMismatch,
+				fmt.Sprintf("MX host %s is not listed in the MTA-STS policy, so compliant senders will refuse to deliver to it", host),
+				strings.Join(policy.MX, ", "), host))
+		}
+	}
+
 	return issues
 }
 
@@ -265,7 +1463,7 @@ func (c *ConsistencyChecker) validateDKIMRecord(domain, dkimRecord, server strin
 	// Check for missing public key
 	if !strings.Contains(dkimRecord, "p=") {
 		issues = append(issues, ConsistencyIssue{
-			Type:        "dkim_missing_public_key",
+			Type:        IssueDKIMMissingPublicKey,
 			Domain:      domain,
 			RecordType:  RecordTypeTXT,
 			Description: "DKIM record is missing public key (p=) tag",
@@ -278,7 +1476,7 @@ func (c *ConsistencyChecker) validateDKIMRecord(domain, dkimRecord, server strin
 	// Check for revoked key
 	if strings.Contains(dkimRecord, "p=;") || strings.Contains(dkimRecord, "p=\"\";") {
 		issues = append(issues, ConsistencyIssue{
-			Type:        "dkim_revoked_key",
+			Type:        IssueDKIMRevokedKey,
 			Domain:      domain,
 			RecordType:  RecordTypeTXT,
 			Description: "DKIM key appears to be revoked (empty public key)",
@@ -0,0 +1,105 @@
+// =============================================================================
+// internal/dns/checkpoint.go - Resumable bulk runs
+// =============================================================================
+package dns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkpointInterval is how often a bulk run rewrites its checkpoint file
+const checkpointInterval = 5 * time.Second
+
+// bulkCheckpoint is the on-disk state of a bulk run: the successful results so far.
+// Failed domains are not recorded, so a resumed run retries them.
+type bulkCheckpoint struct {
+	Operation BulkOperation `json:"operation"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Results   []BulkResult  `json:"results"`
+}
+
+// loadCheckpoint reads the checkpoint at path; a missing file starts an empty one
+func loadCheckpoint(path string, operation BulkOperation) (*bulkCheckpoint, error) {
+	checkpoint := &bulkCheckpoint{Operation: operation}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if checkpoint.Operation != operation {
+		return nil, fmt.Errorf("checkpoint %s is from a bulk %s run, not %s", path, checkpoint.Operation, operation)
+	}
+	return checkpoint, nil
+}
+
+// save writes the checkpoint to a temporary file next to path and renames it into place,
+// so an interrupted write never leaves a truncated checkpoint behind
+func (c *bulkCheckpoint) save(path string) error {
+	c.UpdatedAt = time.Now()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if err := json.NewEncoder(tmp).Encode(c); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// UnmarshalJSON restores Error from its message, the inverse of MarshalJSON
+func (r *BulkResult) UnmarshalJSON(data []byte) error {
+	type plain BulkResult
+	aux := struct {
+		*plain
+		Error string `json:"error"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Error != "" {
+		r.Error = errors.New(aux.Error)
+	}
+	return nil
+}
+
+// UnmarshalJSON restores Error from its message, the inverse of MarshalJSON
+func (r *DNSResult) UnmarshalJSON(data []byte) error {
+	type plain DNSResult
+	aux := struct {
+		*plain
+		Error string `json:"error"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Error != "" {
+		r.Error = errors.New(aux.Error)
+	}
+	return nil
+}
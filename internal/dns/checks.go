@@ -0,0 +1,317 @@
+// =============================================================================
+// internal/dns/checks.go - Registry of named consistency checks
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// consistencyRecordTypes are the record types fetched for the per-type checks
+var consistencyRecordTypes = []DNSRecordType{RecordTypeA, RecordTypeAAAA, RecordTypeMX, RecordTypeNS, RecordTypeTXT}
+
+// consistencyCheck is one named, individually selectable check
+type consistencyCheck struct {
+	name        string
+	description string
+	run         func(c *ConsistencyChecker, ctx context.Context, run *consistencyRun) []ConsistencyIssue
+	optIn       bool // Runs only when named in SetChecks' only list
+}
+
+// consistencyChecks lists every check in the order it runs
+var consistencyChecks = []consistencyCheck{
+	{"propagation", "Records that differ between nameservers", (*ConsistencyChecker).runPropagationCheck, false},
+	{"private-ip", "A/AAAA records pointing at private or reserved addresses", (*ConsistencyChecker).runPrivateIPCheck, false},
+	{"mx", "MX priorities, targets and (with --check-ptr) reverse DNS", (*ConsistencyChecker).runMXCheck, false},
+	{"ns", "NS count, lame and unreachable nameservers, network diversity", (*ConsistencyChecker).runNSCheck, false},
+	{"open-resolver", "Nameservers that answer recursive queries for unrelated names", (*ConsistencyChecker).runOpenResolverCheck, false},
+	{"txt", "TXT record length", (*ConsistencyChecker).runTXTCheck, false},
+	{"spf", "SPF syntax, lookup limit, includes and all qualifier", (*ConsistencyChecker).runSPFCheck, false},
+	{"dmarc", "DMARC policy, pct and report destinations", (*ConsistencyChecker).runDMARCCheck, false},
+	{"dkim", "DKIM keys under common and configured selectors", (*ConsistencyChecker).runDKIMCheck, false},
+	{"ttl", "TTLs outside the configured range or varying between servers", (*ConsistencyChecker).runTTLCheck, false},
+	{"ttl-divergence", "Servers serving a TTL far above the others, e.g. a stale cache (opt-in)", (*ConsistencyChecker).runTTLDivergenceCheck, true},
+	{"soa", "SOA serial drift and timer ranges", (*ConsistencyChecker).runSOACheck, false},
+	{"delegation", "Parent and child NS sets match", (*ConsistencyChecker).runDelegationCheck, false},
+	{"dangling-cname", "CNAMEs to nonexistent or takeover-prone targets", (*ConsistencyChecker).runDanglingCNAMECheck, false},
+	{"conflicts", "CNAMEs at the apex or beside other records, duplicate records", (*ConsistencyChecker).runConflictsCheck, false},
+	{"mta-sts", "MTA-STS policy and TLS-RPT record for mail domains", (*ConsistencyChecker).runMTASTSCheck, false},
+	{"www", "www exists and shares addresses with the apex (opt-in, for web zones)", (*ConsistencyChecker).runWWWCheck, true},
+}
+
+// ConsistencyCheckNames returns the names accepted by SetChecks, in run order
+func ConsistencyCheckNames() []string {
+	names := make([]string, len(consistencyChecks))
+	for i, check := range consistencyChecks {
+		names[i] = check.name
+	}
+	return names
+}
+
+// ConsistencyCheckDescriptions maps each check name to a one-line description
+func ConsistencyCheckDescriptions() map[string]string {
+	descriptions := make(map[string]string, len(consistencyChecks))
+	for _, check := range consistencyChecks {
+		descriptions[check.name] = check.description
+	}
+	return descriptions
+}
+
+// SetChecks restricts CheckConsistency to the named checks minus those in skip. An empty only
+// list (or "all" in it) selects every check that is not opt-in; opt-in checks run only when
+// named. Unknown names are an error.
+func (c *ConsistencyChecker) SetChecks(only, skip []string) error {
+	known := make(map[string]bool, len(consistencyChecks))
+	for _, check := range consistencyChecks {
+		known[check.name] = true
+	}
+
+	normalize := func(names []string, allowAll bool) (map[string]bool, error) {
+		set := make(map[string]bool)
+		var unknown []string
+		for _, name := range names {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if !known[name] && !(allowAll && name == "all") {
+				unknown = append(unknown, name)
+				continue
+			}
+			set[name] = true
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("unknown check(s): %s (available: %s)", strings.Join(unknown, ", "), strings.Join(ConsistencyCheckNames(), ", "))
+		}
+		return set, nil
+	}
+
+	onlySet, err := normalize(only, true)
+	if err != nil {
+		return err
+	}
+	skipSet, err := normalize(skip, false)
+	if err != nil {
+		return err
+	}
+
+	c.enabledChecks = onlySet
+	c.skippedChecks = skipSet
+	return nil
+}
+
+// checkEnabled reports whether a check runs under the current selection
+func (c *ConsistencyChecker) checkEnabled(check consistencyCheck) bool {
+	if c.skippedChecks[check.name] {
+		return false
+	}
+	if check.optIn {
+		return c.enabledChecks[check.name]
+	}
+	return len(c.enabledChecks) == 0 || c.enabledChecks["all"] || c.enabledChecks[check.name]
+}
+
+// consistencyRun holds the lookups shared between the checks of one CheckConsistency call,
+// so each record type is fetched at most once and only when a selected check needs it
+type consistencyRun struct {
+	domain      string
+	nameservers []string
+	results     map[DNSRecordType]*PropagationResult
+}
+
+// propagation returns the cached propagation result for recordType, or nil if it failed
+func (run *consistencyRun) propagation(ctx context.Context, c *ConsistencyChecker, recordType DNSRecordType) *PropagationResult {
+	if result, ok := run.results[recordType]; ok {
+		return result
+	}
+	result, err := c.resolver.CheckPropagation(ctx, run.domain, recordType, run.nameservers)
+	if err != nil {
+		result = nil
+	}
+	run.results[recordType] = result
+	return result
+}
+
+// mxHosts returns the domain's distinct MX targets
+func (run *consistencyRun) mxHosts(ctx context.Context, c *ConsistencyChecker) []string {
+	if propagation := run.propagation(ctx, c, RecordTypeMX); propagation != nil {
+		return mxTargets(propagation)
+	}
+	return nil
+}
+
+func (c *ConsistencyChecker) runPropagationCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	var issues []ConsistencyIssue
+	for _, recordType := range consistencyRecordTypes {
+		propagation := run.propagation(ctx, c, recordType)
+		if propagation == nil || !propagation.Inconsistent {
+			continue
+		}
+		issues = append(issues, ConsistencyIssue{
+			Type:        IssuePropagationInconsistency,
+			Domain:      run.domain,
+			RecordType:  recordType,
+			Description: fmt.Sprintf("%s records are inconsistent across nameservers", recordType),
+			Severity:    c.determineSeverity(recordType),
+			Servers:     c.getInconsistentServers(propagation.Results),
+		})
+	}
+	return issues
+}
+
+func (c *ConsistencyChecker) runPrivateIPCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	if c.allowPrivateIPs {
+		return nil
+	}
+	var issues []ConsistencyIssue
+	for _, recordType := range []DNSRecordType{RecordTypeA, RecordTypeAAAA} {
+		if propagation := run.propagation(ctx, c, recordType); propagation != nil {
+			issues = append(issues, c.checkPrivateAddresses(propagation)...)
+		}
+	}
+	return issues
+}
+
+func (c *ConsistencyChecker) runMXCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	propagation := run.propagation(ctx, c, RecordTypeMX)
+	if propagation == nil {
+		return nil
+	}
+	issues := c.checkMXIssues(propagation)
+	return append(issues, c.checkMXHosts(ctx, propagation)...)
+}
+
+func (c *ConsistencyChecker) runNSCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	propagation := run.propagation(ctx, c, RecordTypeNS)
+	if propagation == nil {
+		return nil
+	}
+	issues := c.checkNSIssues(propagation)
+	return append(issues, c.checkNameserverHealth(ctx, propagation)...)
+}
+
+func (c *ConsistencyChecker) runOpenResolverCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	if propagation := run.propagation(ctx, c, RecordTypeNS); propagation != nil {
+		return c.checkOpenResolvers(ctx, propagation)
+	}
+	return nil
+}
+
+func (c *ConsistencyChecker) runTXTCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	if propagation := run.propagation(ctx, c, RecordTypeTXT); propagation != nil {
+		return c.checkTXTIssues(propagation)
+	}
+	return nil
+}
+
+func (c *ConsistencyChecker) runSPFCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	var issues []ConsistencyIssue
+	c.eachTXTRecord(ctx, run, func(server string, record DNSRecord) {
+		if strings.HasPrefix(record.Value, "v=spf1") {
+			issues = append(issues, c.validateSPFRecord(ctx, run.domain, record.Value, server)...)
+		}
+	})
+	return issues
+}
+
+func (c *ConsistencyChecker) runDMARCCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	// The policy lives at _dmarc.<domain>, which the apex TXT lookup never sees
+	if len(run.nameservers) == 0 {
+		return nil
+	}
+	ns := run.nameservers[0]
+	name := "_dmarc." + strings.TrimSuffix(run.domain, ".")
+
+	records, err := c.resolver.lookupTXT(ctx, name, ns)
+	if err != nil {
+		return nil
+	}
+	var issues []ConsistencyIssue
+	for _, record := range records {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(record)), "v=dmarc1") {
+			issues = append(issues, c.validateDMARCRecord(ctx, name, record, ns, run.mxHosts(ctx, c))...)
+		}
+	}
+	return issues
+}
+
+func (c *ConsistencyChecker) runDKIMCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	var issues []ConsistencyIssue
+	c.eachTXTRecord(ctx, run, func(server string, record DNSRecord) {
+		if strings.Contains(record.Name, "_domainkey") {
+			issues = append(issues, c.validateDKIMRecord(run.domain, record.Value, server)...)
+		}
+	})
+
+	// DKIM keys live under <selector>._domainkey, which the TXT lookup above never sees
+	return append(issues, c.checkDKIMSelectors(ctx, run.domain, run.nameservers, len(run.mxHosts(ctx, c)) > 0)...)
+}
+
+// eachTXTRecord calls fn for every TXT record returned by every server
+func (c *ConsistencyChecker) eachTXTRecord(ctx context.Context, run *consistencyRun, fn func(server string, record DNSRecord)) {
+	propagation := run.propagation(ctx, c, RecordTypeTXT)
+	if propagation == nil {
+		return
+	}
+	for server, records := range propagation.Results {
+		for _, record := range records {
+			fn(server, record)
+		}
+	}
+}
+
+func (c *ConsistencyChecker) runTTLCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	var issues []ConsistencyIssue
+	for _, recordType := range consistencyRecordTypes {
+		if propagation := run.propagation(ctx, c, recordType); propagation != nil {
+			issues = append(issues, c.checkTTLIssues(propagation)...)
+		}
+	}
+	return issues
+}
+
+func (c *ConsistencyChecker) runTTLDivergenceCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	var issues []ConsistencyIssue
+	for _, recordType := range consistencyRecordTypes {
+		if propagation := run.propagation(ctx, c, recordType); propagation != nil {
+			issues = append(issues, c.checkTTLDivergence(propagation)...)
+		}
+	}
+	return issues
+}
+
+func (c *ConsistencyChecker) runSOACheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	if propagation := run.propagation(ctx, c, RecordTypeSOA); propagation != nil {
+		return c.checkSOAIssues(propagation)
+	}
+	return nil
+}
+
+func (c *ConsistencyChecker) runDelegationCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	return c.checkDelegation(ctx, run.domain, run.nameservers)
+}
+
+func (c *ConsistencyChecker) runDanglingCNAMECheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	return c.checkDanglingCNAMEs(ctx, run.domain, run.nameservers)
+}
+
+func (c *ConsistencyChecker) runConflictsCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	return c.checkRecordConflicts(ctx, run.domain, run.nameservers)
+}
+
+func (c *ConsistencyChecker) runWWWCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	return c.checkWWW(ctx, run.domain, run.nameservers)
+}
+
+func (c *ConsistencyChecker) runMTASTSCheck(ctx context.Context, run *consistencyRun) []ConsistencyIssue {
+	// MTA-STS and TLS-RPT only matter for domains that receive mail
+	mxHosts := run.mxHosts(ctx, c)
+	if len(mxHosts) == 0 {
+		return nil
+	}
+	return c.checkMTASTS(ctx, run.domain, run.nameservers, mxHosts)
+}
@@ -0,0 +1,224 @@
+// =============================================================================
+// internal/dns/delegation.go - Parent/child NS delegation lookup
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// Delegation holds a zone's NS RRset as published by its parent and by the zone itself
+type Delegation struct {
+	Zone         string   `json:"zone"`
+	ParentZone   string   `json:"parent_zone"`
+	ParentServer string   `json:"parent_server"` // Parent nameserver that answered the delegation query
+	ParentNS     []string `json:"parent_ns"`
+	ChildNS      []string `json:"child_ns"`
+}
+
+// CheckDelegation finds the zone containing domain and fetches its NS set from both sides of
+// the zone cut. recursive is used to locate zones and resolve nameserver addresses; the parent's
+// view is read directly from the parent's authoritative servers without recursion.
+func (r *Resolver) CheckDelegation(ctx context.Context, domain, recursive string) (*Delegation, error) {
+	zone, childNS, err := r.findZone(ctx, dns.Fqdn(strings.ToLower(domain)), recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	parentName, ok := parentOf(zone)
+	if !ok {
+		return nil, fmt.Errorf("%s is a top-level zone; no parent delegation to compare", zone)
+	}
+
+	parentZone, parentServers, err := r.findZone(ctx, parentName, recursive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate parent zone of %s: %w", zone, err)
+	}
+
+	delegation := &Delegation{
+		Zone:       zone,
+		ParentZone: parentZone,
+		ChildNS:    childNS,
+	}
+
+	var lastErr error
+	for _, server := range parentServers {
+		addresses, err := r.lookupAddresses(ctx, server, recursive)
+		if err != nil || len(addresses) == 0 {
+			lastErr = fmt.Errorf("could not resolve parent nameserver %s", server)
+			continue
+		}
+
+		parentNS, err := r.queryDelegationNS(ctx, zone, addresses[0])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		delegation.ParentServer = server
+		delegation.ParentNS = parentNS
+		return delegation, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no nameservers found for parent zone %s", parentZone)
+	}
+	return nil, lastErr
+}
+
+// AuthoritativeServer is one of a zone's nameservers and the addresses it resolves to
+type AuthoritativeServer struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses"`
+}
+
+// AuthoritativeNameservers finds the zone containing domain and resolves each of its NS
+// hosts through recursive, so the zone's own servers can be queried directly. IPv4 addresses
+// are returned when a host has any, since every network can reach them; hosts that don't
+// resolve are returned without addresses. Servers are sorted by name.
+func (r *Resolver) AuthoritativeNameservers(ctx context.Context, domain, recursive string) (string, []AuthoritativeServer, error) {
+	zone, hosts, err := r.findZone(ctx, dns.Fqdn(strings.ToLower(domain)), recursive)
+	if err != nil {
+		return "", nil, err
+	}
+
+	servers := make([]AuthoritativeServer, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			servers[i].Name = host
+			addresses, err := r.lookupIPs(ctx, host, recursive)
+			if err != nil {
+				return
+			}
+			var ipv4 []string
+			for _, address := range addresses {
+				if net.ParseIP(address).To4() != nil {
+					ipv4 = append(ipv4, address)
+				}
+			}
+			if len(ipv4) > 0 {
+				addresses = ipv4
+			}
+			servers[i].Addresses = addresses
+		}(i, host)
+	}
+	wg.Wait()
+
+	return zone, servers, nil
+}
+
+// findZone walks up from name until it reaches a name that owns an NS RRset, returning that
+// zone and its nameservers
+func (r *Resolver) findZone(ctx context.Context, name, recursive string) (string, []string, error) {
+	for current := name; ; {
+		msg := new(dns.Msg)
+		msg.SetQuestion(current, dns.TypeNS)
+		msg.RecursionDesired = true
+
+		response, err := r.exchange(ctx, msg, recursive)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if servers := nsTargets(response.Answer, current); len(servers) > 0 {
+			return current, servers, nil
+		}
+
+		parent, ok := parentOf(current)
+		if !ok {
+			return "", nil, fmt.Errorf("no zone found for %s", name)
+		}
+		current = parent
+	}
+}
+
+// queryDelegationNS asks a parent nameserver, without recursion, for the delegation NS set of zone.
+// Referrals carry it in the authority section; servers authoritative for both sides answer directly.
+func (r *Resolver) queryDelegationNS(ctx context.Context, zone, server string) ([]string, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(zone, dns.TypeNS)
+	msg.RecursionDesired = false
+
+	response, err := r.exchange(ctx, msg, server)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := nsTargets(append(response.Answer, response.Ns...), zone)
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("parent server %s returned no delegation for %s", server, zone)
+	}
+	return servers, nil
+}
+
+// probeAuthority sends a non-recursive SOA query for zone straight to server, as a resolver
+// following the delegation would, and reports the rcode and whether the answer was authoritative
+func (r *Resolver) probeAuthority(ctx context.Context, zone, server string) (string, bool, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(zone), dns.TypeSOA)
+	msg.RecursionDesired = false
+
+	response, err := r.exchange(ctx, msg, server)
+	if err != nil {
+		return "", false, err
+	}
+	return dns.RcodeToString[response.Rcode], response.Authoritative, nil
+}
+
+// lookupAddresses resolves a nameserver hostname to its IPv4 addresses
+func (r *Resolver) lookupAddresses(ctx context.Context, host, recursive string) ([]string, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(host), dns.TypeA)
+	msg.RecursionDesired = true
+
+	response, err := r.exchange(ctx, msg, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	for _, answer := range response.Answer {
+		if a, ok := answer.(*dns.A); ok {
+			addresses = append(addresses, a.A.String())
+		}
+	}
+	return addresses, nil
+}
+
+// nsTargets returns the sorted, lowercased NS targets owned by zone
+func nsTargets(rrs []dns.RR, zone string) []string {
+	seen := make(map[string]bool)
+	var servers []string
+	for _, rr := range rrs {
+		ns, ok := rr.(*dns.NS)
+		if !ok || !strings.EqualFold(ns.Hdr.Name, zone) {
+			continue
+		}
+		target := strings.ToLower(ns.Ns)
+		if !seen[target] {
+			seen[target] = true
+			servers = append(servers, target)
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// parentOf strips the left-most label from an FQDN; it reports false for the root and TLDs
+func parentOf(name string) (string, bool) {
+	labels := dns.SplitDomainName(name)
+	if len(labels) < 2 {
+		return "", false
+	}
+	return dns.Fqdn(strings.Join(labels[1:], ".")), true
+}
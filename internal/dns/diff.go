@@ -0,0 +1,152 @@
+// =============================================================================
+// internal/dns/diff.go - Record-level diff between two nameservers
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiffChange describes how a record differs between server A and server B
+type DiffChange string
+
+const (
+	DiffAdded   DiffChange = "added"   // Present only on server B
+	DiffRemoved DiffChange = "removed" // Present only on server A
+	DiffChanged DiffChange = "changed" // Same record, different data (e.g. MX priority, CNAME target)
+)
+
+// DefaultDiffRecordTypes are compared when no explicit list is given
+var DefaultDiffRecordTypes = []DNSRecordType{
+	RecordTypeA, RecordTypeAAAA, RecordTypeMX, RecordTypeNS, RecordTypeTXT, RecordTypeCNAME,
+}
+
+// RecordDiff is a single difference between the two servers
+type RecordDiff struct {
+	RecordType DNSRecordType `json:"record_type"`
+	Change     DiffChange    `json:"change"`
+	ValueA     string        `json:"value_a,omitempty"`
+	ValueB     string        `json:"value_b,omitempty"`
+}
+
+// DiffResult holds the records returned by both servers and their differences
+type DiffResult struct {
+	Domain      string                        `json:"domain"`
+	ServerA     string                        `json:"server_a"`
+	ServerB     string                        `json:"server_b"`
+	RecordTypes []DNSRecordType               `json:"record_types"`
+	RecordsA    map[DNSRecordType][]DNSRecord `json:"records_a"`
+	RecordsB    map[DNSRecordType][]DNSRecord `json:"records_b"`
+	Differences []RecordDiff                  `json:"differences"`
+	Errors      []string                      `json:"errors,omitempty"`
+	Identical   bool                          `json:"identical"`
+	Timestamp   time.Time                     `json:"timestamp"`
+}
+
+// DiffServers queries both nameservers for each record type and reports added, removed
+// and changed records. Query failures are recorded in Errors rather than aborting the diff.
+func (r *Resolver) DiffServers(ctx context.Context, domain string, recordTypes []DNSRecordType, serverA, serverB string) *DiffResult {
+	result := &DiffResult{
+		Domain:      domain,
+		ServerA:     serverA,
+		ServerB:     serverB,
+		RecordTypes: recordTypes,
+		RecordsA:    make(map[DNSRecordType][]DNSRecord),
+		RecordsB:    make(map[DNSRecordType][]DNSRecord),
+		Differences: []RecordDiff{},
+		Timestamp:   time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	query := func(recordType DNSRecordType, server string, into map[DNSRecordType][]DNSRecord) {
+		defer wg.Done()
+		answer, err := r.Query(ctx, domain, recordType, server)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %s: %v", server, recordType, err))
+			return
+		}
+		into[recordType] = answer.Records
+	}
+
+	for _, recordType := range recordTypes {
+		wg.Add(2)
+		go query(recordType, serverA, result.RecordsA)
+		go query(recordType, serverB, result.RecordsB)
+	}
+	wg.Wait()
+
+	sort.Strings(result.Errors)
+	for _, recordType := range recordTypes {
+		result.Differences = append(result.Differences,
+			diffRecords(recordType, result.RecordsA[recordType], result.RecordsB[recordType])...)
+	}
+	result.Identical = len(result.Differences) == 0 && len(result.Errors) == 0
+
+	return result
+}
+
+// diffRecords compares one record type's answers. Records are matched on their value;
+// MX records are matched on exchange host so a priority change shows as "changed".
+func diffRecords(recordType DNSRecordType, a, b []DNSRecord) []RecordDiff {
+	setA := recordsByKey(a)
+	setB := recordsByKey(b)
+
+	var diffs []RecordDiff
+	for _, key := range sortedKeys(setA) {
+		recordA := setA[key]
+		recordB, found := setB[key]
+		switch {
+		case !found:
+			diffs = append(diffs, RecordDiff{RecordType: recordType, Change: DiffRemoved, ValueA: displayValue(recordA)})
+		case recordA.Priority != recordB.Priority:
+			diffs = append(diffs, RecordDiff{RecordType: recordType, Change: DiffChanged, ValueA: displayValue(recordA), ValueB: displayValue(recordB)})
+		}
+	}
+	for _, key := range sortedKeys(setB) {
+		if _, found := setA[key]; !found {
+			diffs = append(diffs, RecordDiff{RecordType: recordType, Change: DiffAdded, ValueB: displayValue(setB[key])})
+		}
+	}
+
+	// A lone CNAME pointing somewhere else is a change, not an add/remove pair
+	if recordType == RecordTypeCNAME && len(setA) == 1 && len(setB) == 1 && len(diffs) == 2 {
+		diffs = []RecordDiff{{RecordType: recordType, Change: DiffChanged, ValueA: diffs[0].ValueA, ValueB: diffs[1].ValueB}}
+	}
+
+	return diffs
+}
+
+func recordsByKey(records []DNSRecord) map[string]DNSRecord {
+	keyed := make(map[string]DNSRecord, len(records))
+	for _, record := range records {
+		keyed[strings.ToLower(record.Value)] = record
+	}
+	return keyed
+}
+
+func sortedKeys(records map[string]DNSRecord) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// displayValue renders a record value, prefixing the priority for MX records
+func displayValue(record DNSRecord) string {
+	if record.Type == RecordTypeMX {
+		return fmt.Sprintf("%d %s", record.Priority, record.Value)
+	}
+	return record.Value
+}
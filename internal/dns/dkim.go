@@ -0,0 +1,102 @@
+// =============================================================================
+// internal/dns/dkim.go - DKIM selector probing
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultDKIMSelectors are selectors used by common mail providers and signing tools,
+// probed on every consistency check
+var DefaultDKIMSelectors = []string{
+	"default", "dkim", "mail", "email", "smtp",
+	"google",                 // Google Workspace
+	"selector1", "selector2", // Microsoft 365
+	"k1", "k2", "k3", // Mailchimp, Klaviyo
+	"mandrill", // Mandrill
+	"s1", "s2", // SendGrid, generic rotation
+	"mxvault", "zoho", "protonmail", "fm1", "fm2", "fm3",
+}
+
+// dkimSelector is a selector found under _domainkey together with its key records
+type dkimSelector struct {
+	Selector string
+	Name     string
+	Records  []string
+}
+
+// probeDKIMSelectors queries <selector>._domainkey.<domain> for each selector and returns the
+// ones publishing a DKIM key record, sorted by selector
+func (r *Resolver) probeDKIMSelectors(ctx context.Context, domain string, selectors []string, nameserver string) []dkimSelector {
+	domain = strings.TrimSuffix(domain, ".")
+
+	var (
+		found []dkimSelector
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+	)
+
+	for _, selector := range selectors {
+		wg.Add(1)
+		go func(selector string) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("%s._domainkey.%s", selector, domain)
+			values, err := r.lookupTXT(ctx, name, nameserver)
+			if err != nil {
+				return
+			}
+
+			var records []string
+			for _, value := range values {
+				if isDKIMRecord(value) {
+					records = append(records, value)
+				}
+			}
+			if len(records) == 0 {
+				return
+			}
+
+			mu.Lock()
+			found = append(found, dkimSelector{Selector: selector, Name: name, Records: records})
+			mu.Unlock()
+		}(selector)
+	}
+	wg.Wait()
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Selector < found[j].Selector })
+	return found
+}
+
+// isDKIMRecord reports whether a TXT value looks like a DKIM key record rather than unrelated
+// text (such as a wildcard TXT record answering for every selector)
+func isDKIMRecord(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if strings.HasPrefix(value, "v=dkim1") {
+		return true
+	}
+	tags := parseDMARCTags(value)
+	_, hasKey := tags["p"]
+	_, hasKeyType := tags["k"]
+	return hasKey || hasKeyType
+}
+
+// dkimSelectorList merges the default selectors with user-supplied ones, dropping duplicates
+func dkimSelectorList(extra []string) []string {
+	seen := make(map[string]bool)
+	var selectors []string
+	for _, selector := range append(append([]string{}, DefaultDKIMSelectors...), extra...) {
+		selector = strings.ToLower(strings.Trim(strings.TrimSpace(selector), "."))
+		if selector == "" || seen[selector] {
+			continue
+		}
+		seen[selector] = true
+		selectors = append(selectors, selector)
+	}
+	return selectors
+}
@@ -0,0 +1,98 @@
+// =============================================================================
+// internal/dns/dmarc.go - DMARC tag parsing and report destination checks
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// parseDMARCTags splits a DMARC record into its tag=value pairs, lowercasing tag names
+func parseDMARCTags(record string) map[string]string {
+	tags := make(map[string]string)
+	for _, part := range strings.Split(record, ";") {
+		name, value, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		tags[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return tags
+}
+
+// parseDMARCURIs validates a rua/ruf value (comma-separated mailto: URIs with an optional
+// "!size" limit) and returns the domains of the report addresses
+func parseDMARCURIs(value string) ([]string, error) {
+	var domains []string
+	for _, uri := range strings.Split(value, ",") {
+		uri = strings.TrimSpace(uri)
+		if uri == "" {
+			return nil, fmt.Errorf("empty URI in list")
+		}
+
+		if !strings.HasPrefix(strings.ToLower(uri), "mailto:") {
+			return nil, fmt.Errorf("%q is not a mailto: URI", uri)
+		}
+		address := uri[len("mailto:"):]
+		if bang := strings.LastIndex(address, "!"); bang >= 0 {
+			address = address[:bang]
+		}
+
+		parsed, err := mail.ParseAddress(address)
+		if err != nil || parsed.Address != address {
+			return nil, fmt.Errorf("%q is not a valid email address", address)
+		}
+
+		at := strings.LastIndex(parsed.Address, "@")
+		domains = append(domains, strings.ToLower(parsed.Address[at+1:]))
+	}
+	return domains, nil
+}
+
+// dmarcReportAuthorized reports whether dest publishes the RFC 7489 section 7.1 record
+// allowing it to receive DMARC reports for domain
+func (r *Resolver) dmarcReportAuthorized(ctx context.Context, domain, dest, nameserver string) (bool, error) {
+	name := fmt.Sprintf("%s._report._dmarc.%s", strings.TrimSuffix(domain, "."), strings.TrimSuffix(dest, "."))
+
+	records, err := r.lookupTXT(ctx, name, nameserver)
+	if err != nil {
+		return false, err
+	}
+	for _, record := range records {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(record)), "v=dmarc1") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// subdomainProbeLabels are common host names looked up to tell whether a domain has
+// subdomains, since DNS offers no way to list them
+var subdomainProbeLabels = []string{"www", "mail", "smtp", "webmail", "api", "app", "portal", "vpn", "remote", "blog", "shop", "dev", "staging", "test", "ftp"}
+
+// hasSubdomains reports whether domain has names beneath it: one of knownHosts lies below
+// it, or one of the common subdomain labels exists (answers anything but NXDOMAIN)
+func (r *Resolver) hasSubdomains(ctx context.Context, domain, nameserver string, knownHosts []string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	for _, host := range knownHosts {
+		if host = strings.TrimSuffix(strings.ToLower(host), "."); host != domain && isSameOrSubdomain(host, domain) {
+			return true
+		}
+	}
+	for _, label := range subdomainProbeLabels {
+		if nx, err := r.isNXDomain(ctx, label+"."+domain, nameserver); err == nil && !nx {
+			return true
+		}
+	}
+	return false
+}
+
+// isSameOrSubdomain reports whether name equals domain or sits beneath it
+func isSameOrSubdomain(name, domain string) bool {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
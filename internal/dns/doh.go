@@ -0,0 +1,99 @@
+// =============================================================================
+// internal/dns/doh.go - DNS-over-HTTPS (RFC 8484) exchanges
+// =============================================================================
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// dohMediaType is the RFC 8484 content type of wire-format DNS messages
+const dohMediaType = "application/dns-message"
+
+// dohMaxResponse bounds the response body read, the largest possible DNS message
+const dohMaxResponse = 65535
+
+// dohClient is shared so connections to an endpoint are reused across queries
+var dohClient = &http.Client{}
+
+// IsDoHEndpoint reports whether nameserver is a DNS-over-HTTPS URL rather than an address.
+// The resolver sends queries for such nameservers as RFC 8484 POST requests.
+func IsDoHEndpoint(nameserver string) bool {
+	return strings.HasPrefix(strings.ToLower(nameserver), "https://")
+}
+
+// exchangeDoH posts msg to a DNS-over-HTTPS endpoint, bounded by timeout. Like exchangeOnce
+// it reports the connect time (TCP and TLS setup, zero for a reused connection) apart from
+// the round trip.
+func exchangeDoH(ctx context.Context, timeout time.Duration, msg *dns.Msg, endpoint string) (*dns.Msg, time.Duration, time.Duration, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// RFC 8484 section 4.1: use ID 0 so responses are cacheable, restoring it afterwards
+	query := msg.Copy()
+	query.Id = 0
+	wire, err := query.Pack()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to pack query: %w", err)
+	}
+
+	var connectStart time.Time
+	var connect time.Duration
+	trace := &httptrace.ClientTrace{
+		ConnectStart: func(string, string) { connectStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			if !connectStart.IsZero() {
+				connect = time.Since(connectStart)
+			}
+		},
+	}
+
+	request, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodPost, endpoint, bytes.NewReader(wire))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("invalid DoH endpoint %s: %w", endpoint, err)
+	}
+	request.Header.Set("Content-Type", dohMediaType)
+	request.Header.Set("Accept", dohMediaType)
+
+	start := time.Now()
+	response, err := dohClient.Do(request)
+	if err != nil {
+		return nil, connect, 0, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, dohMaxResponse+1))
+	rtt := time.Since(start) - connect
+	if err != nil {
+		return nil, connect, rtt, fmt.Errorf("failed to read DoH response: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, connect, rtt, fmt.Errorf("DoH endpoint returned HTTP %s", response.Status)
+	}
+	if mediaType := response.Header.Get("Content-Type"); !strings.HasPrefix(mediaType, dohMediaType) {
+		return nil, connect, rtt, fmt.Errorf("DoH endpoint returned %q instead of %s", mediaType, dohMediaType)
+	}
+	if len(body) > dohMaxResponse {
+		return nil, connect, rtt, fmt.Errorf("DoH response exceeds %d bytes", dohMaxResponse)
+	}
+
+	reply := new(dns.Msg)
+	if err := reply.Unpack(body); err != nil {
+		return nil, connect, rtt, fmt.Errorf("invalid DoH response: %w", err)
+	}
+	reply.Id = msg.Id
+	return reply, connect, rtt, nil
+}
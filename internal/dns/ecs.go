@@ -0,0 +1,55 @@
+// =============================================================================
+// internal/dns/ecs.go - EDNS Client Subnet (RFC 7871) query option
+// =============================================================================
+package dns
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// ParseClientSubnet validates an ECS prefix such as 203.0.113.0/24 or 2001:db8::/56. Host bits
+// beyond the prefix length are zeroed, as RFC 7871 section 6 requires.
+func ParseClientSubnet(cidr string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid client subnet %q: expected CIDR notation such as 203.0.113.0/24", cidr)
+	}
+	if prefix.Addr().Zone() != "" {
+		return netip.Prefix{}, fmt.Errorf("invalid client subnet %q: zones are not allowed", cidr)
+	}
+	if addr := prefix.Addr(); addr.Is4In6() {
+		// ::ffff:a.b.c.d/n is the IPv4 prefix a.b.c.d/(n-96)
+		if prefix.Bits() < 96 {
+			return netip.Prefix{}, fmt.Errorf("invalid client subnet %q: IPv4-mapped prefixes need a length of at least 96", cidr)
+		}
+		prefix = netip.PrefixFrom(addr.Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+// addClientSubnet attaches an EDNS0 Client Subnet option for prefix to msg
+func addClientSubnet(msg *dns.Msg, prefix netip.Prefix) {
+	opt := msg.IsEdns0()
+	if opt == nil {
+		msg.SetEdns0(4096, false)
+		opt = msg.IsEdns0()
+	}
+
+	family := uint16(1) // IPv4, per the IANA address family registry
+	if prefix.Addr().Is6() {
+		family = 2
+	}
+
+	opt.Option = append(opt.Option, &dns.EDNS0_SUBNET{
+		Code:          dns.EDNS0SUBNET,
+		Family:        family,
+		SourceNetmask: uint8(prefix.Bits()),
+		SourceScope:   0,
+		Address:       net.IP(prefix.Addr().AsSlice()),
+	})
+}
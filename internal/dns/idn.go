@@ -0,0 +1,64 @@
+// =============================================================================
+// internal/dns/idn.go - Internationalized domain names (IDNA punycode)
+// =============================================================================
+package dns
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// idnProfile applies the IDNA lookup rules (case folding, normalization and
+// label validation) but, unlike idna.Lookup, allows underscores so service
+// names such as _dmarc.münchen.de convert too
+var idnProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
+// ToASCII converts a domain with Unicode labels, such as münchen.de, to the
+// punycode form queried on the wire (xn--mnchen-3ya.de). ASCII domains are
+// returned unchanged, so names the IDNA rules would reject but DNS allows
+// keep working.
+func ToASCII(domain string) (string, error) {
+	if isASCII(domain) {
+		return domain, nil
+	}
+	ascii, err := idnProfile.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("invalid internationalized domain %q: %w", domain, err)
+	}
+	return ascii, nil
+}
+
+// ToUnicode returns the Unicode form of a domain with punycode labels, or ""
+// when it has none or they don't decode
+func ToUnicode(domain string) string {
+	if !strings.Contains(strings.ToLower(domain), "xn--") {
+		return ""
+	}
+	unicode, err := idnProfile.ToUnicode(domain)
+	if err != nil || unicode == strings.ToLower(domain) {
+		return ""
+	}
+	return unicode
+}
+
+// DisplayDomain shows a punycode domain in both forms, as
+// "münchen.de (xn--mnchen-3ya.de)"; other domains are returned as they are
+func DisplayDomain(domain string) string {
+	if unicode := ToUnicode(domain); unicode != "" {
+		return fmt.Sprintf("%s (%s)", unicode, domain)
+	}
+	return domain
+}
+
+// isASCII reports whether s holds only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+// =============================================================================
+// internal/dns/lookup.go - Low-level lookups used by the consistency checks
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// LookupIPs resolves host to its IPv4 and IPv6 addresses through nameserver
+func (r *Resolver) LookupIPs(ctx context.Context, host, nameserver string) ([]string, error) {
+	return r.lookupIPs(ctx, host, nameserver)
+}
+
+// lookupIPs resolves host to its IPv4 and IPv6 addresses
+func (r *Resolver) lookupIPs(ctx context.Context, host, nameserver string) ([]string, error) {
+	var addresses []string
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		msg := new(dns.Msg)
+		msg.SetQuestion(dns.Fqdn(host), qtype)
+		msg.RecursionDesired = true
+
+		response, err := r.exchange(ctx, msg, nameserver)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, answer := range response.Answer {
+			switch rr := answer.(type) {
+			case *dns.A:
+				addresses = append(addresses, rr.A.String())
+			case *dns.AAAA:
+				addresses = append(addresses, rr.AAAA.String())
+			}
+		}
+	}
+	return addresses, nil
+}
+
+// LookupPTR returns the PTR names for an IP address, lowercased and fully qualified
+func (r *Resolver) LookupPTR(ctx context.Context, ip, nameserver string) ([]string, error) {
+	return r.lookupPTR(ctx, ip, nameserver)
+}
+
+// lookupPTR returns the PTR names for an IP address
+func (r *Resolver) lookupPTR(ctx context.Context, ip, nameserver string) ([]string, error) {
+	reverse, err := dns.ReverseAddr(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(reverse, dns.TypePTR)
+	msg.RecursionDesired = true
+
+	response, err := r.exchange(ctx, msg, nameserver)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, answer := range response.Answer {
+		if ptr, ok := answer.(*dns.PTR); ok {
+			names = append(names, strings.ToLower(ptr.Ptr))
+		}
+	}
+	return names, nil
+}
+
+// lookupTXT returns the TXT records at name, joining each record's character strings
+func (r *Resolver) lookupTXT(ctx context.Context, name, nameserver string) ([]string, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), dns.TypeTXT)
+	msg.RecursionDesired = true
+
+	response, err := r.exchange(ctx, msg, nameserver)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []string
+	for _, answer := range response.Answer {
+		if txt, ok := answer.(*dns.TXT); ok {
+			// Character strings of a single TXT record are concatenated without separators
+			records = append(records, strings.Join(txt.Txt, ""))
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,171 @@
+// =============================================================================
+// internal/dns/mtasts.go - MTA-STS policy retrieval and TLS-RPT parsing
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	mtaSTSFetchTimeout  = 10 * time.Second
+	mtaSTSMaxPolicySize = 64 * 1024
+	mtaSTSMaxAge        = 31557600 // RFC 8461 section 3.2: one year
+)
+
+// mtaSTSIDPattern is the id= syntax of the _mta-sts TXT record (RFC 8461 section 3.1)
+var mtaSTSIDPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,32}$`)
+
+// mtaSTSPolicy is a parsed mta-sts.txt policy file
+type mtaSTSPolicy struct {
+	Version string
+	Mode    string
+	MX      []string
+	MaxAge  int
+}
+
+// parseMTASTSRecord validates the _mta-sts TXT record and returns its id
+func parseMTASTSRecord(record string) (string, error) {
+	tags := parseDMARCTags(record)
+	if !strings.HasPrefix(strings.TrimSpace(record), "v=STSv1") {
+		return "", fmt.Errorf("record must start with v=STSv1")
+	}
+	id, ok := tags["id"]
+	if !ok {
+		return "", fmt.Errorf("missing id= tag")
+	}
+	if !mtaSTSIDPattern.MatchString(id) {
+		return "", fmt.Errorf("id %q must be 1-32 letters or digits", id)
+	}
+	return id, nil
+}
+
+// fetchMTASTSPolicy downloads https://mta-sts.<domain>/.well-known/mta-sts.txt. Redirects are
+// refused and the response must be 200 text/plain, as RFC 8461 section 3.3 requires.
+func fetchMTASTSPolicy(ctx context.Context, domain string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, mtaSTSFetchTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("https://mta-sts.%s/.well-known/mta-sts.txt", strings.TrimSuffix(domain, "."))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return errors.New("policy fetch must not redirect")
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned HTTP %d", url, resp.StatusCode)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "text/plain" {
+		return "", fmt.Errorf("%s served Content-Type %q instead of text/plain", url, resp.Header.Get("Content-Type"))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, mtaSTSMaxPolicySize))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// parseMTASTSPolicy parses a policy file, returning every syntax problem found
+func parseMTASTSPolicy(body string) (*mtaSTSPolicy, []string) {
+	policy := &mtaSTSPolicy{}
+	var problems []string
+	hasMaxAge := false
+
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			problems = append(problems, fmt.Sprintf("malformed line %q", line))
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "version":
+			policy.Version = value
+		case "mode":
+			policy.Mode = value
+		case "mx":
+			policy.MX = append(policy.MX, strings.ToLower(strings.TrimSuffix(value, ".")))
+		case "max_age":
+			hasMaxAge = true
+			age, err := strconv.Atoi(value)
+			if err != nil || age < 0 || age > mtaSTSMaxAge {
+				problems = append(problems, fmt.Sprintf("max_age %q must be an integer between 0 and %d", value, mtaSTSMaxAge))
+				continue
+			}
+			policy.MaxAge = age
+		}
+	}
+
+	if policy.Version != "STSv1" {
+		problems = append(problems, fmt.Sprintf("version must be STSv1, got %q", policy.Version))
+	}
+	switch policy.Mode {
+	case "enforce", "testing", "none":
+	default:
+		problems = append(problems, fmt.Sprintf("mode must be enforce, testing or none, got %q", policy.Mode))
+	}
+	if !hasMaxAge {
+		problems = append(problems, "missing max_age")
+	}
+	if len(policy.MX) == 0 && policy.Mode != "none" {
+		problems = append(problems, "no mx patterns")
+	}
+
+	return policy, problems
+}
+
+// mtaSTSMatches reports whether an MX host matches a policy mx pattern. A leading "*." matches
+// exactly one label.
+func mtaSTSMatches(pattern, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		label, rest, found := strings.Cut(host, ".")
+		return found && label != "" && rest == suffix
+	}
+	return pattern == host
+}
+
+// parseTLSRPTRecord validates a _smtp._tls TXT record (RFC 8460 section 3)
+func parseTLSRPTRecord(record string) error {
+	tags := parseDMARCTags(record)
+	if !strings.HasPrefix(strings.TrimSpace(record), "v=TLSRPTv1") {
+		return fmt.Errorf("record must start with v=TLSRPTv1")
+	}
+	rua, ok := tags["rua"]
+	if !ok || rua == "" {
+		return fmt.Errorf("missing rua= tag")
+	}
+	for _, uri := range strings.Split(rua, ",") {
+		uri = strings.ToLower(strings.TrimSpace(uri))
+		if !strings.HasPrefix(uri, "mailto:") && !strings.HasPrefix(uri, "https://") {
+			return fmt.Errorf("rua URI %q must use mailto: or https:", uri)
+		}
+	}
+	return nil
+}
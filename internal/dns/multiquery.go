@@ -0,0 +1,71 @@
+// =============================================================================
+// internal/dns/multiquery.go - Several record types for one domain in one call
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CommonRecordTypes are fetched by the records command for a quick look at a domain
+var CommonRecordTypes = []DNSRecordType{
+	RecordTypeA, RecordTypeAAAA, RecordTypeCNAME, RecordTypeMX, RecordTypeNS, RecordTypeTXT,
+	RecordTypeSOA, RecordTypeCAA,
+}
+
+// MultiQueryResult holds one DNSResult per requested record type, in the order requested
+type MultiQueryResult struct {
+	Domain      string          `json:"domain"`
+	Nameserver  string          `json:"nameserver"`
+	RecordTypes []DNSRecordType `json:"record_types"`
+	Results     []*DNSResult    `json:"results"`
+	Timestamp   time.Time       `json:"timestamp"`
+}
+
+// Failed returns how many of the record types could not be queried
+func (m *MultiQueryResult) Failed() int {
+	failed := 0
+	for _, result := range m.Results {
+		if result.Error != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// QueryTypes queries nameserver for each record type concurrently, at most
+// QueryOptions.Concurrency at a time when set. A failed type keeps its error in that
+// DNSResult rather than aborting the others.
+func (r *Resolver) QueryTypes(ctx context.Context, domain string, recordTypes []DNSRecordType, nameserver string) *MultiQueryResult {
+	multi := &MultiQueryResult{
+		Domain:      domain,
+		Nameserver:  nameserver,
+		RecordTypes: recordTypes,
+		Results:     make([]*DNSResult, len(recordTypes)),
+		Timestamp:   time.Now(),
+	}
+
+	workers := r.options.Concurrency
+	if workers <= 0 || workers > len(recordTypes) {
+		workers = len(recordTypes)
+	}
+	sem := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	for i, recordType := range recordTypes {
+		wg.Add(1)
+		go func(i int, recordType DNSRecordType) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// The error is also recorded in the result
+			multi.Results[i], _ = r.Query(ctx, domain, recordType, nameserver)
+		}(i, recordType)
+	}
+	wg.Wait()
+
+	return multi
+}
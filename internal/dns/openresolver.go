@@ -0,0 +1,113 @@
+// =============================================================================
+// internal/dns/openresolver.go - Open recursive nameserver detection
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// recursionProbeNames are third-party names asked of each nameserver; the first one outside
+// the audited zone is used so an authoritative answer is never mistaken for recursion
+var recursionProbeNames = []string{"www.iana.org", "www.example.com"}
+
+// probeRecursion sends a recursive query for name to server and reports whether it was
+// answered with recursion available and actual data. Timeouts, refusals and referrals are
+// the healthy outcomes for an authoritative-only server.
+func (r *Resolver) probeRecursion(ctx context.Context, name, server string) (bool, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), dns.TypeA)
+	msg.RecursionDesired = true
+
+	response, err := r.exchange(ctx, msg, server)
+	if err != nil {
+		return false, err
+	}
+	return response.Rcode == dns.RcodeSuccess && response.RecursionAvailable &&
+		!response.Authoritative && len(response.Answer) > 0, nil
+}
+
+// checkOpenResolvers asks every NS host of the zone to resolve an unrelated name and flags
+// hosts that do so, since open resolvers can be abused for DNS amplification attacks
+func (c *ConsistencyChecker) checkOpenResolvers(ctx context.Context, propagation *PropagationResult) []ConsistencyIssue {
+	servers := make([]string, 0, len(propagation.Results))
+	for server := range propagation.Results {
+		servers = append(servers, server)
+	}
+	if len(servers) == 0 {
+		return nil
+	}
+	sort.Strings(servers)
+	recursive := servers[0]
+
+	probeName := ""
+	for _, name := range recursionProbeNames {
+		if !isSameOrSubdomain(name, propagation.Domain) {
+			probeName = name
+			break
+		}
+	}
+
+	hosts := make(map[string]bool)
+	for _, records := range propagation.Results {
+		for _, record := range records {
+			if record.Type == RecordTypeNS {
+				hosts[strings.ToLower(record.Value)] = true
+			}
+		}
+	}
+
+	var (
+		issues []ConsistencyIssue
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+	)
+	for host := range hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+
+			addresses, err := c.resolver.lookupIPs(ctx, host, recursive)
+			if err != nil {
+				return
+			}
+
+			var open []string
+			for _, address := range addresses {
+				probeCtx, cancel := context.WithTimeout(ctx, nameserverProbeTimeout)
+				recurses, err := c.resolver.probeRecursion(probeCtx, probeName, net.JoinHostPort(address, "53"))
+				cancel()
+				if err == nil && recurses {
+					open = append(open, net.JoinHostPort(address, "53"))
+				}
+			}
+			if len(open) == 0 {
+				return
+			}
+
+			mu.Lock()
+			issues = append(issues, ConsistencyIssue{
+				Type:        IssueOpenRecursiveNameserver,
+				Domain:      propagation.Domain,
+				RecordType:  RecordTypeNS,
+				Description: fmt.Sprintf("Nameserver %s resolved %s recursively for anyone, making it usable for DNS amplification attacks", host, probeName),
+				Severity:    "high",
+				Servers:     open,
+				Expected:    "REFUSED or no answer for names outside its zones",
+				Actual:      host,
+			})
+			mu.Unlock()
+		}(host)
+	}
+	wg.Wait()
+
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Actual < issues[j].Actual })
+	return issues
+}
@@ -0,0 +1,180 @@
+// =============================================================================
+// internal/dns/ptr.go - Bulk reverse DNS lookups for address lists
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// DefaultMaxPTRAddresses is how many addresses ReadIPInput expands to before asking for
+	// confirmation; a /16 fits exactly
+	DefaultMaxPTRAddresses = 1 << 16
+
+	// MaxPTRAddresses bounds an input even when confirmed, since every address is held in memory
+	MaxPTRAddresses = 1 << 24
+)
+
+// ReadIPInputFromFile reads addresses from a file (see ReadIPInput); a filename of "-" reads
+// from stdin
+func ReadIPInputFromFile(filename string, maxAddresses int) (*BulkInput, error) {
+	if filename == "-" {
+		return ReadIPInput(os.Stdin, maxAddresses)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadIPInput(file, maxAddresses)
+}
+
+// ReadIPInput reads one IPv4 or IPv6 address or CIDR range per line, taking the first field
+// of CSV rows so firewall exports can be used as they are. Ranges are expanded into every
+// address they contain; an input expanding to more than maxAddresses is an error. Targets
+// carry the address as their Domain, and repeated addresses are dropped. Lines that are not
+// addresses are reported in Skipped.
+func ReadIPInput(reader io.Reader, maxAddresses int) (*BulkInput, error) {
+	input := &BulkInput{}
+	seen := make(map[netip.Addr]bool)
+	total := 0
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	for {
+		fields, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading addresses: %w", err)
+		}
+		lineNum, _ := csvReader.FieldPos(0)
+
+		text := strings.TrimSpace(fields[0])
+		if text == "" {
+			continue
+		}
+
+		addrs, size, err := parseAddressOrPrefix(text)
+		if err != nil {
+			input.Skipped = append(input.Skipped, SkippedRow{Line: lineNum, Text: text, Reason: err.Error()})
+			continue
+		}
+
+		// Check the size before expanding, so a stray /8 is refused without allocating it
+		if size > uint64(maxAddresses-total) {
+			return nil, fmt.Errorf("line %d: %s takes the input past %d addresses; pass --yes to allow up to %d",
+				lineNum, text, maxAddresses, MaxPTRAddresses)
+		}
+		total += int(size)
+
+		for addr := range addrs {
+			if seen[addr] {
+				input.Duplicates++
+				continue
+			}
+			seen[addr] = true
+			input.Targets = append(input.Targets, BulkTarget{Domain: addr.String()})
+		}
+	}
+
+	if len(input.Targets) == 0 {
+		return nil, fmt.Errorf("no valid addresses found in input")
+	}
+
+	return input, nil
+}
+
+// parseAddressOrPrefix parses a single address or a CIDR range, returning its addresses in
+// order and how many there are
+func parseAddressOrPrefix(text string) (func(yield func(netip.Addr) bool), uint64, error) {
+	if !strings.Contains(text, "/") {
+		addr, err := netip.ParseAddr(text)
+		if err != nil {
+			return nil, 0, fmt.Errorf("not an IP address or CIDR range")
+		}
+		addr = addr.Unmap()
+		return func(yield func(netip.Addr) bool) { yield(addr) }, 1, nil
+	}
+
+	prefix, err := netip.ParsePrefix(text)
+	if err != nil {
+		return nil, 0, fmt.Errorf("not an IP address or CIDR range")
+	}
+	prefix = prefix.Masked()
+
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits >= 63 {
+		return nil, 0, fmt.Errorf("range is too large to expand")
+	}
+	size := uint64(1) << hostBits
+
+	return func(yield func(netip.Addr) bool) {
+		for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+			if !yield(addr) {
+				return
+			}
+		}
+	}, size, nil
+}
+
+// ProcessPTR looks up the PTR records of each target's address, querying the target's own
+// nameserver or else the first of nameservers. An address without a PTR record is a failed
+// result, not an error for the run.
+func (bp *BulkProcessor) ProcessPTR(ctx context.Context, targets []BulkTarget, nameservers []string) (*BulkSummary, error) {
+	summary, err := bp.process(ctx, BulkOperationPTR, targets, false, func(ctx context.Context, target BulkTarget) BulkResult {
+		return bp.processSinglePTR(ctx, target.Domain, target.nameservers(nameservers))
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary.RecordTypes = []DNSRecordType{RecordTypePTR}
+	summary.Nameservers = nameservers
+	return summary, nil
+}
+
+// processSinglePTR resolves one address; the result's Domain stays the address while its
+// Query holds the reverse name that was asked for
+func (bp *BulkProcessor) processSinglePTR(ctx context.Context, address string, nameservers []string) BulkResult {
+	startTime := time.Now()
+
+	reverse, err := dns.ReverseAddr(address)
+	if err != nil {
+		return BulkResult{
+			Domain:    address,
+			Error:     fmt.Errorf("invalid address: %w", err),
+			StartTime: startTime,
+			EndTime:   time.Now(),
+		}
+	}
+
+	result, err := bp.resolver.Query(ctx, reverse, RecordTypePTR, nameservers[0])
+	if err == nil && len(result.Records) == 0 {
+		err = fmt.Errorf("no PTR record for %s", address)
+	}
+
+	return BulkResult{
+		Domain:    address,
+		Success:   err == nil,
+		Error:     err,
+		StartTime: startTime,
+		EndTime:   time.Now(),
+		Query:     result,
+	}
+}
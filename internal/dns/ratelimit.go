@@ -0,0 +1,85 @@
+// =============================================================================
+// internal/dns/ratelimit.go - Per-nameserver query rate limiting
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces queries to each nameserver independently with a token bucket.
+// The bucket holds a single token, so queries to one server are evenly spaced rather
+// than sent in bursts; different servers never wait on each other.
+type RateLimiter struct {
+	perSecond float64
+	mu        sync.Mutex
+	next      map[string]time.Time // nameserver -> earliest time the next query may go out
+}
+
+// NewRateLimiter allows perSecond queries per second to each nameserver
+func NewRateLimiter(perSecond float64) *RateLimiter {
+	return &RateLimiter{
+		perSecond: perSecond,
+		next:      make(map[string]time.Time),
+	}
+}
+
+// Rate returns the per-nameserver limit in queries per second
+func (l *RateLimiter) Rate() float64 {
+	return l.perSecond
+}
+
+// Wait blocks until a query may be sent to nameserver or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, nameserver string) error {
+	interval := time.Duration(float64(time.Second) / l.perSecond)
+
+	// Reserve the next slot for this server, then sleep until it comes up
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[nameserver]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[nameserver] = slot.Add(interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ParseRate parses a query rate such as "20", "20/s" or "600/m" into queries per second
+func ParseRate(rate string) (float64, error) {
+	count, unit, _ := strings.Cut(strings.TrimSpace(rate), "/")
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(count), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a positive rate (use e.g. 20/s or 600/m)", rate)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "", "s", "sec":
+		return n, nil
+	case "m", "min":
+		return n / 60, nil
+	case "h", "hr":
+		return n / 3600, nil
+	default:
+		return 0, fmt.Errorf("unknown unit %q in %q (use s, m or h)", unit, rate)
+	}
+}
@@ -0,0 +1,110 @@
+// =============================================================================
+// internal/dns/rdns.go - Forward-confirmed reverse DNS (FCrDNS) checks
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// PTRCheck is the forward lookup of one name returned by the PTR query
+type PTRCheck struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses,omitempty"` // A/AAAA records of Name
+	Confirmed bool     `json:"confirmed"`           // Addresses include the checked IP
+	Error     string   `json:"error,omitempty"`
+}
+
+// ReverseDNSResult holds the PTR names of an IP and whether each resolves back to it
+type ReverseDNSResult struct {
+	IP          string             `json:"ip"`
+	ReverseName string             `json:"reverse_name"`
+	Nameserver  string             `json:"nameserver"`
+	PTRs        []PTRCheck         `json:"ptrs"`
+	Confirmed   bool               `json:"confirmed"` // At least one PTR name resolves back to IP
+	Issues      []ConsistencyIssue `json:"issues,omitempty"`
+	Timestamp   time.Time          `json:"timestamp"`
+}
+
+// CheckReverseDNS looks up the PTR records of ip and forward-resolves each name, reporting
+// whether the round trip leads back to ip. Missing PTRs and names that do not resolve back
+// are returned as issues; only an invalid IP or a failed PTR query is an error.
+func (r *Resolver) CheckReverseDNS(ctx context.Context, ip, nameserver string) (*ReverseDNSResult, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	addr = addr.Unmap()
+	ip = addr.String()
+
+	reverse, err := dns.ReverseAddr(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReverseDNSResult{
+		IP:          ip,
+		ReverseName: reverse,
+		Nameserver:  nameserver,
+		Timestamp:   time.Now(),
+	}
+
+	names, err := r.lookupPTR(ctx, ip, nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("PTR lookup failed: %w", err)
+	}
+
+	issue := func(issueType, description, actual string) {
+		result.Issues = append(result.Issues, ConsistencyIssue{
+			Type:        issueType,
+			Domain:      reverse,
+			RecordType:  RecordTypePTR,
+			Description: description,
+			Severity:    "medium",
+			Servers:     []string{nameserver},
+			Expected:    ip,
+			Actual:      actual,
+		})
+	}
+
+	if len(names) == 0 {
+		issue(IssuePTRMissing, fmt.Sprintf("%s has no PTR record", ip), "")
+		addRemediations(result.Issues)
+		return result, nil
+	}
+
+	for _, name := range names {
+		check := PTRCheck{Name: name}
+
+		addresses, err := r.lookupIPs(ctx, name, nameserver)
+		switch {
+		case err != nil:
+			check.Error = err.Error()
+		case len(addresses) == 0:
+			issue(IssuePTRForwardMissing, fmt.Sprintf("PTR name %s has no A or AAAA record", name), name)
+		default:
+			check.Addresses = addresses
+			for _, address := range addresses {
+				if forward, err := netip.ParseAddr(address); err == nil && forward.Unmap() == addr {
+					check.Confirmed = true
+					break
+				}
+			}
+			if !check.Confirmed {
+				issue(IssuePTRForwardMismatch, fmt.Sprintf("PTR name %s resolves to %s, not back to %s", name, strings.Join(addresses, ", "), ip), name)
+			}
+		}
+
+		result.Confirmed = result.Confirmed || check.Confirmed
+		result.PTRs = append(result.PTRs, check)
+	}
+
+	addRemediations(result.Issues)
+	return result, nil
+}
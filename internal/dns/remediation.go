@@ -0,0 +1,107 @@
+// =============================================================================
+// internal/dns/remediation.go - Suggested fixes for each consistency issue type
+// =============================================================================
+package dns
+
+// remediations maps every issue type to a concrete suggestion for fixing it. Keep one entry
+// per Issue* constant.
+var remediations = map[string]string{
+	// Propagation and record sets
+	IssuePropagationInconsistency: "Wait for the previous TTL to expire; if servers still disagree, make sure every authoritative server has loaded the same zone version",
+	IssueDuplicateRecord:          "Remove the duplicate record from the zone",
+	IssueCNAMEAtApex:              "Replace the apex CNAME with A/AAAA records, or an ALIAS/ANAME/CNAME-flattening record if your DNS provider supports one",
+	IssueCNAMEConflict:            "Remove the other records at this name or replace the CNAME with the records it points to; a CNAME must be the only record at its name",
+	IssuePrivateIPInPublicDNS:     "Publish private addresses only in an internal (split-horizon) view, or pass --allow-private if this is intentional",
+
+	// MX
+	IssueMXPriorityZero:    "Confirm priority 0 is intended; use 10, 20, ... so backup MX hosts can be slotted in around the primary",
+	IssueMXIPLiteral:       "Point the MX record at a hostname with an A/AAAA record instead of an IP address",
+	IssueMXTargetCNAME:     "Point the MX record directly at the canonical hostname instead of a CNAME",
+	IssueMXTargetNoAddress: "Add an A or AAAA record for the MX host, or point the MX record at a host that has one",
+	IssueMXMissingPTR:      "Ask the owner of the address block (usually the hosting provider) to set a PTR record naming the mail server",
+	IssueMXPTRMismatch:     "Make the PTR name resolve back to the address, or change the PTR to the mail server's own hostname",
+
+	// Reverse DNS
+	IssuePTRMissing:         "Ask the owner of the address block (usually the hosting provider) to set a PTR record for this IP",
+	IssuePTRForwardMissing:  "Add an A/AAAA record for the PTR name pointing at this IP, or change the PTR to a name that has one",
+	IssuePTRForwardMismatch: "Point the PTR name's A/AAAA record at this IP, or change the PTR to a name that resolves to it",
+
+	// Nameservers and delegation
+	IssueInsufficientNameservers: "Add a second NS record on a different network so the zone survives one server failing",
+	IssueNameserversNotDiverse:   "Add a nameserver in a different network (another /24 or /48, ideally another provider)",
+	IssueUnreachableNameserver:   "Fix or remove the unreachable nameserver and update the delegation at the registrar",
+	IssueLameDelegation:          "Load the zone on this nameserver, or remove it from the NS records and the parent delegation",
+	IssueOpenRecursiveNameserver: "Disable recursion on the authoritative server (e.g. recursion no; in BIND) or restrict it to your own networks",
+	IssueDelegationMismatch:      "Update the NS records at the registrar and in the zone so both list the same nameservers",
+
+	// SOA
+	IssueSOASerialMismatch:    "Check that zone transfers (AXFR/IXFR or NOTIFY) reach every secondary, then wait for them to catch up",
+	IssueSOARefreshOutOfRange: "Set the SOA refresh between 1200 and 43200 seconds (RFC 1912)",
+	IssueSOARetryOutOfRange:   "Set the SOA retry between 120 and 7200 seconds (RFC 1912)",
+	IssueSOAExpireOutOfRange:  "Set the SOA expire between 1209600 and 2419200 seconds (RFC 1912)",
+	IssueSOAMinimumOutOfRange: "Set the SOA minimum (negative caching TTL) between 3600 and 86400 seconds (RFC 1912)",
+
+	// TTL
+	IssueTTLTooLow:     "Raise the TTL unless a migration is in progress; very short TTLs increase query load and latency",
+	IssueTTLTooHigh:    "Lower the TTL so changes and failovers take effect in a reasonable time",
+	IssueTTLVariance:   "Make the TTL identical on every authoritative server by serving the same zone data everywhere",
+	IssueTTLDivergence: "Flush the cache on the listed resolvers or wait for it to expire; if the servers are authoritative, make sure they load the same zone version",
+
+	// Dangling CNAMEs
+	IssueDanglingCNAME:             "Remove the CNAME or recreate the resource it points to",
+	IssuePossibleSubdomainTakeover: "Remove the CNAME immediately, or reclaim the resource at the provider before someone else does",
+
+	// TXT and SPF
+	IssueTXTRecordTooLong:   "Split the value into several quoted strings of at most 255 characters each",
+	IssueMultipleSPFRecords: "Merge all SPF mechanisms into a single v=spf1 record",
+	IssueSPFRecordTooLong:   "Shorten the record by consolidating mechanisms, or split it into several quoted strings",
+	IssueSPFTooManyLookups:  "Reduce SPF includes or flatten the record (replace includes with ip4:/ip6: ranges) to stay within 10 lookups",
+	IssueSPFIncludeLoop:     "Remove the include that leads back to a domain already in the chain",
+	IssueSPFIncludeMissing:  "Remove the include/redirect or publish an SPF record at the target domain",
+	IssueSPFIncludeTooDeep:  "Flatten nested includes so the chain is fewer than 10 levels deep",
+	IssueSPFPermissiveAll:   "End the record with -all (or ~all while testing) instead of +all",
+	IssueSPFNeutralAll:      "End the record with -all (or ~all while testing) so unlisted senders fail SPF",
+
+	// DMARC
+	IssueDMARCMissingPolicy:           "Add a p= tag, starting with p=none while monitoring reports and moving to quarantine or reject",
+	IssueDMARCWeakPolicy:              "Move to p=quarantine and then p=reject once DMARC reports show legitimate mail passing",
+	IssueDMARCWeakSubdomainPolicy:     "Remove sp=none or set it to quarantine or reject to match the domain policy",
+	IssueDMARCInvalidPct:              "Set pct= to a whole number from 0 to 100, or remove it (the default is 100)",
+	IssueDMARCInvalidRUA:              "List aggregate report addresses as comma-separated mailto: URIs, e.g. rua=mailto:dmarc@example.com",
+	IssueDMARCInvalidRUF:              "List failure report addresses as comma-separated mailto: URIs, e.g. ruf=mailto:dmarc@example.com",
+	IssueDMARCExternalRUAUnauthorized: "Have the receiving domain publish TXT \"v=DMARC1\" at <your-domain>._report._dmarc.<their-domain>, or send reports to your own domain",
+	IssueDMARCExternalRUFUnauthorized: "Have the receiving domain publish TXT \"v=DMARC1\" at <your-domain>._report._dmarc.<their-domain>, or send reports to your own domain",
+
+	// DKIM
+	IssueDKIMMissingPublicKey: "Publish the selector's public key in a p= tag",
+	IssueDKIMRevokedKey:       "Remove the selector record if the key was retired on purpose; otherwise publish the current public key",
+	IssueDKIMNoSelectorFound:  "Enable DKIM signing with your mail provider, or pass your selector with --dkim-selectors if it is not a common one",
+	IssueDKIMSelectorsFound:   "No action needed; check that every listed selector belongs to a sender you use",
+
+	// Web
+	IssueWWWMissing:      "Add a www record: a CNAME to the apex or the same A/AAAA records, then make sure the web server answers for it",
+	IssueWWWApexMismatch: "Point www and the apex at the same infrastructure, or confirm the difference is intended (e.g. www on a CDN)",
+
+	// MTA-STS and TLS-RPT
+	IssueMTASTSMissing:           "Publish TXT \"v=STSv1; id=<timestamp>\" at _mta-sts.<domain> and serve a policy at https://mta-sts.<domain>/.well-known/mta-sts.txt",
+	IssueMTASTSInvalidRecord:     "Publish exactly one TXT record of the form \"v=STSv1; id=<1-32 letters or digits>\"",
+	IssueMTASTSPolicyUnreachable: "Serve the policy over HTTPS with a valid certificate for mta-sts.<domain>, as text/plain, without redirects",
+	IssueMTASTSInvalidPolicy:     "Fix the policy file: version: STSv1, mode: enforce|testing|none, one mx: line per MX host, and max_age in seconds",
+	IssueMTASTSMXMismatch:        "Add an mx: line covering this MX host to the policy and bump the id in the _mta-sts record",
+	IssueTLSRPTMissing:           "Publish TXT \"v=TLSRPTv1; rua=mailto:tls-reports@<domain>\" at _smtp._tls.<domain>",
+	IssueTLSRPTInvalid:           "Publish exactly one TXT record of the form \"v=TLSRPTv1; rua=mailto:<address>\" (https: URIs are also allowed)",
+}
+
+// RemediationFor returns the suggested fix for an issue type, or "" if none is known
+func RemediationFor(issueType string) string {
+	return remediations[issueType]
+}
+
+// addRemediations fills in the Remediation of every issue that does not already carry one
+func addRemediations(issues []ConsistencyIssue) {
+	for i := range issues {
+		if issues[i].Remediation == "" {
+			issues[i].Remediation = RemediationFor(issues[i].Type)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package dns
+
+import "testing"
+
+func TestEveryIssueTypeHasRemediation(t *testing.T) {
+	issueTypes := []string{
+		IssuePropagationInconsistency, IssueDuplicateRecord, IssueCNAMEAtApex, IssueCNAMEConflict, IssuePrivateIPInPublicDNS,
+		IssueMXPriorityZero, IssueMXIPLiteral, IssueMXTargetCNAME, IssueMXTargetNoAddress, IssueMXMissingPTR, IssueMXPTRMismatch,
+		IssuePTRMissing, IssuePTRForwardMissing, IssuePTRForwardMismatch,
+		IssueInsufficientNameservers, IssueNameserversNotDiverse, IssueUnreachableNameserver, IssueLameDelegation, IssueOpenRecursiveNameserver, IssueDelegationMismatch,
+		IssueSOASerialMismatch, IssueSOARefreshOutOfRange, IssueSOARetryOutOfRange, IssueSOAExpireOutOfRange, IssueSOAMinimumOutOfRange,
+		IssueTTLTooLow, IssueTTLTooHigh, IssueTTLVariance, IssueTTLDivergence,
+		IssueDanglingCNAME, IssuePossibleSubdomainTakeover,
+		IssueTXTRecordTooLong, IssueMultipleSPFRecords, IssueSPFRecordTooLong, IssueSPFTooManyLookups, IssueSPFIncludeLoop, IssueSPFIncludeMissing, IssueSPFIncludeTooDeep, IssueSPFPermissiveAll, IssueSPFNeutralAll,
+		IssueDMARCMissingPolicy, IssueDMARCWeakPolicy, IssueDMARCWeakSubdomainPolicy, IssueDMARCInvalidPct, IssueDMARCInvalidRUA, IssueDMARCInvalidRUF, IssueDMARCExternalRUAUnauthorized, IssueDMARCExternalRUFUnauthorized,
+		IssueDKIMMissingPublicKey, IssueDKIMRevokedKey, IssueDKIMNoSelectorFound, IssueDKIMSelectorsFound,
+		IssueWWWMissing, IssueWWWApexMismatch,
+		IssueMTASTSMissing, IssueMTASTSInvalidRecord, IssueMTASTSPolicyUnreachable, IssueMTASTSInvalidPolicy, IssueMTASTSMXMismatch, IssueTLSRPTMissing, IssueTLSRPTInvalid,
+	}
+
+	for _, issueType := range issueTypes {
+		if RemediationFor(issueType) == "" {
+			t.Errorf("issue type %q has no entry in remediations", issueType)
+		}
+	}
+	if len(remediations) != len(issueTypes) {
+		t.Errorf("remediations has %d entries, want one per issue type (%d)", len(remediations), len(issueTypes))
+	}
+}
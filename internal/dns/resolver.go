@@ -6,7 +6,10 @@ package dns
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/miekg/dns"
@@ -16,23 +19,29 @@ import (
 type Resolver struct {
 	client  *dns.Client
 	options QueryOptions
+	limiter *RateLimiter // Paces queries per nameserver; nil means unlimited
+	qps     *RateLimiter // Paces all queries together, whichever nameserver; nil means unlimited
+	queries atomic.Int64 // Exchanges sent, for QueryCount
+	cache   *queryCache  // Answers reused by Query until their TTL expires; nil disables
+}
+
+// DefaultQueryOptions returns the options used by NewResolver
+func DefaultQueryOptions() QueryOptions {
+	return QueryOptions{
+		Timeout:      5 * time.Second,
+		Retries:      3,
+		UseRecursion: true,
+		CheckDNSSEC:  false,
+		IPv4Only:     false,
+		IPv6Only:     false,
+		Concurrency:  10,
+		QueryTimeout: 10 * time.Second,
+	}
 }
 
 // NewResolver creates a new DNS resolver with default options
 func NewResolver() *Resolver {
-	return &Resolver{
-		client: &dns.Client{
-			Timeout: 5 * time.Second,
-		},
-		options: QueryOptions{
-			Timeout:      5 * time.Second,
-			Retries:      3,
-			UseRecursion: true,
-			CheckDNSSEC:  false,
-			IPv4Only:     false,
-			IPv6Only:     false,
-		},
-	}
+	return NewResolverWithOptions(DefaultQueryOptions())
 }
 
 // NewResolverWithOptions creates a resolver with custom options
@@ -45,22 +54,55 @@ func NewResolverWithOptions(opts QueryOptions) *Resolver {
 	}
 }
 
+// SetRateLimiter paces every query this resolver sends through limiter; nil removes the limit
+func (r *Resolver) SetRateLimiter(limiter *RateLimiter) {
+	r.limiter = limiter
+}
+
+// SetQPSLimiter paces the total of all queries this resolver sends through limiter, on top
+// of any per-nameserver limit; nil removes the cap
+func (r *Resolver) SetQPSLimiter(limiter *RateLimiter) {
+	r.qps = limiter
+}
+
+// EnableCache makes Query reuse successful answers, keyed by domain, record type and
+// nameserver, until the lowest TTL in the answer expires. Reused results are marked Cached.
+func (r *Resolver) EnableCache() {
+	if r.cache == nil {
+		r.cache = newQueryCache()
+	}
+}
+
+// QueryCount returns how many exchanges the resolver has sent, including retries
+func (r *Resolver) QueryCount() int64 {
+	return r.queries.Load()
+}
+
 // Query performs a DNS query for a specific domain and record type
 func (r *Resolver) Query(ctx context.Context, domain string, recordType DNSRecordType, nameserver string) (*DNSResult, error) {
 	start := time.Now()
-	
+
 	result := &DNSResult{
 		Query: DNSQuery{
-			Domain:       domain,
-			RecordType:   recordType,
-			Nameserver:   nameserver,
-			Timeout:      r.options.Timeout,
-			UseRecursion: r.options.UseRecursion,
+			Domain:        domain,
+			UnicodeDomain: ToUnicode(domain),
+			RecordType:    recordType,
+			Nameserver:    nameserver,
+			Timeout:       r.options.Timeout,
+			UseRecursion:  r.options.UseRecursion,
 		},
 		Timestamp:  start,
 		Nameserver: nameserver,
 	}
 
+	var key string
+	if r.cache != nil {
+		key = cacheKey(domain, recordType, nameserver)
+		if cached, ok := r.cache.get(key); ok {
+			return cached, nil
+		}
+	}
+
 	// Prepare the DNS message
 	msg := new(dns.Msg)
 	msg.SetQuestion(dns.Fqdn(domain), r.getRecordTypeCode(recordType))
@@ -70,64 +112,206 @@ func (r *Resolver) Query(ctx context.Context, domain string, recordType DNSRecor
 		msg.SetEdns0(4096, true)
 	}
 
+	if r.options.ClientSubnet.IsValid() {
+		addClientSubnet(msg, r.options.ClientSubnet)
+		result.Query.ClientSubnet = r.options.ClientSubnet.String()
+	}
+
+	// Perform the query with retries
+	response, timing, err := r.exchangeTimed(ctx, msg, nameserver)
+	result.ResponseTime = time.Since(start)
+	result.ConnectTime = timing.Connect
+	result.QueryTime = timing.RTT
+	result.Attempts = timing.Attempts
+
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Parse the response
+	result.Authoritative = response.Authoritative
+	result.Records = r.parseResponse(response, recordType)
+	if r.cache != nil {
+		r.cache.put(key, result)
+	}
+	return result, nil
+}
+
+// exchangeTiming breaks down the time spent in exchangeTimed
+type exchangeTiming struct {
+	Connect  time.Duration // Dialing the connection used by the successful attempt
+	RTT      time.Duration // Query round trip of the successful attempt
+	Attempts int           // Exchanges sent, including failed retries and any TCP fallback
+}
+
+// exchange sends msg to nameserver (port 53 unless given), retrying per the resolver options
+func (r *Resolver) exchange(ctx context.Context, msg *dns.Msg, nameserver string) (*dns.Msg, error) {
+	response, _, err := r.exchangeTimed(ctx, msg, nameserver)
+	return response, err
+}
+
+// exchangeTimed is exchange, also reporting connect and round-trip time of the successful
+// attempt and how many attempts were made
+func (r *Resolver) exchangeTimed(ctx context.Context, msg *dns.Msg, nameserver string) (*dns.Msg, exchangeTiming, error) {
 	// Ensure nameserver has port
 	if !strings.Contains(nameserver, ":") {
 		nameserver += ":53"
 	}
 
-	// Perform the query with retries
 	var response *dns.Msg
+	var timing exchangeTiming
 	var err error
-	
+
+	transport := r.client.Net
+	if IsDoHEndpoint(nameserver) {
+		transport = "https"
+	}
+
 	for attempt := 0; attempt < r.options.Retries; attempt++ {
-		response, _, err = r.client.ExchangeContext(ctx, msg, nameserver)
+		if err = r.waitForSlot(ctx, nameserver); err != nil {
+			break
+		}
+		timing.Attempts++
+		response, timing.Connect, timing.RTT, err = exchangeOnce(ctx, r.client, msg, nameserver)
+		logExchange(ctx, msg, response, nameserver, transport, attempt+1, timing.RTT, err)
 		if err == nil {
 			break
 		}
 		if attempt < r.options.Retries-1 {
-			time.Sleep(time.Duration(attempt+1) * 500 * time.Millisecond)
+			select {
+			case <-time.After(time.Duration(attempt+1) * 500 * time.Millisecond):
+			case <-ctx.Done():
+				return nil, timing, fmt.Errorf("DNS query failed: %w", ctx.Err())
+			}
 		}
 	}
 
-	result.ResponseTime = time.Since(start)
-
 	if err != nil {
-		result.Error = fmt.Errorf("DNS query failed: %w", err)
-		return result, result.Error
+		return nil, timing, fmt.Errorf("DNS query failed: %w", err)
 	}
 
 	if response == nil {
-		result.Error = fmt.Errorf("received nil response")
-		return result, result.Error
+		return nil, timing, fmt.Errorf("received nil response")
 	}
 
-	// Parse the response
-	result.Records = r.parseResponse(response, recordType)
-	return result, nil
+	// Truncated UDP answers are retried over TCP to get the full RRset
+	if response.Truncated && r.client.Net != "tcp" {
+		tcpClient := *r.client
+		tcpClient.Net = "tcp"
+		if err = r.waitForSlot(ctx, nameserver); err != nil {
+			return nil, timing, fmt.Errorf("TCP retry after truncated UDP response failed: %w", err)
+		}
+		timing.Attempts++
+		response, timing.Connect, timing.RTT, err = exchangeOnce(ctx, &tcpClient, msg, nameserver)
+		logExchange(ctx, msg, response, nameserver, tcpClient.Net, 1, timing.RTT, err)
+		if err != nil {
+			return nil, timing, fmt.Errorf("TCP retry after truncated UDP response failed: %w", err)
+		}
+	}
+
+	return response, timing, nil
+}
+
+// waitForSlot applies the rate limits, if any, and counts the exchange about to be sent
+func (r *Resolver) waitForSlot(ctx context.Context, nameserver string) error {
+	if r.limiter != nil {
+		if err := r.limiter.Wait(ctx, nameserver); err != nil {
+			return err
+		}
+	}
+	if r.qps != nil {
+		// Every query shares one key, so the limit applies to the total
+		if err := r.qps.Wait(ctx, ""); err != nil {
+			return err
+		}
+	}
+	r.queries.Add(1)
+	return nil
+}
+
+// exchangeOnce dials nameserver and sends msg, timing the dial separately from the round trip.
+// It is equivalent to dns.Client.ExchangeContext, except that DoH endpoints are queried over HTTPS.
+func exchangeOnce(ctx context.Context, client *dns.Client, msg *dns.Msg, nameserver string) (*dns.Msg, time.Duration, time.Duration, error) {
+	if IsDoHEndpoint(nameserver) {
+		return exchangeDoH(ctx, client.Timeout, msg, nameserver)
+	}
+
+	start := time.Now()
+	conn, err := client.DialContext(ctx, nameserver)
+	connect := time.Since(start)
+	if err != nil {
+		return nil, connect, 0, err
+	}
+	defer conn.Close()
+
+	response, rtt, err := client.ExchangeWithConnContext(ctx, msg, conn)
+	return response, connect, rtt, err
+}
+
+// logExchange emits a debug event describing one DNS round trip, tagged with ctx's trace ID if any
+func logExchange(ctx context.Context, msg, response *dns.Msg, nameserver, transport string, attempt int, rtt time.Duration, err error) {
+	if transport == "" {
+		transport = "udp"
+	}
+
+	attrs := []any{
+		"question", msg.Question[0].Name,
+		"qtype", dns.TypeToString[msg.Question[0].Qtype],
+		"nameserver", nameserver,
+		"transport", transport,
+		"attempt", attempt,
+		"rtt", rtt,
+	}
+	attrs = append(attrs, traceAttrs(ctx)...)
+	if err != nil {
+		slog.Debug("dns exchange failed", append(attrs, "error", err)...)
+		return
+	}
+	if response != nil {
+		attrs = append(attrs, "rcode", dns.RcodeToString[response.Rcode], "truncated", response.Truncated)
+	}
+	slog.Debug("dns exchange", attrs...)
 }
 
 // QueryMultipleServers queries multiple nameservers for the same domain
 func (r *Resolver) QueryMultipleServers(ctx context.Context, domain string, recordType DNSRecordType, nameservers []string) ([]*DNSResult, error) {
 	results := make([]*DNSResult, len(nameservers))
-	errors := make([]error, len(nameservers))
 
-	// Create a channel to collect results
+	// Workers only send on resultChan; results is written by the collector loop alone
 	type resultWithIndex struct {
 		index  int
 		result *DNSResult
 	}
-	
+
 	resultChan := make(chan resultWithIndex, len(nameservers))
 
-	// Launch goroutines for parallel queries
-	for i, ns := range nameservers {
-		go func(index int, nameserver string) {
-			result, err := r.Query(ctx, domain, recordType, nameserver)
-			if err != nil {
-				errors[index] = err
+	// Queue every nameserver for the worker pool
+	indexChan := make(chan int, len(nameservers))
+	for i := range nameservers {
+		indexChan <- i
+	}
+	close(indexChan)
+
+	workers := r.options.Concurrency
+	if workers <= 0 || workers > len(nameservers) {
+		workers = len(nameservers)
+	}
+
+	// Launch a bounded number of workers for parallel queries
+	for w := 0; w < workers; w++ {
+		go func() {
+			for index := range indexChan {
+				// Drain the queue without querying once the caller has given up
+				if ctx.Err() != nil {
+					resultChan <- resultWithIndex{index: index}
+					continue
+				}
+				// Query records any failure in result.Error, so the returned error is redundant here
+				result, _ := r.queryWithDeadline(ctx, domain, recordType, nameservers[index])
+				resultChan <- resultWithIndex{index: index, result: result}
 			}
-			resultChan <- resultWithIndex{index: index, result: result}
-		}(i, ns)
+		}()
 	}
 
 	// Collect results
@@ -143,6 +327,16 @@ func (r *Resolver) QueryMultipleServers(ctx context.Context, domain string, reco
 	return results, nil
 }
 
+// queryWithDeadline runs Query bounded by the per-server QueryTimeout, if one is set
+func (r *Resolver) queryWithDeadline(ctx context.Context, domain string, recordType DNSRecordType, nameserver string) (*DNSResult, error) {
+	if r.options.QueryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.options.QueryTimeout)
+		defer cancel()
+	}
+	return r.Query(ctx, domain, recordType, nameserver)
+}
+
 // CheckPropagation checks DNS propagation across multiple nameservers
 func (r *Resolver) CheckPropagation(ctx context.Context, domain string, recordType DNSRecordType, nameservers []string) (*PropagationResult, error) {
 	results, err := r.QueryMultipleServers(ctx, domain, recordType, nameservers)
@@ -164,6 +358,9 @@ func (r *Resolver) CheckPropagation(ctx context.Context, domain string, recordTy
 		if result != nil && result.Error == nil && len(result.Records) > 0 {
 			propagation.Results[nameservers[i]] = result.Records
 			propagation.SuccessCount++
+			if result.Authoritative {
+				propagation.Authoritative = append(propagation.Authoritative, nameservers[i])
+			}
 
 			if firstValidResult == nil {
 				firstValidResult = result.Records
@@ -177,11 +374,29 @@ func (r *Resolver) CheckPropagation(ctx context.Context, domain string, recordTy
 	return propagation, nil
 }
 
+// Agreement reports how many of the queried servers returned the most common answer set.
+// Servers that failed or returned no records count against agreement.
+func (p *PropagationResult) Agreement() (agreeing, total int) {
+	counts := make(map[string]int)
+	for _, records := range p.Results {
+		values := make([]string, 0, len(records))
+		for _, record := range records {
+			values = append(values, record.Value)
+		}
+		slices.Sort(values)
+		key := strings.Join(slices.Compact(values), "\n")
+		counts[key]++
+		agreeing = max(agreeing, counts[key])
+	}
+	return agreeing, p.TotalServers
+}
+
 // parseResponse converts DNS response to our record format
 func (r *Resolver) parseResponse(response *dns.Msg, recordType DNSRecordType) []DNSRecord {
 	var records []DNSRecord
 
 	for _, answer := range response.Answer {
+		// Type reflects the answer's own RR type, so a CNAME in an A answer is reported as CNAME
 		record := DNSRecord{
 			Name: answer.Header().Name,
 			Type: recordType,
@@ -190,26 +405,58 @@ func (r *Resolver) parseResponse(response *dns.Msg, recordType DNSRecordType) []
 
 		switch rr := answer.(type) {
 		case *dns.A:
+			record.Type = RecordTypeA
 			record.Value = rr.A.String()
 		case *dns.AAAA:
+			record.Type = RecordTypeAAAA
 			record.Value = rr.AAAA.String()
 		case *dns.CNAME:
+			record.Type = RecordTypeCNAME
 			record.Value = rr.Target
 		case *dns.MX:
+			record.Type = RecordTypeMX
 			record.Value = rr.Mx
 			record.Priority = int(rr.Preference)
 		case *dns.NS:
+			record.Type = RecordTypeNS
 			record.Value = rr.Ns
 		case *dns.TXT:
-			record.Value = strings.Join(rr.Txt, " ")
+			record.Type = RecordTypeTXT
+			// Character-strings are joined without a separator, the way SPF and
+			// DKIM verifiers reassemble long records; Segments keeps the split
+			record.Value = strings.Join(rr.Txt, "")
+			if len(rr.Txt) > 1 {
+				record.Segments = append([]string(nil), rr.Txt...)
+			}
 		case *dns.PTR:
+			record.Type = RecordTypePTR
 			record.Value = rr.Ptr
 		case *dns.SOA:
+			record.Type = RecordTypeSOA
 			record.Value = fmt.Sprintf("%s %s %d %d %d %d %d",
 				rr.Ns, rr.Mbox, rr.Serial, rr.Refresh, rr.Retry, rr.Expire, rr.Minttl)
+			record.SOA = &SOARecord{
+				PrimaryNS:  rr.Ns,
+				Mailbox:    rr.Mbox,
+				Serial:     rr.Serial,
+				Refresh:    rr.Refresh,
+				Retry:      rr.Retry,
+				Expire:     rr.Expire,
+				MinimumTTL: rr.Minttl,
+			}
 		case *dns.SRV:
-			record.Value = rr.Target
+			record.Type = RecordTypeSRV
+			record.Value = fmt.Sprintf("%d %d %d %s", rr.Priority, rr.Weight, rr.Port, rr.Target)
 			record.Priority = int(rr.Priority)
+			record.SRV = &SRVRecord{
+				Priority: rr.Priority,
+				Weight:   rr.Weight,
+				Port:     rr.Port,
+				Target:   rr.Target,
+			}
+		case *dns.CAA:
+			record.Type = RecordTypeCAA
+			record.Value = fmt.Sprintf("%d %s %q", rr.Flag, rr.Tag, rr.Value)
 		default:
 			record.Value = answer.String()
 		}
@@ -241,6 +488,8 @@ func (r *Resolver) getRecordTypeCode(recordType DNSRecordType) uint16 {
 		return dns.TypePTR
 	case RecordTypeSRV:
 		return dns.TypeSRV
+	case RecordTypeCAA:
+		return dns.TypeCAA
 	default:
 		return dns.TypeA
 	}
@@ -280,4 +529,4 @@ func (r *Resolver) checkInconsistency(results map[string][]DNSRecord) bool {
 	}
 
 	return false
-}
\ No newline at end of file
+}
@@ -0,0 +1,152 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// startTestServer runs a UDP DNS server on a free local port until the test ends
+// and returns its address
+func startTestServer(t *testing.T, handler dns.HandlerFunc) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: conn, Handler: handler, NotifyStartedFunc: func() { close(started) }}
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+	return conn.LocalAddr().String()
+}
+
+// answerA replies to every question with a single A record for ip
+func answerA(ip string) dns.HandlerFunc {
+	return func(w dns.ResponseWriter, req *dns.Msg) {
+		reply := new(dns.Msg)
+		reply.SetReply(req)
+		reply.Answer = append(reply.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+			A:   net.ParseIP(ip),
+		})
+		w.WriteMsg(reply)
+	}
+}
+
+func TestQueryMultipleServersCollectsEveryResult(t *testing.T) {
+	const servers = 64
+	const concurrency = 4
+
+	// Each server holds its answer briefly so the workers overlap, and records how
+	// many queries were in flight at once
+	var inFlight, peak atomic.Int32
+	nameservers := make([]string, servers)
+	for i := range nameservers {
+		answer := answerA(fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+		nameservers[i] = startTestServer(t, func(w dns.ResponseWriter, req *dns.Msg) {
+			current := inFlight.Add(1)
+			for {
+				seen := peak.Load()
+				if current <= seen || peak.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+			answer(w, req)
+		})
+	}
+	// A port nothing listens on, so one query fails among the answers
+	closed, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	nameservers = append(nameservers, closed.LocalAddr().String())
+	closed.Close()
+
+	opts := DefaultQueryOptions()
+	opts.Timeout = time.Second
+	opts.Retries = 1
+	opts.Concurrency = concurrency
+	resolver := NewResolverWithOptions(opts)
+
+	results, err := resolver.QueryMultipleServers(context.Background(), "example.com", RecordTypeA, nameservers)
+	if err != nil {
+		t.Fatalf("QueryMultipleServers: %v", err)
+	}
+	if len(results) != len(nameservers) {
+		t.Fatalf("got %d results, want %d", len(results), len(nameservers))
+	}
+
+	for i, result := range results[:servers] {
+		if result == nil {
+			t.Fatalf("result %d is missing", i)
+		}
+		if result.Error != nil {
+			t.Errorf("result %d: unexpected error %v", i, result.Error)
+			continue
+		}
+		if result.Nameserver != nameservers[i] {
+			t.Errorf("result %d came from %s, want %s", i, result.Nameserver, nameservers[i])
+		}
+		want := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		if len(result.Records) != 1 || result.Records[0].Value != want {
+			t.Errorf("result %d: got records %+v, want a single A %s", i, result.Records, want)
+		}
+	}
+
+	if failed := results[servers]; failed == nil || failed.Error == nil {
+		t.Errorf("query to a closed port should have failed, got %+v", failed)
+	}
+	if got := peak.Load(); got > concurrency {
+		t.Errorf("%d queries were in flight at once, want at most %d", got, concurrency)
+	}
+}
+
+func TestParseResponseJoinsTXTSegments(t *testing.T) {
+	// A DKIM key split across character-strings mid-token, as long records are published
+	segments := []string{"v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC1", "TkRVZuDv1oBhVt6Ny2Rmi1zEyqoD", "AQAB"}
+
+	response := new(dns.Msg)
+	response.Answer = []dns.RR{
+		&dns.TXT{
+			Hdr: dns.RR_Header{Name: "selector._domainkey.example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 3600},
+			Txt: segments,
+		},
+		&dns.TXT{
+			Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 3600},
+			Txt: []string{"v=spf1 -all"},
+		},
+	}
+
+	records := NewResolver().parseResponse(response, RecordTypeTXT)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	dkim := records[0]
+	if want := segments[0] + segments[1] + segments[2]; dkim.Value != want {
+		t.Errorf("Value = %q, want the segments joined without a separator %q", dkim.Value, want)
+	}
+	if len(dkim.Segments) != len(segments) {
+		t.Fatalf("Segments = %q, want %q", dkim.Segments, segments)
+	}
+	for i := range segments {
+		if dkim.Segments[i] != segments[i] {
+			t.Errorf("Segments[%d] = %q, want %q", i, dkim.Segments[i], segments[i])
+		}
+	}
+
+	// A single-string record has nothing to preserve
+	if spf := records[1]; spf.Value != "v=spf1 -all" || spf.Segments != nil {
+		t.Errorf("single-segment record = %q with segments %q, want %q and none", spf.Value, spf.Segments, "v=spf1 -all")
+	}
+}
@@ -0,0 +1,116 @@
+// =============================================================================
+// internal/dns/retry.go - Re-running the failed domains of a previous bulk run
+// =============================================================================
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadBulkSummary reads a summary written by a bulk command with --format json. It must
+// come from the same kind of bulk run as operation; summaries from older versions that
+// don't record their operation are accepted as is.
+func LoadBulkSummary(filename string, operation BulkOperation) (*BulkSummary, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary: %w", err)
+	}
+
+	var summary BulkSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse summary %s (expected --format json output): %w", filename, err)
+	}
+
+	if summary.Operation != "" && summary.Operation != operation {
+		return nil, fmt.Errorf("summary %s is from a bulk %s run, not %s", filename, summary.Operation, operation)
+	}
+	if len(summary.Results) == 0 && summary.TotalDomains > 0 {
+		return nil, fmt.Errorf("summary %s has no per-domain results (was it written with --stream?)", filename)
+	}
+
+	return &summary, nil
+}
+
+// FailedTargets returns the targets whose results failed, in result order
+func (s *BulkSummary) FailedTargets() []BulkTarget {
+	var targets []BulkTarget
+	for _, result := range s.Results {
+		if !result.Success {
+			targets = append(targets, result.target())
+		}
+	}
+	return targets
+}
+
+// MergeRetry combines previous with retry, a run over previous.FailedTargets(). Each retried
+// target's newer result replaces the old one in place and is marked Retried; counts, including
+// per record type, duration and query totals cover both passes.
+func MergeRetry(previous, retry *BulkSummary) *BulkSummary {
+	retried := make(map[string]BulkResult, len(retry.Results))
+	for _, result := range retry.Results {
+		result.Retried = true
+		retried[result.target().key()] = result
+	}
+
+	merged := &BulkSummary{
+		Operation:   previous.Operation,
+		RecordTypes: previous.RecordTypes,
+		Nameservers: previous.Nameservers,
+		Duration:    previous.Duration + retry.Duration,
+		Duplicates:  previous.Duplicates,
+		Skipped:     previous.Skipped,
+		Queries:     previous.Queries + retry.Queries,
+		Concurrency: retry.Concurrency,
+		TimedOut:    retry.TimedOut, // Every earlier timeout was a failure and so was retried
+		AutoRetried: previous.AutoRetried + retry.AutoRetried,
+		Recovered:   previous.Recovered + retry.Recovered,
+		Results:     make([]BulkResult, 0, len(previous.Results)),
+	}
+
+	for _, result := range previous.Results {
+		key := result.target().key()
+		if newer, ok := retried[key]; ok {
+			result = newer
+			delete(retried, key)
+		}
+		merged.Results = append(merged.Results, result)
+	}
+
+	// Keep retry results for targets the previous summary didn't list, in retry order
+	for _, result := range retry.Results {
+		if _, ok := retried[result.target().key()]; ok {
+			result.Retried = true
+			merged.Results = append(merged.Results, result)
+		}
+	}
+
+	// Every retried target was a failure in previous, so adjust its counts rather than
+	// recounting Results, which may have been narrowed by a filter
+	merged.TotalDomains = previous.TotalDomains
+	merged.Successful = previous.Successful + retry.Successful
+	merged.Failed = previous.Failed - retry.TotalDomains + retry.Failed
+	if previous.ByRecordType != nil {
+		merged.ByRecordType = make(map[DNSRecordType]BulkTypeCount, len(previous.ByRecordType))
+		for recordType, count := range previous.ByRecordType {
+			merged.ByRecordType[recordType] = count
+		}
+		for _, result := range retry.Results {
+			count := merged.ByRecordType[result.RecordType]
+			count.Failed--
+			if result.Success {
+				count.Successful++
+			} else {
+				count.Failed++
+			}
+			merged.ByRecordType[result.RecordType] = count
+		}
+	}
+	merged.Stats = computeBulkStats(merged.Results)
+	if merged.Duration > 0 {
+		merged.QPS = float64(merged.Queries) / merged.Duration.Seconds()
+	}
+
+	return merged
+}
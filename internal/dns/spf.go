@@ -0,0 +1,135 @@
+// =============================================================================
+// internal/dns/spf.go - Recursive SPF evaluation and DNS lookup counting
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	maxSPFLookups = 10 // RFC 7208 section 4.6.4
+	maxSPFDepth   = 10 // Include/redirect nesting followed before giving up
+)
+
+// spfEvaluation summarises an SPF record together with everything it includes
+type spfEvaluation struct {
+	Lookups   int      // DNS-querying terms across the whole include tree
+	Loops     []string // Include chains that lead back to a domain already being evaluated
+	Missing   []string // include/redirect targets that publish no SPF record
+	Truncated bool     // Nesting exceeded maxSPFDepth, so Lookups is a lower bound
+}
+
+// evaluateSPF walks record and every include:/redirect= it references, counting the terms
+// that cost a DNS lookup under RFC 7208's 10-lookup limit
+func (r *Resolver) evaluateSPF(ctx context.Context, domain, record, nameserver string) *spfEvaluation {
+	eval := &spfEvaluation{}
+	r.walkSPF(ctx, record, nameserver, []string{normalizeSPFDomain(domain)}, eval)
+	return eval
+}
+
+func (r *Resolver) walkSPF(ctx context.Context, record, nameserver string, path []string, eval *spfEvaluation) {
+	_, hasAll := spfAllQualifier(record)
+
+	for _, term := range strings.Fields(record)[1:] {
+		name, target := spfTerm(term)
+		switch name {
+		case "a", "mx", "ptr", "exists":
+			eval.Lookups++
+		case "include", "redirect":
+			// redirect= is ignored when the record has an all mechanism
+			if name == "redirect" && hasAll {
+				continue
+			}
+			eval.Lookups++
+			r.followSPF(ctx, target, nameserver, path, eval)
+		}
+	}
+}
+
+// followSPF evaluates the SPF record of an include/redirect target
+func (r *Resolver) followSPF(ctx context.Context, target, nameserver string, path []string, eval *spfEvaluation) {
+	// Macro-expanded targets depend on the sender and cannot be followed statically
+	if target == "" || strings.Contains(target, "%") {
+		return
+	}
+
+	target = normalizeSPFDomain(target)
+	for _, visited := range path {
+		if visited == target {
+			eval.Loops = append(eval.Loops, strings.Join(append(path, target), " -> "))
+			return
+		}
+	}
+
+	if len(path) > maxSPFDepth {
+		eval.Truncated = true
+		return
+	}
+
+	record, found, err := r.lookupSPF(ctx, target, nameserver)
+	if err != nil {
+		return
+	}
+	if !found {
+		eval.Missing = append(eval.Missing, target)
+		return
+	}
+
+	r.walkSPF(ctx, record, nameserver, append(path[:len(path):len(path)], target), eval)
+}
+
+// lookupSPF returns the SPF record published at domain, if any
+func (r *Resolver) lookupSPF(ctx context.Context, domain, nameserver string) (string, bool, error) {
+	records, err := r.lookupTXT(ctx, domain, nameserver)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, value := range records {
+		if value == "v=spf1" || strings.HasPrefix(strings.ToLower(value), "v=spf1 ") {
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// spfTerm splits an SPF term into its lowercased mechanism/modifier name and domain argument,
+// dropping any qualifier and CIDR length
+func spfTerm(term string) (string, string) {
+	term = strings.TrimLeft(strings.ToLower(term), "+-~?")
+
+	end := strings.IndexAny(term, ":=/")
+	if end < 0 {
+		return term, ""
+	}
+	name := term[:end]
+	if term[end] == '/' {
+		return name, ""
+	}
+
+	target := term[end+1:]
+	if slash := strings.Index(target, "/"); slash >= 0 && name != "redirect" && name != "exists" {
+		target = target[:slash]
+	}
+	return name, target
+}
+
+// spfAllQualifier returns the qualifier of the record's all mechanism ("+" when omitted)
+func spfAllQualifier(record string) (string, bool) {
+	for _, term := range strings.Fields(strings.ToLower(record)) {
+		qualifier := "+"
+		if strings.ContainsAny(term[:1], "+-~?") {
+			qualifier, term = term[:1], term[1:]
+		}
+		if term == "all" {
+			return qualifier, true
+		}
+	}
+	return "", false
+}
+
+func normalizeSPFDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
+}
@@ -0,0 +1,121 @@
+// =============================================================================
+// internal/dns/srv.go - SRV service resolution with RFC 2782 target selection
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// SRVResult holds the targets of a service in the order clients should try them
+type SRVResult struct {
+	Service     string      `json:"service"` // Service label, such as _sip
+	Proto       string      `json:"proto"`   // Protocol label, such as _tcp
+	Domain      string      `json:"domain"`
+	Name        string      `json:"name"` // The queried _service._proto.domain name
+	Nameserver  string      `json:"nameserver"`
+	Targets     []SRVRecord `json:"targets"`               // In RFC 2782 selection order
+	Unavailable bool        `json:"unavailable,omitempty"` // A lone "." target: the service is deliberately not offered
+	Timestamp   time.Time   `json:"timestamp"`
+}
+
+// ResolveSRV queries the SRV records of _service._proto.domain and orders their targets
+// the way RFC 2782 clients pick them: lowest priority first, and within a priority a
+// weighted random order, so a target with twice the weight is twice as likely to come
+// first. Service and proto may be given with or without their leading underscore. A name
+// without SRV records yields no targets rather than an error.
+func (r *Resolver) ResolveSRV(ctx context.Context, service, proto, domain, nameserver string) (*SRVResult, error) {
+	service = "_" + strings.TrimPrefix(strings.TrimSpace(service), "_")
+	proto = "_" + strings.TrimPrefix(strings.TrimSpace(proto), "_")
+	if service == "_" || proto == "_" {
+		return nil, fmt.Errorf("service and protocol must not be empty")
+	}
+
+	result := &SRVResult{
+		Service:    strings.ToLower(service),
+		Proto:      strings.ToLower(proto),
+		Domain:     domain,
+		Name:       fmt.Sprintf("%s.%s.%s", service, proto, strings.TrimSuffix(domain, ".")),
+		Nameserver: nameserver,
+		Targets:    []SRVRecord{},
+		Timestamp:  time.Now(),
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(result.Name), dns.TypeSRV)
+	msg.RecursionDesired = true
+
+	response, err := r.exchange(ctx, msg, nameserver)
+	if err != nil {
+		return nil, err
+	}
+	if response.Rcode != dns.RcodeSuccess && response.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("SRV lookup for %s failed: %s", result.Name, dns.RcodeToString[response.Rcode])
+	}
+
+	var records []SRVRecord
+	for _, answer := range response.Answer {
+		if srv, ok := answer.(*dns.SRV); ok {
+			records = append(records, SRVRecord{Priority: srv.Priority, Weight: srv.Weight, Port: srv.Port, Target: srv.Target})
+		}
+	}
+
+	// RFC 2782: a single record with target "." means the service is decidedly not available
+	if len(records) == 1 && records[0].Target == "." {
+		result.Unavailable = true
+		return result, nil
+	}
+	result.Targets = orderSRV(records)
+	return result, nil
+}
+
+// orderSRV returns records sorted by priority, each priority's records in the weighted
+// random order of RFC 2782: zero-weight records go first so they keep a small chance of
+// being picked, then records are drawn one at a time with probability proportional to
+// their weight among those left
+func orderSRV(records []SRVRecord) []SRVRecord {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Priority != records[j].Priority {
+			return records[i].Priority < records[j].Priority
+		}
+		return records[i].Weight == 0 && records[j].Weight != 0
+	})
+
+	ordered := make([]SRVRecord, 0, len(records))
+	for start := 0; start < len(records); {
+		end := start
+		for end < len(records) && records[end].Priority == records[start].Priority {
+			end++
+		}
+
+		group := records[start:end]
+		for len(group) > 0 {
+			total := 0
+			for _, record := range group {
+				total += int(record.Weight)
+			}
+
+			pick := rand.IntN(total + 1)
+			chosen, sum := len(group)-1, 0
+			for i, record := range group {
+				sum += int(record.Weight)
+				if sum >= pick {
+					chosen = i
+					break
+				}
+			}
+
+			ordered = append(ordered, group[chosen])
+			group = append(group[:chosen], group[chosen+1:]...)
+		}
+		start = end
+	}
+	return ordered
+}
@@ -0,0 +1,167 @@
+// =============================================================================
+// internal/dns/stats.go - Aggregated statistics for bulk summaries
+// =============================================================================
+package dns
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// slowestShown is how many of the slowest targets BulkStats lists
+const slowestShown = 10
+
+// BulkStats aggregates a bulk run beyond its success and failure counts
+type BulkStats struct {
+	P50              time.Duration  `json:"p50"` // Response-time percentiles over every result
+	P95              time.Duration  `json:"p95"`
+	P99              time.Duration  `json:"p99"`
+	Slowest          []SlowTarget   `json:"slowest,omitempty"`            // Slowest first
+	Failures         []FailureGroup `json:"failures,omitempty"`           // Largest group first
+	IssuesByType     map[string]int `json:"issues_by_type,omitempty"`     // Consistency runs only
+	IssuesBySeverity map[string]int `json:"issues_by_severity,omitempty"` // Consistency runs only
+}
+
+// SlowTarget is one of the slowest targets of a bulk run
+type SlowTarget struct {
+	Domain     string        `json:"domain"`
+	RecordType DNSRecordType `json:"record_type,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// FailureGroup counts failures that share a reason, see FailureReason
+type FailureGroup struct {
+	Reason  string `json:"reason"`
+	Count   int    `json:"count"`
+	Example string `json:"example"` // One full error message from the group
+}
+
+// failureReasons maps substrings of error messages to the bucket they are counted under;
+// the first match wins, so the more specific entries come first
+var failureReasons = []struct{ substring, reason string }{
+	{"timed out after", "per-domain timeout"},
+	{"i/o timeout", "timeout"},
+	{"deadline exceeded", "timeout"},
+	{"timeout", "timeout"},
+	{"nxdomain", "NXDOMAIN"},
+	{"no such host", "NXDOMAIN"},
+	{"servfail", "SERVFAIL"},
+	{"no ptr record", "no PTR record"},
+	{"connection refused", "connection refused"},
+	{"network is unreachable", "network unreachable"},
+	{"no route to host", "network unreachable"},
+	{"context canceled", "canceled"},
+}
+
+// FailureReason reduces an error message to a stable bucket such as "timeout" or "NXDOMAIN",
+// so failures differing only in addresses or durations are counted together. Messages
+// matching no known reason are kept as they are.
+func FailureReason(message string) string {
+	lower := strings.ToLower(message)
+	for _, known := range failureReasons {
+		if strings.Contains(lower, known.substring) {
+			return known.reason
+		}
+	}
+	return message
+}
+
+// bulkStatsCollector accumulates BulkStats one result at a time, so streamed runs that
+// keep no results still get statistics
+type bulkStatsCollector struct {
+	durations   []time.Duration
+	slowest     []SlowTarget
+	failures    map[string]*FailureGroup
+	byType      map[string]int
+	bySeverity  map[string]int
+	consistency bool
+}
+
+func newBulkStatsCollector() *bulkStatsCollector {
+	return &bulkStatsCollector{
+		failures:   make(map[string]*FailureGroup),
+		byType:     make(map[string]int),
+		bySeverity: make(map[string]int),
+	}
+}
+
+func (c *bulkStatsCollector) add(result BulkResult) {
+	duration := result.EndTime.Sub(result.StartTime)
+	c.durations = append(c.durations, duration)
+
+	// Keep only the slowest few, in descending order
+	c.slowest = append(c.slowest, SlowTarget{Domain: result.Domain, RecordType: result.RecordType, Duration: duration})
+	sort.SliceStable(c.slowest, func(i, j int) bool { return c.slowest[i].Duration > c.slowest[j].Duration })
+	if len(c.slowest) > slowestShown {
+		c.slowest = c.slowest[:slowestShown]
+	}
+
+	if !result.Success {
+		message := errorString(result.Error)
+		if message == "" {
+			message = "unknown error"
+		}
+		reason := FailureReason(message)
+		if group, ok := c.failures[reason]; ok {
+			group.Count++
+		} else {
+			c.failures[reason] = &FailureGroup{Reason: reason, Count: 1, Example: message}
+		}
+	}
+
+	if result.Consistency != nil {
+		c.consistency = true
+		for _, issue := range result.Consistency.Issues {
+			c.byType[issue.Type]++
+			c.bySeverity[issue.Severity]++
+		}
+	}
+}
+
+func (c *bulkStatsCollector) stats() *BulkStats {
+	if len(c.durations) == 0 {
+		return nil
+	}
+
+	sorted := append([]time.Duration(nil), c.durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	stats := &BulkStats{
+		P50:     percentile(sorted, 50),
+		P95:     percentile(sorted, 95),
+		P99:     percentile(sorted, 99),
+		Slowest: c.slowest,
+	}
+
+	for _, group := range c.failures {
+		stats.Failures = append(stats.Failures, *group)
+	}
+	sort.Slice(stats.Failures, func(i, j int) bool {
+		if stats.Failures[i].Count != stats.Failures[j].Count {
+			return stats.Failures[i].Count > stats.Failures[j].Count
+		}
+		return stats.Failures[i].Reason < stats.Failures[j].Reason
+	})
+
+	if c.consistency {
+		stats.IssuesByType = c.byType
+		stats.IssuesBySeverity = c.bySeverity
+	}
+	return stats
+}
+
+// computeBulkStats aggregates statistics over a complete set of results
+func computeBulkStats(results []BulkResult) *BulkStats {
+	collector := newBulkStatsCollector()
+	for _, result := range results {
+		collector.add(result)
+	}
+	return collector.stats()
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
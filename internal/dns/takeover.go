@@ -0,0 +1,100 @@
+// =============================================================================
+// internal/dns/takeover.go - Dangling CNAME and subdomain takeover detection
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// TakeoverFingerprint identifies a hosting service whose resources can be claimed by anyone
+// once deprovisioned, leaving CNAMEs that point at them open to takeover
+type TakeoverFingerprint struct {
+	Suffix   string // Domain suffix of the service, e.g. "azurewebsites.net"
+	Provider string // Human-readable service name
+}
+
+// TakeoverFingerprints lists takeover-prone service suffixes; extend with RegisterTakeoverFingerprint
+var TakeoverFingerprints = []TakeoverFingerprint{
+	{Suffix: "s3.amazonaws.com", Provider: "AWS S3"},
+	{Suffix: "cloudfront.net", Provider: "AWS CloudFront"},
+	{Suffix: "elasticbeanstalk.com", Provider: "AWS Elastic Beanstalk"},
+	{Suffix: "azurewebsites.net", Provider: "Azure App Service"},
+	{Suffix: "cloudapp.net", Provider: "Azure Cloud Services"},
+	{Suffix: "cloudapp.azure.com", Provider: "Azure Virtual Machines"},
+	{Suffix: "trafficmanager.net", Provider: "Azure Traffic Manager"},
+	{Suffix: "blob.core.windows.net", Provider: "Azure Blob Storage"},
+	{Suffix: "azureedge.net", Provider: "Azure CDN"},
+	{Suffix: "github.io", Provider: "GitHub Pages"},
+	{Suffix: "herokuapp.com", Provider: "Heroku"},
+	{Suffix: "herokudns.com", Provider: "Heroku"},
+	{Suffix: "netlify.app", Provider: "Netlify"},
+	{Suffix: "pantheonsite.io", Provider: "Pantheon"},
+	{Suffix: "myshopify.com", Provider: "Shopify"},
+	{Suffix: "ghost.io", Provider: "Ghost"},
+	{Suffix: "surge.sh", Provider: "Surge"},
+	{Suffix: "bitbucket.io", Provider: "Bitbucket"},
+	{Suffix: "zendesk.com", Provider: "Zendesk"},
+	{Suffix: "readthedocs.io", Provider: "Read the Docs"},
+}
+
+// RegisterTakeoverFingerprint adds a service suffix to the takeover table
+func RegisterTakeoverFingerprint(suffix, provider string) {
+	TakeoverFingerprints = append(TakeoverFingerprints, TakeoverFingerprint{
+		Suffix:   strings.Trim(strings.ToLower(suffix), "."),
+		Provider: provider,
+	})
+}
+
+// MatchTakeoverFingerprint returns the fingerprint whose suffix covers target, if any
+func MatchTakeoverFingerprint(target string) (TakeoverFingerprint, bool) {
+	host := strings.TrimSuffix(strings.ToLower(target), ".")
+	for _, fingerprint := range TakeoverFingerprints {
+		if host == fingerprint.Suffix || strings.HasSuffix(host, "."+fingerprint.Suffix) {
+			return fingerprint, true
+		}
+	}
+	return TakeoverFingerprint{}, false
+}
+
+// cnameLink is one hop of a CNAME chain
+type cnameLink struct {
+	Owner  string
+	Target string
+}
+
+// lookupCNAMEChain returns the CNAME hops encountered when resolving name
+func (r *Resolver) lookupCNAMEChain(ctx context.Context, name, nameserver string) ([]cnameLink, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), dns.TypeA)
+	msg.RecursionDesired = true
+
+	response, err := r.exchange(ctx, msg, nameserver)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []cnameLink
+	for _, answer := range response.Answer {
+		if cname, ok := answer.(*dns.CNAME); ok {
+			chain = append(chain, cnameLink{Owner: cname.Hdr.Name, Target: cname.Target})
+		}
+	}
+	return chain, nil
+}
+
+// isNXDomain reports whether name does not exist according to nameserver
+func (r *Resolver) isNXDomain(ctx context.Context, name, nameserver string) (bool, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), dns.TypeA)
+	msg.RecursionDesired = true
+
+	response, err := r.exchange(ctx, msg, nameserver)
+	if err != nil {
+		return false, err
+	}
+	return response.Rcode == dns.RcodeNameError, nil
+}
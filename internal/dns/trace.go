@@ -0,0 +1,51 @@
+// =============================================================================
+// internal/dns/trace.go - Correlation IDs for tracing operations through logs
+// =============================================================================
+package dns
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// TraceIDAuto asks for plain generated trace IDs, without a prefix
+const TraceIDAuto = "auto"
+
+// traceIDKey is the context key holding an operation's trace ID
+type traceIDKey struct{}
+
+// WithTraceID returns a copy of ctx carrying id; debug log lines for DNS
+// exchanges made under it include the ID as trace_id
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceID returns the trace ID carried by ctx, or "" when there is none
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// NewTraceID generates a random (version 4) UUID, as prefix-<uuid> when a
+// prefix other than TraceIDAuto is given
+func NewTraceID(prefix string) string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+
+	id := fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	if prefix == "" || prefix == TraceIDAuto {
+		return id
+	}
+	return prefix + "-" + id
+}
+
+// traceAttrs returns the slog attributes identifying ctx's operation, if it has an ID
+func traceAttrs(ctx context.Context) []any {
+	if id := TraceID(ctx); id != "" {
+		return []any{"trace_id", id}
+	}
+	return nil
+}
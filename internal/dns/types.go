@@ -4,7 +4,11 @@
 package dns
 
 import (
+	"encoding/json"
+	"fmt"
 	"net"
+	"net/netip"
+	"strings"
 	"time"
 )
 
@@ -21,8 +25,45 @@ const (
 	RecordTypeSOA   DNSRecordType = "SOA"
 	RecordTypePTR   DNSRecordType = "PTR"
 	RecordTypeSRV   DNSRecordType = "SRV"
+	RecordTypeCAA   DNSRecordType = "CAA"
 )
 
+// ParseRecordType validates a record type name, case-insensitively
+func ParseRecordType(name string) (DNSRecordType, error) {
+	recordType := DNSRecordType(strings.ToUpper(strings.TrimSpace(name)))
+	switch recordType {
+	case RecordTypeA, RecordTypeAAAA, RecordTypeCNAME, RecordTypeMX, RecordTypeNS,
+		RecordTypeTXT, RecordTypeSOA, RecordTypePTR, RecordTypeSRV, RecordTypeCAA:
+		return recordType, nil
+	default:
+		return "", fmt.Errorf("unsupported record type %q", name)
+	}
+}
+
+// ParseRecordTypes parses a comma-separated list of record types such as "A,AAAA,MX",
+// dropping repeats while keeping the given order
+func ParseRecordTypes(list string) ([]DNSRecordType, error) {
+	var recordTypes []DNSRecordType
+	seen := make(map[DNSRecordType]bool)
+	for _, name := range strings.Split(list, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		recordType, err := ParseRecordType(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[recordType] {
+			seen[recordType] = true
+			recordTypes = append(recordTypes, recordType)
+		}
+	}
+	if len(recordTypes) == 0 {
+		return nil, fmt.Errorf("no record types in %q", list)
+	}
+	return recordTypes, nil
+}
+
 // DNSRecord represents a single DNS record
 type DNSRecord struct {
 	Name     string        `json:"name"`
@@ -30,58 +71,241 @@ type DNSRecord struct {
 	Value    string        `json:"value"`
 	TTL      uint32        `json:"ttl"`
 	Priority int           `json:"priority,omitempty"` // For MX, SRV records
+	SOA      *SOARecord    `json:"soa,omitempty"`      // Structured fields for SOA records
+	SRV      *SRVRecord    `json:"srv,omitempty"`      // Structured fields for SRV records
+	Segments []string      `json:"segments,omitempty"` // TXT character-strings as published, when there are several
+}
+
+// SOARecord holds the fields of an SOA record
+type SOARecord struct {
+	PrimaryNS  string `json:"primary_ns"`
+	Mailbox    string `json:"mailbox"`
+	Serial     uint32 `json:"serial"`
+	Refresh    uint32 `json:"refresh"`
+	Retry      uint32 `json:"retry"`
+	Expire     uint32 `json:"expire"`
+	MinimumTTL uint32 `json:"minimum_ttl"`
+}
+
+// SRVRecord holds the fields of an SRV record
+type SRVRecord struct {
+	Priority uint16 `json:"priority"`
+	Weight   uint16 `json:"weight"`
+	Port     uint16 `json:"port"`
+	Target   string `json:"target"`
 }
 
 // DNSQuery represents a DNS query to be performed
 type DNSQuery struct {
-	Domain      string          `json:"domain"`
-	RecordType  DNSRecordType   `json:"record_type"`
-	Nameserver  string          `json:"nameserver"`
-	Timeout     time.Duration   `json:"timeout"`
-	UseRecursion bool           `json:"use_recursion"`
+	Domain        string        `json:"domain"`
+	UnicodeDomain string        `json:"unicode_domain,omitempty"` // Unicode form of a punycode (IDN) domain
+	RecordType    DNSRecordType `json:"record_type"`
+	Nameserver    string        `json:"nameserver"`
+	Timeout       time.Duration `json:"timeout"`
+	UseRecursion  bool          `json:"use_recursion"`
+	ClientSubnet  string        `json:"client_subnet,omitempty"` // EDNS Client Subnet sent with the query
 }
 
 // DNSResult represents the result of a DNS query
 type DNSResult struct {
-	Query       DNSQuery      `json:"query"`
-	Records     []DNSRecord   `json:"records"`
-	ResponseTime time.Duration `json:"response_time"`
-	Error       error         `json:"error,omitempty"`
-	Timestamp   time.Time     `json:"timestamp"`
-	Nameserver  string        `json:"nameserver"`
+	Query         DNSQuery      `json:"query"`
+	Records       []DNSRecord   `json:"records"`
+	ResponseTime  time.Duration `json:"response_time"` // Total, including retries and backoff
+	ConnectTime   time.Duration `json:"connect_time"`  // Dial time of the successful attempt
+	QueryTime     time.Duration `json:"query_time"`    // Round trip of the successful attempt
+	Attempts      int           `json:"attempts"`      // Exchanges sent, including retries and TCP fallback
+	Error         error         `json:"error,omitempty"`
+	Timestamp     time.Time     `json:"timestamp"`
+	Nameserver    string        `json:"nameserver"`
+	Authoritative bool          `json:"authoritative"`    // Answer carried the AA bit
+	Cached        bool          `json:"cached,omitempty"` // Served from the resolver's cache, see Resolver.EnableCache
+}
+
+// MarshalJSON encodes Error as its message, omitting it when the query succeeded
+func (r DNSResult) MarshalJSON() ([]byte, error) {
+	type plain DNSResult
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain(r), errorString(r.Error)})
+}
+
+// errorString returns err's message, or "" for a nil error
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
 }
 
 // PropagationResult represents DNS propagation check results
 type PropagationResult struct {
-	Domain        string                   `json:"domain"`
-	RecordType    DNSRecordType           `json:"record_type"`
-	Results       map[string][]DNSRecord  `json:"results"` // nameserver -> records
-	Inconsistent  bool                    `json:"inconsistent"`
-	TotalServers  int                     `json:"total_servers"`
-	SuccessCount  int                     `json:"success_count"`
-	Timestamp     time.Time               `json:"timestamp"`
+	Domain        string                 `json:"domain"`
+	RecordType    DNSRecordType          `json:"record_type"`
+	Results       map[string][]DNSRecord `json:"results"` // nameserver -> records
+	Inconsistent  bool                   `json:"inconsistent"`
+	TotalServers  int                    `json:"total_servers"`
+	SuccessCount  int                    `json:"success_count"`
+	Authoritative []string               `json:"authoritative,omitempty"` // nameservers that answered authoritatively
+	Locations     map[string]string      `json:"locations,omitempty"`     // nameserver -> location label, when loaded from a nameserver file
+	Timestamp     time.Time              `json:"timestamp"`
 }
 
 // ConsistencyIssue represents a DNS consistency problem
 type ConsistencyIssue struct {
-	Type        string    `json:"type"`
-	Domain      string    `json:"domain"`
+	Type        string        `json:"type"`
+	Domain      string        `json:"domain"`
 	RecordType  DNSRecordType `json:"record_type"`
-	Description string    `json:"description"`
-	Severity    string    `json:"severity"` // "low", "medium", "high"
-	Servers     []string  `json:"servers"`
-	Expected    string    `json:"expected,omitempty"`
-	Actual      string    `json:"actual,omitempty"`
+	Description string        `json:"description"`
+	Severity    string        `json:"severity"` // "info", "low", "medium", "high"
+	Servers     []string      `json:"servers"`
+	Expected    string        `json:"expected,omitempty"`
+	Actual      string        `json:"actual,omitempty"`
+	Remediation string        `json:"remediation,omitempty"` // Suggested fix, see RemediationFor
+}
+
+// Issue types reported in ConsistencyIssue.Type
+const (
+	// Propagation and record sets
+	IssuePropagationInconsistency = "propagation_inconsistency"
+	IssueDuplicateRecord          = "duplicate_record"
+	IssueCNAMEAtApex              = "cname_at_apex"
+	IssueCNAMEConflict            = "cname_conflict"
+	IssuePrivateIPInPublicDNS     = "private_ip_in_public_dns"
+
+	// MX
+	IssueMXPriorityZero    = "mx_priority_zero"
+	IssueMXIPLiteral       = "mx_ip_literal"
+	IssueMXTargetCNAME     = "mx_target_cname"
+	IssueMXTargetNoAddress = "mx_target_no_address"
+	IssueMXMissingPTR      = "mx_missing_ptr"
+	IssueMXPTRMismatch     = "mx_ptr_mismatch"
+
+	// Reverse DNS
+	IssuePTRMissing         = "ptr_missing"
+	IssuePTRForwardMissing  = "ptr_forward_missing"
+	IssuePTRForwardMismatch = "ptr_forward_mismatch"
+
+	// Nameservers and delegation
+	IssueInsufficientNameservers = "insufficient_nameservers"
+	IssueNameserversNotDiverse   = "nameservers_not_diverse"
+	IssueUnreachableNameserver   = "unreachable_nameserver"
+	IssueLameDelegation          = "lame_delegation"
+	IssueOpenRecursiveNameserver = "open_recursive_nameserver"
+	IssueDelegationMismatch      = "delegation_mismatch"
+
+	// SOA
+	IssueSOASerialMismatch    = "soa_serial_mismatch"
+	IssueSOARefreshOutOfRange = "soa_refresh_out_of_range"
+	IssueSOARetryOutOfRange   = "soa_retry_out_of_range"
+	IssueSOAExpireOutOfRange  = "soa_expire_out_of_range"
+	IssueSOAMinimumOutOfRange = "soa_minimum_out_of_range"
+
+	// TTL
+	IssueTTLTooLow     = "ttl_too_low"
+	IssueTTLTooHigh    = "ttl_too_high"
+	IssueTTLVariance   = "ttl_variance"
+	IssueTTLDivergence = "ttl_divergence"
+
+	// Dangling CNAMEs
+	IssueDanglingCNAME             = "dangling_cname"
+	IssuePossibleSubdomainTakeover = "possible_subdomain_takeover"
+
+	// TXT and SPF
+	IssueTXTRecordTooLong   = "txt_record_too_long"
+	IssueMultipleSPFRecords = "multiple_spf_records"
+	IssueSPFRecordTooLong   = "spf_record_too_long"
+	IssueSPFTooManyLookups  = "spf_too_many_lookups"
+	IssueSPFIncludeLoop     = "spf_include_loop"
+	IssueSPFIncludeMissing  = "spf_include_missing"
+	IssueSPFIncludeTooDeep  = "spf_include_too_deep"
+	IssueSPFPermissiveAll   = "spf_permissive_all"
+	IssueSPFNeutralAll      = "spf_neutral_all"
+
+	// DMARC
+	IssueDMARCMissingPolicy           = "dmarc_missing_policy"
+	IssueDMARCWeakPolicy              = "dmarc_weak_policy"
+	IssueDMARCWeakSubdomainPolicy     = "dmarc_weak_subdomain_policy"
+	IssueDMARCInvalidPct              = "dmarc_invalid_pct"
+	IssueDMARCInvalidRUA              = "dmarc_invalid_rua"
+	IssueDMARCInvalidRUF              = "dmarc_invalid_ruf"
+	IssueDMARCExternalRUAUnauthorized = "dmarc_external_rua_unauthorized"
+	IssueDMARCExternalRUFUnauthorized = "dmarc_external_ruf_unauthorized"
+
+	// DKIM
+	IssueDKIMMissingPublicKey = "dkim_missing_public_key"
+	IssueDKIMRevokedKey       = "dkim_revoked_key"
+	IssueDKIMNoSelectorFound  = "dkim_no_selector_found"
+	IssueDKIMSelectorsFound   = "dkim_selectors_found"
+
+	// Web
+	IssueWWWMissing      = "www_missing"
+	IssueWWWApexMismatch = "www_apex_mismatch"
+
+	// MTA-STS and TLS-RPT
+	IssueMTASTSMissing           = "mta_sts_missing"
+	IssueMTASTSInvalidRecord     = "mta_sts_invalid_record"
+	IssueMTASTSPolicyUnreachable = "mta_sts_policy_unreachable"
+	IssueMTASTSInvalidPolicy     = "mta_sts_invalid_policy"
+	IssueMTASTSMXMismatch        = "mta_sts_mx_mismatch"
+	IssueTLSRPTMissing           = "tls_rpt_missing"
+	IssueTLSRPTInvalid           = "tls_rpt_invalid"
+)
+
+// ConsistencyReport holds the issues found by a consistency run and the checks that produced them
+type ConsistencyReport struct {
+	Domain    string             `json:"domain"`
+	Checks    []string           `json:"checks"` // Checks that ran, in order
+	Issues    []ConsistencyIssue `json:"issues"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// SeverityRank orders issue severities from "info" (0) to "high" (3); unknown values rank -1
+func SeverityRank(severity string) int {
+	switch severity {
+	case "info":
+		return 0
+	case "low":
+		return 1
+	case "medium":
+		return 2
+	case "high":
+		return 3
+	default:
+		return -1
+	}
+}
+
+// FilterBySeverity returns the issues whose severity ranks at or above minSeverity
+func FilterBySeverity(issues []ConsistencyIssue, minSeverity string) []ConsistencyIssue {
+	filtered := make([]ConsistencyIssue, 0, len(issues))
+	for _, issue := range issues {
+		if SeverityRank(issue.Severity) >= SeverityRank(minSeverity) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// HighestSeverity returns the most severe severity among issues, or "" when there are none
+func HighestSeverity(issues []ConsistencyIssue) string {
+	highest := ""
+	for _, issue := range issues {
+		if highest == "" || SeverityRank(issue.Severity) > SeverityRank(highest) {
+			highest = issue.Severity
+		}
+	}
+	return highest
 }
 
 // BulkQueryResult represents results from bulk DNS queries
 type BulkQueryResult struct {
-	TotalQueries    int                    `json:"total_queries"`
+	TotalQueries      int                  `json:"total_queries"`
 	SuccessfulQueries int                  `json:"successful_queries"`
-	FailedQueries   int                    `json:"failed_queries"`
-	Results         map[string]DNSResult   `json:"results"` // domain -> result
-	Duration        time.Duration          `json:"duration"`
-	Timestamp       time.Time              `json:"timestamp"`
+	FailedQueries     int                  `json:"failed_queries"`
+	Results           map[string]DNSResult `json:"results"` // domain -> result
+	Duration          time.Duration        `json:"duration"`
+	Timestamp         time.Time            `json:"timestamp"`
 }
 
 // Nameserver represents a DNS nameserver
@@ -96,12 +320,15 @@ type Nameserver struct {
 
 // QueryOptions represents options for DNS queries
 type QueryOptions struct {
-	Timeout      time.Duration   `json:"timeout"`
-	Retries      int            `json:"retries"`
-	UseRecursion bool           `json:"use_recursion"`
-	CheckDNSSEC  bool           `json:"check_dnssec"`
-	IPv4Only     bool           `json:"ipv4_only"`
-	IPv6Only     bool           `json:"ipv6_only"`
+	Timeout      time.Duration `json:"timeout"`
+	Retries      int           `json:"retries"`
+	UseRecursion bool          `json:"use_recursion"`
+	CheckDNSSEC  bool          `json:"check_dnssec"`
+	IPv4Only     bool          `json:"ipv4_only"`
+	IPv6Only     bool          `json:"ipv6_only"`
+	Concurrency  int           `json:"concurrency"`   // Max parallel queries in QueryMultipleServers and QueryTypes; 0 means all at once
+	QueryTimeout time.Duration `json:"query_timeout"` // Per-server deadline covering all retries; 0 means bounded only by the caller's context
+	ClientSubnet netip.Prefix  `json:"client_subnet"` // EDNS Client Subnet attached to queries; the zero value sends none
 }
 
 // OutputFormat represents different output formats
@@ -112,4 +339,4 @@ const (
 	OutputFormatJSON  OutputFormat = "json"
 	OutputFormatCSV   OutputFormat = "csv"
 	OutputFormatXML   OutputFormat = "xml"
-)
\ No newline at end of file
+)
@@ -0,0 +1,184 @@
+// =============================================================================
+// internal/dnssec/chain.go - Chain of trust validation from the root zone down
+// =============================================================================
+package dnssec
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// ZoneValidation is the validation outcome for one zone in the chain of trust
+type ZoneValidation struct {
+	Zone     string   `json:"zone"`
+	HasDS    bool     `json:"has_ds"`    // DS RRset present in the parent (trust anchor for the root)
+	DSSigned bool     `json:"ds_signed"` // DS RRset carries a valid signature from the parent's keys
+	IsSigned bool     `json:"is_signed"` // Zone publishes DNSKEY records
+	IsValid  bool     `json:"is_valid"`  // DS matches a DNSKEY and the DNSKEY RRset is validly signed
+	KeyTags  []uint16 `json:"key_tags"`
+	Errors   []string `json:"errors"`
+}
+
+// RootTrustAnchors are the DS records of the root zone KSKs published by IANA
+var RootTrustAnchors = []*dns.DS{
+	{
+		Hdr:        dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET},
+		KeyTag:     20326,
+		Algorithm:  dns.RSASHA256,
+		DigestType: dns.SHA256,
+		Digest:     "E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	},
+	{
+		Hdr:        dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET},
+		KeyTag:     38696,
+		Algorithm:  dns.RSASHA256,
+		DigestType: dns.SHA256,
+		Digest:     "683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+	},
+}
+
+// VerifyDNSSECChain performs VerifyDNSSEC and additionally validates every delegation from
+// the root down to the zone containing domain, recording per-zone results in Chain
+func VerifyDNSSECChain(domain string, nameserver string) (*ValidationResult, error) {
+	result, err := VerifyDNSSEC(domain, nameserver)
+	if err != nil {
+		return nil, err
+	}
+
+	client := new(dns.Client)
+	client.Net = "udp"
+
+	zones, err := zoneCuts(client, domain, nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate zone cuts for %s: %w", domain, err)
+	}
+
+	now := time.Now()
+	var parentKeys []*dns.DNSKEY
+	result.ChainValid = true
+
+	for _, zone := range zones {
+		validation := &ZoneValidation{Zone: zone}
+		result.Chain = append(result.Chain, validation)
+
+		// DS set from the parent, or the configured trust anchors for the root
+		var dsSet []*dns.DS
+		if zone == "." {
+			dsSet = RootTrustAnchors
+			validation.DSSigned = true
+		} else {
+			ds, dsSigs, err := queryDS(client, zone, getParentZone(zone), nameserver)
+			if err != nil {
+				validation.Errors = append(validation.Errors, fmt.Sprintf("Error querying DS records: %v", err))
+			}
+			dsSet = ds
+
+			if len(ds) > 0 {
+				rrset := make([]dns.RR, len(ds))
+				for i, rr := range ds {
+					rrset[i] = rr
+				}
+				if err := verifyRRSet(rrset, dsSigs, parentKeys, now); err != nil {
+					validation.Errors = append(validation.Errors, fmt.Sprintf("DS RRset in parent: %v", err))
+				} else {
+					validation.DSSigned = true
+				}
+			}
+		}
+		validation.HasDS = len(dsSet) > 0
+
+		keys, keySigs, err := queryDNSKEY(client, zone, nameserver)
+		if err != nil {
+			validation.Errors = append(validation.Errors, fmt.Sprintf("Error querying DNSKEY records: %v", err))
+		}
+		validation.IsSigned = len(keys) > 0
+		for _, key := range keys {
+			validation.KeyTags = append(validation.KeyTags, key.KeyTag())
+		}
+
+		if !validation.HasDS {
+			validation.Errors = append(validation.Errors, "no DS record in parent zone; delegation is insecure")
+			result.ChainValid = false
+			break
+		}
+
+		valid, errs := validateChainOfTrust(dsSet, keys, keySigs, now)
+		validation.Errors = append(validation.Errors, errs...)
+		validation.IsValid = valid && validation.DSSigned
+
+		if !validation.IsValid {
+			result.ChainValid = false
+			break
+		}
+		parentKeys = keys
+	}
+
+	return result, nil
+}
+
+// zoneCuts returns the apexes of the zones from the root down to the one containing domain
+func zoneCuts(client *dns.Client, domain, nameserver string) ([]string, error) {
+	zones := []string{"."}
+
+	labels := dns.SplitDomainName(domain)
+	for i := len(labels) - 1; i >= 0; i-- {
+		name := dns.Fqdn(strings.Join(labels[i:], "."))
+
+		apex, err := isZoneApex(client, name, nameserver)
+		if err != nil {
+			return nil, err
+		}
+		if apex {
+			zones = append(zones, name)
+		}
+	}
+
+	return zones, nil
+}
+
+// isZoneApex reports whether name owns an SOA record, i.e. starts a zone
+func isZoneApex(client *dns.Client, name, nameserver string) (bool, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(name, dns.TypeSOA)
+
+	r, err := exchange(client, m, nameserver)
+	if err != nil {
+		return false, err
+	}
+
+	for _, ans := range r.Answer {
+		if soa, ok := ans.(*dns.SOA); ok && strings.EqualFold(soa.Hdr.Name, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// verifyRRSet checks that at least one RRSIG over rrset verifies against keys
+func verifyRRSet(rrset []dns.RR, sigs []*dns.RRSIG, keys []*dns.DNSKEY, now time.Time) error {
+	if len(sigs) == 0 {
+		return fmt.Errorf("no RRSIG covering the RRset")
+	}
+
+	var lastErr error
+	for _, sig := range sigs {
+		key := findKey(keys, sig.KeyTag, sig.Algorithm)
+		if key == nil {
+			lastErr = fmt.Errorf("no DNSKEY with key tag %d to verify RRSIG", sig.KeyTag)
+			continue
+		}
+		if !sig.ValidityPeriod(now) {
+			lastErr = fmt.Errorf("RRSIG by key tag %d is outside its validity period", sig.KeyTag)
+			continue
+		}
+		if err := sig.Verify(key, rrset); err != nil {
+			lastErr = fmt.Errorf("RRSIG by key tag %d failed verification: %v", sig.KeyTag, err)
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
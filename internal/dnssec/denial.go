@@ -0,0 +1,206 @@
+// =============================================================================
+// internal/dnssec/denial.go - Authenticated denial of existence (NSEC/NSEC3)
+// =============================================================================
+package dnssec
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// checkDenialOfExistence queries a name that cannot exist under domain and verifies that the
+// NXDOMAIN answer carries a complete, signed NSEC or NSEC3 proof that it doesn't exist
+func checkDenialOfExistence(client *dns.Client, domain, nameserver string, keys []*dns.DNSKEY, result *ValidationResult) {
+	qname := dns.Fqdn(fmt.Sprintf("_systool-nx-%x.%s", rand.Uint64(), strings.TrimSuffix(domain, ".")))
+	result.DenialQueryName = qname
+
+	m := new(dns.Msg)
+	m.SetQuestion(qname, dns.TypeA)
+	m.SetEdns0(4096, true)
+
+	r, err := exchange(client, m, nameserver)
+	if err != nil {
+		result.DenialErrors = append(result.DenialErrors, fmt.Sprintf("Error querying %s: %v", qname, err))
+		return
+	}
+
+	if r.Rcode != dns.RcodeNameError {
+		if r.Rcode == dns.RcodeSuccess && len(r.Answer) > 0 {
+			result.DenialErrors = append(result.DenialErrors, "name was answered by a wildcard; NXDOMAIN handling could not be exercised")
+		} else {
+			result.DenialErrors = append(result.DenialErrors, fmt.Sprintf("expected NXDOMAIN for %s, got %s", qname, dns.RcodeToString[r.Rcode]))
+		}
+		return
+	}
+
+	var nsecs, nsec3s []dns.RR
+	var sigs []*dns.RRSIG
+	for _, rr := range r.Ns {
+		switch rr := rr.(type) {
+		case *dns.NSEC:
+			nsecs = append(nsecs, rr)
+		case *dns.NSEC3:
+			nsec3s = append(nsec3s, rr)
+		case *dns.RRSIG:
+			sigs = append(sigs, rr)
+		}
+	}
+
+	var denial []dns.RR
+	var missing []string
+	switch {
+	case len(nsec3s) > 0:
+		result.DenialType = "NSEC3"
+		denial = nsec3s
+		missing = nsec3NameErrorProof(nsec3s, qname, domain)
+	case len(nsecs) > 0:
+		result.DenialType = "NSEC"
+		denial = nsecs
+		missing = nsecNameErrorProof(nsecs, qname)
+	default:
+		result.DenialErrors = append(result.DenialErrors, "NXDOMAIN response carries no NSEC or NSEC3 records")
+		return
+	}
+	result.DenialErrors = append(result.DenialErrors, missing...)
+
+	// Every denial record must be signed by the zone
+	now := time.Now()
+	signed := true
+	for _, rr := range denial {
+		if err := verifyRRSet([]dns.RR{rr}, sigsFor(sigs, rr), keys, now); err != nil {
+			result.DenialErrors = append(result.DenialErrors, fmt.Sprintf("%s %s: %v", result.DenialType, rr.Header().Name, err))
+			signed = false
+		}
+	}
+
+	result.DenialValidated = len(missing) == 0 && signed
+}
+
+// nsecNameErrorProof checks the NSEC proof of an NXDOMAIN answer (RFC 4035 section 5.4): one
+// record must cover qname and one must cover the wildcard at its closest encloser, showing
+// that neither the name nor a wildcard that would have matched it exists. It returns the
+// missing parts of the proof.
+func nsecNameErrorProof(nsecs []dns.RR, qname string) []string {
+	var covering *dns.NSEC
+	for _, rr := range nsecs {
+		if nsec := rr.(*dns.NSEC); nsecCovers(nsec, qname) {
+			covering = nsec
+			break
+		}
+	}
+	if covering == nil {
+		return []string{fmt.Sprintf("no NSEC record covers %s", qname)}
+	}
+
+	// The closest encloser is the longest ancestor of qname shared with either end of the
+	// covering record
+	shared := max(dns.CompareDomainName(qname, covering.Hdr.Name), dns.CompareDomainName(qname, covering.NextDomain))
+	wildcard := wildcardAt(ancestor(qname, shared))
+	for _, rr := range nsecs {
+		if nsecCovers(rr.(*dns.NSEC), wildcard) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("no NSEC record covers the wildcard %s", wildcard)}
+}
+
+// nsec3NameErrorProof checks the NSEC3 proof of an NXDOMAIN answer (RFC 5155 section 8.4): a
+// record must match the closest encloser, the next closer name must be covered, and so must
+// the wildcard at the closest encloser. It returns the missing parts of the proof.
+func nsec3NameErrorProof(nsec3s []dns.RR, qname, zone string) []string {
+	matches := func(name string) bool {
+		for _, rr := range nsec3s {
+			if rr.(*dns.NSEC3).Match(name) {
+				return true
+			}
+		}
+		return false
+	}
+	// Cover also accepts a name whose hash equals the owner's, which would prove it exists
+	covers := func(name string) bool {
+		for _, rr := range nsec3s {
+			if nsec3 := rr.(*dns.NSEC3); nsec3.Cover(name) && !nsec3.Match(name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// The closest encloser is the nearest ancestor of qname, no higher than the zone apex,
+	// that a record matches; the next closer name is one label longer
+	labels := dns.CountLabel(qname)
+	encloser := ""
+	for shared := labels - 1; shared >= dns.CountLabel(zone); shared-- {
+		if candidate := ancestor(qname, shared); matches(candidate) {
+			encloser = candidate
+			break
+		}
+	}
+	if encloser == "" {
+		return []string{fmt.Sprintf("no NSEC3 record matches a closest encloser of %s", qname)}
+	}
+
+	var missing []string
+	if nextCloser := ancestor(qname, dns.CountLabel(encloser)+1); !covers(nextCloser) {
+		missing = append(missing, fmt.Sprintf("no NSEC3 record covers the next closer name %s", nextCloser))
+	}
+	if wildcard := wildcardAt(encloser); !covers(wildcard) {
+		missing = append(missing, fmt.Sprintf("no NSEC3 record covers the wildcard %s", wildcard))
+	}
+	return missing
+}
+
+// ancestor returns the last labels labels of name, e.g. "example.com." for "a.b.example.com."
+// and 2; zero labels is the root
+func ancestor(name string, labels int) string {
+	if labels <= 0 {
+		return "."
+	}
+	parts := dns.SplitDomainName(name)
+	return dns.Fqdn(strings.Join(parts[len(parts)-labels:], "."))
+}
+
+// wildcardAt returns the wildcard name directly below encloser
+func wildcardAt(encloser string) string {
+	return dns.Fqdn("*." + strings.TrimSuffix(encloser, "."))
+}
+
+// sigsFor returns the RRSIGs covering rr's RRset
+func sigsFor(sigs []*dns.RRSIG, rr dns.RR) []*dns.RRSIG {
+	var matching []*dns.RRSIG
+	for _, sig := range sigs {
+		if sig.TypeCovered == rr.Header().Rrtype && strings.EqualFold(sig.Hdr.Name, rr.Header().Name) {
+			matching = append(matching, sig)
+		}
+	}
+	return matching
+}
+
+// nsecCovers reports whether name falls strictly between the NSEC owner and its next name in
+// canonical order, wrapping around at the end of the zone
+func nsecCovers(nsec *dns.NSEC, name string) bool {
+	owner, next := nsec.Hdr.Name, nsec.NextDomain
+	if canonicalCompare(owner, next) < 0 {
+		return canonicalCompare(owner, name) < 0 && canonicalCompare(name, next) < 0
+	}
+	// Last NSEC in the zone points back to the apex
+	return canonicalCompare(owner, name) < 0
+}
+
+// canonicalCompare orders domain names per RFC 4034 section 6.1: label by label from the
+// right, comparing lowercased label bytes
+func canonicalCompare(a, b string) int {
+	labelsA := dns.SplitDomainName(strings.ToLower(a))
+	labelsB := dns.SplitDomainName(strings.ToLower(b))
+
+	for i, j := len(labelsA)-1, len(labelsB)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if c := strings.Compare(labelsA[i], labelsB[j]); c != 0 {
+			return c
+		}
+	}
+	return len(labelsA) - len(labelsB)
+}
@@ -5,6 +5,7 @@ package dnssec
 
 import (
 	"fmt"
+	"log/slog"
 	"net"
 	"strings"
 	"time"
@@ -14,44 +15,50 @@ import (
 
 // ValidationResult represents the result of DNSSEC validation
 type ValidationResult struct {
-	Domain           string
-	HasDNSSEC        bool
-	IsSigned         bool
-	IsValid          bool
-	ValidationErrors []string
-	DS               *DSRecord
-	DNSKEY           []*DNSKEYRecord
-	RRSIG            []*RRSIGRecord
-	Timestamp        time.Time
+	Domain           string            `json:"domain"`
+	HasDNSSEC        bool              `json:"has_dnssec"`
+	IsSigned         bool              `json:"is_signed"`
+	IsValid          bool              `json:"is_valid"`
+	ValidationErrors []string          `json:"validation_errors"`
+	DS               *DSRecord         `json:"ds"`
+	DNSKEY           []*DNSKEYRecord   `json:"dnskey"`
+	RRSIG            []*RRSIGRecord    `json:"rrsig"`
+	Chain            []*ZoneValidation `json:"chain"` // Per-zone results from the root down; set by VerifyDNSSECChain
+	ChainValid       bool              `json:"chain_valid"`
+	DenialType       string            `json:"denial_type,omitempty"` // "NSEC" or "NSEC3" as seen in the NXDOMAIN proof
+	DenialValidated  bool              `json:"denial_validated"`      // A nonexistent name was answered with a signed, complete NSEC/NSEC3 proof
+	DenialQueryName  string            `json:"denial_query_name,omitempty"`
+	DenialErrors     []string          `json:"denial_errors"`
+	Timestamp        time.Time         `json:"timestamp"`
 }
 
 // DSRecord represents a DS (Delegation Signer) record
 type DSRecord struct {
-	KeyTag     uint16
-	Algorithm  uint8
-	DigestType uint8
-	Digest     string
+	KeyTag     uint16 `json:"key_tag"`
+	Algorithm  uint8  `json:"algorithm"`
+	DigestType uint8  `json:"digest_type"`
+	Digest     string `json:"digest"`
 }
 
 // DNSKEYRecord represents a DNSKEY record
 type DNSKEYRecord struct {
-	Flags     uint16
-	Protocol  uint8
-	Algorithm uint8
-	PublicKey string
+	Flags     uint16 `json:"flags"`
+	Protocol  uint8  `json:"protocol"`
+	Algorithm uint8  `json:"algorithm"`
+	PublicKey string `json:"public_key"`
 }
 
 // RRSIGRecord represents an RRSIG record
 type RRSIGRecord struct {
-	TypeCovered uint16
-	Algorithm   uint8
-	Labels      uint8
-	TTL         uint32
-	Expiration  time.Time
-	Inception   time.Time
-	KeyTag      uint16
-	SignerName  string
-	Signature   string
+	TypeCovered uint16    `json:"type_covered"`
+	Algorithm   uint8     `json:"algorithm"`
+	Labels      uint8     `json:"labels"`
+	TTL         uint32    `json:"ttl"`
+	Expiration  time.Time `json:"expiration"`
+	Inception   time.Time `json:"inception"`
+	KeyTag      uint16    `json:"key_tag"`
+	SignerName  string    `json:"signer_name"`
+	Signature   string    `json:"signature"`
 }
 
 // VerifyDNSSEC performs DNSSEC validation for a domain
@@ -69,26 +76,35 @@ func VerifyDNSSEC(domain string, nameserver string) (*ValidationResult, error) {
 	client.Net = "udp"
 
 	// Check for DS records at parent zone
+	var dsSet []*dns.DS
 	parentZone := getParentZone(domain)
 	if parentZone != "" {
-		dsResult, err := queryDS(client, domain, parentZone, nameserver)
+		dsRecords, _, err := queryDS(client, domain, parentZone, nameserver)
 		if err != nil {
 			result.ValidationErrors = append(result.ValidationErrors,
 				fmt.Sprintf("Error querying DS records: %v", err))
-		} else if dsResult != nil {
+		} else if len(dsRecords) > 0 {
 			result.HasDNSSEC = true
-			result.DS = dsResult
+			result.DS = newDSRecord(dsRecords[0])
+			dsSet = dsRecords
 		}
 	}
 
-	// Query DNSKEY records
-	dnskeyResult, err := queryDNSKEY(client, domain, nameserver)
+	// Query DNSKEY records (and the RRSIGs covering them)
+	keys, keySigs, err := queryDNSKEY(client, domain, nameserver)
 	if err != nil {
 		result.ValidationErrors = append(result.ValidationErrors,
 			fmt.Sprintf("Error querying DNSKEY records: %v", err))
 	} else {
-		result.DNSKEY = dnskeyResult
-		if len(dnskeyResult) > 0 {
+		for _, key := range keys {
+			result.DNSKEY = append(result.DNSKEY, &DNSKEYRecord{
+				Flags:     key.Flags,
+				Protocol:  key.Protocol,
+				Algorithm: key.Algorithm,
+				PublicKey: key.PublicKey,
+			})
+		}
+		if len(keys) > 0 {
 			result.IsSigned = true
 		}
 	}
@@ -102,13 +118,15 @@ func VerifyDNSSEC(domain string, nameserver string) (*ValidationResult, error) {
 		result.RRSIG = rrsigResult
 	}
 
+	// Check authenticated denial of existence for a name that cannot exist
+	if result.IsSigned {
+		checkDenialOfExistence(client, domain, nameserver, keys, result)
+	}
+
 	// Validate chain of trust
 	if result.HasDNSSEC && result.IsSigned {
-		valid, err := validateChainOfTrust(result)
-		if err != nil {
-			result.ValidationErrors = append(result.ValidationErrors,
-				fmt.Sprintf("Chain of trust validation error: %v", err))
-		}
+		valid, errs := validateChainOfTrust(dsSet, keys, keySigs, time.Now())
+		result.ValidationErrors = append(result.ValidationErrors, errs...)
 		result.IsValid = valid
 	}
 
@@ -117,6 +135,46 @@ func VerifyDNSSEC(domain string, nameserver string) (*ValidationResult, error) {
 
 // Helper functions
 
+// exchange sends m over the client's transport, retrying over TCP when the UDP answer comes back
+// truncated; large DNSKEY RRsets and their signatures routinely exceed the UDP payload size
+func exchange(client *dns.Client, m *dns.Msg, nameserver string) (*dns.Msg, error) {
+	address := net.JoinHostPort(nameserver, "53")
+
+	r, rtt, err := client.Exchange(m, address)
+	logExchange(m, r, address, client.Net, rtt, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Truncated && client.Net != "tcp" {
+		tcpClient := *client
+		tcpClient.Net = "tcp"
+		r, rtt, err = tcpClient.Exchange(m, address)
+		logExchange(m, r, address, tcpClient.Net, rtt, err)
+		if err != nil {
+			return nil, fmt.Errorf("TCP retry after truncated UDP response failed: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// logExchange emits a debug event describing one DNS round trip
+func logExchange(m, r *dns.Msg, address, transport string, rtt time.Duration, err error) {
+	attrs := []any{
+		"question", m.Question[0].Name,
+		"qtype", dns.TypeToString[m.Question[0].Qtype],
+		"nameserver", address,
+		"transport", transport,
+		"rtt", rtt,
+	}
+	if err != nil {
+		slog.Debug("dnssec exchange failed", append(attrs, "error", err)...)
+		return
+	}
+	slog.Debug("dnssec exchange", append(attrs, "rcode", dns.RcodeToString[r.Rcode], "truncated", r.Truncated)...)
+}
+
 func getParentZone(domain string) string {
 	parts := dns.SplitDomainName(domain)
 	if len(parts) <= 1 {
@@ -125,53 +183,65 @@ func getParentZone(domain string) string {
 	return dns.Fqdn(strings.Join(parts[1:], "."))
 }
 
-func queryDS(client *dns.Client, domain, parentZone, nameserver string) (*DSRecord, error) {
+func queryDS(client *dns.Client, domain, parentZone, nameserver string) ([]*dns.DS, []*dns.RRSIG, error) {
 	m := new(dns.Msg)
 	m.SetQuestion(dns.Fqdn(domain), dns.TypeDS)
 	m.SetEdns0(4096, true)
 
-	r, _, err := client.Exchange(m, net.JoinHostPort(nameserver, "53"))
+	r, err := exchange(client, m, nameserver)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	var dsSet []*dns.DS
+	var sigs []*dns.RRSIG
 	for _, ans := range r.Answer {
-		if ds, ok := ans.(*dns.DS); ok {
-			return &DSRecord{
-				KeyTag:     ds.KeyTag,
-				Algorithm:  ds.Algorithm,
-				DigestType: ds.DigestType,
-				Digest:     ds.Digest,
-			}, nil
+		switch rr := ans.(type) {
+		case *dns.DS:
+			dsSet = append(dsSet, rr)
+		case *dns.RRSIG:
+			if rr.TypeCovered == dns.TypeDS {
+				sigs = append(sigs, rr)
+			}
 		}
 	}
 
-	return nil, nil
+	return dsSet, sigs, nil
+}
+
+func newDSRecord(ds *dns.DS) *DSRecord {
+	return &DSRecord{
+		KeyTag:     ds.KeyTag,
+		Algorithm:  ds.Algorithm,
+		DigestType: ds.DigestType,
+		Digest:     ds.Digest,
+	}
 }
 
-func queryDNSKEY(client *dns.Client, domain, nameserver string) ([]*DNSKEYRecord, error) {
+func queryDNSKEY(client *dns.Client, domain, nameserver string) ([]*dns.DNSKEY, []*dns.RRSIG, error) {
 	m := new(dns.Msg)
 	m.SetQuestion(dns.Fqdn(domain), dns.TypeDNSKEY)
 	m.SetEdns0(4096, true)
 
-	r, _, err := client.Exchange(m, net.JoinHostPort(nameserver, "53"))
+	r, err := exchange(client, m, nameserver)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var keys []*DNSKEYRecord
+	var keys []*dns.DNSKEY
+	var sigs []*dns.RRSIG
 	for _, ans := range r.Answer {
-		if dnskey, ok := ans.(*dns.DNSKEY); ok {
-			keys = append(keys, &DNSKEYRecord{
-				Flags:     dnskey.Flags,
-				Protocol:  dnskey.Protocol,
-				Algorithm: dnskey.Algorithm,
-				PublicKey: dnskey.PublicKey,
-			})
+		switch rr := ans.(type) {
+		case *dns.DNSKEY:
+			keys = append(keys, rr)
+		case *dns.RRSIG:
+			if rr.TypeCovered == dns.TypeDNSKEY {
+				sigs = append(sigs, rr)
+			}
 		}
 	}
 
-	return keys, nil
+	return keys, sigs, nil
 }
 
 func queryRRSIG(client *dns.Client, domain, nameserver string) ([]*RRSIGRecord, error) {
@@ -179,7 +249,7 @@ func queryRRSIG(client *dns.Client, domain, nameserver string) ([]*RRSIGRecord,
 	m.SetQuestion(dns.Fqdn(domain), dns.TypeRRSIG)
 	m.SetEdns0(4096, true)
 
-	r, _, err := client.Exchange(m, net.JoinHostPort(nameserver, "53"))
+	r, err := exchange(client, m, nameserver)
 	if err != nil {
 		return nil, err
 	}
@@ -204,40 +274,85 @@ func queryRRSIG(client *dns.Client, domain, nameserver string) ([]*RRSIGRecord,
 	return sigs, nil
 }
 
-func validateChainOfTrust(result *ValidationResult) (bool, error) {
-	// Basic validation checks
-	if result.DS == nil {
-		return false, fmt.Errorf("no DS record found")
+// validateChainOfTrust checks that a DS record matches a DNSKEY by digest and that the DNSKEY
+// RRset carries a valid signature from such a DS-authenticated key. It returns every failure found.
+func validateChainOfTrust(dsSet []*dns.DS, keys []*dns.DNSKEY, sigs []*dns.RRSIG, now time.Time) (bool, []string) {
+	var errs []string
+
+	if len(dsSet) == 0 {
+		return false, []string{"no DS record found"}
+	}
+	if len(keys) == 0 {
+		return false, []string{"no DNSKEY records found"}
+	}
+
+	// Step 1: DS digest must match a DNSKEY published by the zone
+	trusted := make(map[uint16]*dns.DNSKEY)
+	for _, ds := range dsSet {
+		key := findKey(keys, ds.KeyTag, ds.Algorithm)
+		if key == nil {
+			errs = append(errs, fmt.Sprintf("DS key tag %d: no DNSKEY with matching key tag and algorithm %d", ds.KeyTag, ds.Algorithm))
+			continue
+		}
+
+		computed := key.ToDS(ds.DigestType)
+		if computed == nil {
+			errs = append(errs, fmt.Sprintf("DS key tag %d: unsupported digest type %d", ds.KeyTag, ds.DigestType))
+			continue
+		}
+		if !strings.EqualFold(computed.Digest, ds.Digest) {
+			errs = append(errs, fmt.Sprintf("DS key tag %d: digest does not match DNSKEY (DS %s, computed %s)", ds.KeyTag, ds.Digest, computed.Digest))
+			continue
+		}
+
+		trusted[ds.KeyTag] = key
+	}
+
+	if len(trusted) == 0 {
+		return false, append(errs, "no DNSKEY is authenticated by a DS record")
 	}
 
-	if len(result.DNSKEY) == 0 {
-		return false, fmt.Errorf("no DNSKEY records found")
+	// Step 2: the DNSKEY RRset must be signed by a DS-authenticated key
+	if len(sigs) == 0 {
+		return false, append(errs, "no RRSIG covering the DNSKEY RRset")
 	}
 
-	if len(result.RRSIG) == 0 {
-		return false, fmt.Errorf("no RRSIG records found")
+	rrset := make([]dns.RR, len(keys))
+	for i, key := range keys {
+		rrset[i] = key
 	}
 
-	// Check DNSKEY validity
-	var foundValidKey bool
-	for _, key := range result.DNSKEY {
-		if key.Flags&256 != 0 { // Zone Signing Key
-			foundValidKey = true
-			break
+	verified := false
+	for _, sig := range sigs {
+		key, ok := trusted[sig.KeyTag]
+		if !ok {
+			continue
 		}
+		if !sig.ValidityPeriod(now) {
+			errs = append(errs, fmt.Sprintf("RRSIG by key tag %d over DNSKEY is outside its validity period (%s to %s)",
+				sig.KeyTag, dns.TimeToString(sig.Inception), dns.TimeToString(sig.Expiration)))
+			continue
+		}
+		if err := sig.Verify(key, rrset); err != nil {
+			errs = append(errs, fmt.Sprintf("RRSIG by key tag %d over DNSKEY failed verification: %v", sig.KeyTag, err))
+			continue
+		}
+		verified = true
 	}
 
-	if !foundValidKey {
-		return false, fmt.Errorf("no valid zone signing key found")
+	if !verified {
+		errs = append(errs, "no valid RRSIG over the DNSKEY RRset from a DS-authenticated key")
 	}
 
-	// Check RRSIG validity
-	now := time.Now()
-	for _, sig := range result.RRSIG {
-		if now.After(sig.Expiration) || now.Before(sig.Inception) {
-			return false, fmt.Errorf("RRSIG timing validation failed")
+	return verified, errs
+}
+
+// findKey returns the DNSKEY with the given key tag and algorithm, if present
+func findKey(keys []*dns.DNSKEY, keyTag uint16, algorithm uint8) *dns.DNSKEY {
+	for _, key := range keys {
+		if key.KeyTag() == keyTag && key.Algorithm == algorithm {
+			return key
 		}
 	}
-
-	return true, nil
+	return nil
 }
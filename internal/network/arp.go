@@ -0,0 +1,264 @@
+// =============================================================================
+// internal/network/arp.go - ARP neighbor discovery and MAC vendor lookup
+// =============================================================================
+package network
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed data/oui.txt
+var ouiData string
+
+// ouiVendors maps upper-case "AA:BB:CC" prefixes to vendor names, parsed from
+// ouiData on first use
+var ouiVendors = sync.OnceValue(func() map[string]string {
+	vendors := make(map[string]string)
+	for _, line := range dataLines(ouiData) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		vendors[strings.ToUpper(fields[0])] = strings.Join(fields[1:], " ")
+	}
+	return vendors
+})
+
+// VendorLocallyAdministered is reported for MAC addresses with the locally
+// administered bit set, such as the randomized addresses of phones and laptops
+const VendorLocallyAdministered = "Locally administered"
+
+// MACVendor returns the vendor registered for a MAC address's OUI, or "" when
+// the prefix is not in the embedded table
+func MACVendor(mac string) string {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) < 3 {
+		return ""
+	}
+	if vendor, ok := ouiVendors()[fmt.Sprintf("%02X:%02X:%02X", hw[0], hw[1], hw[2])]; ok {
+		return vendor
+	}
+	if hw[0]&0x02 != 0 {
+		return VendorLocallyAdministered
+	}
+	return ""
+}
+
+// localSubnets returns the IPv4 prefixes of the up, non-loopback interfaces;
+// addresses inside them are reachable without a router, so they answer ARP
+func localSubnets() []netip.Prefix {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		slog.Debug("listing interfaces failed", "error", err)
+		return nil
+	}
+
+	var subnets []netip.Prefix
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil {
+				continue
+			}
+			prefix, err := netip.ParsePrefix(ipNet.String())
+			if err == nil {
+				subnets = append(subnets, prefix.Masked())
+			}
+		}
+	}
+	return subnets
+}
+
+// onLink reports whether ip is an IPv4 address inside one of subnets
+func onLink(subnets []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || !addr.Is4() {
+		return false
+	}
+	for _, subnet := range subnets {
+		if subnet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// solicitARP makes the OS resolve ip's MAC address by sending it a one-byte UDP
+// datagram on the discard port; no privileges are needed and the reply, if
+// any, lands in the neighbor table
+func solicitARP(ip string) {
+	conn, err := net.Dial("udp4", net.JoinHostPort(ip, "9"))
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.Write([]byte{0})
+}
+
+// waitARP waits until wait has passed since start, giving the OS time to
+// resolve the addresses solicited then, or until ctx is done
+func waitARP(ctx context.Context, start time.Time, wait time.Duration) {
+	timer := time.NewTimer(time.Until(start.Add(wait)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// readNeighbors returns the resolved entries of the OS IPv4 neighbor table as
+// address to MAC: /proc/net/arp on Linux and the output of arp -a elsewhere
+func readNeighbors() (map[string]string, error) {
+	if runtime.GOOS == "linux" {
+		data, err := os.ReadFile("/proc/net/arp")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read neighbor table: %w", err)
+		}
+		return parseProcARP(string(data)), nil
+	}
+
+	args := []string{"-an"}
+	if runtime.GOOS == "windows" {
+		args = []string{"-a"}
+	}
+	out, err := exec.Command("arp", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read neighbor table: %w", err)
+	}
+	return parseARPOutput(string(out)), nil
+}
+
+// parseProcARP parses /proc/net/arp, keeping entries whose flags mark them complete
+func parseProcARP(data string) map[string]string {
+	const completeFlag = 0x2
+
+	neighbors := make(map[string]string)
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 4 {
+			continue
+		}
+		flags, err := strconv.ParseInt(strings.TrimPrefix(fields[2], "0x"), 16, 64)
+		if err != nil || flags&completeFlag == 0 {
+			continue
+		}
+		if mac, ok := normalizeMAC(fields[3]); ok {
+			neighbors[fields[0]] = mac
+		}
+	}
+	return neighbors
+}
+
+// parseARPOutput parses arp -a output, which lists one neighbor per line as
+// "? (10.0.0.1) at 0:1b:21:aa:bb:cc on en0" on macOS and the BSDs and as
+// "10.0.0.1  00-1b-21-aa-bb-cc  dynamic" on Windows. Incomplete entries have
+// no MAC and are skipped.
+func parseARPOutput(output string) map[string]string {
+	neighbors := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		var ip, mac string
+		for _, field := range strings.Fields(line) {
+			field = strings.Trim(field, "()")
+			if addr, err := netip.ParseAddr(field); err == nil && addr.Is4() && ip == "" {
+				ip = field
+			} else if normalized, ok := normalizeMAC(field); ok && mac == "" {
+				mac = normalized
+			}
+		}
+		if ip != "" && mac != "" {
+			neighbors[ip] = mac
+		}
+	}
+	return neighbors
+}
+
+// normalizeMAC rewrites a unicast MAC address separated by colons or dashes,
+// with octets of one or two digits, as lower-case aa:bb:cc:dd:ee:ff. All-zero
+// and multicast addresses, which don't identify a neighbor, are rejected.
+func normalizeMAC(mac string) (string, bool) {
+	octets := strings.FieldsFunc(mac, func(r rune) bool { return r == ':' || r == '-' })
+	if len(octets) != 6 {
+		return "", false
+	}
+
+	var hw [6]byte
+	for i, octet := range octets {
+		value, err := strconv.ParseUint(octet, 16, 8)
+		if err != nil || len(octet) > 2 {
+			return "", false
+		}
+		hw[i] = byte(value)
+	}
+	if hw == [6]byte{} || hw[0]&0x01 != 0 {
+		return "", false
+	}
+	return net.HardwareAddr(hw[:]).String(), true
+}
+
+// addARPHosts adds the solicited addresses that resolved in the neighbor table
+// as hosts found by ARP, and fills in the MAC and vendor of the hosts already
+// found by other probes
+func addARPHosts(hosts []HostResult, solicited []string) []HostResult {
+	neighbors, err := readNeighbors()
+	if err != nil {
+		slog.Debug("ARP results unavailable", "error", err)
+		return hosts
+	}
+
+	found := make(map[string]int, len(hosts))
+	for i, host := range hosts {
+		found[host.IP] = i
+	}
+	for _, ip := range solicited {
+		mac, ok := neighbors[ip]
+		if !ok {
+			continue
+		}
+		if i, seen := found[ip]; seen {
+			hosts[i].MAC = mac
+			hosts[i].Vendor = MACVendor(mac)
+			continue
+		}
+		hosts = append(hosts, HostResult{IP: ip, Alive: true, Method: PingMethodARP, MAC: mac, Vendor: MACVendor(mac)})
+	}
+	return hosts
+}
+
+// addNeighborMACs fills in the MAC address and vendor of the hosts that appear
+// in the OS neighbor table, which holds the hosts on local subnets that were
+// just probed. A table that cannot be read leaves the hosts unchanged.
+func addNeighborMACs(hosts []HostResult) {
+	if len(hosts) == 0 {
+		return
+	}
+	neighbors, err := readNeighbors()
+	if err != nil {
+		slog.Debug("MAC addresses unavailable", "error", err)
+		return
+	}
+	for i := range hosts {
+		if mac, ok := neighbors[hosts[i].IP]; ok {
+			hosts[i].MAC = mac
+			hosts[i].Vendor = MACVendor(mac)
+		}
+	}
+}
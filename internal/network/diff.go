@@ -0,0 +1,183 @@
+// =============================================================================
+// internal/network/diff.go - Change detection between two saved scans
+// =============================================================================
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"time"
+)
+
+// ScanChange describes how a host or port differs between two scans
+type ScanChange string
+
+const (
+	ScanHostAdded   ScanChange = "host_added"   // Alive only in the new scan
+	ScanHostRemoved ScanChange = "host_removed" // Alive only in the old scan
+	ScanPortOpened  ScanChange = "port_opened"  // Open only in the new scan
+	ScanPortClosed  ScanChange = "port_closed"  // Open only in the old scan
+)
+
+// ScanDiffEntry is a single change; Port and Protocol are unset for host changes
+type ScanDiffEntry struct {
+	IP       string     `json:"ip"`
+	Hostname string     `json:"hostname,omitempty"`
+	Change   ScanChange `json:"change"`
+	Port     int        `json:"port,omitempty"`
+	Protocol string     `json:"protocol,omitempty"`
+	Service  string     `json:"service,omitempty"`
+}
+
+// ScanDiff holds the changes from an old scan to a new one
+type ScanDiff struct {
+	OldNetwork   string          `json:"old_network"`
+	NewNetwork   string          `json:"new_network"`
+	OldTime      time.Time       `json:"old_time"`
+	NewTime      time.Time       `json:"new_time"`
+	HostsAdded   int             `json:"hosts_added"`
+	HostsRemoved int             `json:"hosts_removed"`
+	PortsOpened  int             `json:"ports_opened"`
+	PortsClosed  int             `json:"ports_closed"`
+	Changes      []ScanDiffEntry `json:"changes"`
+}
+
+// Changed reports whether the scans differ at all
+func (d *ScanDiff) Changed() bool {
+	return len(d.Changes) > 0
+}
+
+// portKey identifies a port by number and protocol
+type portKey struct {
+	port     int
+	protocol string
+}
+
+// ReadScanResult loads a scan result saved with --format json
+func ReadScanResult(path string) (*ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan result: %w", err)
+	}
+	var result ScanResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse scan result %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+// DiffScans compares two scans. Hosts are matched by IP and count when alive;
+// ports are matched by number and protocol and count when open. The open ports
+// of an added or removed host are reported as opened or closed as well, so
+// every newly exposed port shows up. Changes are ordered by IP, then port.
+func DiffScans(oldScan, newScan *ScanResult) *ScanDiff {
+	diff := &ScanDiff{
+		OldNetwork: oldScan.Network,
+		NewNetwork: newScan.Network,
+		OldTime:    oldScan.StartTime,
+		NewTime:    newScan.StartTime,
+		Changes:    []ScanDiffEntry{},
+	}
+
+	oldHosts := aliveHosts(oldScan)
+	newHosts := aliveHosts(newScan)
+
+	for ip, host := range newHosts {
+		previous, seen := oldHosts[ip]
+		if !seen {
+			diff.add(ScanDiffEntry{IP: ip, Hostname: host.Hostname, Change: ScanHostAdded})
+		}
+		diff.comparePorts(host, previous)
+	}
+	for ip, host := range oldHosts {
+		if _, seen := newHosts[ip]; !seen {
+			diff.add(ScanDiffEntry{IP: ip, Hostname: host.Hostname, Change: ScanHostRemoved})
+			diff.comparePorts(HostResult{IP: ip, Hostname: host.Hostname}, host)
+		}
+	}
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		if a.IP != b.IP {
+			addrA, errA := netip.ParseAddr(a.IP)
+			addrB, errB := netip.ParseAddr(b.IP)
+			if errA != nil || errB != nil {
+				return a.IP < b.IP
+			}
+			return addrA.Less(addrB)
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		return a.Protocol < b.Protocol
+	})
+	return diff
+}
+
+// comparePorts records the ports open on host but not on previous as opened, and
+// the reverse as closed; previous is the zero HostResult for a new host
+func (d *ScanDiff) comparePorts(host, previous HostResult) {
+	before := openPortSet(previous)
+	after := openPortSet(host)
+	hostname := host.Hostname
+	if hostname == "" {
+		hostname = previous.Hostname
+	}
+
+	for key, port := range after {
+		if _, ok := before[key]; !ok {
+			d.add(ScanDiffEntry{IP: host.IP, Hostname: hostname, Change: ScanPortOpened, Port: key.port, Protocol: key.protocol, Service: port.Service})
+		}
+	}
+	for key, port := range before {
+		if _, ok := after[key]; !ok {
+			d.add(ScanDiffEntry{IP: host.IP, Hostname: hostname, Change: ScanPortClosed, Port: key.port, Protocol: key.protocol, Service: port.Service})
+		}
+	}
+}
+
+// add appends a change and counts it
+func (d *ScanDiff) add(entry ScanDiffEntry) {
+	switch entry.Change {
+	case ScanHostAdded:
+		d.HostsAdded++
+	case ScanHostRemoved:
+		d.HostsRemoved++
+	case ScanPortOpened:
+		d.PortsOpened++
+	case ScanPortClosed:
+		d.PortsClosed++
+	}
+	d.Changes = append(d.Changes, entry)
+}
+
+// aliveHosts indexes the alive hosts of a scan by IP
+func aliveHosts(result *ScanResult) map[string]HostResult {
+	hosts := make(map[string]HostResult)
+	for _, host := range result.Hosts {
+		if host.Alive && host.IP != "" {
+			hosts[host.IP] = host
+		}
+	}
+	return hosts
+}
+
+// openPortSet indexes the open ports of a host; results saved before ports
+// carried a protocol count as TCP
+func openPortSet(host HostResult) map[portKey]PortResult {
+	ports := make(map[portKey]PortResult)
+	for _, port := range host.Ports {
+		if !port.Open {
+			continue
+		}
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = ProtocolTCP
+		}
+		ports[portKey{port: port.Port, protocol: protocol}] = port
+	}
+	return ports
+}
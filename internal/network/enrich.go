@@ -0,0 +1,74 @@
+// =============================================================================
+// internal/network/enrich.go - Reverse DNS and certificate details for discovered hosts
+// =============================================================================
+package network
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bryanCE/sysadmin/internal/dns"
+	"github.com/bryanCE/sysadmin/internal/ssl"
+)
+
+// enrichTimeout bounds how long the lookups for one host may add to a scan
+const enrichTimeout = 3 * time.Second
+
+// tlsPorts are the open ports whose certificates are fetched during enrichment
+var tlsPorts = map[int]bool{443: true, 8443: true}
+
+// SetResolver makes NetworkDiscovery look up the PTR name of each live host
+// through resolver and nameserver, and fetch the certificate served on open
+// TLS ports. A nil resolver turns the lookups off.
+func (s *Scanner) SetResolver(resolver *dns.Resolver, nameserver string) {
+	s.resolver = resolver
+	s.nameserver = nameserver
+}
+
+// enrichHost fills in the hostname and TLS certificate names of a live host.
+// The lookups run in parallel and share one deadline, so a host adds at most
+// enrichTimeout to the scan; failed lookups leave the fields empty.
+func (s *Scanner) enrichHost(ctx context.Context, host *HostResult) {
+	ctx, cancel := context.WithTimeout(ctx, enrichTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		names, err := s.resolver.LookupPTR(ctx, host.IP, s.nameserver)
+		if err != nil {
+			slog.Debug("reverse lookup failed", "address", host.IP, "error", err)
+			return
+		}
+		if len(names) > 0 {
+			host.Hostname = strings.TrimSuffix(names[0], ".")
+		}
+	}()
+
+	for i := range host.Ports {
+		port := &host.Ports[i]
+		if !port.Open || !tlsPorts[port.Port] {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Without a name for SNI the server presents its default certificate
+			cert, err := ssl.CheckCertificateAt(ctx, host.IP, host.IP, strconv.Itoa(port.Port), ssl.DialConfig{Timeout: enrichTimeout})
+			if err != nil {
+				slog.Debug("certificate fetch failed", "address", host.IP, "port", port.Port, "error", err)
+				return
+			}
+			port.CertCN = cert.CommonName
+			port.CertSANs = cert.DNSNames
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,337 @@
+// =============================================================================
+// internal/network/history.go - SQLite history of discovery scans
+// =============================================================================
+package network
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	// Pure-Go driver, so cross-compiled builds need no C toolchain
+	_ "modernc.org/sqlite"
+)
+
+// historySchemaVersion is stored in PRAGMA user_version. Columns are only ever
+// added, so queries written against an older version keep working.
+const historySchemaVersion = 1
+
+// historySchema creates the tables of a scan history database. Times are Unix
+// seconds (UTC). Only live hosts and open ports are stored, so a sighting means
+// the host answered or the port accepted connections in that scan.
+const historySchema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id            INTEGER PRIMARY KEY,
+	kind          TEXT    NOT NULL,
+	network       TEXT    NOT NULL,
+	started_at    INTEGER NOT NULL,
+	duration_ms   INTEGER NOT NULL,
+	hosts_scanned INTEGER NOT NULL,
+	live_hosts    INTEGER NOT NULL,
+	open_ports    INTEGER NOT NULL,
+	interrupted   INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS hosts (
+	scan_id    INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+	ip         TEXT    NOT NULL,
+	hostname   TEXT    NOT NULL,
+	mac        TEXT    NOT NULL,
+	vendor     TEXT    NOT NULL,
+	latency_us INTEGER NOT NULL,
+	PRIMARY KEY (scan_id, ip)
+);
+CREATE TABLE IF NOT EXISTS ports (
+	scan_id  INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+	ip       TEXT    NOT NULL,
+	port     INTEGER NOT NULL,
+	protocol TEXT    NOT NULL,
+	state    TEXT    NOT NULL,
+	service  TEXT    NOT NULL,
+	banner   TEXT    NOT NULL,
+	PRIMARY KEY (scan_id, ip, port, protocol)
+);
+CREATE INDEX IF NOT EXISTS hosts_ip ON hosts(ip);
+CREATE INDEX IF NOT EXISTS ports_endpoint ON ports(ip, port, protocol);
+`
+
+// HistoryEntry is the sighting history of a host, or of one of its ports when
+// Port is set. FirstSeen and LastSeen are the start times of the earliest and
+// latest scans that saw it; the other fields come from the latest one.
+type HistoryEntry struct {
+	IP        string    `json:"ip"`
+	Hostname  string    `json:"hostname,omitempty"`
+	Port      int       `json:"port,omitempty"`
+	Protocol  string    `json:"protocol,omitempty"`
+	Service   string    `json:"service,omitempty"`
+	Banner    string    `json:"banner,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Sightings int       `json:"sightings"`
+}
+
+// ScanHistoryReport answers one question about a scan history database.
+// Cutoff is set for not-seen queries.
+type ScanHistoryReport struct {
+	Database string         `json:"database"`
+	Query    string         `json:"query"`
+	Scans    int            `json:"scans"`
+	Cutoff   *time.Time     `json:"cutoff,omitempty"`
+	Entries  []HistoryEntry `json:"entries"`
+}
+
+// ScanHistory records scan results in a SQLite database and answers questions
+// about when hosts and ports were seen. It is safe for concurrent use: inserts
+// are serialized within the process, and other processes writing the same file
+// are waited for rather than failing with "database is locked".
+type ScanHistory struct {
+	db   *sql.DB
+	path string
+	mu   sync.Mutex
+}
+
+// OpenScanHistory opens the database at path, creating it and its tables if needed
+func OpenScanHistory(path string) (*ScanHistory, error) {
+	// busy_timeout waits out writers in other processes, and immediate
+	// transactions take the write lock up front so two writers cannot deadlock
+	dsn := path + "?_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scan history %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+
+	history := &ScanHistory{db: db, path: path}
+	if err := history.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open scan history %s: %w", path, err)
+	}
+	return history, nil
+}
+
+// migrate creates the schema, refusing databases written by a newer version
+func (h *ScanHistory) migrate() error {
+	var version int
+	if err := h.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > historySchemaVersion {
+		return fmt.Errorf("schema version %d is newer than this build supports (%d)", version, historySchemaVersion)
+	}
+	if _, err := h.db.Exec(historySchema); err != nil {
+		return err
+	}
+	_, err := h.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", historySchemaVersion))
+	return err
+}
+
+// Close closes the database
+func (h *ScanHistory) Close() error {
+	return h.db.Close()
+}
+
+// Record stores a scan under kind (the command that produced it, such as
+// "discovery") in a single transaction, so a failed write leaves no partial scan
+func (h *ScanHistory) Record(kind string, result *ScanResult) (err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record scan: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	scan, err := tx.Exec(`INSERT INTO scans (kind, network, started_at, duration_ms, hosts_scanned, live_hosts, open_ports, interrupted)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		kind, result.Network, result.StartTime.Unix(), result.Duration.Milliseconds(),
+		result.Summary.HostsScanned, result.Summary.LiveHosts, result.Summary.OpenPorts, result.Summary.Interrupted)
+	if err != nil {
+		return fmt.Errorf("failed to record scan: %w", err)
+	}
+	scanID, err := scan.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to record scan: %w", err)
+	}
+
+	insertHost, err := tx.Prepare(`INSERT OR IGNORE INTO hosts (scan_id, ip, hostname, mac, vendor, latency_us) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to record scan: %w", err)
+	}
+	defer insertHost.Close()
+	insertPort, err := tx.Prepare(`INSERT OR IGNORE INTO ports (scan_id, ip, port, protocol, state, service, banner) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to record scan: %w", err)
+	}
+	defer insertPort.Close()
+
+	for _, host := range result.Hosts {
+		var open []PortResult
+		for _, port := range host.Ports {
+			if port.Open {
+				open = append(open, port)
+			}
+		}
+		if !host.Alive && len(open) == 0 {
+			continue
+		}
+
+		if _, err = insertHost.Exec(scanID, host.IP, host.Hostname, host.MAC, host.Vendor, host.Latency.Microseconds()); err != nil {
+			return fmt.Errorf("failed to record host %s: %w", host.IP, err)
+		}
+		for _, port := range open {
+			if _, err = insertPort.Exec(scanID, host.IP, port.Port, port.Protocol, port.State, port.Service, port.Banner); err != nil {
+				return fmt.Errorf("failed to record %s port %d: %w", host.IP, port.Port, err)
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record scan: %w", err)
+	}
+	return nil
+}
+
+// FirstSeen reports when ip, or with a non-zero port that port on ip, was first
+// and last seen. For a host, the entries are the host followed by each port it
+// has had open. The report has no entries when the history never saw it.
+func (h *ScanHistory) FirstSeen(ip string, port int) (*ScanHistoryReport, error) {
+	query := fmt.Sprintf("first-seen %s", ip)
+	if port != 0 {
+		query = fmt.Sprintf("first-seen %s port %d", ip, port)
+	}
+	report, err := h.newReport(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if port == 0 {
+		hosts, err := h.queryHosts(`WHERE h.ip = ?`, ``, ip)
+		if err != nil {
+			return nil, err
+		}
+		report.Entries = append(report.Entries, hosts...)
+	}
+
+	ports, err := h.queryPorts(ip, port)
+	if err != nil {
+		return nil, err
+	}
+	report.Entries = append(report.Entries, ports...)
+	return report, nil
+}
+
+// NotSeenSince lists the hosts no scan has seen since cutoff, longest gone first
+func (h *ScanHistory) NotSeenSince(cutoff time.Time, days int) (*ScanHistoryReport, error) {
+	report, err := h.newReport(fmt.Sprintf("not-seen %d days", days))
+	if err != nil {
+		return nil, err
+	}
+	report.Cutoff = &cutoff
+
+	report.Entries, err = h.queryHosts(``, `HAVING MAX(s.started_at) < ?`, cutoff.Unix())
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// newReport starts a report with the number of recorded scans
+func (h *ScanHistory) newReport(query string) (*ScanHistoryReport, error) {
+	report := &ScanHistoryReport{Database: h.path, Query: query, Entries: []HistoryEntry{}}
+	if err := h.db.QueryRow(`SELECT COUNT(*) FROM scans`).Scan(&report.Scans); err != nil {
+		return nil, fmt.Errorf("failed to query scan history: %w", err)
+	}
+	return report, nil
+}
+
+// queryHosts aggregates host sightings per IP, filtered by where (before
+// grouping) and having (after), ordered by when the host was last seen
+func (h *ScanHistory) queryHosts(where, having string, args ...any) ([]HistoryEntry, error) {
+	query := `SELECT h.ip, MIN(s.started_at), MAX(s.started_at), COUNT(*),
+			(SELECT h2.hostname FROM hosts h2 JOIN scans s2 ON s2.id = h2.scan_id
+				WHERE h2.ip = h.ip ORDER BY s2.started_at DESC, s2.id DESC LIMIT 1)
+		FROM hosts h JOIN scans s ON s.id = h.scan_id ` + where + `
+		GROUP BY h.ip ` + having + `
+		ORDER BY MAX(s.started_at), h.ip`
+
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scan history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var entry HistoryEntry
+		var first, last int64
+		if err := rows.Scan(&entry.IP, &first, &last, &entry.Sightings, &entry.Hostname); err != nil {
+			return nil, fmt.Errorf("failed to read scan history: %w", err)
+		}
+		entry.FirstSeen, entry.LastSeen = time.Unix(first, 0), time.Unix(last, 0)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read scan history: %w", err)
+	}
+	return entries, nil
+}
+
+// queryPorts aggregates the sightings of the open ports of ip, or only of port
+// when it is non-zero, ordered by port and protocol
+func (h *ScanHistory) queryPorts(ip string, port int) ([]HistoryEntry, error) {
+	rows, err := h.db.Query(`SELECT p.port, p.protocol, s.started_at, p.service, p.banner,
+			(SELECT h.hostname FROM hosts h WHERE h.scan_id = s.id AND h.ip = p.ip)
+		FROM ports p JOIN scans s ON s.id = p.scan_id
+		WHERE p.ip = ? AND (? = 0 OR p.port = ?)
+		ORDER BY s.started_at, s.id`, ip, port, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scan history: %w", err)
+	}
+	defer rows.Close()
+
+	// Sightings arrive oldest first, so the last one seen sets the details
+	type endpoint struct {
+		port     int
+		protocol string
+	}
+	byEndpoint := make(map[endpoint]*HistoryEntry)
+	for rows.Next() {
+		var key endpoint
+		var seen int64
+		var service, banner string
+		var hostname sql.NullString
+		if err := rows.Scan(&key.port, &key.protocol, &seen, &service, &banner, &hostname); err != nil {
+			return nil, fmt.Errorf("failed to read scan history: %w", err)
+		}
+
+		entry, ok := byEndpoint[key]
+		if !ok {
+			entry = &HistoryEntry{IP: ip, Port: key.port, Protocol: key.protocol, FirstSeen: time.Unix(seen, 0)}
+			byEndpoint[key] = entry
+		}
+		entry.LastSeen = time.Unix(seen, 0)
+		entry.Sightings++
+		entry.Hostname, entry.Service, entry.Banner = hostname.String, service, banner
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read scan history: %w", err)
+	}
+
+	entries := make([]HistoryEntry, 0, len(byEndpoint))
+	for _, entry := range byEndpoint {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Port != entries[j].Port {
+			return entries[i].Port < entries[j].Port
+		}
+		return entries[i].Protocol < entries[j].Protocol
+	})
+	return entries, nil
+}
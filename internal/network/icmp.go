@@ -0,0 +1,218 @@
+// =============================================================================
+// internal/network/icmp.go - ICMP echo ping with unprivileged fallback
+// =============================================================================
+package network
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// Ping methods
+const (
+	PingMethodAuto = "auto"
+	PingMethodICMP = "icmp"
+	PingMethodTCP  = "tcp"
+	PingMethodARP  = "arp"
+)
+
+// icmpProtocolNumber is the IANA protocol number for ICMP over IPv4
+const icmpProtocolNumber = 1
+
+// icmpKey identifies an outstanding echo request by target and sequence number
+type icmpKey struct {
+	ip  string
+	seq int
+}
+
+// icmpPinger sends echo requests over one shared socket and hands the TTL of
+// each reply back to the goroutine waiting for it
+type icmpPinger struct {
+	conn       *icmp.PacketConn
+	packetConn *ipv4.PacketConn
+	privileged bool
+	id         int
+
+	mu      sync.Mutex
+	seq     int
+	pending map[icmpKey]chan int
+}
+
+// newICMPPinger opens a raw ICMP socket when running as root or with
+// CAP_NET_RAW, and otherwise a datagram ICMP socket, which Linux allows for
+// groups in net.ipv4.ping_group_range and macOS allows for everyone
+func newICMPPinger() (*icmpPinger, error) {
+	privileged := true
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		slog.Debug("raw ICMP socket unavailable", "error", err)
+		privileged = false
+		conn, err = icmp.ListenPacket("udp4", "0.0.0.0")
+		if err != nil {
+			return nil, fmt.Errorf("ICMP ping needs root, CAP_NET_RAW or net.ipv4.ping_group_range: %w", err)
+		}
+	}
+
+	p := &icmpPinger{
+		conn:       conn,
+		packetConn: conn.IPv4PacketConn(),
+		privileged: privileged,
+		id:         os.Getpid() & 0xffff,
+		pending:    make(map[icmpKey]chan int),
+	}
+	// Replies carry their TTL where the OS supports it; without it hosts
+	// just get no OS hint
+	if p.packetConn != nil {
+		if err := p.packetConn.SetControlMessage(ipv4.FlagTTL, true); err != nil {
+			slog.Debug("ICMP reply TTL unavailable", "error", err)
+		}
+	}
+	go p.readReplies()
+	return p, nil
+}
+
+// Close stops the reader and releases the socket
+func (p *icmpPinger) Close() error {
+	return p.conn.Close()
+}
+
+// readReplies matches echo replies to waiting pings until the socket is closed
+func (p *icmpPinger) readReplies() {
+	buffer := make([]byte, 1500)
+	for {
+		var n, ttl int
+		var peer net.Addr
+		var err error
+		if p.packetConn != nil {
+			var cm *ipv4.ControlMessage
+			n, cm, peer, err = p.packetConn.ReadFrom(buffer)
+			if cm != nil {
+				ttl = cm.TTL
+			}
+		} else {
+			n, peer, err = p.conn.ReadFrom(buffer)
+		}
+		if err != nil {
+			return
+		}
+
+		msg, err := icmp.ParseMessage(icmpProtocolNumber, buffer[:n])
+		if err != nil || msg.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+		echo, ok := msg.Body.(*icmp.Echo)
+		if !ok {
+			continue
+		}
+		// Datagram sockets rewrite the ID to the local port, so only raw
+		// sockets can filter on it
+		if p.privileged && echo.ID != p.id {
+			continue
+		}
+
+		var ip net.IP
+		switch addr := peer.(type) {
+		case *net.IPAddr:
+			ip = addr.IP
+		case *net.UDPAddr:
+			ip = addr.IP
+		}
+
+		key := icmpKey{ip: ip.String(), seq: echo.Seq}
+		p.mu.Lock()
+		if done, ok := p.pending[key]; ok {
+			done <- ttl
+			delete(p.pending, key)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// ping sends one echo request and waits up to timeout for the reply, returning
+// the round-trip time and the reply's TTL (0 when the OS does not report it)
+func (p *icmpPinger) ping(ctx context.Context, ip string, timeout time.Duration) (time.Duration, int, bool) {
+	target := net.ParseIP(ip).To4()
+	if target == nil {
+		return 0, 0, false
+	}
+
+	done := make(chan int, 1)
+	p.mu.Lock()
+	p.seq = (p.seq + 1) & 0xffff
+	key := icmpKey{ip: target.String(), seq: p.seq}
+	p.pending[key] = done
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, key)
+		p.mu.Unlock()
+	}()
+
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{ID: p.id, Seq: key.seq, Data: []byte("systool")},
+	}
+	packet, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	var dst net.Addr = &net.IPAddr{IP: target}
+	if !p.privileged {
+		dst = &net.UDPAddr{IP: target}
+	}
+
+	start := time.Now()
+	if _, err := p.conn.WriteTo(packet, dst); err != nil {
+		slog.Debug("icmp probe", "address", ip, "error", err)
+		return 0, 0, false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case ttl := <-done:
+		rtt := time.Since(start)
+		slog.Debug("icmp probe", "address", ip, "reachable", true, "rtt", rtt, "ttl", ttl)
+		return rtt, ttl, true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	slog.Debug("icmp probe", "address", ip, "reachable", false)
+	return 0, 0, false
+}
+
+// pingICMP sends one echo through pinger once the scanner's rate limit allows
+func (s *Scanner) pingICMP(ctx context.Context, pinger *icmpPinger, ip string) (time.Duration, int, bool) {
+	if s.pace(ctx) != nil {
+		return 0, 0, false
+	}
+	return pinger.ping(ctx, ip, s.timeout)
+}
+
+// osHintFromTTL guesses the operating system family from a reply TTL by
+// rounding up to the nearest common initial TTL. Each router on the path
+// lowers the TTL by one, and many systems let the initial value be changed,
+// so this is only a hint.
+func osHintFromTTL(ttl int) string {
+	switch {
+	case ttl <= 0:
+		return ""
+	case ttl <= 64:
+		return "Linux/Unix"
+	case ttl <= 128:
+		return "Windows"
+	default:
+		return "Network device"
+	}
+}
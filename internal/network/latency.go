@@ -0,0 +1,89 @@
+// =============================================================================
+// internal/network/latency.go - Repeated ping probes and latency statistics
+// =============================================================================
+package network
+
+import (
+	"context"
+	"time"
+)
+
+// pingInterval is the pause between repeated probes of one host
+const pingInterval = 100 * time.Millisecond
+
+// LatencyStats summarizes repeated probes of one host. Jitter is the mean
+// difference between consecutive round-trip times.
+type LatencyStats struct {
+	Sent     int           `json:"sent"`
+	Received int           `json:"received"`
+	Min      time.Duration `json:"min"`
+	Avg      time.Duration `json:"avg"`
+	Max      time.Duration `json:"max"`
+	Jitter   time.Duration `json:"jitter"`
+}
+
+// Loss returns the percentage of probes that went unanswered
+func (l *LatencyStats) Loss() float64 {
+	if l.Sent == 0 {
+		return 0
+	}
+	return float64(l.Sent-l.Received) / float64(l.Sent) * 100
+}
+
+// measureLatency probes a live host until pingCount probes have been sent,
+// counting the probe that detected it as the first. ICMP is used when the
+// host answered it, otherwise TCP.
+func (s *Scanner) measureLatency(ctx context.Context, pinger *icmpPinger, useICMP bool, ip string, first time.Duration) *LatencyStats {
+	samples := []time.Duration{first}
+	sent := 1
+
+	for sent < s.pingCount {
+		select {
+		case <-ctx.Done():
+			return newLatencyStats(sent, samples)
+		case <-time.After(pingInterval):
+		}
+
+		sent++
+		var rtt time.Duration
+		var ok bool
+		if useICMP {
+			rtt, _, ok = s.pingICMP(ctx, pinger, ip)
+		} else {
+			rtt, ok = s.pingHostFast(ctx, ip)
+		}
+		if ok {
+			samples = append(samples, rtt)
+		}
+	}
+
+	return newLatencyStats(sent, samples)
+}
+
+// newLatencyStats computes min/avg/max/jitter over the answered probes
+func newLatencyStats(sent int, samples []time.Duration) *LatencyStats {
+	stats := &LatencyStats{Sent: sent, Received: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	stats.Min, stats.Max = samples[0], samples[0]
+	var total, variation time.Duration
+	for i, rtt := range samples {
+		total += rtt
+		stats.Min = min(stats.Min, rtt)
+		stats.Max = max(stats.Max, rtt)
+		if i > 0 {
+			diff := rtt - samples[i-1]
+			if diff < 0 {
+				diff = -diff
+			}
+			variation += diff
+		}
+	}
+	stats.Avg = total / time.Duration(len(samples))
+	if len(samples) > 1 {
+		stats.Jitter = variation / time.Duration(len(samples)-1)
+	}
+	return stats
+}
@@ -0,0 +1,165 @@
+// =============================================================================
+// internal/network/pacing.go - Probe rate limiting and adaptive connect timeouts
+// =============================================================================
+package network
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Adaptive connect timeout tuning
+const (
+	adaptiveInitialTimeout = 250 * time.Millisecond // Used until enough RTTs have been measured
+	adaptiveMinTimeout     = 50 * time.Millisecond  // Floor, so fast networks still tolerate jitter
+	adaptiveMinSamples     = 10                     // RTTs measured before the timeout is tuned
+	adaptiveMaxSamples     = 256                    // Most recent RTTs the percentile is taken over
+	adaptiveRTTMultiplier  = 3                      // Timeout as a multiple of the p95 RTT
+)
+
+// PacingStats reports how fast a scan sent its probes and, with an adaptive
+// timeout, the TCP connect timeout it settled on
+type PacingStats struct {
+	Probes         int64         `json:"probes"`
+	Rate           float64       `json:"rate"`                      // Probes per second achieved
+	RateLimit      int           `json:"rate_limit,omitempty"`      // Configured probes per second; 0 is unlimited
+	ConnectTimeout time.Duration `json:"connect_timeout,omitempty"` // Final adaptive connect timeout
+}
+
+// probePacer spaces probes to stay under a rate shared by every host and port
+// goroutine, counts them, and tracks connect RTTs for the adaptive timeout.
+// Copies of a Scanner share one pacer, so the limit holds across all of them.
+type probePacer struct {
+	probes atomic.Int64
+
+	mu        sync.Mutex
+	rateLimit int
+	interval  time.Duration
+	next      time.Time // Earliest time the next probe may go out
+	adaptive  bool
+	rtts      []time.Duration
+	rttIndex  int
+	timeout   time.Duration // Adaptive timeout from the RTTs, 0 until tuned
+}
+
+// SetRateLimit caps how many probes per second the scanner sends across all
+// hosts and ports: TCP connects, UDP datagrams, ICMP echoes and ARP
+// solicitations alike. Probes are spaced evenly rather than sent in bursts.
+// Zero or less removes the limit.
+func (s *Scanner) SetRateLimit(pps int) {
+	s.pacer.mu.Lock()
+	defer s.pacer.mu.Unlock()
+	s.pacer.rateLimit = max(pps, 0)
+	s.pacer.interval = 0
+	if pps > 0 {
+		s.pacer.interval = time.Second / time.Duration(pps)
+	}
+}
+
+// SetAdaptiveTimeout makes TCP port probes start from a short connect timeout
+// and retune it from the round-trip times of successful connects, to three
+// times their 95th percentile. The SetTimeout value stays the ceiling.
+func (s *Scanner) SetAdaptiveTimeout(enabled bool) {
+	s.pacer.mu.Lock()
+	defer s.pacer.mu.Unlock()
+	s.pacer.adaptive = enabled
+}
+
+// Pacing reports the probes sent by the scanner so far, averaged over elapsed.
+// It returns nil unless a rate limit or adaptive timeout is configured.
+func (s *Scanner) Pacing(elapsed time.Duration) *PacingStats {
+	return s.pacingSince(0, elapsed)
+}
+
+// pacingSince reports the probes sent since the counter stood at before
+func (s *Scanner) pacingSince(before int64, elapsed time.Duration) *PacingStats {
+	s.pacer.mu.Lock()
+	defer s.pacer.mu.Unlock()
+	if s.pacer.rateLimit == 0 && !s.pacer.adaptive {
+		return nil
+	}
+
+	stats := &PacingStats{Probes: s.pacer.probes.Load() - before, RateLimit: s.pacer.rateLimit}
+	if elapsed > 0 {
+		stats.Rate = float64(stats.Probes) / elapsed.Seconds()
+	}
+	if s.pacer.adaptive {
+		stats.ConnectTimeout = s.pacer.connectTimeout(s.timeout)
+	}
+	return stats
+}
+
+// pace waits until the rate limit allows another probe, then counts it. It
+// returns ctx's error when ctx ends first.
+func (s *Scanner) pace(ctx context.Context) error {
+	p := s.pacer
+	p.mu.Lock()
+	interval := p.interval
+	now := time.Now()
+	slot := p.next
+	if slot.Before(now) {
+		slot = now
+	}
+	if interval > 0 {
+		p.next = slot.Add(interval)
+	}
+	p.mu.Unlock()
+
+	if delay := time.Until(slot); interval > 0 && delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	p.probes.Add(1)
+	return nil
+}
+
+// connectTimeout returns the timeout for a TCP port probe
+func (s *Scanner) connectTimeout() time.Duration {
+	s.pacer.mu.Lock()
+	defer s.pacer.mu.Unlock()
+	if !s.pacer.adaptive {
+		return s.timeout
+	}
+	return s.pacer.connectTimeout(s.timeout)
+}
+
+// observeConnect records the RTT of a successful connect for the adaptive timeout
+func (s *Scanner) observeConnect(rtt time.Duration) {
+	p := s.pacer
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.adaptive {
+		return
+	}
+
+	if len(p.rtts) < adaptiveMaxSamples {
+		p.rtts = append(p.rtts, rtt)
+	} else {
+		p.rtts[p.rttIndex] = rtt
+		p.rttIndex = (p.rttIndex + 1) % adaptiveMaxSamples
+	}
+	if len(p.rtts) >= adaptiveMinSamples {
+		sorted := slices.Clone(p.rtts)
+		slices.Sort(sorted)
+		p95 := sorted[(len(sorted)*95+99)/100-1]
+		p.timeout = adaptiveRTTMultiplier * p95
+	}
+}
+
+// connectTimeout returns the adaptive timeout within [adaptiveMinTimeout, ceiling];
+// the caller holds p.mu
+func (p *probePacer) connectTimeout(ceiling time.Duration) time.Duration {
+	timeout := p.timeout
+	if timeout == 0 {
+		timeout = adaptiveInitialTimeout
+	}
+	return min(max(timeout, adaptiveMinTimeout), ceiling)
+}
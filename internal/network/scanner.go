@@ -5,30 +5,58 @@ package network
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net"
-	"os"
+	"net/netip"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
+
+	"github.com/bryanCE/sysadmin/internal/dns"
 )
 
 // PortResult represents the result of scanning a single port
 type PortResult struct {
-	Port    int    `json:"port"`
-	Open    bool   `json:"open"`
-	Service string `json:"service"`
-	Banner  string `json:"banner"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	State    string `json:"state"`
+	Open     bool   `json:"open"`
+	Service  string `json:"service"`
+	Banner   string `json:"banner"`
+	Error    string `json:"error,omitempty"`
+
+	// Names on the certificate served by a TLS port, set when discovery
+	// enrichment is on
+	CertCN   string   `json:"cert_cn,omitempty"`
+	CertSANs []string `json:"cert_sans,omitempty"`
 }
 
-// HostResult represents the result of scanning a single host
+// HostResult represents the result of scanning a single host. For ping sweeps,
+// Method names the probe(s) that detected the host and Latency is the
+// round-trip time of the ICMP echo, or of the TCP connect when only TCP answered.
+// Stats is set when each host was probed more than once. For port scans of
+// named targets, Hostname is the name that resolved to IP, and Error explains
+// a target that could not be scanned. TTL and OSHint come from an ICMP echo
+// reply and are empty when the host did not answer ICMP. MAC and Vendor come
+// from the OS neighbor table and are only set for hosts on a local subnet.
 type HostResult struct {
-	IP      string        `json:"ip"`
-	Alive   bool          `json:"alive"`
-	Ports   []PortResult  `json:"ports"`
-	Latency time.Duration `json:"latency"`
+	IP       string        `json:"ip"`
+	Hostname string        `json:"hostname,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Alive    bool          `json:"alive"`
+	Ports    []PortResult  `json:"ports"`
+	Latency  time.Duration `json:"latency"`
+	Method   string        `json:"method,omitempty"`
+	Stats    *LatencyStats `json:"latency_stats,omitempty"`
+	TTL      int           `json:"ttl,omitempty"`
+	OSHint   string        `json:"os_hint,omitempty"`
+	MAC      string        `json:"mac,omitempty"`
+	Vendor   string        `json:"vendor,omitempty"`
 }
 
 // ScanResult represents the complete scan results
@@ -48,6 +76,25 @@ type ScanSummary struct {
 	OpenPorts    int `json:"open_ports"`
 	HostsScanned int `json:"hosts_scanned"`
 	PortsScanned int `json:"ports_scanned"`
+
+	// Interrupted is set when the scan was cancelled or hit its deadline, so
+	// only HostsScanned of TotalHosts addresses were probed
+	Interrupted bool `json:"interrupted,omitempty"`
+
+	// Generated counts every address the target expanded to and Excluded the
+	// ones the exclude list skipped; TotalHosts is Generated minus Excluded
+	Generated int `json:"generated,omitempty"`
+	Excluded  int `json:"excluded,omitempty"`
+
+	// Ping sweep only: the methods used and hosts that answered just one of them
+	PingMethod string `json:"ping_method,omitempty"`
+	PingCount  int    `json:"ping_count,omitempty"`
+	ICMPOnly   int    `json:"icmp_only,omitempty"`
+	TCPOnly    int    `json:"tcp_only,omitempty"`
+	ARPOnly    int    `json:"arp_only,omitempty"`
+
+	// Pacing is set when a rate limit or adaptive timeout was configured
+	Pacing *PacingStats `json:"pacing,omitempty"`
 }
 
 // Scanner provides network scanning capabilities
@@ -56,6 +103,31 @@ type Scanner struct {
 	maxHostConcurrency int
 	maxPortConcurrency int
 	batchSize          int
+	maxHosts           int
+	exclude            []string
+	showClosed         bool
+	showFiltered       bool
+	resolver           *dns.Resolver
+	nameserver         string
+	targetResolver     *dns.Resolver
+	targetNameserver   string
+	addressFamily      string
+	pingMethod         string
+	pingCount          int
+	progressCallback   func(ProgressEvent)
+	pacer              *probePacer
+}
+
+// ProgressEvent reports a completed batch of a scan. Done, Total and the found
+// counts are hosts for PingSweep and NetworkDiscovery and ports for ScanPorts.
+type ProgressEvent struct {
+	Batch      int           `json:"batch"`
+	Batches    int           `json:"batches"`
+	Done       int           `json:"done"`
+	Total      int           `json:"total"`
+	Found      int           `json:"found"`
+	TotalFound int           `json:"total_found"`
+	Elapsed    time.Duration `json:"elapsed"`
 }
 
 // NewScanner creates a new scanner with optimized default settings
@@ -65,6 +137,10 @@ func NewScanner() *Scanner {
 		maxHostConcurrency: 500,             // Increased for better performance
 		maxPortConcurrency: 5000,            // Significantly increased for port scanning
 		batchSize:          254,             // Process one subnet at a time
+		maxHosts:           DefaultMaxHosts,
+		pingMethod:         PingMethodAuto,
+		pingCount:          1,
+		pacer:              &probePacer{},
 	}
 }
 
@@ -84,76 +160,165 @@ func (s *Scanner) SetBatchSize(size int) {
 	s.batchSize = size
 }
 
-// Common services for port identification
-var commonServices = map[int]string{
-	21:   "FTP",
-	22:   "SSH",
-	23:   "Telnet",
-	25:   "SMTP",
-	53:   "DNS",
-	80:   "HTTP",
-	110:  "POP3",
-	135:  "RPC",
-	139:  "NetBIOS",
-	143:  "IMAP",
-	443:  "HTTPS",
-	445:  "SMB",
-	993:  "IMAPS",
-	995:  "POP3S",
-	1433: "MSSQL",
-	3306: "MySQL",
-	3389: "RDP",
-	5432: "PostgreSQL",
-	5900: "VNC",
-	6379: "Redis",
-	8080: "HTTP-Alt",
-	9200: "Elasticsearch",
+// SetMaxHosts sets how many addresses a scan target may expand to, guarding
+// against accidentally sweeping a huge range such as an IPv6 /64
+func (s *Scanner) SetMaxHosts(maxHosts int) {
+	s.maxHosts = maxHosts
+}
+
+// SetExclude sets addresses that network scans skip. Each entry is an address,
+// first-last range or CIDR prefix, or a comma-separated list of them; invalid
+// entries are reported when the scan starts.
+func (s *Scanner) SetExclude(exclude []string) {
+	s.exclude = exclude
+}
+
+// SetShowPortStates makes port scans report closed ports, and filtered or
+// failed ports, alongside the open ones
+func (s *Scanner) SetShowPortStates(closed, filtered bool) {
+	s.showClosed = closed
+	s.showFiltered = filtered
+}
+
+// SetPingMethod selects how PingSweep detects hosts: PingMethodICMP, PingMethodTCP,
+// PingMethodARP, or PingMethodAuto, which sends ICMP and TCP, adds ARP for
+// addresses on a local subnet and falls back to TCP alone when no ICMP socket
+// can be opened. PingMethodARP probes addresses outside local subnets with TCP.
+func (s *Scanner) SetPingMethod(method string) {
+	s.pingMethod = method
+}
+
+// SetPingCount sets how many probes PingSweep sends to each live host; above
+// one, HostResult.Stats records min/avg/max latency, jitter and loss
+func (s *Scanner) SetPingCount(count int) {
+	s.pingCount = count
+}
+
+// SetProgressCallback sets a function called after each batch of a scan completes.
+// The scanner prints nothing itself; display is left to the caller.
+func (s *Scanner) SetProgressCallback(callback func(ProgressEvent)) {
+	s.progressCallback = callback
+}
+
+// progressTracker accumulates batch results into ProgressEvents for the callback
+type progressTracker struct {
+	callback func(ProgressEvent)
+	start    time.Time
+	event    ProgressEvent
+}
+
+// newProgress starts tracking a scan of total items processed batchSize at a time
+func (s *Scanner) newProgress(total, batchSize int) *progressTracker {
+	return &progressTracker{
+		callback: s.progressCallback,
+		start:    time.Now(),
+		event:    ProgressEvent{Batches: (total + batchSize - 1) / batchSize, Total: total},
+	}
+}
+
+// batchDone records a finished batch of size items that found found, and reports it
+func (p *progressTracker) batchDone(size, found int) {
+	p.event.Batch++
+	p.event.Done += size
+	p.event.Found = found
+	p.event.TotalFound += found
+	p.event.Elapsed = time.Since(p.start)
+	if p.callback != nil {
+		p.callback(p.event)
+	}
 }
 
 // PingSweep performs a ping sweep on the given network with batch processing and progress feedback
 func (s *Scanner) PingSweep(ctx context.Context, network string) (*ScanResult, error) {
 	start := time.Now()
-	fmt.Printf("🔍 Batch scanning network: %s\n", network)
+	probesBefore := s.pacer.probes.Load()
 
-	ips, err := s.generateIPs(network)
+	ips, total, skipped, err := s.generateIPs(network)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate IPs: %w", err)
 	}
 
-	var allHosts []HostResult
-	var resultsMutex sync.Mutex
+	var pinger *icmpPinger
+	useTCP := true
+	useARP := false
+	switch s.pingMethod {
+	case PingMethodICMP:
+		pinger, err = newICMPPinger()
+		if err != nil {
+			return nil, err
+		}
+		useTCP = false
+	case PingMethodAuto, "":
+		pinger, err = newICMPPinger()
+		if err != nil {
+			slog.Debug("falling back to TCP ping", "error", err)
+		}
+		useARP = true
+	case PingMethodARP:
+		useARP = true
+	case PingMethodTCP:
+	default:
+		return nil, fmt.Errorf("unknown ping method %q (use icmp, tcp, arp or auto)", s.pingMethod)
+	}
+	if pinger != nil {
+		defer pinger.Close()
+	}
 
-	// Process IPs in batches with progress feedback
-	for i := 0; i < len(ips); i += s.batchSize {
-		end := i + s.batchSize
-		if end > len(ips) {
-			end = len(ips)
+	// ARP only reaches addresses on a local subnet and needs a readable
+	// neighbor table; without one every address is probed as before
+	var subnets []netip.Prefix
+	if useARP {
+		if _, err := readNeighbors(); err != nil {
+			slog.Debug("falling back from ARP", "error", err)
+		} else {
+			subnets = localSubnets()
 		}
+	}
+	arpOnly := s.pingMethod == PingMethodARP
+	var arpUsed, probedRemote bool
 
-		batch := ips[i:end]
-		batchStart := time.Now()
+	var allHosts []HostResult
+	var resultsMutex sync.Mutex
 
+	// Process IPs in batches with progress feedback
+	scanned := s.forEachBatch(ctx, ips, total, func(batch []string) int {
 		var wg sync.WaitGroup
 		results := make(chan HostResult, len(batch))
 		sem := make(chan struct{}, s.maxHostConcurrency)
 
+		// Solicit ARP for the local addresses first, so the OS resolves them
+		// while the other probes run
+		batchStart := time.Now()
+		local := make(map[string]bool)
+		var solicited []string
 		for _, ip := range batch {
+			if onLink(subnets, ip) {
+				local[ip] = true
+				if s.pace(ctx) != nil {
+					break
+				}
+				solicited = append(solicited, ip)
+				solicitARP(ip)
+			}
+		}
+		arpUsed = arpUsed || len(solicited) > 0
+		probedRemote = probedRemote || len(solicited) < len(batch)
+
+		for _, ip := range batch {
+			if arpOnly && local[ip] {
+				continue
+			}
 			wg.Add(1)
 			go func(ip string) {
 				defer wg.Done()
 				sem <- struct{}{}
 				defer func() { <-sem }()
+				if ctx.Err() != nil {
+					return
+				}
 
-				pingStart := time.Now()
-				alive := s.pingHostFast(ctx, ip)
-				latency := time.Since(pingStart)
-
-				if alive {
-					results <- HostResult{
-						IP:      ip,
-						Alive:   alive,
-						Latency: latency,
-					}
+				if host := s.probeHost(ctx, pinger, useTCP, ip); host.Alive {
+					results <- host
 				}
 			}(ip)
 		}
@@ -167,17 +332,17 @@ func (s *Scanner) PingSweep(ctx context.Context, network string) (*ScanResult, e
 		for result := range results {
 			batchHosts = append(batchHosts, result)
 		}
+		if len(solicited) > 0 {
+			waitARP(ctx, batchStart, s.timeout)
+			batchHosts = addARPHosts(batchHosts, solicited)
+		}
 
 		resultsMutex.Lock()
 		allHosts = append(allHosts, batchHosts...)
 		resultsMutex.Unlock()
 
-		batchElapsed := time.Since(batchStart)
-		fmt.Printf("📈 Batch %d/%d: %d hosts found in %v\n",
-			(i/s.batchSize)+1, (len(ips)+s.batchSize-1)/s.batchSize,
-			len(batchHosts), batchElapsed)
-		os.Stdout.Sync() // Force flush output
-	}
+		return len(batchHosts)
+	})
 
 	duration := time.Since(start)
 
@@ -187,9 +352,39 @@ func (s *Scanner) PingSweep(ctx context.Context, network string) (*ScanResult, e
 	})
 
 	summary := ScanSummary{
-		TotalHosts:   len(ips),
+		TotalHosts:   total,
 		LiveHosts:    len(allHosts),
-		HostsScanned: len(ips),
+		HostsScanned: scanned,
+		Interrupted:  ctx.Err() != nil,
+		Generated:    total + skipped,
+		Excluded:     skipped,
+		Pacing:       s.pacingSince(probesBefore, duration),
+	}
+	if s.pingCount > 1 {
+		summary.PingCount = s.pingCount
+	}
+	var methods []string
+	if pinger != nil {
+		methods = append(methods, PingMethodICMP)
+	}
+	if useTCP && (!arpOnly || probedRemote) {
+		methods = append(methods, PingMethodTCP)
+	}
+	if arpUsed {
+		methods = append(methods, PingMethodARP)
+	}
+	summary.PingMethod = strings.Join(methods, "+")
+	if len(methods) > 1 {
+		for _, host := range allHosts {
+			switch host.Method {
+			case PingMethodICMP:
+				summary.ICMPOnly++
+			case PingMethodTCP:
+				summary.TCPOnly++
+			case PingMethodARP:
+				summary.ARPOnly++
+			}
+		}
 	}
 
 	return &ScanResult{
@@ -198,28 +393,25 @@ func (s *Scanner) PingSweep(ctx context.Context, network string) (*ScanResult, e
 		StartTime: start,
 		Duration:  duration,
 		Summary:   summary,
-	}, nil
+	}, ctx.Err()
 }
 
 // ScanPorts scans specific ports on a target host with optimized batching and real-time progress
 func (s *Scanner) ScanPorts(ctx context.Context, target string, ports []int) (*HostResult, error) {
-	fmt.Printf("🔍 Scanning %s for %d ports...\n", target, len(ports))
-
 	const portBatchSize = 1000
 	var allResults []PortResult
 	var resultsMutex sync.Mutex
+	responded := false
+	progress := s.newProgress(len(ports), portBatchSize)
 
-	start := time.Now()
-
-	// Process ports in batches for better performance
-	for i := 0; i < len(ports); i += portBatchSize {
+	// Process ports in batches for better performance, stopping early if cancelled
+	for i := 0; i < len(ports) && ctx.Err() == nil; i += portBatchSize {
 		end := i + portBatchSize
 		if end > len(ports) {
 			end = len(ports)
 		}
 
 		batch := ports[i:end]
-		batchStart := time.Now()
 
 		var wg sync.WaitGroup
 		results := make(chan PortResult, len(batch))
@@ -231,9 +423,12 @@ func (s *Scanner) ScanPorts(ctx context.Context, target string, ports []int) (*H
 				defer wg.Done()
 				sem <- struct{}{}
 				defer func() { <-sem }()
+				if ctx.Err() != nil {
+					return
+				}
 
-				result := s.scanPortFast(target, port)
-				if result.Open {
+				result := s.scanPortFast(ctx, target, port)
+				if ctx.Err() == nil {
 					results <- result
 				}
 			}(port)
@@ -244,26 +439,28 @@ func (s *Scanner) ScanPorts(ctx context.Context, target string, ports []int) (*H
 			close(results)
 		}()
 
-		// Collect batch results
+		// Collect batch results; a refused connection still shows the host is up
 		var batchResults []PortResult
+		found := 0
 		for result := range results {
-			batchResults = append(batchResults, result)
+			if result.Open || result.State == PortStateClosed {
+				responded = true
+			}
+			if result.Open {
+				found++
+			}
+			if s.showPortState(result.State) {
+				batchResults = append(batchResults, result)
+			}
 		}
 
 		resultsMutex.Lock()
 		allResults = append(allResults, batchResults...)
 		resultsMutex.Unlock()
 
-		batchElapsed := time.Since(batchStart)
-		fmt.Printf("📈 Batch %d/%d: %d open ports found in %v\n",
-			(i/portBatchSize)+1, (len(ports)+portBatchSize-1)/portBatchSize,
-			len(batchResults), batchElapsed)
-		os.Stdout.Sync() // Force flush output
+		progress.batchDone(len(batch), found)
 	}
 
-	elapsed := time.Since(start)
-	fmt.Printf("✅ Scan completed in %v\n", elapsed)
-
 	// Sort ports
 	sort.Slice(allResults, func(i, j int) bool {
 		return allResults[i].Port < allResults[j].Port
@@ -271,36 +468,181 @@ func (s *Scanner) ScanPorts(ctx context.Context, target string, ports []int) (*H
 
 	return &HostResult{
 		IP:    target,
-		Alive: len(allResults) > 0,
+		Alive: responded,
 		Ports: allResults,
-	}, nil
+	}, ctx.Err()
 }
 
-// NetworkDiscovery performs network discovery with port scanning using optimized batching
-func (s *Scanner) NetworkDiscovery(ctx context.Context, network string, ports []int, suppressProgress bool) (*ScanResult, error) {
+// showPortState reports whether ScanPorts keeps a port in the given state
+func (s *Scanner) showPortState(state string) bool {
+	switch state {
+	case PortStateOpen:
+		return true
+	case PortStateClosed:
+		return s.showClosed
+	default:
+		return s.showFiltered
+	}
+}
+
+// hostScanWorkers is how many targets ScanHosts scans at once; they share the
+// scanner's port concurrency limit
+const hostScanWorkers = 8
+
+// ScanHosts resolves each target (a hostname or IP address) and scans ports on it
+// with a pool of workers. Results are in input order, one HostResult per address:
+// a hostname with several A or AAAA records yields one per address, each with
+// the hostname recorded. protocol selects TCP or UDP scanning. Progress is
+// reported once per target.
+func (s *Scanner) ScanHosts(ctx context.Context, targets []string, ports []int, protocol string) (*ScanResult, error) {
 	start := time.Now()
-	if !suppressProgress {
-		fmt.Printf("🔍 Network discovery on %s\n", network)
+	probesBefore := s.pacer.probes.Load()
+	workers := max(1, min(hostScanWorkers, len(targets)))
+
+	// Each worker scans with its share of the port concurrency and no per-batch progress
+	hostScanner := *s
+	hostScanner.maxPortConcurrency = max(1, s.maxPortConcurrency/workers)
+	hostScanner.progressCallback = nil
+	scan := hostScanner.ScanPorts
+	if protocol == ProtocolUDP {
+		scan = hostScanner.ScanUDPPorts
 	}
 
-	ips, err := s.generateIPs(network)
+	type indexedResult struct {
+		index int
+		hosts []HostResult
+	}
+	jobs := make(chan int)
+	results := make(chan indexedResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results <- indexedResult{index: index, hosts: hostScanner.scanTarget(ctx, targets[index], ports, scan)}
+			}
+		}()
+	}
+
+	// Send jobs until the targets run out or ctx is cancelled
+	go func() {
+		defer close(jobs)
+		for index := range targets {
+			select {
+			case jobs <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	byIndex := make([][]HostResult, len(targets))
+	progress := s.newProgress(len(targets), 1)
+	for result := range results {
+		byIndex[result.index] = result.hosts
+		open := 0
+		for _, host := range result.hosts {
+			open += countOpenPorts(host.Ports)
+		}
+		progress.batchDone(1, open)
+	}
+
+	// Targets never started because of cancellation are left out, counting as
+	// one host each; hostnames count once per address
+	var hosts []HostResult
+	summary := ScanSummary{TotalHosts: len(targets), TotalPorts: len(ports)}
+	for _, targetHosts := range byIndex {
+		summary.TotalHosts += max(len(targetHosts)-1, 0)
+		hosts = append(hosts, targetHosts...)
+	}
+	for _, host := range hosts {
+		summary.HostsScanned++
+		if host.Error == "" {
+			summary.PortsScanned += len(ports)
+		}
+		if host.Alive {
+			summary.LiveHosts++
+		}
+		summary.OpenPorts += countOpenPorts(host.Ports)
+	}
+	summary.Interrupted = ctx.Err() != nil
+	duration := time.Since(start)
+	summary.Pacing = s.pacingSince(probesBefore, duration)
+
+	return &ScanResult{
+		Network:   strings.Join(targets, ","),
+		Hosts:     hosts,
+		StartTime: start,
+		Duration:  duration,
+		Summary:   summary,
+	}, ctx.Err()
+}
+
+// countOpenPorts counts the ports in a result that answered
+func countOpenPorts(ports []PortResult) int {
+	open := 0
+	for _, port := range ports {
+		if port.Open {
+			open++
+		}
+	}
+	return open
+}
+
+// scanTarget resolves one target and scans each of its addresses, recording a
+// resolution failure as a single result
+func (s *Scanner) scanTarget(ctx context.Context, target string, ports []int, scan func(context.Context, string, []int) (*HostResult, error)) []HostResult {
+	ips, err := s.ResolveTargets(ctx, target)
+	if err != nil {
+		return []HostResult{{Hostname: target, Error: err.Error()}}
+	}
+
+	hosts := make([]HostResult, 0, len(ips))
+	for _, ip := range ips {
+		if ctx.Err() != nil && len(hosts) > 0 {
+			break
+		}
+		// The only error is cancellation, which the caller reports for the whole scan
+		result, _ := scan(ctx, ip, ports)
+		if ip != target {
+			result.Hostname = target
+		}
+		hosts = append(hosts, *result)
+	}
+	return hosts
+}
+
+// NetworkDiscovery performs network discovery with port scanning using optimized batching
+func (s *Scanner) NetworkDiscovery(ctx context.Context, network string, ports []int) (*ScanResult, error) {
+	start := time.Now()
+	probesBefore := s.pacer.probes.Load()
+
+	ips, total, skipped, err := s.generateIPs(network)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate IPs: %w", err)
 	}
 
+	// An ICMP echo to each live host supplies the TTL for the OS hint; without
+	// an ICMP socket hosts simply get no hint
+	pinger, err := newICMPPinger()
+	if err != nil {
+		slog.Debug("OS hints unavailable", "error", err)
+	} else {
+		defer pinger.Close()
+	}
+
 	var allHosts []HostResult
 	var resultsMutex sync.Mutex
 
 	// Process IPs in batches to manage memory and provide progress feedback
-	for i := 0; i < len(ips); i += s.batchSize {
-		end := i + s.batchSize
-		if end > len(ips) {
-			end = len(ips)
-		}
-
-		batch := ips[i:end]
-		batchStart := time.Now()
-
+	scanned := s.forEachBatch(ctx, ips, total, func(batch []string) int {
 		var wg sync.WaitGroup
 		results := make(chan HostResult, len(batch))
 		sem := make(chan struct{}, s.maxHostConcurrency)
@@ -311,12 +653,26 @@ func (s *Scanner) NetworkDiscovery(ctx context.Context, network string, ports []
 				defer wg.Done()
 				sem <- struct{}{}
 				defer func() { <-sem }()
+				if ctx.Err() != nil {
+					return
+				}
 
 				// Use the faster ping method first
-				if !s.pingHostFast(ctx, ip) {
+				if _, alive := s.pingHostFast(ctx, ip); !alive {
 					return
 				}
 
+				// Ping for the TTL while the ports are scanned, so the hint adds no delay
+				var ttl int
+				var pingWg sync.WaitGroup
+				if pinger != nil {
+					pingWg.Add(1)
+					go func() {
+						defer pingWg.Done()
+						_, ttl, _ = s.pingICMP(ctx, pinger, ip)
+					}()
+				}
+
 				// Scan ports concurrently for this host
 				var portWg sync.WaitGroup
 				portResults := make(chan PortResult, len(ports))
@@ -328,8 +684,11 @@ func (s *Scanner) NetworkDiscovery(ctx context.Context, network string, ports []
 						defer portWg.Done()
 						portSem <- struct{}{}
 						defer func() { <-portSem }()
+						if ctx.Err() != nil {
+							return
+						}
 
-						result := s.scanPortFast(ip, port)
+						result := s.scanPortFast(ctx, ip, port)
 						if result.Open {
 							portResults <- result
 						}
@@ -345,13 +704,20 @@ func (s *Scanner) NetworkDiscovery(ctx context.Context, network string, ports []
 				for result := range portResults {
 					openPorts = append(openPorts, result)
 				}
+				pingWg.Wait()
 
 				if len(openPorts) > 0 || len(ports) == 0 {
-					results <- HostResult{
-						IP:    ip,
-						Alive: true,
-						Ports: openPorts,
+					host := HostResult{
+						IP:     ip,
+						Alive:  true,
+						Ports:  openPorts,
+						TTL:    ttl,
+						OSHint: osHintFromTTL(ttl),
+					}
+					if s.resolver != nil {
+						s.enrichHost(ctx, &host)
 					}
+					results <- host
 				}
 			}(ip)
 		}
@@ -371,14 +737,11 @@ func (s *Scanner) NetworkDiscovery(ctx context.Context, network string, ports []
 		allHosts = append(allHosts, batchHosts...)
 		resultsMutex.Unlock()
 
-		batchElapsed := time.Since(batchStart)
-		if !suppressProgress {
-			fmt.Printf("📈 Batch %d/%d: %d hosts found in %v\n",
-				(i/s.batchSize)+1, (len(ips)+s.batchSize-1)/s.batchSize,
-				len(batchHosts), batchElapsed)
-			os.Stdout.Sync() // Force flush output
-		}
-	}
+		return len(batchHosts)
+	})
+
+	// Connecting to the hosts resolved the MACs of those on a local subnet
+	addNeighborMACs(allHosts)
 
 	duration := time.Since(start)
 
@@ -396,12 +759,16 @@ func (s *Scanner) NetworkDiscovery(ctx context.Context, network string, ports []
 	}
 
 	summary := ScanSummary{
-		TotalHosts:   len(ips),
+		TotalHosts:   total,
 		LiveHosts:    len(allHosts),
 		TotalPorts:   len(ports),
 		OpenPorts:    openPorts,
-		HostsScanned: len(ips),
+		HostsScanned: scanned,
+		Interrupted:  ctx.Err() != nil,
+		Generated:    total + skipped,
+		Excluded:     skipped,
 		PortsScanned: totalPorts,
+		Pacing:       s.pacingSince(probesBefore, duration),
 	}
 
 	return &ScanResult{
@@ -410,15 +777,15 @@ func (s *Scanner) NetworkDiscovery(ctx context.Context, network string, ports []
 		StartTime: start,
 		Duration:  duration,
 		Summary:   summary,
-	}, nil
+	}, ctx.Err()
 }
 
 // NetworkDiscoveryWorkerPool performs network discovery using worker pools for maximum performance
 func (s *Scanner) NetworkDiscoveryWorkerPool(ctx context.Context, network string, ports []int) (*ScanResult, error) {
 	start := time.Now()
-	fmt.Printf("🔍 Network discovery on %s (Worker Pool)\n", network)
+	probesBefore := s.pacer.probes.Load()
 
-	ips, err := s.generateIPs(network)
+	ips, total, skipped, err := s.generateIPs(network)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate IPs: %w", err)
 	}
@@ -438,7 +805,10 @@ func (s *Scanner) NetworkDiscoveryWorkerPool(ctx context.Context, network string
 		go func() {
 			defer wg.Done()
 			for ip := range jobs {
-				if !s.pingHostFast(ctx, ip) {
+				if ctx.Err() != nil {
+					continue
+				}
+				if _, alive := s.pingHostFast(ctx, ip); !alive {
 					continue
 				}
 
@@ -450,7 +820,7 @@ func (s *Scanner) NetworkDiscoveryWorkerPool(ctx context.Context, network string
 					portWg.Add(1)
 					go func(port int) {
 						defer portWg.Done()
-						result := s.scanPortFast(ip, port)
+						result := s.scanPortFast(ctx, ip, port)
 						if result.Open {
 							portChan <- result
 						}
@@ -477,12 +847,18 @@ func (s *Scanner) NetworkDiscoveryWorkerPool(ctx context.Context, network string
 		}()
 	}
 
-	// Send jobs
+	// Send jobs until the addresses run out or ctx is cancelled
+	scanned := 0
 	go func() {
-		for _, ip := range ips {
-			jobs <- ip
+		defer close(jobs)
+		for ip := range ips {
+			select {
+			case jobs <- ip:
+				scanned++
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(jobs)
 	}()
 
 	// Collect results
@@ -496,6 +872,9 @@ func (s *Scanner) NetworkDiscoveryWorkerPool(ctx context.Context, network string
 		hosts = append(hosts, result)
 	}
 
+	// Connecting to the hosts resolved the MACs of those on a local subnet
+	addNeighborMACs(hosts)
+
 	duration := time.Since(start)
 
 	sort.Slice(hosts, func(i, j int) bool {
@@ -511,12 +890,16 @@ func (s *Scanner) NetworkDiscoveryWorkerPool(ctx context.Context, network string
 	}
 
 	summary := ScanSummary{
-		TotalHosts:   len(ips),
+		TotalHosts:   total,
 		LiveHosts:    len(hosts),
 		TotalPorts:   len(ports),
 		OpenPorts:    openPorts,
-		HostsScanned: len(ips),
+		HostsScanned: scanned,
+		Interrupted:  ctx.Err() != nil,
+		Generated:    total + skipped,
+		Excluded:     skipped,
 		PortsScanned: totalPorts,
+		Pacing:       s.pacingSince(probesBefore, duration),
 	}
 
 	return &ScanResult{
@@ -525,39 +908,101 @@ func (s *Scanner) NetworkDiscoveryWorkerPool(ctx context.Context, network string
 		StartTime: start,
 		Duration:  duration,
 		Summary:   summary,
-	}, nil
+	}, ctx.Err()
+}
+
+// probeHost checks one host with the ICMP pinger (when non-nil) and/or TCP,
+// running both together so hosts seen by only one method can be told apart
+func (s *Scanner) probeHost(ctx context.Context, pinger *icmpPinger, useTCP bool, ip string) HostResult {
+	var icmpRTT, tcpRTT time.Duration
+	var icmpAlive, tcpAlive bool
+	var ttl int
+
+	var wg sync.WaitGroup
+	if pinger != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			icmpRTT, ttl, icmpAlive = s.pingICMP(ctx, pinger, ip)
+		}()
+	}
+	if useTCP {
+		tcpRTT, tcpAlive = s.pingHostFast(ctx, ip)
+	}
+	wg.Wait()
+
+	host := HostResult{IP: ip, Alive: icmpAlive || tcpAlive, TTL: ttl, OSHint: osHintFromTTL(ttl)}
+	switch {
+	case icmpAlive && tcpAlive:
+		host.Method = PingMethodICMP + "+" + PingMethodTCP
+		host.Latency = icmpRTT
+	case icmpAlive:
+		host.Method = PingMethodICMP
+		host.Latency = icmpRTT
+	case tcpAlive:
+		host.Method = PingMethodTCP
+		host.Latency = tcpRTT
+	}
+
+	if host.Alive && s.pingCount > 1 {
+		host.Stats = s.measureLatency(ctx, pinger, icmpAlive, ip, host.Latency)
+	}
+	return host
 }
 
-// pingHostFast performs a fast ping using TCP connect instead of ICMP
-func (s *Scanner) pingHostFast(ctx context.Context, ip string) bool {
+// pingHostFast performs a fast ping using TCP connect instead of ICMP and
+// returns the connect time of the first port that answered
+func (s *Scanner) pingHostFast(ctx context.Context, ip string) (time.Duration, bool) {
 	// Try multiple common ports quickly
 	ports := []int{80, 443, 22, 21, 23, 25, 53, 135, 139, 445}
 
-	pingCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	// Returning cancels the probes still waiting for their turn under the rate limit
+	pingCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// Use a channel to return as soon as any port responds
-	success := make(chan bool, len(ports))
+	success := make(chan time.Duration, len(ports))
+	var wg sync.WaitGroup
 
 	for _, port := range ports {
+		wg.Add(1)
 		go func(p int) {
-			address := fmt.Sprintf("%s:%d", ip, p)
-			conn, err := net.DialTimeout("tcp", address, 100*time.Millisecond)
+			defer wg.Done()
+			if s.pace(pingCtx) != nil {
+				return
+			}
+			address := net.JoinHostPort(ip, strconv.Itoa(p))
+			start := time.Now()
+			dialer := net.Dialer{Timeout: 100 * time.Millisecond}
+			conn, err := dialer.DialContext(pingCtx, "tcp", address)
+			slog.Debug("ping probe", "address", address, "reachable", err == nil)
 			if err == nil {
+				rtt := time.Since(start)
 				conn.Close()
 				select {
-				case success <- true:
+				case success <- rtt:
 				default:
 				}
 			}
 		}(port)
 	}
 
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
 	select {
-	case <-success:
-		return true
-	case <-pingCtx.Done():
-		return false
+	case rtt := <-success:
+		return rtt, true
+	case <-done:
+	}
+	select {
+	case rtt := <-success:
+		return rtt, true
+	default:
+		return 0, false
 	}
 }
 
@@ -582,25 +1027,51 @@ func (s *Scanner) pingHostFast(ctx context.Context, ip string) bool {
 // 	}
 // }
 
-// scanPortFast scans a single port with optimized timeout
-func (s *Scanner) scanPortFast(host string, port int) PortResult {
-	timeout := 1 * time.Second // Reduced from 3 seconds
-	target := fmt.Sprintf("%s:%d", host, port)
+// scanPortFast scans a single port with the scanner's connect timeout, or the
+// adaptive one when enabled. A refused connection (RST) is closed, a connect
+// that times out is filtered, and any other failure is an error with its
+// message kept.
+func (s *Scanner) scanPortFast(ctx context.Context, host string, port int) PortResult {
+	target := net.JoinHostPort(host, strconv.Itoa(port))
+	if err := s.pace(ctx); err != nil {
+		return PortResult{Port: port, Protocol: ProtocolTCP, Service: ServiceName(port, ProtocolTCP), State: PortStateError, Error: err.Error()}
+	}
 
-	conn, err := net.DialTimeout("tcp", target, timeout)
+	start := time.Now()
+	dialer := net.Dialer{Timeout: s.connectTimeout()}
+	conn, err := dialer.DialContext(ctx, "tcp", target)
 	if err != nil {
-		return PortResult{Port: port, Open: false}
+		result := PortResult{Port: port, Protocol: ProtocolTCP, Service: ServiceName(port, ProtocolTCP)}
+		var netErr net.Error
+		switch {
+		case errors.Is(err, syscall.ECONNREFUSED):
+			// A reset measures the round trip as well as an accept does
+			s.observeConnect(time.Since(start))
+			result.State = PortStateClosed
+		case errors.As(err, &netErr) && netErr.Timeout():
+			result.State = PortStateFiltered
+		default:
+			result.State = PortStateError
+			result.Error = err.Error()
+		}
+		slog.Debug("port probe", "address", target, "transport", "tcp", "state", result.State, "rtt", time.Since(start))
+		return result
 	}
 	defer conn.Close()
+	rtt := time.Since(start)
+	s.observeConnect(rtt)
+	slog.Debug("port probe", "address", target, "transport", "tcp", "state", PortStateOpen, "rtt", rtt)
 
-	service := commonServices[port]
+	service := ServiceName(port, ProtocolTCP)
 	banner := s.grabBannerFast(conn, port)
 
 	return PortResult{
-		Port:    port,
-		Open:    true,
-		Service: service,
-		Banner:  banner,
+		Port:     port,
+		Protocol: ProtocolTCP,
+		State:    PortStateOpen,
+		Open:     true,
+		Service:  service,
+		Banner:   banner,
 	}
 }
 
@@ -678,95 +1149,41 @@ func (s *Scanner) grabBannerFast(conn net.Conn, port int) string {
 	return banner
 }
 
-// generateIPs generates a list of IPs from a network CIDR
-func (s *Scanner) generateIPs(network string) ([]string, error) {
-	var ips []string
-
-	// Simple implementation for /24 networks
-	if strings.HasSuffix(network, "/24") {
-		base := strings.TrimSuffix(network, "/24")
-		baseIP := strings.Split(base, ".")
-		if len(baseIP) == 4 {
-			for i := 1; i < 255; i++ {
-				ip := fmt.Sprintf("%s.%s.%s.%d", baseIP[0], baseIP[1], baseIP[2], i)
-				ips = append(ips, ip)
-			}
-		}
-	} else {
-		// Try to parse as CIDR
-		_, ipNet, err := net.ParseCIDR(network)
-		if err != nil {
-			return nil, fmt.Errorf("invalid network format: %s", network)
-		}
-
-		// Generate IPs for the network
-		for ip := ipNet.IP.Mask(ipNet.Mask); ipNet.Contains(ip); s.incrementIP(ip) {
-			ips = append(ips, ip.String())
-		}
-	}
-
-	return ips, nil
-}
-
-// incrementIP increments an IP address
-func (s *Scanner) incrementIP(ip net.IP) {
-	for j := len(ip) - 1; j >= 0; j-- {
-		ip[j]++
-		if ip[j] > 0 {
-			break
-		}
-	}
-}
-
-// compareIPs compares two IP addresses for sorting
+// compareIPs compares two IP addresses for sorting, placing IPv4 before IPv6
 func (s *Scanner) compareIPs(ip1, ip2 string) bool {
-	parts1 := strings.Split(ip1, ".")
-	parts2 := strings.Split(ip2, ".")
-
-	for i := 0; i < 4; i++ {
-		var n1, n2 int
-		fmt.Sscanf(parts1[i], "%d", &n1)
-		fmt.Sscanf(parts2[i], "%d", &n2)
-		if n1 != n2 {
-			return n1 < n2
-		}
+	addr1, err1 := netip.ParseAddr(ip1)
+	addr2, err2 := netip.ParseAddr(ip2)
+	if err1 != nil || err2 != nil {
+		return ip1 < ip2
 	}
-	return false
+	return addr1.Less(addr2)
 }
 
-// ParsePortRange parses a port range string into a slice of ports
-func ParsePortRange(portRange string) ([]int, error) {
-	var ports []int
+// forEachBatch pulls addresses from ips into batches of the configured size and
+// hands each to process, which returns how many hosts it found, for progress
+// reporting. Only one batch is held in memory at a time. It stops at the first
+// batch boundary after ctx is cancelled and returns how many addresses were scanned.
+func (s *Scanner) forEachBatch(ctx context.Context, ips func(yield func(string) bool), total int, process func(batch []string) int) int {
+	progress := s.newProgress(total, s.batchSize)
+	batch := make([]string, 0, s.batchSize)
+
+	flush := func() {
+		found := process(batch)
+		progress.batchDone(len(batch), found)
+		batch = batch[:0]
+	}
 
-	if strings.Contains(portRange, "-") {
-		// Range format: 1-1000
-		parts := strings.Split(portRange, "-")
-		if len(parts) == 2 {
-			start, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
-			end, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
-			if err1 != nil || err2 != nil {
-				return nil, fmt.Errorf("invalid port range format")
-			}
-			if start > end || start < 1 || end > 65535 {
-				return nil, fmt.Errorf("invalid port range: ports must be between 1-65535 and start <= end")
-			}
-			for i := start; i <= end; i++ {
-				ports = append(ports, i)
-			}
+	for ip := range ips {
+		if ctx.Err() != nil {
+			return progress.event.Done
 		}
-	} else {
-		// Comma-separated format: 80,443,22
-		for _, portStr := range strings.Split(portRange, ",") {
-			port, err := strconv.Atoi(strings.TrimSpace(portStr))
-			if err != nil {
-				return nil, fmt.Errorf("invalid port: %s", portStr)
-			}
-			if port < 1 || port > 65535 {
-				return nil, fmt.Errorf("port out of range: %d (must be 1-65535)", port)
-			}
-			ports = append(ports, port)
+		batch = append(batch, ip)
+		if len(batch) == s.batchSize {
+			flush()
 		}
 	}
-
-	return ports, nil
+	if len(batch) > 0 && ctx.Err() == nil {
+		flush()
+	}
+	return progress.event.Done
 }
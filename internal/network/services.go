@@ -0,0 +1,155 @@
+// =============================================================================
+// internal/network/services.go - Service names and port presets from embedded data
+// =============================================================================
+package network
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/services.txt
+var servicesData string
+
+//go:embed data/top-ports.txt
+var topPortsData string
+
+// Port presets accepted by ParsePortRange
+const (
+	PortPresetTop100  = "top100"
+	PortPresetTop1000 = "top1000"
+	PortPresetAll     = "all"
+)
+
+// serviceKey identifies a service by port and protocol
+type serviceKey struct {
+	port     int
+	protocol string
+}
+
+// services maps ports to service names, parsed from servicesData on first use
+var services = sync.OnceValue(func() map[serviceKey]string {
+	names := make(map[serviceKey]string)
+	for _, line := range dataLines(servicesData) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		portText, protocol, _ := strings.Cut(fields[1], "/")
+		port, err := strconv.Atoi(portText)
+		if err != nil {
+			continue
+		}
+		names[serviceKey{port: port, protocol: protocol}] = fields[0]
+	}
+	return names
+})
+
+// topPorts lists TCP ports from most to least frequently open, parsed from
+// topPortsData on first use
+var topPorts = sync.OnceValue(func() []int {
+	var ports []int
+	for _, line := range dataLines(topPortsData) {
+		if port, err := strconv.Atoi(line); err == nil {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+})
+
+// dataLines returns the non-blank lines of an embedded data file without # comments
+func dataLines(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// ServiceName returns the registered service name for a port and protocol
+// (ProtocolTCP or ProtocolUDP, defaulting to TCP), or "" when there is none
+func ServiceName(port int, protocol string) string {
+	if protocol == "" {
+		protocol = ProtocolTCP
+	}
+	return services()[serviceKey{port: port, protocol: protocol}]
+}
+
+// TopPorts returns the n most frequently open TCP ports, most common first
+func TopPorts(n int) []int {
+	ports := topPorts()
+	if n > len(ports) {
+		n = len(ports)
+	}
+	return append([]int(nil), ports[:n]...)
+}
+
+// ParsePortRange parses a comma-separated list of ports, first-last ranges and
+// the presets top100, top1000 (the most frequently open TCP ports) and all
+// (1-65535), e.g. "top100,8443,9000-9100". Ports are returned in the order
+// given, with repeats dropped.
+func ParsePortRange(portRange string) ([]int, error) {
+	var ports []int
+	seen := make(map[int]bool)
+	add := func(port int) {
+		if !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+
+	for _, part := range strings.Split(portRange, ",") {
+		part = strings.TrimSpace(part)
+		switch strings.ToLower(part) {
+		case "":
+			continue
+		case PortPresetTop100:
+			for _, port := range TopPorts(100) {
+				add(port)
+			}
+			continue
+		case PortPresetTop1000:
+			for _, port := range TopPorts(1000) {
+				add(port)
+			}
+			continue
+		case PortPresetAll:
+			part = "1-65535"
+		}
+
+		if start, end, isRange := strings.Cut(part, "-"); isRange {
+			first, err1 := strconv.Atoi(strings.TrimSpace(start))
+			last, err2 := strconv.Atoi(strings.TrimSpace(end))
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid port range format: %s", part)
+			}
+			if first > last || first < 1 || last > 65535 {
+				return nil, fmt.Errorf("invalid port range: ports must be between 1-65535 and start <= end")
+			}
+			for port := first; port <= last; port++ {
+				add(port)
+			}
+			continue
+		}
+
+		port, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port: %s", part)
+		}
+		if port < 1 || port > 65535 {
+			return nil, fmt.Errorf("port out of range: %d (must be 1-65535)", port)
+		}
+		add(port)
+	}
+
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("no ports given")
+	}
+	return ports, nil
+}
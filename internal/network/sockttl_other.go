@@ -0,0 +1,14 @@
+// =============================================================================
+// internal/network/sockttl_other.go - Socket TTL fallback for other platforms
+// =============================================================================
+
+//go:build !unix && !windows
+
+package network
+
+import "errors"
+
+// setSocketTTL reports that TCP traceroute probes are unsupported here
+func setSocketTTL(fd uintptr, ttl int) error {
+	return errors.New("setting the socket TTL is not supported on this platform")
+}
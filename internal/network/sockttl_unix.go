@@ -0,0 +1,14 @@
+// =============================================================================
+// internal/network/sockttl_unix.go - Setting the IP TTL of a socket on Unix
+// =============================================================================
+
+//go:build unix
+
+package network
+
+import "syscall"
+
+// setSocketTTL sets the IPv4 TTL of the socket fd before it connects
+func setSocketTTL(fd uintptr, ttl int) error {
+	return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TTL, ttl)
+}
@@ -0,0 +1,14 @@
+// =============================================================================
+// internal/network/sockttl_windows.go - Setting the IP TTL of a socket on Windows
+// =============================================================================
+
+//go:build windows
+
+package network
+
+import "syscall"
+
+// setSocketTTL sets the IPv4 TTL of the socket fd before it connects
+func setSocketTTL(fd uintptr, ttl int) error {
+	return syscall.SetsockoptInt(syscall.Handle(fd), syscall.IPPROTO_IP, syscall.IP_TTL, ttl)
+}
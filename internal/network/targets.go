@@ -0,0 +1,359 @@
+// =============================================================================
+// internal/network/targets.go - Scan target parsing and address iteration
+// =============================================================================
+package network
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bryanCE/sysadmin/internal/dns"
+)
+
+// DefaultMaxHosts caps how many addresses a scan expands to unless raised with SetMaxHosts
+const DefaultMaxHosts = 65536
+
+// Address families hostname targets can be limited to with SetAddressFamily
+const (
+	AddressFamilyAll  = ""
+	AddressFamilyIPv4 = "ipv4"
+	AddressFamilyIPv6 = "ipv6"
+)
+
+// addrRange is an inclusive run of addresses of one family
+type addrRange struct {
+	first netip.Addr
+	last  netip.Addr
+}
+
+// generateIPs parses a comma-separated list of CIDR prefixes, first-last ranges
+// and single addresses, returning an iterator over the addresses, how many it
+// yields and how many were skipped by the exclude list. Addresses are produced
+// on demand so memory does not grow with the size of the network. IPv4
+// prefixes of /30 and larger skip their network and broadcast addresses; /31
+// yields both addresses and /32 the one.
+func (s *Scanner) generateIPs(network string) (func(yield func(string) bool), int, int, error) {
+	maxHosts := s.maxHosts
+	if maxHosts <= 0 {
+		maxHosts = DefaultMaxHosts
+	}
+
+	var ranges []addrRange
+	total := uint64(0)
+	for _, part := range strings.Split(network, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		r, err := parseAddrRange(part)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("invalid network format %q: %w", part, err)
+		}
+		size, ok := rangeSize(r)
+		if !ok || total+size > uint64(maxHosts) {
+			return nil, 0, 0, fmt.Errorf("%s expands to more than %d addresses (raise --max-hosts to scan it)", network, maxHosts)
+		}
+		total += size
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, 0, 0, fmt.Errorf("invalid network format: %s", network)
+	}
+
+	exclude, err := parseExcludes(s.exclude)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	each := func(yield func(string) bool) {
+		for _, r := range ranges {
+			for addr := r.first; ; addr = addr.Next() {
+				if !excluded(exclude, addr) && !yield(addr.String()) {
+					return
+				}
+				if addr == r.last {
+					break
+				}
+			}
+		}
+	}
+
+	// The total is capped by maxHosts, so counting the exclusions one
+	// address at a time stays cheap
+	skipped := 0
+	if len(exclude) > 0 {
+		for _, r := range ranges {
+			for addr := r.first; ; addr = addr.Next() {
+				if excluded(exclude, addr) {
+					skipped++
+				}
+				if addr == r.last {
+					break
+				}
+			}
+		}
+	}
+
+	return each, int(total) - skipped, skipped, nil
+}
+
+// CheckExcludes reports the first exclude entry that is not an address,
+// first-last range or CIDR prefix, so bad input fails before a scan starts
+func CheckExcludes(entries []string) error {
+	_, err := parseExcludes(entries)
+	return err
+}
+
+// parseExcludes parses exclude entries, each an address, first-last range or
+// CIDR prefix, or a comma-separated list of them. Prefixes cover every
+// address including the network and broadcast addresses.
+func parseExcludes(entries []string) ([]addrRange, error) {
+	var ranges []addrRange
+	for _, entry := range entries {
+		for _, part := range strings.Split(entry, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			if prefix, err := netip.ParsePrefix(part); err == nil {
+				prefix = prefix.Masked()
+				ranges = append(ranges, addrRange{first: prefix.Addr(), last: lastAddr(prefix)})
+				continue
+			}
+			r, err := parseAddrRange(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclude entry %q: %w", part, err)
+			}
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges, nil
+}
+
+// excluded reports whether addr falls in any of the exclude ranges
+func excluded(exclude []addrRange, addr netip.Addr) bool {
+	for _, r := range exclude {
+		if r.first.Is4() == addr.Is4() && !addr.Less(r.first) && !r.last.Less(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAddrRange parses one CIDR prefix, first-last range or single address
+func parseAddrRange(text string) (addrRange, error) {
+	switch {
+	case strings.Contains(text, "/"):
+		prefix, err := netip.ParsePrefix(text)
+		if err != nil {
+			return addrRange{}, fmt.Errorf("not a CIDR prefix")
+		}
+		prefix = prefix.Masked()
+		first := prefix.Addr()
+		last := lastAddr(prefix)
+		if first.Is4() && prefix.Bits() <= 30 {
+			first, last = first.Next(), last.Prev()
+		}
+		return addrRange{first: first, last: last}, nil
+
+	case strings.Contains(text, "-"):
+		start, end, _ := strings.Cut(text, "-")
+		first, err1 := netip.ParseAddr(strings.TrimSpace(start))
+		last, err2 := netip.ParseAddr(strings.TrimSpace(end))
+		if err1 != nil || err2 != nil {
+			return addrRange{}, fmt.Errorf("not an address range")
+		}
+		first, last = first.Unmap(), last.Unmap()
+		if first.Is4() != last.Is4() {
+			return addrRange{}, fmt.Errorf("range mixes IPv4 and IPv6")
+		}
+		if last.Less(first) {
+			return addrRange{}, fmt.Errorf("range ends before it starts")
+		}
+		return addrRange{first: first, last: last}, nil
+
+	default:
+		addr, err := netip.ParseAddr(text)
+		if err != nil {
+			return addrRange{}, fmt.Errorf("not an IP address, range or CIDR prefix")
+		}
+		addr = addr.Unmap()
+		return addrRange{first: addr, last: addr}, nil
+	}
+}
+
+// lastAddr returns the highest address in a masked prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 0x80 >> (bit % 8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// rangeSize counts the addresses in r, reporting false when the count does not fit in 63 bits
+func rangeSize(r addrRange) (uint64, bool) {
+	first, last := r.first.As16(), r.last.As16()
+	var hiFirst, loFirst, hiLast, loLast uint64
+	for i := 0; i < 8; i++ {
+		hiFirst = hiFirst<<8 | uint64(first[i])
+		hiLast = hiLast<<8 | uint64(last[i])
+		loFirst = loFirst<<8 | uint64(first[i+8])
+		loLast = loLast<<8 | uint64(last[i+8])
+	}
+	// The low halves may wrap when the range crosses into the next high half
+	if hiLast != hiFirst && !(hiLast == hiFirst+1 && loLast < loFirst) {
+		return 0, false
+	}
+	diff := loLast - loFirst
+	if diff >= 1<<63 {
+		return 0, false
+	}
+	return diff + 1, true
+}
+
+// ReadTargetsFromFile reads scan targets from a file (see ReadTargets); a filename of "-"
+// reads from stdin
+func ReadTargetsFromFile(filename string) ([]string, error) {
+	if filename == "-" {
+		return ReadTargets(os.Stdin)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadTargets(file)
+}
+
+// ReadTargets reads one hostname or IP address per line, skipping blank lines,
+// # comments and repeats
+func ReadTargets(reader io.Reader) ([]string, error) {
+	var targets []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		target := strings.TrimSpace(line)
+		if target == "" || seen[target] {
+			continue
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading targets: %w", err)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets found")
+	}
+
+	return targets, nil
+}
+
+// SetAddressFamily limits the addresses hostname targets expand to, to
+// AddressFamilyIPv4 or AddressFamilyIPv6; AddressFamilyAll keeps both
+func (s *Scanner) SetAddressFamily(family string) {
+	s.addressFamily = family
+}
+
+// SetTargetResolver makes ResolveTargets look hostnames up through resolver and
+// nameserver instead of the operating system's resolver. A nil resolver
+// restores the system lookup.
+func (s *Scanner) SetTargetResolver(resolver *dns.Resolver, nameserver string) {
+	s.targetResolver = resolver
+	s.targetNameserver = nameserver
+}
+
+// ResolveTargets returns every address to scan for a hostname or IP address.
+// A hostname expands to all of its A and AAAA records, IPv4 first, so each
+// server behind a round-robin or failover name gets checked; the address
+// family set with SetAddressFamily filters the list.
+func (s *Scanner) ResolveTargets(ctx context.Context, target string) ([]string, error) {
+	if addr, err := netip.ParseAddr(target); err == nil {
+		addr = addr.Unmap()
+		if !s.familyAllowed(addr) {
+			return nil, fmt.Errorf("%s is not an %s address", target, familyName(s.addressFamily))
+		}
+		return []string{addr.String()}, nil
+	}
+
+	addrs, err := s.lookupTarget(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s: %w", target, err)
+	}
+
+	var ips []netip.Addr
+	for _, addr := range addrs {
+		if addr = addr.Unmap(); s.familyAllowed(addr) {
+			ips = append(ips, addr)
+		}
+	}
+	if len(ips) == 0 {
+		if s.addressFamily != AddressFamilyAll {
+			return nil, fmt.Errorf("cannot resolve %s: no %s addresses", target, familyName(s.addressFamily))
+		}
+		return nil, fmt.Errorf("cannot resolve %s: no addresses", target)
+	}
+
+	// IPv4 addresses sort before IPv6 ones
+	slices.SortFunc(ips, netip.Addr.Compare)
+	ips = slices.Compact(ips)
+	resolved := make([]string, len(ips))
+	for i, ip := range ips {
+		resolved[i] = ip.String()
+	}
+	return resolved, nil
+}
+
+// lookupTarget resolves a hostname's A and AAAA records with the target
+// resolver, or the system resolver when none is set
+func (s *Scanner) lookupTarget(ctx context.Context, host string) ([]netip.Addr, error) {
+	if s.targetResolver == nil {
+		return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	}
+
+	ips, err := s.targetResolver.LookupIPs(ctx, host, s.targetNameserver)
+	if err != nil {
+		return nil, err
+	}
+	var addrs []netip.Addr
+	for _, ip := range ips {
+		if addr, err := netip.ParseAddr(ip); err == nil {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs, nil
+}
+
+// familyName spells an address family for messages
+func familyName(family string) string {
+	if family == AddressFamilyIPv6 {
+		return "IPv6"
+	}
+	return "IPv4"
+}
+
+// familyAllowed reports whether addr belongs to the configured address family
+func (s *Scanner) familyAllowed(addr netip.Addr) bool {
+	switch s.addressFamily {
+	case AddressFamilyIPv4:
+		return addr.Is4()
+	case AddressFamilyIPv6:
+		return addr.Is6()
+	}
+	return true
+}
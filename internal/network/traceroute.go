@@ -0,0 +1,382 @@
+// =============================================================================
+// internal/network/traceroute.go - TTL-stepped path tracing with per-hop latency
+// =============================================================================
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// Traceroute probe methods. With ICMP capture, routers are identified from
+// their time-exceeded replies; TCP connect timing only learns at which TTL a
+// hop answered and when the destination was reached.
+const (
+	TraceMethodICMP       = "icmp"
+	TraceMethodTCPConnect = "tcp-connect"
+)
+
+// Traceroute defaults
+const (
+	DefaultMaxHops        = 30
+	DefaultTraceQueries   = 3
+	DefaultTraceUDPPort   = 33434 // First port of the classic traceroute range, normally closed
+	DefaultTraceTCPPort   = 80
+	traceUnreachableGrace = 50 * time.Millisecond // Wait for the ICMP error after a connect fails with it
+)
+
+// TracerouteOptions configures a traceroute. Zero values pick the defaults:
+// UDP probes, DefaultTraceUDPPort or DefaultTraceTCPPort, DefaultMaxHops and
+// DefaultTraceQueries.
+type TracerouteOptions struct {
+	Protocol    string // ProtocolUDP or ProtocolTCP
+	Port        int
+	MaxHops     int
+	Queries     int                 // Probes sent per hop
+	SkipLookups bool                // Don't look up hop PTR names
+	OnHop       func(TracerouteHop) // Called as each hop completes, to show progress
+}
+
+// TracerouteProbe is one probe sent to a hop; IP is empty when the responder
+// is unknown, which is always the case with TCP connect timing
+type TracerouteProbe struct {
+	IP       string        `json:"ip,omitempty"`
+	RTT      time.Duration `json:"rtt,omitempty"`
+	Answered bool          `json:"answered"`
+}
+
+// TracerouteHop lists the probes sent with one TTL. IP and Hostname are those
+// of the first responder; a hop whose probes all went unanswered has neither.
+type TracerouteHop struct {
+	TTL      int               `json:"ttl"`
+	IP       string            `json:"ip,omitempty"`
+	Hostname string            `json:"hostname,omitempty"`
+	Probes   []TracerouteProbe `json:"probes"`
+	Reached  bool              `json:"reached,omitempty"` // The destination itself answered
+}
+
+// TracerouteResult is the path to a target, one hop per TTL up to the one
+// where the destination answered, or MaxHops when it never did
+type TracerouteResult struct {
+	Target    string          `json:"target"`
+	IP        string          `json:"ip"`
+	Protocol  string          `json:"protocol"`
+	Port      int             `json:"port"`
+	Method    string          `json:"method"` // TraceMethodICMP or TraceMethodTCPConnect
+	MaxHops   int             `json:"max_hops"`
+	Hops      []TracerouteHop `json:"hops"`
+	Reached   bool            `json:"reached"`
+	StartTime time.Time       `json:"start_time"`
+	Duration  time.Duration   `json:"duration"`
+}
+
+// traceReply is an ICMP error quoting one of our probes
+type traceReply struct {
+	from     string
+	dstPort  int
+	srcPort  int
+	protocol int
+	exceeded bool // Time exceeded from a router, rather than unreachable
+	received time.Time
+}
+
+// Traceroute sends probes to target with TTLs counting up from 1 until the
+// destination answers or opts.MaxHops is reached, each probe waiting up to the
+// scanner's timeout. Unanswered hops are recorded and the trace goes on.
+//
+// With a raw ICMP socket (root or CAP_NET_RAW), UDP datagrams or TCP connects
+// are sent and each hop is identified by its ICMP time-exceeded reply.
+// Without one, TCP connects are timed per TTL: a connect that succeeds or is
+// refused reached the destination, and one aborted by an ICMP error tells
+// that a hop answered but not which. Only IPv4 targets are supported.
+func (s *Scanner) Traceroute(ctx context.Context, target string, opts TracerouteOptions) (*TracerouteResult, error) {
+	if opts.Protocol == "" {
+		opts.Protocol = ProtocolUDP
+	}
+	if opts.Protocol != ProtocolUDP && opts.Protocol != ProtocolTCP {
+		return nil, fmt.Errorf("unsupported traceroute protocol %q (use udp or tcp)", opts.Protocol)
+	}
+	if opts.MaxHops <= 0 {
+		opts.MaxHops = DefaultMaxHops
+	}
+	if opts.Queries <= 0 {
+		opts.Queries = DefaultTraceQueries
+	}
+
+	ips, err := s.ResolveTargets(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	ip := ""
+	for _, candidate := range ips {
+		if addr, err := netip.ParseAddr(candidate); err == nil && addr.Is4() {
+			ip = candidate
+			break
+		}
+	}
+	if ip == "" {
+		return nil, fmt.Errorf("traceroute supports IPv4 only, and %s has no IPv4 address", target)
+	}
+
+	method := TraceMethodICMP
+	listener, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		slog.Debug("raw ICMP socket unavailable, timing TCP connects", "error", err)
+		method = TraceMethodTCPConnect
+		opts.Protocol = ProtocolTCP
+	}
+	if opts.Port <= 0 {
+		opts.Port = DefaultTraceUDPPort
+		if opts.Protocol == ProtocolTCP {
+			opts.Port = DefaultTraceTCPPort
+		}
+	}
+
+	result := &TracerouteResult{
+		Target:    target,
+		IP:        ip,
+		Protocol:  opts.Protocol,
+		Port:      opts.Port,
+		Method:    method,
+		MaxHops:   opts.MaxHops,
+		Hops:      []TracerouteHop{},
+		StartTime: time.Now(),
+	}
+
+	var replies chan traceReply
+	if listener != nil {
+		defer listener.Close()
+		replies = make(chan traceReply, 16)
+		go readTraceReplies(listener, ip, replies)
+	}
+
+	for ttl := 1; ttl <= opts.MaxHops && ctx.Err() == nil; ttl++ {
+		hop := TracerouteHop{TTL: ttl}
+		for range opts.Queries {
+			if s.pace(ctx) != nil {
+				break
+			}
+			probe, reached := s.traceProbe(ctx, ip, ttl, opts, replies)
+			if probe.Answered && hop.IP == "" {
+				hop.IP = probe.IP
+			}
+			hop.Reached = hop.Reached || reached
+			hop.Probes = append(hop.Probes, probe)
+		}
+		if hop.IP != "" && !opts.SkipLookups {
+			hop.Hostname = s.traceHostname(ctx, hop.IP)
+		}
+
+		result.Hops = append(result.Hops, hop)
+		if opts.OnHop != nil {
+			opts.OnHop(hop)
+		}
+		if hop.Reached {
+			result.Reached = true
+			break
+		}
+	}
+
+	result.Duration = time.Since(result.StartTime)
+	return result, nil
+}
+
+// traceProbe sends one probe with the given TTL and waits for its answer,
+// reporting whether it came from the destination
+func (s *Scanner) traceProbe(ctx context.Context, ip string, ttl int, opts TracerouteOptions, replies <-chan traceReply) (TracerouteProbe, bool) {
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	var probe TracerouteProbe
+	var reached bool
+	if opts.Protocol == ProtocolUDP {
+		probe, reached = s.traceUDP(ctx, ip, ttl, opts.Port, replies, timer)
+	} else {
+		probe, reached = s.traceTCP(ctx, ip, ttl, opts.Port, replies, timer)
+	}
+	slog.Debug("traceroute probe", "address", ip, "ttl", ttl, "transport", opts.Protocol, "answered", probe.Answered, "from", probe.IP, "rtt", probe.RTT, "reached", reached)
+	return probe, reached
+}
+
+// traceUDP sends a datagram from its own socket, so the ICMP reply quoting
+// the socket's port belongs to this probe. Port unreachable from the target
+// means the datagram arrived.
+func (s *Scanner) traceUDP(ctx context.Context, ip string, ttl, port int, replies <-chan traceReply, timer *time.Timer) (TracerouteProbe, bool) {
+	conn, err := net.ListenPacket("udp4", "0.0.0.0:0")
+	if err != nil {
+		return TracerouteProbe{}, false
+	}
+	defer conn.Close()
+	if err := ipv4.NewPacketConn(conn).SetTTL(ttl); err != nil {
+		return TracerouteProbe{}, false
+	}
+	srcPort := conn.LocalAddr().(*net.UDPAddr).Port
+
+	start := time.Now()
+	if _, err := conn.WriteTo([]byte("systool"), &net.UDPAddr{IP: net.ParseIP(ip), Port: port}); err != nil {
+		return TracerouteProbe{}, false
+	}
+
+	for {
+		select {
+		case reply := <-replies:
+			if reply.protocol != syscall.IPPROTO_UDP || reply.srcPort != srcPort {
+				continue
+			}
+			probe := TracerouteProbe{IP: reply.from, RTT: reply.received.Sub(start), Answered: true}
+			return probe, !reply.exceeded && reply.from == ip
+		case <-timer.C:
+			return TracerouteProbe{}, false
+		case <-ctx.Done():
+			return TracerouteProbe{}, false
+		}
+	}
+}
+
+// traceTCP connects with the TTL set on the socket before the SYN goes out.
+// A connect that succeeds or is refused came back from the target; otherwise
+// the hop is named by the ICMP reply quoting the target port, when replies
+// are captured. Without capture, a connect aborted by an ICMP error still
+// shows that a hop answered.
+func (s *Scanner) traceTCP(ctx context.Context, ip string, ttl, port int, replies <-chan traceReply, timer *time.Timer) (TracerouteProbe, bool) {
+	dialCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type dialResult struct {
+		err error
+		rtt time.Duration
+	}
+	dialed := make(chan dialResult, 1)
+
+	start := time.Now()
+	go func() {
+		dialer := net.Dialer{
+			Control: func(network, address string, c syscall.RawConn) error {
+				var sockErr error
+				if err := c.Control(func(fd uintptr) { sockErr = setSocketTTL(fd, ttl) }); err != nil {
+					return err
+				}
+				return sockErr
+			},
+		}
+		conn, err := dialer.DialContext(dialCtx, "tcp4", net.JoinHostPort(ip, strconv.Itoa(port)))
+		if err == nil {
+			conn.Close()
+		}
+		dialed <- dialResult{err: err, rtt: time.Since(start)}
+	}()
+
+	var grace <-chan time.Time
+	var unanswered TracerouteProbe
+	for {
+		select {
+		case reply := <-replies:
+			if reply.protocol != syscall.IPPROTO_TCP || reply.dstPort != port {
+				continue
+			}
+			probe := TracerouteProbe{IP: reply.from, RTT: reply.received.Sub(start), Answered: true}
+			return probe, !reply.exceeded && reply.from == ip
+		case result := <-dialed:
+			if result.err == nil || errors.Is(result.err, syscall.ECONNREFUSED) {
+				return TracerouteProbe{IP: ip, RTT: result.rtt, Answered: true}, true
+			}
+			if !errors.Is(result.err, syscall.EHOSTUNREACH) && !errors.Is(result.err, syscall.ENETUNREACH) {
+				return TracerouteProbe{}, false
+			}
+			// An ICMP error aborted the connect; the captured reply names its sender
+			unanswered = TracerouteProbe{RTT: result.rtt, Answered: true}
+			if replies == nil {
+				return unanswered, false
+			}
+			grace = time.After(traceUnreachableGrace)
+		case <-grace:
+			return unanswered, false
+		case <-timer.C:
+			return unanswered, false
+		case <-ctx.Done():
+			return TracerouteProbe{}, false
+		}
+	}
+}
+
+// readTraceReplies passes the ICMP time-exceeded and destination-unreachable
+// messages quoting a packet sent to ip to replies, until conn is closed.
+// Replies nobody is waiting for are dropped.
+func readTraceReplies(conn *icmp.PacketConn, ip string, replies chan<- traceReply) {
+	buffer := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		received := time.Now()
+
+		msg, err := icmp.ParseMessage(icmpProtocolNumber, buffer[:n])
+		if err != nil {
+			continue
+		}
+		var quoted []byte
+		switch body := msg.Body.(type) {
+		case *icmp.TimeExceeded:
+			quoted = body.Data
+		case *icmp.DstUnreach:
+			quoted = body.Data
+		default:
+			continue
+		}
+
+		// The quote holds the probe's IP header and at least the first 8
+		// bytes of its transport header, which start with both ports
+		header, err := ipv4.ParseHeader(quoted)
+		if err != nil || header.Dst.String() != ip || len(quoted) < header.Len+4 {
+			continue
+		}
+		transport := quoted[header.Len:]
+
+		from, _, _ := strings.Cut(peer.String(), "%")
+		reply := traceReply{
+			from:     from,
+			srcPort:  int(binary.BigEndian.Uint16(transport[0:2])),
+			dstPort:  int(binary.BigEndian.Uint16(transport[2:4])),
+			protocol: header.Protocol,
+			exceeded: msg.Type == ipv4.ICMPTypeTimeExceeded,
+			received: received,
+		}
+		select {
+		case replies <- reply:
+		default:
+		}
+	}
+}
+
+// traceHostname returns the PTR name of a hop through the target resolver,
+// or the system resolver when none is set; "" when the lookup fails
+func (s *Scanner) traceHostname(ctx context.Context, ip string) string {
+	ctx, cancel := context.WithTimeout(ctx, enrichTimeout)
+	defer cancel()
+
+	var names []string
+	var err error
+	if s.targetResolver != nil {
+		names, err = s.targetResolver.LookupPTR(ctx, ip, s.targetNameserver)
+	} else {
+		names, err = net.DefaultResolver.LookupAddr(ctx, ip)
+	}
+	if err != nil || len(names) == 0 {
+		slog.Debug("reverse lookup failed", "address", ip, "error", err)
+		return ""
+	}
+	return strings.TrimSuffix(names[0], ".")
+}
@@ -0,0 +1,178 @@
+// =============================================================================
+// internal/network/udp.go - UDP port scanning with protocol probes
+// =============================================================================
+package network
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Port protocols
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
+// Port states. A TCP port that refuses the connection is closed and one that
+// never answers is filtered; other connect failures are reported as error. A
+// UDP port that neither answers nor refuses is reported as open|filtered
+// because silence cannot tell an idle service from a firewall.
+const (
+	PortStateOpen         = "open"
+	PortStateOpenFiltered = "open|filtered"
+	PortStateClosed       = "closed"
+	PortStateFiltered     = "filtered"
+	PortStateError        = "error"
+)
+
+// udpProbes holds payloads that make well-known UDP services answer; every
+// other port gets an empty datagram
+var udpProbes = map[int][]byte{
+	// DNS: standard query for the root NS set
+	53: {
+		0x53, 0x59, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x02, 0x00, 0x01,
+	},
+	// NTP: version 3 client request
+	123: append([]byte{0x1b}, make([]byte, 47)...),
+	// NetBIOS: node status request for the wildcard name
+	137: netbiosStatusProbe(),
+	// SNMP: v1 get-request for sysDescr.0 with community "public"
+	161: {
+		0x30, 0x29, 0x02, 0x01, 0x00, 0x04, 0x06, 'p', 'u', 'b', 'l', 'i', 'c',
+		0xa0, 0x1c, 0x02, 0x04, 0x00, 0x00, 0x00, 0x01, 0x02, 0x01, 0x00,
+		0x02, 0x01, 0x00, 0x30, 0x0e, 0x30, 0x0c, 0x06, 0x08, 0x2b, 0x06,
+		0x01, 0x02, 0x01, 0x01, 0x01, 0x00, 0x05, 0x00,
+	},
+}
+
+// netbiosStatusProbe builds an NBSTAT query for "*" using first-level name encoding
+func netbiosStatusProbe() []byte {
+	probe := []byte{0x53, 0x59, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20}
+	probe = append(probe, "CK"+strings.Repeat("AA", 15)...)
+	return append(probe, 0x00, 0x00, 0x21, 0x00, 0x01)
+}
+
+// ScanUDPPorts scans UDP ports on a target host. Ports that answer are open,
+// ports that trigger an ICMP port-unreachable are closed and left out unless
+// closed ports are shown, and silent ports are reported as open|filtered.
+func (s *Scanner) ScanUDPPorts(ctx context.Context, target string, ports []int) (*HostResult, error) {
+	const portBatchSize = 1000
+	var allResults []PortResult
+	responded := false
+	progress := s.newProgress(len(ports), portBatchSize)
+
+	for i := 0; i < len(ports) && ctx.Err() == nil; i += portBatchSize {
+		end := i + portBatchSize
+		if end > len(ports) {
+			end = len(ports)
+		}
+
+		batch := ports[i:end]
+
+		var wg sync.WaitGroup
+		results := make(chan PortResult, len(batch))
+		sem := make(chan struct{}, s.maxPortConcurrency)
+
+		for _, port := range batch {
+			wg.Add(1)
+			go func(port int) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				if ctx.Err() != nil {
+					return
+				}
+
+				results <- s.scanUDPPort(ctx, target, port)
+			}(port)
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		found := 0
+		for result := range results {
+			if result.State != PortStateOpenFiltered {
+				responded = true
+			}
+			if result.State == PortStateClosed && !s.showClosed {
+				continue
+			}
+			if result.Open {
+				found++
+			}
+			allResults = append(allResults, result)
+		}
+
+		progress.batchDone(len(batch), found)
+	}
+
+	sort.Slice(allResults, func(i, j int) bool {
+		return allResults[i].Port < allResults[j].Port
+	})
+
+	return &HostResult{
+		IP:    target,
+		Alive: responded,
+		Ports: allResults,
+	}, ctx.Err()
+}
+
+// scanUDPPort sends one probe and classifies the port from the reply
+func (s *Scanner) scanUDPPort(ctx context.Context, host string, port int) PortResult {
+	target := net.JoinHostPort(host, strconv.Itoa(port))
+	result := PortResult{Port: port, Protocol: ProtocolUDP, Service: ServiceName(port, ProtocolUDP)}
+	if err := s.pace(ctx); err != nil {
+		result.State = PortStateError
+		result.Error = err.Error()
+		return result
+	}
+
+	start := time.Now()
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "udp", target)
+	if err != nil {
+		result.State = PortStateClosed
+		return result
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	// A connected UDP socket surfaces ICMP port-unreachable as ECONNREFUSED
+	// on the read following the probe
+	buffer := make([]byte, 512)
+	if _, err = conn.Write(udpProbes[port]); err == nil {
+		_, err = conn.Read(buffer)
+	}
+
+	var netErr net.Error
+	switch {
+	case err == nil:
+		result.State = PortStateOpen
+		result.Open = true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		result.State = PortStateOpenFiltered
+	default:
+		// Port unreachable (ECONNREFUSED) or another ICMP error
+		result.State = PortStateClosed
+	}
+
+	slog.Debug("port probe", "address", target, "transport", "udp", "state", result.State, "rtt", time.Since(start))
+	return result
+}
@@ -9,12 +9,15 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/bryanCE/sysadmin/internal/dns"
 	"github.com/bryanCE/sysadmin/internal/dnssec"
 	"github.com/bryanCE/sysadmin/internal/network"
 	"github.com/bryanCE/sysadmin/internal/ssl"
+	"github.com/bryanCE/sysadmin/internal/whois"
 )
 
 // OutputFormat represents the output format type
@@ -25,11 +28,15 @@ const (
 	FormatJSON  OutputFormat = "json"
 	FormatCSV   OutputFormat = "csv"
 	FormatXML   OutputFormat = "xml"
+	FormatJSONL OutputFormat = "jsonl" // One compact JSON document per line
 )
 
 // Formatter handles output formatting for different formats
 type Formatter struct {
-	format OutputFormat
+	format      OutputFormat
+	detail      bool
+	summaryOnly bool
+	sortKey     string
 }
 
 // NewFormatter creates a new formatter with the specified format
@@ -37,11 +44,25 @@ func NewFormatter(format OutputFormat) *Formatter {
 	return &Formatter{format: format}
 }
 
+// SetDetail includes per-domain results (records, agreement, issue counts) in bulk
+// summary tables and CSV; JSON and XML always carry the full per-domain data
+func (f *Formatter) SetDetail(detail bool) {
+	f.detail = detail
+}
+
+// SetSummaryOnly limits scan and bulk table output to the summary block and CSV
+// output to a single summary row; JSON, JSONL and XML always carry every result
+func (f *Formatter) SetSummaryOnly(summaryOnly bool) {
+	f.summaryOnly = summaryOnly
+}
+
 // FormatData is a generic method that handles all format types
 func (f *Formatter) FormatData(data interface{}, writer io.Writer, tableFormatter func(interface{}, io.Writer) error, csvFormatter func(interface{}, io.Writer) error) error {
 	switch f.format {
 	case FormatJSON:
 		return f.formatJSON(data, writer)
+	case FormatJSONL:
+		return f.formatJSONL(data, writer)
 	case FormatCSV:
 		if csvFormatter != nil {
 			return csvFormatter(data, writer)
@@ -61,6 +82,14 @@ func (f *Formatter) FormatData(data interface{}, writer io.Writer, tableFormatte
 func (f *Formatter) formatJSON(data interface{}, writer io.Writer) error {
 	encoder := json.NewEncoder(writer)
 	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false) // Error messages often contain "->"
+	return encoder.Encode(data)
+}
+
+// Generic JSONL formatter, writing data as a single line
+func (f *Formatter) formatJSONL(data interface{}, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
 	return encoder.Encode(data)
 }
 
@@ -88,9 +117,28 @@ func (f *Formatter) createAndRenderTable(headers []string, rows [][]string, writ
 
 // DNS-specific formatting methods
 func (f *Formatter) FormatQueryResult(result *dns.DNSResult, writer io.Writer) error {
+	sortRecords(result.Records, f.sortKey)
 	return f.FormatData(result, writer, f.formatQueryResultTable, f.formatQueryResultCSV)
 }
 
+// FormatMultiQueryResult renders each record type's answer in turn; CSV output shares one
+// header across all types
+func (f *Formatter) FormatMultiQueryResult(result *dns.MultiQueryResult, writer io.Writer) error {
+	for _, typeResult := range result.Results {
+		sortRecords(typeResult.Records, f.sortKey)
+	}
+	return f.FormatData(result, writer, f.formatMultiQueryResultTable, f.formatMultiQueryResultCSV)
+}
+
+// FormatRecordsReport renders a records lookup as one section per record type that
+// answered; JSON and CSV match FormatMultiQueryResult
+func (f *Formatter) FormatRecordsReport(result *dns.MultiQueryResult, writer io.Writer) error {
+	for _, typeResult := range result.Results {
+		sortRecords(typeResult.Records, f.sortKey)
+	}
+	return f.FormatData(result, writer, f.formatRecordsReportTable, f.formatMultiQueryResultCSV)
+}
+
 func (f *Formatter) FormatPropagationResult(result *dns.PropagationResult, writer io.Writer) error {
 	return f.FormatData(result, writer, f.formatPropagationResultTable, f.formatPropagationResultCSV)
 }
@@ -99,33 +147,149 @@ func (f *Formatter) FormatConsistencyIssues(issues []dns.ConsistencyIssue, write
 	return f.FormatData(issues, writer, f.formatConsistencyIssuesTable, f.formatConsistencyIssuesCSV)
 }
 
+func (f *Formatter) FormatConsistencyReport(report *dns.ConsistencyReport, writer io.Writer) error {
+	return f.FormatData(report, writer, f.formatConsistencyReportTable, f.formatConsistencyReportCSV)
+}
+
+func (f *Formatter) FormatDiffResult(result *dns.DiffResult, writer io.Writer) error {
+	return f.FormatData(result, writer, f.formatDiffResultTable, f.formatDiffResultCSV)
+}
+
+func (f *Formatter) FormatReverseDNSResult(result *dns.ReverseDNSResult, writer io.Writer) error {
+	return f.FormatData(result, writer, f.formatReverseDNSResultTable, f.formatReverseDNSResultCSV)
+}
+
+func (f *Formatter) FormatSRVResult(result *dns.SRVResult, writer io.Writer) error {
+	return f.FormatData(result, writer, f.formatSRVResultTable, f.formatSRVResultCSV)
+}
+
 func (f *Formatter) FormatBulkResult(result *dns.BulkQueryResult, writer io.Writer) error {
 	return f.FormatData(result, writer, f.formatBulkResultTable, f.formatBulkResultCSV)
 }
 
 func (f *Formatter) FormatBulkSummary(summary *dns.BulkSummary, writer io.Writer) error {
+	sortBulkResults(summary.Results, f.sortKey)
+	if f.format == FormatJSONL {
+		return f.formatJSONL(bulkSummaryLine{
+			Type:         "summary",
+			TotalDomains: summary.TotalDomains,
+			Successful:   summary.Successful,
+			Failed:       summary.Failed,
+			Duration:     summary.Duration,
+			Duplicates:   summary.Duplicates,
+			Skipped:      summary.Skipped,
+			Queries:      summary.Queries,
+			QPS:          summary.QPS,
+			Concurrency:  summary.Concurrency,
+			TimedOut:     summary.TimedOut,
+			AutoRetried:  summary.AutoRetried,
+			Recovered:    summary.Recovered,
+			Stats:        summary.Stats,
+			ByRecordType: summary.ByRecordType,
+			Filter:       summary.Filter,
+		}, writer)
+	}
 	return f.FormatData(summary, writer, f.formatBulkSummaryTable, f.formatBulkSummaryCSV)
 }
 
+// bulkResultLine is one bulk result in a JSONL stream
+type bulkResultLine struct {
+	Type        string                 `json:"type"` // Always "result"
+	Domain      string                 `json:"domain"`
+	Success     bool                   `json:"success"`
+	Error       string                 `json:"error,omitempty"`
+	Duration    time.Duration          `json:"duration"`
+	TraceID     string                 `json:"trace_id,omitempty"`
+	Query       *dns.DNSResult         `json:"query,omitempty"`
+	Propagation *dns.PropagationResult `json:"propagation,omitempty"`
+	Consistency *dns.ConsistencyReport `json:"consistency,omitempty"`
+}
+
+// bulkSummaryLine closes a JSONL stream of bulk results
+type bulkSummaryLine struct {
+	Type         string                                  `json:"type"` // Always "summary"
+	TotalDomains int                                     `json:"total_domains"`
+	Successful   int                                     `json:"successful"`
+	Failed       int                                     `json:"failed"`
+	Duration     time.Duration                           `json:"duration"`
+	Duplicates   int                                     `json:"duplicates"`
+	Skipped      []dns.SkippedRow                        `json:"skipped,omitempty"`
+	Queries      int64                                   `json:"queries"`
+	QPS          float64                                 `json:"qps"`
+	Concurrency  int                                     `json:"concurrency"`
+	TimedOut     int                                     `json:"timed_out"`
+	AutoRetried  int                                     `json:"auto_retried"`
+	Recovered    int                                     `json:"recovered"`
+	Stats        *dns.BulkStats                          `json:"stats,omitempty"`
+	ByRecordType map[dns.DNSRecordType]dns.BulkTypeCount `json:"by_record_type,omitempty"`
+	Filter       *dns.BulkFilter                         `json:"filter,omitempty"`
+}
+
+// FormatBulkResultLine writes a single bulk result as one JSON line, for streaming with
+// BulkProcessor.SetResultCallback; FormatBulkSummary in JSONL format writes the closing line
+func (f *Formatter) FormatBulkResultLine(result *dns.BulkResult, writer io.Writer) error {
+	line := bulkResultLine{
+		Type:        "result",
+		Domain:      result.Domain,
+		Success:     result.Success,
+		Duration:    result.EndTime.Sub(result.StartTime),
+		TraceID:     result.TraceID,
+		Query:       result.Query,
+		Propagation: result.Propagation,
+		Consistency: result.Consistency,
+	}
+	if result.Error != nil {
+		line.Error = result.Error.Error()
+	}
+	return f.formatJSONL(line, writer)
+}
+
 // SSL-specific formatting methods
 func (f *Formatter) FormatCertInfo(info *ssl.CertInfo, writer io.Writer) error {
 	return f.FormatData(info, writer, f.formatCertInfoTable, f.formatCertInfoCSV)
 }
 
+func (f *Formatter) FormatMultiIPCertResult(result *ssl.MultiIPResult, writer io.Writer) error {
+	return f.FormatData(result, writer, f.formatMultiIPCertResultTable, f.formatMultiIPCertResultCSV)
+}
+
+func (f *Formatter) FormatCertComparison(comparison *ssl.CertComparison, writer io.Writer) error {
+	return f.FormatData(comparison, writer, f.formatCertComparisonTable, f.formatCertComparisonCSV)
+}
+
 // Network-specific formatting methods
 func (f *Formatter) FormatScanResult(result *network.ScanResult, writer io.Writer) error {
+	sortScanResult(result, f.sortKey)
 	return f.FormatData(result, writer, f.formatScanResultTable, f.formatScanResultCSV)
 }
 
 func (f *Formatter) FormatHostResult(result *network.HostResult, writer io.Writer) error {
+	sortPorts(result.Ports, f.sortKey)
 	return f.FormatData(result, writer, f.formatHostResultTable, f.formatHostResultCSV)
 }
 
+func (f *Formatter) FormatScanDiff(diff *network.ScanDiff, writer io.Writer) error {
+	return f.FormatData(diff, writer, f.formatScanDiffTable, f.formatScanDiffCSV)
+}
+
+func (f *Formatter) FormatScanHistory(report *network.ScanHistoryReport, writer io.Writer) error {
+	return f.FormatData(report, writer, f.formatScanHistoryTable, f.formatScanHistoryCSV)
+}
+
+func (f *Formatter) FormatTracerouteResult(result *network.TracerouteResult, writer io.Writer) error {
+	return f.FormatData(result, writer, f.formatTracerouteResultTable, f.formatTracerouteResultCSV)
+}
+
 // DNSSEC-specific formatting methods
 func (f *Formatter) FormatDNSSECResult(result *dnssec.ValidationResult, writer io.Writer) error {
 	return f.FormatData(result, writer, f.formatDNSSECResultTable, f.formatDNSSECResultCSV)
 }
 
+// WHOIS formatting methods
+func (f *Formatter) FormatWhoisResult(result *whois.Result, writer io.Writer) error {
+	return f.FormatData(result, writer, f.formatWhoisResultTable, f.formatWhoisResultCSV)
+}
+
 // Table formatting methods
 func (f *Formatter) formatQueryResultTable(data interface{}, writer io.Writer) error {
 	result := data.(*dns.DNSResult)
@@ -134,18 +298,32 @@ func (f *Formatter) formatQueryResultTable(data interface{}, writer io.Writer) e
 		return nil
 	}
 
-	fmt.Fprintf(writer, "🔍 DNS Query Results for %s (%s)\n", result.Query.Domain, result.Query.RecordType)
+	fmt.Fprintf(writer, "🔍 DNS Query Results for %s (%s)\n", dns.DisplayDomain(result.Query.Domain), result.Query.RecordType)
 	fmt.Fprintf(writer, "📡 Nameserver: %s\n", result.Nameserver)
+	if result.Query.ClientSubnet != "" {
+		fmt.Fprintf(writer, "🌐 Client subnet: %s\n", result.Query.ClientSubnet)
+	}
 	fmt.Fprintf(writer, "⏱️  Response time: %v\n", result.ResponseTime)
 	fmt.Fprintf(writer, "🕐 Queried at: %s\n\n", result.Timestamp.Format("2006-01-02 15:04:05"))
 
 	if len(result.Records) == 0 {
 		fmt.Fprintf(writer, "No records found.\n")
+		f.writeQueryTiming(result, writer)
 		return nil
 	}
 
+	if err := f.renderRecordsTable(result.Records, writer); err != nil {
+		return err
+	}
+
+	f.writeQueryTiming(result, writer)
+	return nil
+}
+
+// renderRecordsTable renders answer records as a Name/Type/Value/TTL/Priority table
+func (f *Formatter) renderRecordsTable(records []dns.DNSRecord, writer io.Writer) error {
 	var rows [][]string
-	for _, record := range result.Records {
+	for _, record := range records {
 		priority := ""
 		if record.Priority > 0 {
 			priority = fmt.Sprintf("%d", record.Priority)
@@ -163,9 +341,60 @@ func (f *Formatter) formatQueryResultTable(data interface{}, writer io.Writer) e
 	return f.createAndRenderTable([]string{"Name", "Type", "Value", "TTL", "Priority"}, rows, writer)
 }
 
+func (f *Formatter) formatRecordsReportTable(data interface{}, writer io.Writer) error {
+	result := data.(*dns.MultiQueryResult)
+	fmt.Fprintf(writer, "📋 DNS Records for %s\n", dns.DisplayDomain(result.Domain))
+	fmt.Fprintf(writer, "📡 Nameserver: %s\n", result.Nameserver)
+	fmt.Fprintf(writer, "🕐 Queried at: %s\n", result.Timestamp.Format("2006-01-02 15:04:05"))
+
+	var empty []string
+	for _, typed := range result.Results {
+		recordType := string(typed.Query.RecordType)
+		switch {
+		case typed.Error != nil:
+			fmt.Fprintf(writer, "\n❌ %s: %v\n", recordType, typed.Error)
+		case len(typed.Records) == 0:
+			empty = append(empty, recordType)
+		default:
+			fmt.Fprintf(writer, "\n── %s (%v) ──\n", recordType, typed.ResponseTime.Round(time.Microsecond))
+			if err := f.renderRecordsTable(typed.Records, writer); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(empty) > 0 {
+		fmt.Fprintf(writer, "\nNo records: %s\n", strings.Join(empty, ", "))
+	}
+	return nil
+}
+
+func (f *Formatter) formatMultiQueryResultTable(data interface{}, writer io.Writer) error {
+	result := data.(*dns.MultiQueryResult)
+	for i, typed := range result.Results {
+		if i > 0 {
+			fmt.Fprintln(writer)
+		}
+		if typed.Error != nil {
+			fmt.Fprintf(writer, "❌ %s query failed: %v\n", typed.Query.RecordType, typed.Error)
+			continue
+		}
+		if err := f.formatQueryResultTable(typed, writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeQueryTiming prints the connect/query breakdown beneath a query result
+func (f *Formatter) writeQueryTiming(result *dns.DNSResult, writer io.Writer) {
+	fmt.Fprintf(writer, "\n🔌 Connect: %v | 📨 Query: %v | 🔁 Attempts: %d\n",
+		result.ConnectTime.Round(time.Microsecond), result.QueryTime.Round(time.Microsecond), result.Attempts)
+}
+
 func (f *Formatter) formatPropagationResultTable(data interface{}, writer io.Writer) error {
 	result := data.(*dns.PropagationResult)
-	fmt.Fprintf(writer, "🌐 DNS Propagation Check for %s (%s)\n", result.Domain, result.RecordType)
+	fmt.Fprintf(writer, "🌐 DNS Propagation Check for %s (%s)\n", dns.DisplayDomain(result.Domain), result.RecordType)
 	fmt.Fprintf(writer, "📊 Checked %d servers, %d responded successfully\n", result.TotalServers, result.SuccessCount)
 
 	if result.Inconsistent {
@@ -181,6 +410,10 @@ func (f *Formatter) formatPropagationResultTable(data interface{}, writer io.Wri
 		return nil
 	}
 
+	if len(result.Locations) > 0 {
+		return f.formatPropagationByLocation(result, writer)
+	}
+
 	var rows [][]string
 	for nameserver, records := range result.Results {
 		status := "✅ OK"
@@ -203,6 +436,72 @@ func (f *Formatter) formatPropagationResultTable(data interface{}, writer io.Wri
 	return f.createAndRenderTable([]string{"Nameserver", "Status", "Records", "Values"}, rows, writer)
 }
 
+// formatPropagationByLocation renders a propagation table grouped by the servers' location
+// labels, listing labelled servers that returned nothing so regional gaps stand out
+func (f *Formatter) formatPropagationByLocation(result *dns.PropagationResult, writer io.Writer) error {
+	servers := make([]string, 0, len(result.Locations))
+	seen := make(map[string]bool)
+	for nameserver := range result.Locations {
+		servers = append(servers, nameserver)
+		seen[nameserver] = true
+	}
+	for nameserver := range result.Results {
+		if !seen[nameserver] {
+			servers = append(servers, nameserver)
+		}
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		a, b := result.Locations[servers[i]], result.Locations[servers[j]]
+		if a != b {
+			return a < b
+		}
+		return servers[i] < servers[j]
+	})
+
+	type locationCount struct{ answered, total int }
+	counts := make(map[string]*locationCount)
+	var labels []string
+	var rows [][]string
+	for _, nameserver := range servers {
+		location := result.Locations[nameserver]
+		if location == "" {
+			location = "-"
+		}
+		if counts[location] == nil {
+			counts[location] = &locationCount{}
+			labels = append(labels, location)
+		}
+		counts[location].total++
+
+		records, ok := result.Results[nameserver]
+		if !ok {
+			rows = append(rows, []string{location, f.getNameserverDisplayName(nameserver), "❌ No answer", "0", ""})
+			continue
+		}
+		counts[location].answered++
+
+		var values []string
+		for _, record := range records {
+			values = append(values, record.Value)
+		}
+		rows = append(rows, []string{
+			location,
+			f.getNameserverDisplayName(nameserver),
+			"✅ OK",
+			fmt.Sprintf("%d", len(records)),
+			truncateString(strings.Join(values, ", "), 50),
+		})
+	}
+
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = fmt.Sprintf("%s %d/%d", label, counts[label].answered, counts[label].total)
+	}
+	fmt.Fprintf(writer, "📍 By location: %s\n\n", strings.Join(parts, " | "))
+
+	return f.createAndRenderTable([]string{"Location", "Nameserver", "Status", "Records", "Values"}, rows, writer)
+}
+
 func (f *Formatter) formatConsistencyIssuesTable(data interface{}, writer io.Writer) error {
 	issues := data.([]dns.ConsistencyIssue)
 	if len(issues) == 0 {
@@ -212,7 +511,7 @@ func (f *Formatter) formatConsistencyIssuesTable(data interface{}, writer io.Wri
 
 	fmt.Fprintf(writer, "🔍 DNS Consistency Issues Found: %d\n\n", len(issues))
 
-	var rows [][]string
+	table := NewTable([]string{"Severity", "Type", "Domain", "Record", "Description"})
 	for _, issue := range issues {
 		severity := ""
 		switch issue.Severity {
@@ -222,18 +521,162 @@ func (f *Formatter) formatConsistencyIssuesTable(data interface{}, writer io.Wri
 			severity = "🟡 MEDIUM"
 		case "low":
 			severity = "🟢 LOW"
+		case "info":
+			severity = "🔵 INFO"
 		}
 
-		rows = append(rows, []string{
+		table.AddRow([]string{
 			severity,
 			issue.Type,
 			issue.Domain,
 			string(issue.RecordType),
 			truncateString(issue.Description, 50),
 		})
+		if issue.Remediation != "" {
+			table.AddDetail("💡 " + issue.Remediation)
+		}
+	}
+
+	return table.Render(writer)
+}
+
+func (f *Formatter) formatConsistencyReportTable(data interface{}, writer io.Writer) error {
+	report := data.(*dns.ConsistencyReport)
+	if err := f.formatConsistencyIssuesTable(report.Issues, writer); err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "\n🧪 Checks run: %s\n", strings.Join(report.Checks, ", "))
+	return nil
+}
+
+func (f *Formatter) formatDiffResultTable(data interface{}, writer io.Writer) error {
+	result := data.(*dns.DiffResult)
+	fmt.Fprintf(writer, "🔀 DNS Diff for %s\n", dns.DisplayDomain(result.Domain))
+	fmt.Fprintf(writer, "   A: %s\n", f.getNameserverDisplayName(result.ServerA))
+	fmt.Fprintf(writer, "   B: %s\n", f.getNameserverDisplayName(result.ServerB))
+	fmt.Fprintf(writer, "🕐 Checked at: %s\n\n", result.Timestamp.Format("2006-01-02 15:04:05"))
+
+	for _, queryErr := range result.Errors {
+		fmt.Fprintf(writer, "⚠️  %s\n", queryErr)
+	}
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(writer, "\n")
+	}
+
+	if len(result.Differences) == 0 {
+		if len(result.Errors) > 0 {
+			fmt.Fprintf(writer, "⚠️  No differences in the records that could be retrieved\n")
+		} else {
+			fmt.Fprintf(writer, "✅ No differences found\n")
+		}
+		return nil
+	}
+
+	var rows [][]string
+	for _, diff := range result.Differences {
+		var change string
+		switch diff.Change {
+		case dns.DiffAdded:
+			change = "➕ added"
+		case dns.DiffRemoved:
+			change = "➖ removed"
+		default:
+			change = "✏️  changed"
+		}
+
+		rows = append(rows, []string{
+			string(diff.RecordType),
+			change,
+			truncateString(diff.ValueA, 45),
+			truncateString(diff.ValueB, 45),
+		})
+	}
+
+	if err := f.createAndRenderTable([]string{"Type", "Change", "Server A", "Server B"}, rows, writer); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(writer, "\n❌ %d difference(s) found\n", len(result.Differences))
+	return nil
+}
+
+func (f *Formatter) formatReverseDNSResultTable(data interface{}, writer io.Writer) error {
+	result := data.(*dns.ReverseDNSResult)
+	fmt.Fprintf(writer, "🔁 Reverse DNS for %s\n", result.IP)
+	fmt.Fprintf(writer, "📛 PTR name: %s\n", result.ReverseName)
+	fmt.Fprintf(writer, "📡 Nameserver: %s\n", f.getNameserverDisplayName(result.Nameserver))
+	fmt.Fprintf(writer, "🕐 Checked at: %s\n\n", result.Timestamp.Format("2006-01-02 15:04:05"))
+
+	if len(result.PTRs) == 0 {
+		fmt.Fprintf(writer, "❌ No PTR record found\n")
+		return nil
+	}
+
+	var rows [][]string
+	for _, ptr := range result.PTRs {
+		status := "❌ MISMATCH"
+		forward := strings.Join(ptr.Addresses, ", ")
+		switch {
+		case ptr.Error != "":
+			status = "⚠️  ERROR"
+			forward = ptr.Error
+		case ptr.Confirmed:
+			status = "✅ MATCH"
+		case len(ptr.Addresses) == 0:
+			forward = "(no A/AAAA records)"
+		}
+
+		rows = append(rows, []string{
+			truncateString(ptr.Name, 40),
+			truncateString(forward, 45),
+			status,
+		})
+	}
+
+	if err := f.createAndRenderTable([]string{"PTR Name", "Forward Addresses", "Status"}, rows, writer); err != nil {
+		return err
+	}
+
+	if result.Confirmed {
+		fmt.Fprintf(writer, "\n✅ Forward-confirmed reverse DNS is valid\n")
+	} else {
+		fmt.Fprintf(writer, "\n❌ No PTR name resolves back to %s\n", result.IP)
+	}
+	return nil
+}
+
+func (f *Formatter) formatSRVResultTable(data interface{}, writer io.Writer) error {
+	result := data.(*dns.SRVResult)
+	fmt.Fprintf(writer, "🧭 SRV Targets for %s\n", dns.DisplayDomain(result.Name))
+	fmt.Fprintf(writer, "📡 Nameserver: %s\n", f.getNameserverDisplayName(result.Nameserver))
+	fmt.Fprintf(writer, "🕐 Resolved at: %s\n\n", result.Timestamp.Format("2006-01-02 15:04:05"))
+
+	if result.Unavailable {
+		fmt.Fprintf(writer, "⛔ Service is not offered (SRV target \".\")\n")
+		return nil
+	}
+	if len(result.Targets) == 0 {
+		fmt.Fprintf(writer, "❌ No SRV records found\n")
+		return nil
+	}
+
+	var rows [][]string
+	for i, target := range result.Targets {
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", i+1),
+			fmt.Sprintf("%d", target.Priority),
+			fmt.Sprintf("%d", target.Weight),
+			fmt.Sprintf("%d", target.Port),
+			truncateString(target.Target, 50),
+		})
+	}
+
+	if err := f.createAndRenderTable([]string{"Order", "Priority", "Weight", "Port", "Target"}, rows, writer); err != nil {
+		return err
 	}
 
-	return f.createAndRenderTable([]string{"Severity", "Type", "Domain", "Record", "Description"}, rows, writer)
+	fmt.Fprintf(writer, "\n💡 Try targets in this order; equal priorities are shuffled by weight on every run\n")
+	return nil
 }
 
 func (f *Formatter) formatBulkResultTable(data interface{}, writer io.Writer) error {
@@ -267,12 +710,54 @@ func (f *Formatter) formatBulkResultTable(data interface{}, writer io.Writer) er
 	return f.createAndRenderTable([]string{"Domain", "Status", "Records", "Response Time"}, rows, writer)
 }
 
-func (f *Formatter) formatBulkSummaryTable(data interface{}, writer io.Writer) error {
-	summary := data.(*dns.BulkSummary)
+// WriteBulkSummaryHeader writes the counts that open the bulk summary table, for showing a
+// run's outcome when its results went elsewhere
+func WriteBulkSummaryHeader(summary *dns.BulkSummary, writer io.Writer) {
 	fmt.Fprintf(writer, "\n📋 Bulk Operation Summary\n")
 	fmt.Fprintf(writer, "📊 Total: %d | ✅ Success: %d | ❌ Failed: %d\n",
 		summary.TotalDomains, summary.Successful, summary.Failed)
-	fmt.Fprintf(writer, "⏱️  Duration: %v\n\n", summary.Duration)
+	fmt.Fprintf(writer, "⏱️  Duration: %v | 📨 Queries: %d (%.1f/s)\n", summary.Duration, summary.Queries, summary.QPS)
+	if summary.Concurrency > 0 {
+		fmt.Fprintf(writer, "⚙️  Concurrency: %d", summary.Concurrency)
+		if summary.TimedOut > 0 {
+			fmt.Fprintf(writer, " | ⌛ Timed out: %d", summary.TimedOut)
+		}
+		fmt.Fprintln(writer)
+	}
+	if summary.Duplicates > 0 {
+		fmt.Fprintf(writer, "🔁 Duplicate rows removed: %d\n", summary.Duplicates)
+	}
+	if len(summary.ByRecordType) > 0 {
+		fmt.Fprintf(writer, "📑 By type: %s\n", formatTypeCounts(summary))
+	}
+	if summary.Filter != nil {
+		fmt.Fprintf(writer, "🔎 Showing %d of %d results (%s)\n",
+			summary.Filter.Shown, summary.TotalDomains, describeBulkFilter(summary.Filter))
+	}
+	if summary.AutoRetried > 0 {
+		fmt.Fprintf(writer, "♻️  Retried automatically: %d | Recovered: %d\n", summary.AutoRetried, summary.Recovered)
+	}
+	if retried := countRetried(summary.Results); retried > 0 {
+		fmt.Fprintf(writer, "🔄 Retried: %d (marked 🔄)\n", retried)
+	}
+	if len(summary.Skipped) > 0 {
+		fmt.Fprintf(writer, "⚠️  Skipped %d input row(s):\n", len(summary.Skipped))
+		for _, row := range summary.Skipped {
+			fmt.Fprintf(writer, "   line %d: %s (%s)\n", row.Line, row.Text, row.Reason)
+		}
+	}
+}
+
+func (f *Formatter) formatBulkSummaryTable(data interface{}, writer io.Writer) error {
+	summary := data.(*dns.BulkSummary)
+	WriteBulkSummaryHeader(summary, writer)
+	if f.summaryOnly {
+		if summary.Stats == nil {
+			return nil
+		}
+		return f.formatBulkStatsTable(summary.Stats, writer)
+	}
+	fmt.Fprintln(writer)
 
 	if len(summary.Results) == 0 {
 		fmt.Fprintf(writer, "No results to display.\n")
@@ -292,22 +777,200 @@ func (f *Formatter) formatBulkSummaryTable(data interface{}, writer io.Writer) e
 			}
 		}
 
+		if result.Retried {
+			status += " 🔄"
+		}
+		if result.Retries > 0 {
+			status += fmt.Sprintf(" (retry %d)", result.Retries)
+		}
+
 		duration := result.EndTime.Sub(result.StartTime)
 
+		width := 40
+		if f.detail {
+			width = 60
+		}
+		// Hostnames are the point of a PTR run, so they are always shown
+		if result.Success && (f.detail || summary.Operation == dns.BulkOperationPTR) {
+			resultStr = bulkResultDetail(&result)
+		}
+
+		domain := result.Domain
+		if result.RecordType != "" {
+			domain += " (" + string(result.RecordType) + ")"
+		}
+
 		rows = append(rows, []string{
-			truncateString(result.Domain, 30),
+			truncateString(domain, 30),
 			status,
-			truncateString(resultStr, 40),
+			truncateString(resultStr, width),
 			duration.String(),
 		})
 	}
 
-	return f.createAndRenderTable([]string{"Domain", "Status", "Result", "Duration"}, rows, writer)
+	if err := f.createAndRenderTable([]string{"Domain", "Status", "Result", "Duration"}, rows, writer); err != nil {
+		return err
+	}
+	if summary.Stats == nil {
+		return nil
+	}
+	return f.formatBulkStatsTable(summary.Stats, writer)
+}
+
+// formatBulkStatsTable renders the statistics section that closes a bulk summary table
+func (f *Formatter) formatBulkStatsTable(stats *dns.BulkStats, writer io.Writer) error {
+	fmt.Fprintf(writer, "\n📈 Statistics\n")
+	fmt.Fprintf(writer, "⏱️  Response time: p50 %v | p95 %v | p99 %v\n",
+		stats.P50.Round(time.Microsecond), stats.P95.Round(time.Microsecond), stats.P99.Round(time.Microsecond))
+
+	if len(stats.IssuesBySeverity) > 0 {
+		var parts []string
+		for _, severity := range []string{"high", "medium", "low", "info"} {
+			if count := stats.IssuesBySeverity[severity]; count > 0 {
+				parts = append(parts, fmt.Sprintf("%s %d", severity, count))
+			}
+		}
+		fmt.Fprintf(writer, "🔍 Issues by severity: %s\n", strings.Join(parts, " | "))
+	}
+
+	if len(stats.Slowest) > 0 {
+		fmt.Fprintf(writer, "\n🐢 Slowest domains\n")
+		rows := make([][]string, len(stats.Slowest))
+		for i, slow := range stats.Slowest {
+			domain := slow.Domain
+			if slow.RecordType != "" {
+				domain += " (" + string(slow.RecordType) + ")"
+			}
+			rows[i] = []string{truncateString(domain, 40), slow.Duration.Round(time.Microsecond).String()}
+		}
+		if err := f.createAndRenderTable([]string{"Domain", "Duration"}, rows, writer); err != nil {
+			return err
+		}
+	}
+
+	if len(stats.Failures) > 0 {
+		fmt.Fprintf(writer, "\n❌ Failures by reason\n")
+		rows := make([][]string, len(stats.Failures))
+		for i, group := range stats.Failures {
+			rows[i] = []string{truncateString(group.Reason, 30), fmt.Sprintf("%d", group.Count), truncateString(group.Example, 50)}
+		}
+		if err := f.createAndRenderTable([]string{"Reason", "Count", "Example"}, rows, writer); err != nil {
+			return err
+		}
+	}
+
+	if len(stats.IssuesByType) > 0 {
+		fmt.Fprintf(writer, "\n🔍 Issues by type\n")
+		types := make([]string, 0, len(stats.IssuesByType))
+		for issueType := range stats.IssuesByType {
+			types = append(types, issueType)
+		}
+		sort.Slice(types, func(i, j int) bool {
+			a, b := stats.IssuesByType[types[i]], stats.IssuesByType[types[j]]
+			if a != b {
+				return a > b
+			}
+			return types[i] < types[j]
+		})
+		rows := make([][]string, len(types))
+		for i, issueType := range types {
+			rows[i] = []string{issueType, fmt.Sprintf("%d", stats.IssuesByType[issueType])}
+		}
+		if err := f.createAndRenderTable([]string{"Type", "Count"}, rows, writer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatTypeCounts renders per-type success counts as "A 3/3 | MX 2/3", in the order the
+// types were requested
+func formatTypeCounts(summary *dns.BulkSummary) string {
+	var parts []string
+	listed := make(map[dns.DNSRecordType]bool)
+	add := func(recordType dns.DNSRecordType) {
+		count, ok := summary.ByRecordType[recordType]
+		if !ok || listed[recordType] {
+			return
+		}
+		listed[recordType] = true
+		parts = append(parts, fmt.Sprintf("%s %d/%d", recordType, count.Successful, count.Successful+count.Failed))
+	}
+
+	for _, recordType := range summary.RecordTypes {
+		add(recordType)
+	}
+	// Types that only came from per-row overrides follow alphabetically
+	var others []string
+	for recordType := range summary.ByRecordType {
+		if !listed[recordType] {
+			others = append(others, string(recordType))
+		}
+	}
+	sort.Strings(others)
+	for _, recordType := range others {
+		add(dns.DNSRecordType(recordType))
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+// describeBulkFilter names the filters applied, e.g. "failures only"
+func describeBulkFilter(filter *dns.BulkFilter) string {
+	var parts []string
+	if filter.OnlyFailures {
+		parts = append(parts, "failures only")
+	}
+	if filter.MinSeverity != "" {
+		parts = append(parts, "issues at or above "+filter.MinSeverity)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// countRetried returns how many results came from a --retry-from pass
+func countRetried(results []dns.BulkResult) int {
+	count := 0
+	for _, result := range results {
+		if result.Retried {
+			count++
+		}
+	}
+	return count
+}
+
+// bulkResultDetail summarizes a successful bulk result for the detail table: the answer
+// values for queries, agreement for propagation and the worst issue for consistency
+func bulkResultDetail(result *dns.BulkResult) string {
+	switch {
+	case result.Query != nil:
+		if len(result.Query.Records) == 0 {
+			return "No records"
+		}
+		values := make([]string, len(result.Query.Records))
+		for i, record := range result.Query.Records {
+			values[i] = record.Value
+		}
+		return strings.Join(values, ", ")
+	case result.Propagation != nil:
+		agreeing, total := result.Propagation.Agreement()
+		if result.Propagation.Inconsistent {
+			return fmt.Sprintf("⚠️ Inconsistent, %d/%d servers agree", agreeing, total)
+		}
+		return fmt.Sprintf("Consistent, %d/%d servers agree", agreeing, total)
+	case result.Consistency != nil:
+		if len(result.Consistency.Issues) == 0 {
+			return "No issues"
+		}
+		return fmt.Sprintf("%d issue(s), highest %s", len(result.Consistency.Issues), dns.HighestSeverity(result.Consistency.Issues))
+	default:
+		return "Success"
+	}
 }
 
 func (f *Formatter) formatCertInfoTable(data interface{}, writer io.Writer) error {
 	info := data.(*ssl.CertInfo)
-	fmt.Fprintf(writer, "🔒 SSL Certificate Information for %s\n", info.Domain)
+	fmt.Fprintf(writer, "🔒 SSL Certificate Information for %s\n", dns.DisplayDomain(info.Domain))
 	fmt.Fprintf(writer, "----------------------------------------\n\n")
 
 	rows := [][]string{
@@ -320,17 +983,197 @@ func (f *Formatter) formatCertInfoTable(data interface{}, writer io.Writer) erro
 		{"Serial Number", info.SerialNumber},
 		{"Signature Algorithm", info.SignatureAlg},
 		{"DNS Names", truncateString(strings.Join(info.DNSNames, ", "), 60)},
+		{"Handshake Time", info.HandshakeTime.String()},
+		{"Self-Signed", fmt.Sprintf("%t", info.IsSelfSigned)},
+		{"Wildcard", fmt.Sprintf("%t", info.IsWildcard)},
+		{"Matches Domain", fmt.Sprintf("%t", info.MatchesDomain)},
+		{"Hostname Covered", fmt.Sprintf("%t", info.HostnameCovered)},
+		{"SAN Count", fmt.Sprintf("%d", info.SANCount)},
+		{"SHA-256 Fingerprint", info.SHA256Fingerprint},
+		{"Publicly Trusted", fmt.Sprintf("%t", info.PubliclyTrusted)},
+		{"SCTs", formatSCTSummary(info.SCTs)},
+	}
+	if info.CTLogEntries != nil {
+		rows = append(rows, []string{"CT Log Entries", fmt.Sprintf("%d", *info.CTLogEntries)})
+	}
+	rows = append(rows, []string{"Client Cert Requested", fmt.Sprintf("%t", info.ClientCertRequested)})
+	if info.ClientCertRequested {
+		rows = append(rows, []string{"Client Cert Sent", fmt.Sprintf("%t", info.ClientCertSent)})
+		if len(info.ClientCertCAs) > 0 {
+			rows = append(rows, []string{"Acceptable Client CAs", truncateString(strings.Join(info.ClientCertCAs, "; "), 60)})
+		}
 	}
 
-	return f.createAndRenderTable([]string{"Field", "Value"}, rows, writer)
-}
+	if err := f.createAndRenderTable([]string{"Field", "Value"}, rows, writer); err != nil {
+		return err
+	}
+
+	if len(info.SCTs) > 0 {
+		fmt.Fprintf(writer, "\n📜 Signed Certificate Timestamps\n")
+		fmt.Fprintf(writer, "----------------------------------------\n")
+
+		var sctRows [][]string
+		for _, sct := range info.SCTs {
+			sctRows = append(sctRows, []string{
+				sctSourceName(sct.Source),
+				sct.LogID,
+				sct.Timestamp.Format("2006-01-02 15:04:05"),
+			})
+		}
+
+		if err := f.createAndRenderTable([]string{"Source", "Log ID", "Timestamp"}, sctRows, writer); err != nil {
+			return err
+		}
+	}
+
+	// Results saved before the SAN check carry no note, only the CN-or-SAN match
+	mismatch := info.HostnameNote != "" || !info.MatchesDomain
+	switch {
+	case info.HostnameNote != "":
+		fmt.Fprintf(writer, "\n❌ %s", info.HostnameNote)
+	case !info.MatchesDomain:
+		fmt.Fprintf(writer, "\n❌ certificate does not cover queried domain %s", info.Domain)
+	}
+	if info.IsSelfSigned {
+		fmt.Fprintf(writer, "\n⚠️  certificate is self-signed")
+	}
+	for _, warning := range info.Warnings {
+		fmt.Fprintf(writer, "\n⚠️  %s", warning)
+	}
+	if mismatch || info.IsSelfSigned || len(info.Warnings) > 0 {
+		fmt.Fprintf(writer, "\n")
+	}
+
+	return nil
+}
+
+// formatSCTSummary renders the SCT count followed by the distinct sources they came from
+func formatSCTSummary(scts []ssl.SCTInfo) string {
+	if len(scts) == 0 {
+		return "0"
+	}
+
+	var sources []string
+	seen := make(map[string]bool)
+	for _, sct := range scts {
+		if !seen[sct.Source] {
+			seen[sct.Source] = true
+			sources = append(sources, sctSourceName(sct.Source))
+		}
+	}
+	return fmt.Sprintf("%d (%s)", len(scts), strings.Join(sources, ", "))
+}
+
+func sctSourceName(source string) string {
+	switch source {
+	case ssl.SCTSourceTLSExtension:
+		return "TLS extension"
+	case ssl.SCTSourceEmbedded:
+		return "embedded"
+	case ssl.SCTSourceOCSP:
+		return "OCSP"
+	default:
+		return source
+	}
+}
+
+func (f *Formatter) formatMultiIPCertResultTable(data interface{}, writer io.Writer) error {
+	result := data.(*ssl.MultiIPResult)
+	fmt.Fprintf(writer, "🔒 SSL Certificates for %s:%s across %d addresses\n", result.Domain, result.Port, len(result.Results))
+	fmt.Fprintf(writer, "📊 Reachable: %d | ❌ Failed: %d\n", result.Reachable, result.Failed)
+	fmt.Fprintf(writer, "🕐 Checked at: %s\n\n", result.Timestamp.Format("2006-01-02 15:04:05"))
+
+	var rows [][]string
+	for _, ip := range result.SortedIPs() {
+		ipResult := result.Results[ip]
+		if ipResult.Cert == nil {
+			rows = append(rows, []string{ip, "❌ ERROR", "-", "-", truncateString(ipResult.Error, 50)})
+			continue
+		}
+
+		cert := ipResult.Cert
+		status := "✅ OK"
+		if !cert.IsValid {
+			status = "⚠️  INVALID"
+		}
+
+		rows = append(rows, []string{
+			ip,
+			status,
+			truncateString(cert.SerialNumber, 40),
+			fmt.Sprintf("%d days", cert.ExpiresIn),
+			truncateString(cert.Issuer, 50),
+		})
+	}
+
+	if err := f.createAndRenderTable([]string{"IP", "Status", "Serial Number", "Expires In", "Issuer / Error"}, rows, writer); err != nil {
+		return err
+	}
+
+	if result.Consistent && result.Failed == 0 {
+		fmt.Fprintf(writer, "\n✅ %s\n", result.Verdict)
+	} else {
+		fmt.Fprintf(writer, "\n⚠️  %s\n", result.Verdict)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(writer, "   - %s\n", warning)
+	}
+
+	return nil
+}
+
+func (f *Formatter) formatCertComparisonTable(data interface{}, writer io.Writer) error {
+	comparison := data.(*ssl.CertComparison)
+	fmt.Fprintf(writer, "🔒 SSL Certificate Comparison\n")
+	fmt.Fprintf(writer, "   A: %s\n", comparison.EndpointA)
+	fmt.Fprintf(writer, "   B: %s\n\n", comparison.EndpointB)
+
+	var rows [][]string
+	for _, field := range comparison.Fields {
+		match := "✅"
+		if !field.Match {
+			match = "❌"
+		}
+		rows = append(rows, []string{
+			field.Field,
+			truncateString(field.A, 50),
+			truncateString(field.B, 50),
+			match,
+		})
+	}
+
+	if err := f.createAndRenderTable([]string{"Field", "A", "B", "Match"}, rows, writer); err != nil {
+		return err
+	}
+
+	if comparison.Identical {
+		fmt.Fprintf(writer, "\n✅ Certificates match\n")
+	} else {
+		fmt.Fprintf(writer, "\n❌ Certificates differ in %d field(s)\n", len(comparison.Differences))
+	}
+
+	return nil
+}
 
 func (f *Formatter) formatScanResultTable(data interface{}, writer io.Writer) error {
 	result := data.(*network.ScanResult)
+	if result.Summary.TotalPorts == 0 {
+		return f.formatPingSweepTable(result, writer)
+	}
+
 	fmt.Fprintf(writer, "🔍 Network Discovery Results for %s\n", result.Network)
-	fmt.Fprintf(writer, "📊 Found %d live hosts out of %d scanned\n", result.Summary.LiveHosts, result.Summary.TotalHosts)
+	fmt.Fprintf(writer, "📊 Found %d live hosts out of %d scanned\n", result.Summary.LiveHosts, result.Summary.HostsScanned)
+	if result.Summary.Excluded > 0 {
+		fmt.Fprintf(writer, "🚫 Excluded: %d of %d addresses\n", result.Summary.Excluded, result.Summary.Generated)
+	}
 	fmt.Fprintf(writer, "⏱️  Duration: %v\n", result.Duration)
-	fmt.Fprintf(writer, "🕐 Completed at: %s\n\n", result.StartTime.Add(result.Duration).Format("2006-01-02 15:04:05"))
+	WriteScanPacing(result.Summary.Pacing, writer)
+	fmt.Fprintf(writer, "🕐 Completed at: %s\n", result.StartTime.Add(result.Duration).Format("2006-01-02 15:04:05"))
+	writeScanInterrupted(writer, result)
+	if f.summaryOnly {
+		return nil
+	}
+	fmt.Fprintf(writer, "\n")
 
 	if len(result.Hosts) == 0 {
 		fmt.Fprintf(writer, "No live hosts found.\n")
@@ -338,21 +1181,24 @@ func (f *Formatter) formatScanResultTable(data interface{}, writer io.Writer) er
 	}
 
 	for _, host := range result.Hosts {
-		fmt.Fprintf(writer, "🖥️  %s\n", host.IP)
-		if len(host.Ports) > 0 {
+		fmt.Fprintf(writer, "🖥️  %s\n", hostLabel(host))
+		if host.Error != "" {
+			fmt.Fprintf(writer, "   ❌ %s\n", host.Error)
+		} else if len(host.Ports) > 0 {
 			for _, port := range host.Ports {
-				service := port.Service
-				if service == "" {
-					service = "Unknown"
-				}
-				fmt.Fprintf(writer, "   🟢 %-5d %-12s", port.Port, service)
+				fmt.Fprintf(writer, "   %s %-9s %-12s", portStateIcon(port), portLabel(port), serviceLabel(port))
 				if port.Banner != "" {
 					fmt.Fprintf(writer, " - %s", port.Banner)
 				}
-				fmt.Fprintf(writer, "\n")
+				fmt.Fprintf(writer, "%s\n", portStateNote(port))
+				if names := certNames(port); names != "" {
+					fmt.Fprintf(writer, "      🔒 %s\n", names)
+				}
 			}
-		} else {
+		} else if host.Alive {
 			fmt.Fprintf(writer, "   📝 Host alive but no open ports found in scanned range\n")
+		} else {
+			fmt.Fprintf(writer, "   🔴 No open ports found in scanned range\n")
 		}
 		fmt.Fprintf(writer, "\n")
 	}
@@ -360,10 +1206,160 @@ func (f *Formatter) formatScanResultTable(data interface{}, writer io.Writer) er
 	return nil
 }
 
+// hostLabel names a scanned host as "name (address)" when it was given by
+// hostname, followed by its OS hint when there is one
+func hostLabel(host network.HostResult) string {
+	var label string
+	switch {
+	case host.Hostname != "" && host.IP != "":
+		label = fmt.Sprintf("%s (%s)", host.Hostname, host.IP)
+	case host.Hostname != "":
+		label = host.Hostname
+	default:
+		label = host.IP
+	}
+	return label + osHintNote(host) + macNote(host)
+}
+
+// macNote renders a host's MAC address and vendor as " aa:bb:cc:dd:ee:ff (Vendor)"
+func macNote(host network.HostResult) string {
+	switch {
+	case host.MAC == "":
+		return ""
+	case host.Vendor == "":
+		return " " + host.MAC
+	default:
+		return fmt.Sprintf(" %s (%s)", host.MAC, host.Vendor)
+	}
+}
+
+// osHintNote renders a host's TTL-based OS guess as " [Linux/Unix?, TTL 64]"
+func osHintNote(host network.HostResult) string {
+	if host.OSHint == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [%s?, TTL %d]", host.OSHint, host.TTL)
+}
+
+// formatTTL renders a reply TTL, leaving it blank when none was seen
+func formatTTL(ttl int) string {
+	if ttl == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", ttl)
+}
+
+// formatPingSweepTable lists live hosts with their latency for scans without ports
+func (f *Formatter) formatPingSweepTable(result *network.ScanResult, writer io.Writer) error {
+	fmt.Fprintf(writer, "🔍 Ping Sweep Results for %s\n", result.Network)
+	fmt.Fprintf(writer, "📊 Found %d live hosts out of %d scanned\n", result.Summary.LiveHosts, result.Summary.HostsScanned)
+	if result.Summary.Excluded > 0 {
+		fmt.Fprintf(writer, "🚫 Excluded: %d of %d addresses\n", result.Summary.Excluded, result.Summary.Generated)
+	}
+	if result.Summary.PingMethod != "" {
+		fmt.Fprintf(writer, "📡 Method: %s", result.Summary.PingMethod)
+		if strings.Contains(result.Summary.PingMethod, network.PingMethodICMP+"+"+network.PingMethodTCP) {
+			fmt.Fprintf(writer, " | ICMP only: %d | TCP only: %d", result.Summary.ICMPOnly, result.Summary.TCPOnly)
+		}
+		if strings.Contains(result.Summary.PingMethod, "+"+network.PingMethodARP) {
+			fmt.Fprintf(writer, " | ARP only: %d", result.Summary.ARPOnly)
+		}
+		fmt.Fprintf(writer, "\n")
+	}
+	if result.Summary.PingCount > 1 {
+		fmt.Fprintf(writer, "📶 Probes per host: %d\n", result.Summary.PingCount)
+	}
+	fmt.Fprintf(writer, "⏱️  Duration: %v\n", result.Duration)
+	WriteScanPacing(result.Summary.Pacing, writer)
+	writeScanInterrupted(writer, result)
+	if f.summaryOnly {
+		return nil
+	}
+	fmt.Fprintf(writer, "\n")
+
+	if len(result.Hosts) == 0 {
+		fmt.Fprintf(writer, "No live hosts found.\n")
+		return nil
+	}
+
+	if result.Summary.PingCount > 1 {
+		var rows [][]string
+		for _, host := range result.Hosts {
+			stats := host.Stats
+			if stats == nil {
+				// Hosts found only by ARP were not probed again
+				rows = append(rows, []string{host.IP, host.Method, "-", "-", "-", "-", "-", "-", host.MAC, host.Vendor})
+				continue
+			}
+			rows = append(rows, []string{
+				host.IP,
+				host.Method,
+				fmt.Sprintf("%d/%d", stats.Received, stats.Sent),
+				fmt.Sprintf("%.0f%%", stats.Loss()),
+				formatMilliseconds(stats.Min),
+				formatMilliseconds(stats.Avg),
+				formatMilliseconds(stats.Max),
+				formatMilliseconds(stats.Jitter),
+				host.MAC,
+				host.Vendor,
+			})
+		}
+		return f.createAndRenderTable([]string{"Host", "Method", "Replies", "Loss", "Min", "Avg", "Max", "Jitter", "MAC", "Vendor"}, rows, writer)
+	}
+
+	for _, host := range result.Hosts {
+		latency := formatMilliseconds(host.Latency)
+		if host.Method == network.PingMethodARP {
+			latency = "no RTT"
+		}
+		fmt.Fprintf(writer, "🟢 %-15s (%s)", host.IP, latency)
+		if host.Method != "" {
+			fmt.Fprintf(writer, " [%s]", host.Method)
+		}
+		fmt.Fprintf(writer, "%s%s\n", osHintNote(host), macNote(host))
+	}
+
+	return nil
+}
+
+// writeScanInterrupted warns that a cancelled scan's results cover only part of the network
+func writeScanInterrupted(writer io.Writer, result *network.ScanResult) {
+	if result.Summary.Interrupted {
+		fmt.Fprintf(writer, "⚠️  Interrupted: only %d of %d addresses were scanned\n", result.Summary.HostsScanned, result.Summary.TotalHosts)
+	}
+}
+
+// WriteScanPacing writes the probe rate a scan achieved and its adaptive connect
+// timeout; nothing is written when neither a rate limit nor adaptive timeouts
+// were configured
+func WriteScanPacing(pacing *network.PacingStats, writer io.Writer) {
+	if pacing == nil {
+		return
+	}
+	fmt.Fprintf(writer, "🚦 Rate: %.1f probes/s (%d probes", pacing.Rate, pacing.Probes)
+	if pacing.RateLimit > 0 {
+		fmt.Fprintf(writer, ", limit %d/s", pacing.RateLimit)
+	}
+	fmt.Fprintf(writer, ")")
+	if pacing.ConnectTimeout > 0 {
+		fmt.Fprintf(writer, " | Adaptive connect timeout: %v", pacing.ConnectTimeout.Round(time.Millisecond))
+	}
+	fmt.Fprintf(writer, "\n")
+}
+
+// formatMilliseconds renders a latency as milliseconds with two decimals
+func formatMilliseconds(d time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(d.Nanoseconds())/1000000)
+}
+
 func (f *Formatter) formatHostResultTable(data interface{}, writer io.Writer) error {
 	result := data.(*network.HostResult)
 	fmt.Fprintf(writer, "🔍 Port Scan Results for %s\n", result.IP)
-	fmt.Fprintf(writer, "📊 Found %d open ports\n\n", len(result.Ports))
+	if f.summaryOnly {
+		fmt.Fprintf(writer, "📊 %s\n", portCountSummary(result.Ports))
+		return nil
+	}
+	fmt.Fprintf(writer, "📊 %s\n\n", portCountSummary(result.Ports))
 
 	if len(result.Ports) == 0 {
 		fmt.Fprintf(writer, "No open ports found.\n")
@@ -371,23 +1367,243 @@ func (f *Formatter) formatHostResultTable(data interface{}, writer io.Writer) er
 	}
 
 	for _, port := range result.Ports {
-		service := port.Service
-		if service == "" {
-			service = "Unknown"
-		}
-		fmt.Fprintf(writer, "🟢 Port %-5d %-12s", port.Port, service)
+		fmt.Fprintf(writer, "%s Port %-9s %-12s", portStateIcon(port), portLabel(port), serviceLabel(port))
 		if port.Banner != "" {
 			fmt.Fprintf(writer, " - %s", port.Banner)
 		}
-		fmt.Fprintf(writer, "\n")
+		fmt.Fprintf(writer, "%s\n", portStateNote(port))
+	}
+
+	return nil
+}
+
+// portLabel renders a port as "53/udp", defaulting to TCP for results without a protocol
+func portLabel(port network.PortResult) string {
+	protocol := port.Protocol
+	if protocol == "" {
+		protocol = network.ProtocolTCP
+	}
+	return fmt.Sprintf("%d/%s", port.Port, protocol)
+}
+
+// serviceLabel names the service on a port, looking it up when the result
+// does not carry one
+func serviceLabel(port network.PortResult) string {
+	if port.Service != "" {
+		return port.Service
+	}
+	if service := network.ServiceName(port.Port, port.Protocol); service != "" {
+		return service
+	}
+	return "Unknown"
+}
+
+// portStateIcon marks open ports green, filtered or silent ports yellow,
+// closed ports red and failed probes with a cross
+func portStateIcon(port network.PortResult) string {
+	switch port.State {
+	case network.PortStateOpenFiltered, network.PortStateFiltered:
+		return "🟡"
+	case network.PortStateClosed:
+		return "🔴"
+	case network.PortStateError:
+		return "❌"
+	default:
+		return "🟢"
+	}
+}
+
+// certNames summarizes the certificate found on a TLS port as "CN (SANs: a, b)"
+func certNames(port network.PortResult) string {
+	switch {
+	case port.CertCN == "" && len(port.CertSANs) == 0:
+		return ""
+	case len(port.CertSANs) == 0:
+		return port.CertCN
+	case port.CertCN == "":
+		return fmt.Sprintf("SANs: %s", strings.Join(port.CertSANs, ", "))
+	default:
+		return fmt.Sprintf("%s (SANs: %s)", port.CertCN, strings.Join(port.CertSANs, ", "))
+	}
+}
+
+// portStateNote spells out the state of ports that are not plainly open
+func portStateNote(port network.PortResult) string {
+	switch {
+	case port.State == network.PortStateError && port.Error != "":
+		return fmt.Sprintf(" (error: %s)", port.Error)
+	case port.State != "" && port.State != network.PortStateOpen:
+		return fmt.Sprintf(" (%s)", port.State)
+	default:
+		return ""
+	}
+}
+
+// portCountSummary counts open ports, mentioning ports in other states separately
+func portCountSummary(ports []network.PortResult) string {
+	counts := make(map[string]int)
+	for _, port := range ports {
+		state := port.State
+		if state == "" {
+			state = network.PortStateOpen
+		}
+		counts[state]++
+	}
+
+	summary := fmt.Sprintf("Found %d open ports", counts[network.PortStateOpen])
+	for _, state := range []string{network.PortStateOpenFiltered, network.PortStateFiltered, network.PortStateClosed, network.PortStateError} {
+		if counts[state] > 0 {
+			summary += fmt.Sprintf(", %d %s", counts[state], state)
+		}
+	}
+	return summary
+}
+
+func (f *Formatter) formatScanDiffTable(data interface{}, writer io.Writer) error {
+	diff := data.(*network.ScanDiff)
+	fmt.Fprintf(writer, "🔀 Scan Diff\n")
+	fmt.Fprintf(writer, "   Old: %s (%s)\n", diff.OldNetwork, diff.OldTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(writer, "   New: %s (%s)\n\n", diff.NewNetwork, diff.NewTime.Format("2006-01-02 15:04:05"))
+
+	if !diff.Changed() {
+		fmt.Fprintf(writer, "✅ No changes found\n")
+		return nil
 	}
 
+	var rows [][]string
+	for _, entry := range diff.Changes {
+		var change, port, service string
+		switch entry.Change {
+		case network.ScanHostAdded:
+			change = "➕ new host"
+		case network.ScanHostRemoved:
+			change = "➖ host gone"
+		case network.ScanPortOpened:
+			change = "🟢 opened"
+		default:
+			change = "🔴 closed"
+		}
+		if entry.Port != 0 {
+			portResult := network.PortResult{Port: entry.Port, Protocol: entry.Protocol, Service: entry.Service}
+			port = portLabel(portResult)
+			service = serviceLabel(portResult)
+		}
+		rows = append(rows, []string{entry.IP, entry.Hostname, change, port, service})
+	}
+
+	if err := f.createAndRenderTable([]string{"IP", "Hostname", "Change", "Port", "Service"}, rows, writer); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(writer, "\n❌ %d new host(s), %d host(s) gone, %d port(s) opened, %d port(s) closed\n",
+		diff.HostsAdded, diff.HostsRemoved, diff.PortsOpened, diff.PortsClosed)
+	return nil
+}
+
+func (f *Formatter) formatScanHistoryTable(data interface{}, writer io.Writer) error {
+	report := data.(*network.ScanHistoryReport)
+	fmt.Fprintf(writer, "🗄️  Scan History: %s (%d scans)\n", report.Database, report.Scans)
+	fmt.Fprintf(writer, "🔎 Query: %s\n", report.Query)
+	if report.Cutoff != nil {
+		fmt.Fprintf(writer, "🕐 Not seen since: %s\n", report.Cutoff.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintln(writer)
+
+	if len(report.Entries) == 0 {
+		fmt.Fprintf(writer, "✅ No matching hosts or ports in the history\n")
+		return nil
+	}
+
+	var rows [][]string
+	for _, entry := range report.Entries {
+		var port, service string
+		if entry.Port != 0 {
+			portResult := network.PortResult{Port: entry.Port, Protocol: entry.Protocol, Service: entry.Service}
+			port = portLabel(portResult)
+			service = serviceLabel(portResult)
+		}
+		rows = append(rows, []string{
+			entry.IP,
+			truncateString(entry.Hostname, 30),
+			port,
+			service,
+			entry.FirstSeen.Format("2006-01-02 15:04:05"),
+			entry.LastSeen.Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%d", entry.Sightings),
+		})
+	}
+
+	return f.createAndRenderTable([]string{"IP", "Hostname", "Port", "Service", "First Seen", "Last Seen", "Scans"}, rows, writer)
+}
+
+func (f *Formatter) formatTracerouteResultTable(data interface{}, writer io.Writer) error {
+	result := data.(*network.TracerouteResult)
+	target := result.Target
+	if target != result.IP {
+		target = fmt.Sprintf("%s (%s)", result.Target, result.IP)
+	}
+	fmt.Fprintf(writer, "🛤️  Traceroute to %s, %d hops max\n", target, result.MaxHops)
+	fmt.Fprintf(writer, "📡 Probes: %s port %d (%s)\n", strings.ToUpper(result.Protocol), result.Port, result.Method)
+	fmt.Fprintf(writer, "🕐 Started at: %s\n\n", result.StartTime.Format("2006-01-02 15:04:05"))
+
+	queries := 0
+	for _, hop := range result.Hops {
+		queries = max(queries, len(hop.Probes))
+	}
+
+	var rows [][]string
+	for _, hop := range result.Hops {
+		address, hostname := hop.IP, hop.Hostname
+		if address == "" {
+			address = "*"
+			if tracerouteHopAnswered(hop) {
+				address = "?"
+			}
+		}
+
+		row := []string{fmt.Sprintf("%d", hop.TTL), address, truncateString(hostname, 40)}
+		for i := range queries {
+			rtt := "*"
+			if i < len(hop.Probes) && hop.Probes[i].Answered {
+				rtt = formatMilliseconds(hop.Probes[i].RTT)
+			}
+			row = append(row, rtt)
+		}
+		rows = append(rows, row)
+	}
+
+	headers := []string{"Hop", "Address", "Hostname"}
+	for i := range queries {
+		headers = append(headers, fmt.Sprintf("RTT %d", i+1))
+	}
+	if err := f.createAndRenderTable(headers, rows, writer); err != nil {
+		return err
+	}
+
+	if result.Method == network.TraceMethodTCPConnect {
+		fmt.Fprintf(writer, "\n💡 Without a raw ICMP socket (root or CAP_NET_RAW) routers can't be identified; ? marks a hop that answered\n")
+	}
+	if result.Reached {
+		fmt.Fprintf(writer, "\n✅ Reached %s in %d hop(s) (%v)\n", result.IP, len(result.Hops), result.Duration.Round(time.Millisecond))
+	} else {
+		fmt.Fprintf(writer, "\n❌ %s not reached within %d hop(s) (%v)\n", result.IP, len(result.Hops), result.Duration.Round(time.Millisecond))
+	}
 	return nil
 }
 
+// tracerouteHopAnswered reports whether any probe of a hop got an answer
+func tracerouteHopAnswered(hop network.TracerouteHop) bool {
+	for _, probe := range hop.Probes {
+		if probe.Answered {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *Formatter) formatDNSSECResultTable(data interface{}, writer io.Writer) error {
 	result := data.(*dnssec.ValidationResult)
-	fmt.Fprintf(writer, "🔐 DNSSEC Validation Results for %s\n", result.Domain)
+	fmt.Fprintf(writer, "🔐 DNSSEC Validation Results for %s\n", dns.DisplayDomain(result.Domain))
 	fmt.Fprintf(writer, "----------------------------------------\n\n")
 
 	rows := [][]string{
@@ -397,6 +1613,24 @@ func (f *Formatter) formatDNSSECResultTable(data interface{}, writer io.Writer)
 		{"Checked At", result.Timestamp.Format("2006-01-02 15:04:05")},
 	}
 
+	if len(result.Chain) > 0 {
+		rows = append(rows, []string{"Chain Valid", fmt.Sprintf("%t", result.ChainValid)})
+	}
+
+	if result.IsSigned {
+		denialType := result.DenialType
+		if denialType == "" {
+			denialType = "none"
+		}
+		rows = append(rows,
+			[]string{"Denial of Existence", denialType},
+			[]string{"Denial Validated", fmt.Sprintf("%t", result.DenialValidated)},
+		)
+		if len(result.DenialErrors) > 0 {
+			rows = append(rows, []string{"Denial Errors", strings.Join(result.DenialErrors, "; ")})
+		}
+	}
+
 	if len(result.ValidationErrors) > 0 {
 		rows = append(rows, []string{"Validation Errors", strings.Join(result.ValidationErrors, "\n")})
 	}
@@ -471,21 +1705,92 @@ func (f *Formatter) formatDNSSECResultTable(data interface{}, writer io.Writer)
 		}
 	}
 
+	// Chain of trust from the root
+	if len(result.Chain) > 0 {
+		fmt.Fprintf(writer, "\n🔗 Chain of Trust\n")
+		fmt.Fprintf(writer, "----------------------------------------\n")
+
+		var chainRows [][]string
+		for _, zone := range result.Chain {
+			status := "✅"
+			if !zone.IsValid {
+				status = "❌"
+			}
+			chainRows = append(chainRows, []string{
+				zone.Zone,
+				fmt.Sprintf("%t", zone.HasDS),
+				fmt.Sprintf("%t", zone.DSSigned),
+				fmt.Sprintf("%t", zone.IsSigned),
+				status,
+				truncateString(strings.Join(zone.Errors, "; "), 60),
+			})
+		}
+
+		if err := f.createAndRenderTable([]string{"Zone", "DS", "DS Signed", "DNSKEY", "Valid", "Errors"}, chainRows, writer); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // CSV formatting methods
+// queryResultCSVHeader is shared by single and multi-type query CSV output; the SOA and SRV
+// columns are filled only for records of that type
+var queryResultCSVHeader = []string{"Domain", "RecordType", "Nameserver", "Name", "Type", "Value", "TTL", "Priority", "ResponseTime", "ConnectTime", "QueryTime", "Attempts", "Error",
+	"SOAPrimaryNS", "SOAMailbox", "SOASerial", "SOARefresh", "SOARetry", "SOAExpire", "SOAMinimumTTL",
+	"SRVWeight", "SRVPort", "SRVTarget"}
+
 func (f *Formatter) formatQueryResultCSV(data interface{}, writer io.Writer) error {
 	result := data.(*dns.DNSResult)
 	csvWriter := f.createCSVWriter(writer)
 	defer csvWriter.Flush()
 
 	// Write header
-	header := []string{"Domain", "RecordType", "Nameserver", "Name", "Type", "Value", "TTL", "Priority", "ResponseTime", "Error"}
-	if err := csvWriter.Write(header); err != nil {
+	if err := csvWriter.Write(queryResultCSVHeader); err != nil {
+		return err
+	}
+
+	return writeQueryResultCSVRows(csvWriter, result)
+}
+
+func (f *Formatter) formatMultiQueryResultCSV(data interface{}, writer io.Writer) error {
+	result := data.(*dns.MultiQueryResult)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	// Write header
+	if err := csvWriter.Write(queryResultCSVHeader); err != nil {
 		return err
 	}
 
+	for _, typed := range result.Results {
+		if err := writeQueryResultCSVRows(csvWriter, typed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeQueryResultCSVRows writes one row per record of result; a failed query gets a single
+// row carrying its error
+func writeQueryResultCSVRows(csvWriter *csv.Writer, result *dns.DNSResult) error {
+	if result.Error != nil && len(result.Records) == 0 {
+		return csvWriter.Write([]string{
+			result.Query.Domain,
+			string(result.Query.RecordType),
+			result.Nameserver,
+			"", "", "", "", "",
+			result.ResponseTime.String(),
+			result.ConnectTime.String(),
+			result.QueryTime.String(),
+			fmt.Sprintf("%d", result.Attempts),
+			result.Error.Error(),
+			"", "", "", "", "", "", "",
+			"", "", "",
+		})
+	}
+
 	// Write records
 	for _, record := range result.Records {
 		row := []string{
@@ -498,13 +1803,145 @@ func (f *Formatter) formatQueryResultCSV(data interface{}, writer io.Writer) err
 			fmt.Sprintf("%d", record.TTL),
 			fmt.Sprintf("%d", record.Priority),
 			result.ResponseTime.String(),
+			result.ConnectTime.String(),
+			result.QueryTime.String(),
+			fmt.Sprintf("%d", result.Attempts),
 			"",
 		}
 
-		if result.Error != nil {
-			row[len(row)-1] = result.Error.Error()
-		}
+		if result.Error != nil {
+			row[len(row)-1] = result.Error.Error()
+		}
+		row = append(row, soaCSVFields(record.SOA)...)
+		row = append(row, srvCSVFields(record.SRV)...)
+
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// soaCSVFields returns the SOA columns of a query CSV row, empty for other records
+func soaCSVFields(soa *dns.SOARecord) []string {
+	if soa == nil {
+		return []string{"", "", "", "", "", "", ""}
+	}
+	return []string{
+		soa.PrimaryNS,
+		soa.Mailbox,
+		fmt.Sprintf("%d", soa.Serial),
+		fmt.Sprintf("%d", soa.Refresh),
+		fmt.Sprintf("%d", soa.Retry),
+		fmt.Sprintf("%d", soa.Expire),
+		fmt.Sprintf("%d", soa.MinimumTTL),
+	}
+}
+
+// srvCSVFields returns the SRV columns of a query CSV row, empty for other records; the
+// priority is in the shared Priority column
+func srvCSVFields(srv *dns.SRVRecord) []string {
+	if srv == nil {
+		return []string{"", "", ""}
+	}
+	return []string{
+		fmt.Sprintf("%d", srv.Weight),
+		fmt.Sprintf("%d", srv.Port),
+		srv.Target,
+	}
+}
+
+func (f *Formatter) formatPropagationResultCSV(data interface{}, writer io.Writer) error {
+	result := data.(*dns.PropagationResult)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	// Write header
+	header := []string{"Domain", "RecordType", "Nameserver", "RecordName", "RecordValue", "TTL", "Inconsistent", "Location"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	// Write data
+	for nameserver, records := range result.Results {
+		for _, record := range records {
+			row := []string{
+				result.Domain,
+				string(result.RecordType),
+				nameserver,
+				record.Name,
+				record.Value,
+				fmt.Sprintf("%d", record.TTL),
+				fmt.Sprintf("%t", result.Inconsistent),
+				result.Locations[nameserver],
+			}
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) formatConsistencyIssuesCSV(data interface{}, writer io.Writer) error {
+	issues := data.([]dns.ConsistencyIssue)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	// Write header
+	header := []string{"Type", "Domain", "RecordType", "Severity", "Description", "Servers", "Expected", "Actual", "Remediation"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	// Write data
+	for _, issue := range issues {
+		row := []string{
+			issue.Type,
+			issue.Domain,
+			string(issue.RecordType),
+			issue.Severity,
+			issue.Description,
+			strings.Join(issue.Servers, ";"),
+			issue.Expected,
+			issue.Actual,
+			issue.Remediation,
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) formatConsistencyReportCSV(data interface{}, writer io.Writer) error {
+	return f.formatConsistencyIssuesCSV(data.(*dns.ConsistencyReport).Issues, writer)
+}
+
+func (f *Formatter) formatDiffResultCSV(data interface{}, writer io.Writer) error {
+	result := data.(*dns.DiffResult)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
 
+	// Write header
+	if err := csvWriter.Write([]string{"Domain", "RecordType", "Change", "ServerA", "ValueA", "ServerB", "ValueB"}); err != nil {
+		return err
+	}
+
+	// Write data
+	for _, diff := range result.Differences {
+		row := []string{
+			result.Domain,
+			string(diff.RecordType),
+			string(diff.Change),
+			result.ServerA,
+			diff.ValueA,
+			result.ServerB,
+			diff.ValueB,
+		}
 		if err := csvWriter.Write(row); err != nil {
 			return err
 		}
@@ -513,60 +1950,54 @@ func (f *Formatter) formatQueryResultCSV(data interface{}, writer io.Writer) err
 	return nil
 }
 
-func (f *Formatter) formatPropagationResultCSV(data interface{}, writer io.Writer) error {
-	result := data.(*dns.PropagationResult)
+func (f *Formatter) formatReverseDNSResultCSV(data interface{}, writer io.Writer) error {
+	result := data.(*dns.ReverseDNSResult)
 	csvWriter := f.createCSVWriter(writer)
 	defer csvWriter.Flush()
 
-	// Write header
-	header := []string{"Domain", "RecordType", "Nameserver", "RecordName", "RecordValue", "TTL", "Inconsistent"}
+	header := []string{"IP", "PTR Name", "Forward Addresses", "Confirmed", "Error"}
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
-	// Write data
-	for nameserver, records := range result.Results {
-		for _, record := range records {
-			row := []string{
-				result.Domain,
-				string(result.RecordType),
-				nameserver,
-				record.Name,
-				record.Value,
-				fmt.Sprintf("%d", record.TTL),
-				fmt.Sprintf("%t", result.Inconsistent),
-			}
-			if err := csvWriter.Write(row); err != nil {
-				return err
-			}
+	if len(result.PTRs) == 0 {
+		return csvWriter.Write([]string{result.IP, "", "", "false", "no PTR record"})
+	}
+
+	for _, ptr := range result.PTRs {
+		row := []string{
+			result.IP,
+			ptr.Name,
+			strings.Join(ptr.Addresses, "; "),
+			fmt.Sprintf("%t", ptr.Confirmed),
+			ptr.Error,
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func (f *Formatter) formatConsistencyIssuesCSV(data interface{}, writer io.Writer) error {
-	issues := data.([]dns.ConsistencyIssue)
+func (f *Formatter) formatSRVResultCSV(data interface{}, writer io.Writer) error {
+	result := data.(*dns.SRVResult)
 	csvWriter := f.createCSVWriter(writer)
 	defer csvWriter.Flush()
 
-	// Write header
-	header := []string{"Type", "Domain", "RecordType", "Severity", "Description", "Servers", "Expected", "Actual"}
+	header := []string{"Name", "Order", "Priority", "Weight", "Port", "Target"}
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
-	// Write data
-	for _, issue := range issues {
+	for i, target := range result.Targets {
 		row := []string{
-			issue.Type,
-			issue.Domain,
-			string(issue.RecordType),
-			issue.Severity,
-			issue.Description,
-			strings.Join(issue.Servers, ";"),
-			issue.Expected,
-			issue.Actual,
+			result.Name,
+			fmt.Sprintf("%d", i+1),
+			fmt.Sprintf("%d", target.Priority),
+			fmt.Sprintf("%d", target.Weight),
+			fmt.Sprintf("%d", target.Port),
+			target.Target,
 		}
 		if err := csvWriter.Write(row); err != nil {
 			return err
@@ -610,13 +2041,46 @@ func (f *Formatter) formatBulkResultCSV(data interface{}, writer io.Writer) erro
 	return nil
 }
 
+// bulkResultDetailColumns fills the detail CSV columns (Records, Inconsistent, AgreeingServers,
+// TotalServers, IssueCount, HighestSeverity); columns that don't apply to the operation stay empty
+func bulkResultDetailColumns(result *dns.BulkResult) []string {
+	columns := make([]string, 6)
+	switch {
+	case result.Query != nil:
+		values := make([]string, len(result.Query.Records))
+		for i, record := range result.Query.Records {
+			values[i] = record.Value
+		}
+		columns[0] = strings.Join(values, "; ")
+	case result.Propagation != nil:
+		agreeing, total := result.Propagation.Agreement()
+		columns[1] = fmt.Sprintf("%t", result.Propagation.Inconsistent)
+		columns[2] = fmt.Sprintf("%d", agreeing)
+		columns[3] = fmt.Sprintf("%d", total)
+	case result.Consistency != nil:
+		columns[4] = fmt.Sprintf("%d", len(result.Consistency.Issues))
+		columns[5] = dns.HighestSeverity(result.Consistency.Issues)
+	}
+	return columns
+}
+
 func (f *Formatter) formatBulkSummaryCSV(data interface{}, writer io.Writer) error {
 	summary := data.(*dns.BulkSummary)
+	if f.summaryOnly {
+		return f.formatBulkSummaryOnlyCSV(summary, writer)
+	}
+	if summary.Operation == dns.BulkOperationPTR {
+		return f.formatBulkPTRCSV(summary, writer)
+	}
+
 	csvWriter := f.createCSVWriter(writer)
 	defer csvWriter.Flush()
 
 	// Write header
-	header := []string{"Domain", "Status", "Success", "Error", "StartTime", "EndTime", "Duration"}
+	header := []string{"Domain", "Status", "Success", "Error", "StartTime", "EndTime", "Duration", "RecordType", "Nameserver", "Retried", "Retries"}
+	if f.detail {
+		header = append(header, "Records", "Inconsistent", "AgreeingServers", "TotalServers", "IssueCount", "HighestSeverity")
+	}
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
@@ -642,6 +2106,13 @@ func (f *Formatter) formatBulkSummaryCSV(data interface{}, writer io.Writer) err
 			result.StartTime.Format("2006-01-02 15:04:05"),
 			result.EndTime.Format("2006-01-02 15:04:05"),
 			duration.String(),
+			string(result.RecordType),
+			result.Nameserver,
+			fmt.Sprintf("%t", result.Retried),
+			fmt.Sprintf("%d", result.Retries),
+		}
+		if f.detail {
+			row = append(row, bulkResultDetailColumns(&result)...)
 		}
 		if err := csvWriter.Write(row); err != nil {
 			return err
@@ -651,6 +2122,62 @@ func (f *Formatter) formatBulkSummaryCSV(data interface{}, writer io.Writer) err
 	return nil
 }
 
+// formatBulkPTRCSV writes one ip,hostname,ttl,error row per PTR record, and a single row
+// with the error for an address that didn't resolve
+// formatBulkSummaryOnlyCSV writes the counts of a bulk run as a single row
+func (f *Formatter) formatBulkSummaryOnlyCSV(summary *dns.BulkSummary, writer io.Writer) error {
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	header := []string{"Operation", "TotalDomains", "Successful", "Failed", "Duplicates", "Skipped", "TimedOut", "Queries", "QPS", "Duration"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	return csvWriter.Write([]string{
+		string(summary.Operation),
+		fmt.Sprintf("%d", summary.TotalDomains),
+		fmt.Sprintf("%d", summary.Successful),
+		fmt.Sprintf("%d", summary.Failed),
+		fmt.Sprintf("%d", summary.Duplicates),
+		fmt.Sprintf("%d", len(summary.Skipped)),
+		fmt.Sprintf("%d", summary.TimedOut),
+		fmt.Sprintf("%d", summary.Queries),
+		fmt.Sprintf("%.1f", summary.QPS),
+		summary.Duration.String(),
+	})
+}
+
+func (f *Formatter) formatBulkPTRCSV(summary *dns.BulkSummary, writer io.Writer) error {
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write([]string{"ip", "hostname", "ttl", "error"}); err != nil {
+		return err
+	}
+
+	for _, result := range summary.Results {
+		if !result.Success || result.Query == nil {
+			errorMsg := "failed"
+			if result.Error != nil {
+				errorMsg = result.Error.Error()
+			}
+			if err := csvWriter.Write([]string{result.Domain, "", "", errorMsg}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, record := range result.Query.Records {
+			row := []string{result.Domain, strings.TrimSuffix(record.Value, "."), fmt.Sprintf("%d", record.TTL), ""}
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (f *Formatter) formatCertInfoCSV(data interface{}, writer io.Writer) error {
 	info := data.(*ssl.CertInfo)
 	csvWriter := f.createCSVWriter(writer)
@@ -668,11 +2195,30 @@ func (f *Formatter) formatCertInfoCSV(data interface{}, writer io.Writer) error
 		"SerialNumber",
 		"SignatureAlgorithm",
 		"DNSNames",
+		"HandshakeTime",
+		"IsSelfSigned",
+		"IsWildcard",
+		"MatchesDomain",
+		"HostnameCovered",
+		"HostnameNote",
+		"SANCount",
+		"SHA256Fingerprint",
+		"PubliclyTrusted",
+		"SCTCount",
+		"CTLogEntries",
+		"ClientCertRequested",
+		"ClientCertCAs",
+		"Warnings",
 	}
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
 
+	ctLogEntries := ""
+	if info.CTLogEntries != nil {
+		ctLogEntries = fmt.Sprintf("%d", *info.CTLogEntries)
+	}
+
 	// Write data
 	row := []string{
 		info.Domain,
@@ -685,17 +2231,109 @@ func (f *Formatter) formatCertInfoCSV(data interface{}, writer io.Writer) error
 		info.SerialNumber,
 		info.SignatureAlg,
 		strings.Join(info.DNSNames, ";"),
+		info.HandshakeTime.String(),
+		fmt.Sprintf("%t", info.IsSelfSigned),
+		fmt.Sprintf("%t", info.IsWildcard),
+		fmt.Sprintf("%t", info.MatchesDomain),
+		fmt.Sprintf("%t", info.HostnameCovered),
+		info.HostnameNote,
+		fmt.Sprintf("%d", info.SANCount),
+		info.SHA256Fingerprint,
+		fmt.Sprintf("%t", info.PubliclyTrusted),
+		fmt.Sprintf("%d", len(info.SCTs)),
+		ctLogEntries,
+		fmt.Sprintf("%t", info.ClientCertRequested),
+		strings.Join(info.ClientCertCAs, ";"),
+		strings.Join(info.Warnings, ";"),
 	}
 	return csvWriter.Write(row)
 }
 
+func (f *Formatter) formatMultiIPCertResultCSV(data interface{}, writer io.Writer) error {
+	result := data.(*ssl.MultiIPResult)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	// Write header
+	header := []string{"Domain", "IP", "Port", "CommonName", "Issuer", "SerialNumber", "ValidUntil", "ExpiresIn", "IsValid",
+		"IsSelfSigned", "IsWildcard", "MatchesDomain", "HostnameCovered", "SANCount", "Error"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	// Write data
+	for _, ip := range result.SortedIPs() {
+		ipResult := result.Results[ip]
+		row := []string{result.Domain, ip, result.Port, "", "", "", "", "", "false", "", "", "", "", "", ipResult.Error}
+		if cert := ipResult.Cert; cert != nil {
+			row = []string{
+				result.Domain,
+				ip,
+				result.Port,
+				cert.CommonName,
+				cert.Issuer,
+				cert.SerialNumber,
+				cert.NotAfter.Format("2006-01-02 15:04:05"),
+				fmt.Sprintf("%d", cert.ExpiresIn),
+				fmt.Sprintf("%t", cert.IsValid),
+				fmt.Sprintf("%t", cert.IsSelfSigned),
+				fmt.Sprintf("%t", cert.IsWildcard),
+				fmt.Sprintf("%t", cert.MatchesDomain),
+				fmt.Sprintf("%t", cert.HostnameCovered),
+				fmt.Sprintf("%d", cert.SANCount),
+				"",
+			}
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) formatCertComparisonCSV(data interface{}, writer io.Writer) error {
+	comparison := data.(*ssl.CertComparison)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	// Write header
+	if err := csvWriter.Write([]string{"Field", "EndpointA", "EndpointB", "ValueA", "ValueB", "Match"}); err != nil {
+		return err
+	}
+
+	// Write data
+	for _, field := range comparison.Fields {
+		row := []string{
+			field.Field,
+			comparison.EndpointA,
+			comparison.EndpointB,
+			field.A,
+			field.B,
+			fmt.Sprintf("%t", field.Match),
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (f *Formatter) formatScanResultCSV(data interface{}, writer io.Writer) error {
 	result := data.(*network.ScanResult)
+	if f.summaryOnly {
+		return f.formatScanSummaryCSV(result, writer)
+	}
+	if result.Summary.TotalPorts == 0 {
+		return f.formatPingSweepCSV(result, writer)
+	}
+
 	csvWriter := f.createCSVWriter(writer)
 	defer csvWriter.Flush()
 
 	// Write header
-	header := []string{"Network", "IP", "Alive", "Port", "Open", "Service", "Banner", "Duration", "TotalHosts", "LiveHosts"}
+	header := []string{"Network", "IP", "Hostname", "Alive", "TTL", "OSHint", "MAC", "Vendor", "Port", "Protocol", "State", "Open", "Service", "Banner", "CertCN", "CertSANs", "Error", "Duration", "TotalHosts", "LiveHosts"}
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
@@ -707,11 +2345,21 @@ func (f *Formatter) formatScanResultCSV(data interface{}, writer io.Writer) erro
 				row := []string{
 					result.Network,
 					host.IP,
+					host.Hostname,
 					fmt.Sprintf("%t", host.Alive),
+					formatTTL(host.TTL),
+					host.OSHint,
+					host.MAC,
+					host.Vendor,
 					fmt.Sprintf("%d", port.Port),
+					port.Protocol,
+					port.State,
 					fmt.Sprintf("%t", port.Open),
 					port.Service,
 					port.Banner,
+					port.CertCN,
+					strings.Join(port.CertSANs, ";"),
+					port.Error,
 					result.Duration.String(),
 					fmt.Sprintf("%d", result.Summary.TotalHosts),
 					fmt.Sprintf("%d", result.Summary.LiveHosts),
@@ -721,15 +2369,25 @@ func (f *Formatter) formatScanResultCSV(data interface{}, writer io.Writer) erro
 				}
 			}
 		} else {
-			// Host alive but no open ports
+			// No open ports, or the host could not be scanned
 			row := []string{
 				result.Network,
 				host.IP,
+				host.Hostname,
 				fmt.Sprintf("%t", host.Alive),
+				formatTTL(host.TTL),
+				host.OSHint,
+				host.MAC,
+				host.Vendor,
+				"-",
+				"-",
 				"-",
 				"false",
 				"-",
 				"-",
+				"",
+				"",
+				host.Error,
 				result.Duration.String(),
 				fmt.Sprintf("%d", result.Summary.TotalHosts),
 				fmt.Sprintf("%d", result.Summary.LiveHosts),
@@ -743,13 +2401,95 @@ func (f *Formatter) formatScanResultCSV(data interface{}, writer io.Writer) erro
 	return nil
 }
 
+// formatScanSummaryCSV writes the counts of a scan as a single row
+func (f *Formatter) formatScanSummaryCSV(result *network.ScanResult, writer io.Writer) error {
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	header := []string{"Network", "TotalHosts", "HostsScanned", "LiveHosts", "Generated", "Excluded", "TotalPorts", "PortsScanned", "OpenPorts", "Interrupted", "Duration"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	summary := result.Summary
+	return csvWriter.Write([]string{
+		result.Network,
+		fmt.Sprintf("%d", summary.TotalHosts),
+		fmt.Sprintf("%d", summary.HostsScanned),
+		fmt.Sprintf("%d", summary.LiveHosts),
+		fmt.Sprintf("%d", summary.Generated),
+		fmt.Sprintf("%d", summary.Excluded),
+		fmt.Sprintf("%d", summary.TotalPorts),
+		fmt.Sprintf("%d", summary.PortsScanned),
+		fmt.Sprintf("%d", summary.OpenPorts),
+		fmt.Sprintf("%t", summary.Interrupted),
+		result.Duration.String(),
+	})
+}
+
+// formatPingSweepCSV writes one row per live host; the latency statistics
+// columns are empty unless hosts were probed more than once
+func (f *Formatter) formatPingSweepCSV(result *network.ScanResult, writer io.Writer) error {
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	header := []string{"Network", "IP", "Alive", "Method", "Latency", "Sent", "Received", "Loss", "Min", "Avg", "Max", "Jitter", "TTL", "OSHint", "MAC", "Vendor", "Duration", "TotalHosts", "LiveHosts"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, host := range result.Hosts {
+		stats := make([]string, 7)
+		if host.Stats != nil {
+			stats = []string{
+				fmt.Sprintf("%d", host.Stats.Sent),
+				fmt.Sprintf("%d", host.Stats.Received),
+				fmt.Sprintf("%.1f", host.Stats.Loss()),
+				host.Stats.Min.String(),
+				host.Stats.Avg.String(),
+				host.Stats.Max.String(),
+				host.Stats.Jitter.String(),
+			}
+		}
+
+		row := []string{result.Network, host.IP, fmt.Sprintf("%t", host.Alive), host.Method, host.Latency.String()}
+		row = append(row, stats...)
+		row = append(row,
+			formatTTL(host.TTL),
+			host.OSHint,
+			host.MAC,
+			host.Vendor,
+			result.Duration.String(),
+			fmt.Sprintf("%d", result.Summary.TotalHosts),
+			fmt.Sprintf("%d", result.Summary.LiveHosts),
+		)
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (f *Formatter) formatHostResultCSV(data interface{}, writer io.Writer) error {
 	result := data.(*network.HostResult)
 	csvWriter := f.createCSVWriter(writer)
 	defer csvWriter.Flush()
 
+	if f.summaryOnly {
+		open := 0
+		for _, port := range result.Ports {
+			if port.Open {
+				open++
+			}
+		}
+		if err := csvWriter.Write([]string{"IP", "Alive", "OpenPorts"}); err != nil {
+			return err
+		}
+		return csvWriter.Write([]string{result.IP, fmt.Sprintf("%t", result.Alive), fmt.Sprintf("%d", open)})
+	}
+
 	// Write header
-	header := []string{"IP", "Alive", "Port", "Open", "Service", "Banner"}
+	header := []string{"IP", "Alive", "Port", "Protocol", "State", "Open", "Service", "Banner", "Error"}
 	if err := csvWriter.Write(header); err != nil {
 		return err
 	}
@@ -760,9 +2500,77 @@ func (f *Formatter) formatHostResultCSV(data interface{}, writer io.Writer) erro
 			result.IP,
 			fmt.Sprintf("%t", result.Alive),
 			fmt.Sprintf("%d", port.Port),
+			port.Protocol,
+			port.State,
 			fmt.Sprintf("%t", port.Open),
 			port.Service,
 			port.Banner,
+			port.Error,
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) formatScanDiffCSV(data interface{}, writer io.Writer) error {
+	diff := data.(*network.ScanDiff)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write([]string{"IP", "Hostname", "Change", "Port", "Protocol", "Service", "OldNetwork", "NewNetwork"}); err != nil {
+		return err
+	}
+
+	for _, entry := range diff.Changes {
+		port := ""
+		if entry.Port != 0 {
+			port = fmt.Sprintf("%d", entry.Port)
+		}
+		row := []string{
+			entry.IP,
+			entry.Hostname,
+			string(entry.Change),
+			port,
+			entry.Protocol,
+			entry.Service,
+			diff.OldNetwork,
+			diff.NewNetwork,
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) formatScanHistoryCSV(data interface{}, writer io.Writer) error {
+	report := data.(*network.ScanHistoryReport)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write([]string{"IP", "Hostname", "Port", "Protocol", "Service", "Banner", "FirstSeen", "LastSeen", "Sightings"}); err != nil {
+		return err
+	}
+
+	for _, entry := range report.Entries {
+		port := ""
+		if entry.Port != 0 {
+			port = fmt.Sprintf("%d", entry.Port)
+		}
+		row := []string{
+			entry.IP,
+			entry.Hostname,
+			port,
+			entry.Protocol,
+			entry.Service,
+			entry.Banner,
+			entry.FirstSeen.Format("2006-01-02 15:04:05"),
+			entry.LastSeen.Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%d", entry.Sightings),
 		}
 		if err := csvWriter.Write(row); err != nil {
 			return err
@@ -772,6 +2580,50 @@ func (f *Formatter) formatHostResultCSV(data interface{}, writer io.Writer) erro
 	return nil
 }
 
+func (f *Formatter) formatTracerouteResultCSV(data interface{}, writer io.Writer) error {
+	result := data.(*network.TracerouteResult)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	header := []string{"Target", "TargetIP", "Protocol", "Port", "Method", "Hop", "Probe", "IP", "Hostname", "Answered", "RTT", "Reached"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	// One row per probe, so hops whose probes were answered by different routers keep every responder
+	for _, hop := range result.Hops {
+		for i, probe := range hop.Probes {
+			rtt := ""
+			if probe.Answered {
+				rtt = probe.RTT.String()
+			}
+			hostname := ""
+			if probe.IP != "" && probe.IP == hop.IP {
+				hostname = hop.Hostname
+			}
+			row := []string{
+				result.Target,
+				result.IP,
+				result.Protocol,
+				fmt.Sprintf("%d", result.Port),
+				result.Method,
+				fmt.Sprintf("%d", hop.TTL),
+				fmt.Sprintf("%d", i+1),
+				probe.IP,
+				hostname,
+				fmt.Sprintf("%t", probe.Answered),
+				rtt,
+				fmt.Sprintf("%t", hop.Reached && probe.IP == result.IP),
+			}
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (f *Formatter) formatDNSSECResultCSV(data interface{}, writer io.Writer) error {
 	result := data.(*dnssec.ValidationResult)
 	csvWriter := f.createCSVWriter(writer)
@@ -785,6 +2637,9 @@ func (f *Formatter) formatDNSSECResultCSV(data interface{}, writer io.Writer) er
 		"IsValid",
 		"ValidationErrors",
 		"CheckedAt",
+		"DenialType",
+		"DenialValidated",
+		"DenialErrors",
 	}
 	if err := csvWriter.Write(header); err != nil {
 		return err
@@ -797,6 +2652,9 @@ func (f *Formatter) formatDNSSECResultCSV(data interface{}, writer io.Writer) er
 		fmt.Sprintf("%t", result.IsValid),
 		strings.Join(result.ValidationErrors, "; "),
 		result.Timestamp.Format("2006-01-02 15:04:05"),
+		result.DenialType,
+		fmt.Sprintf("%t", result.DenialValidated),
+		strings.Join(result.DenialErrors, "; "),
 	}
 	if err := csvWriter.Write(row); err != nil {
 		return err
@@ -873,9 +2731,74 @@ func (f *Formatter) formatDNSSECResultCSV(data interface{}, writer io.Writer) er
 		}
 	}
 
+	// Write chain of trust
+	if len(result.Chain) > 0 {
+		if err := csvWriter.Write([]string{"", "Chain of Trust"}); err != nil {
+			return err
+		}
+		if err := csvWriter.Write([]string{"Zone", "HasDS", "DSSigned", "IsSigned", "IsValid", "KeyTags", "Errors"}); err != nil {
+			return err
+		}
+		for _, zone := range result.Chain {
+			keyTags := make([]string, len(zone.KeyTags))
+			for i, tag := range zone.KeyTags {
+				keyTags[i] = fmt.Sprintf("%d", tag)
+			}
+			if err := csvWriter.Write([]string{
+				zone.Zone,
+				fmt.Sprintf("%t", zone.HasDS),
+				fmt.Sprintf("%t", zone.DSSigned),
+				fmt.Sprintf("%t", zone.IsSigned),
+				fmt.Sprintf("%t", zone.IsValid),
+				strings.Join(keyTags, " "),
+				strings.Join(zone.Errors, "; "),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) formatWhoisResultTable(data interface{}, writer io.Writer) error {
+	result := data.(*whois.Result)
+	fmt.Fprintf(writer, "🔎 WHOIS for %s\n", dns.DisplayDomain(result.Domain))
+	fmt.Fprintf(writer, "📡 Server: %s\n", result.Server)
+	if len(result.Referrals) > 1 {
+		fmt.Fprintf(writer, "↪️  Referrals: %s\n", strings.Join(result.Referrals, " → "))
+	}
+	for _, e := range result.Errors {
+		fmt.Fprintf(writer, "⚠️  %s\n", e)
+	}
+	fmt.Fprintf(writer, "----------------------------------------\n\n")
+
+	fmt.Fprint(writer, strings.TrimRight(result.Raw, "\r\n")+"\n")
 	return nil
 }
 
+func (f *Formatter) formatWhoisResultCSV(data interface{}, writer io.Writer) error {
+	result := data.(*whois.Result)
+	csvWriter := f.createCSVWriter(writer)
+	defer csvWriter.Flush()
+
+	header := []string{"Domain", "Server", "Registrar", "CreatedAt", "UpdatedAt", "ExpiresAt", "Nameservers", "Status"}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	return csvWriter.Write([]string{
+		result.Domain,
+		result.Server,
+		result.Registrar,
+		result.CreatedAt,
+		result.UpdatedAt,
+		result.ExpiresAt,
+		strings.Join(result.Nameservers, "; "),
+		strings.Join(result.Status, "; "),
+	})
+}
+
 // Utility functions
 // getNameserverDisplayName creates a display name with both nameserver name and IP
 func (f *Formatter) getNameserverDisplayName(ip string) string {
@@ -0,0 +1,216 @@
+// =============================================================================
+// internal/output/import.go - Re-formatting results saved as JSON or JSONL
+// =============================================================================
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bryanCE/sysadmin/internal/dns"
+)
+
+// savedFormatters decode a saved result of each type and write it through the
+// matching Format method
+var savedFormatters = map[string]func(f *Formatter, data []byte, writer io.Writer) error{
+	"query": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatQueryResult)
+	},
+	"multiquery": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatMultiQueryResult)
+	},
+	"records": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatRecordsReport)
+	},
+	"propagation": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatPropagationResult)
+	},
+	"consistency": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatConsistencyReport)
+	},
+	"diff": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatDiffResult)
+	},
+	"rdns-check": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, func(result *dns.ReverseDNSResult, writer io.Writer) error {
+			if err := f.FormatReverseDNSResult(result, writer); err != nil {
+				return err
+			}
+			// As in rdns-check, tables list the issues below the result
+			if len(result.Issues) > 0 && f.format == FormatTable {
+				fmt.Fprintln(writer)
+				return f.FormatConsistencyIssues(result.Issues, writer)
+			}
+			return nil
+		})
+	},
+	"srv": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatSRVResult)
+	},
+	"bulk": func(f *Formatter, data []byte, writer io.Writer) error {
+		if isBulkJSONL(data) {
+			summary, err := readBulkJSONL(data)
+			if err != nil {
+				return err
+			}
+			return f.FormatBulkSummary(summary, writer)
+		}
+		return formatSaved(data, writer, f.FormatBulkSummary)
+	},
+	"ssl-check": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatCertInfo)
+	},
+	"ssl-all-ips": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatMultiIPCertResult)
+	},
+	"ssl-compare": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatCertComparison)
+	},
+	"scanresult": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatScanResult)
+	},
+	"hostresult": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatHostResult)
+	},
+	"traceroute": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatTracerouteResult)
+	},
+	"dnssec": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatDNSSECResult)
+	},
+	"whois": func(f *Formatter, data []byte, writer io.Writer) error {
+		return formatSaved(data, writer, f.FormatWhoisResult)
+	},
+}
+
+// SavedResultTypes lists the result types FormatSaved accepts, sorted by name
+func SavedResultTypes() []string {
+	types := make([]string, 0, len(savedFormatters))
+	for name := range savedFormatters {
+		types = append(types, name)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// FormatSaved decodes a result previously written with --format json and
+// writes it in the formatter's format, so saved runs can be turned into
+// tables or CSV without repeating the network operations. Bulk results may
+// also be a --stream JSONL file.
+func (f *Formatter) FormatSaved(resultType string, data []byte, writer io.Writer) error {
+	format, ok := savedFormatters[strings.ToLower(resultType)]
+	if !ok {
+		return fmt.Errorf("unknown result type %q (use %s)", resultType, strings.Join(SavedResultTypes(), ", "))
+	}
+	return format(f, data, writer)
+}
+
+// formatSaved unmarshals data into a T and passes it to format
+func formatSaved[T any](data []byte, writer io.Writer, format func(*T, io.Writer) error) error {
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to parse saved result: %w", err)
+	}
+	return format(&result, writer)
+}
+
+// isBulkJSONL reports whether data starts with a bulk JSONL line rather than a
+// JSON summary; only the lines carry a "type" field
+func isBulkJSONL(data []byte) bool {
+	var first struct {
+		Type string `json:"type"`
+	}
+	return json.NewDecoder(bytes.NewReader(data)).Decode(&first) == nil && first.Type != ""
+}
+
+// readBulkJSONL rebuilds a bulk summary from the result and summary lines of
+// a stream. The lines carry durations but no start times, so each result is
+// placed at the zero time.
+func readBulkJSONL(data []byte) (*dns.BulkSummary, error) {
+	summary := &dns.BulkSummary{Operation: dns.BulkOperationQuery}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for line := 1; ; line++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse line %d: %w", line, err)
+		}
+
+		var kind struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &kind); err != nil {
+			return nil, fmt.Errorf("failed to parse line %d: %w", line, err)
+		}
+		switch kind.Type {
+		case "result":
+			var entry bulkResultLine
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				return nil, fmt.Errorf("failed to parse line %d: %w", line, err)
+			}
+			summary.Results = append(summary.Results, bulkResultFromLine(entry))
+		case "summary":
+			var totals bulkSummaryLine
+			if err := json.Unmarshal(raw, &totals); err != nil {
+				return nil, fmt.Errorf("failed to parse line %d: %w", line, err)
+			}
+			summary.TotalDomains = totals.TotalDomains
+			summary.Successful = totals.Successful
+			summary.Failed = totals.Failed
+			summary.Duration = totals.Duration
+			summary.Duplicates = totals.Duplicates
+			summary.Skipped = totals.Skipped
+			summary.Queries = totals.Queries
+			summary.QPS = totals.QPS
+			summary.Concurrency = totals.Concurrency
+			summary.TimedOut = totals.TimedOut
+			summary.AutoRetried = totals.AutoRetried
+			summary.Recovered = totals.Recovered
+			summary.Stats = totals.Stats
+			summary.ByRecordType = totals.ByRecordType
+			summary.Filter = totals.Filter
+		default:
+			return nil, fmt.Errorf("line %d: unexpected line type %q", line, kind.Type)
+		}
+	}
+
+	// The stream doesn't name its operation; the result payloads tell
+	for _, result := range summary.Results {
+		switch {
+		case result.Propagation != nil:
+			summary.Operation = dns.BulkOperationPropagation
+		case result.Consistency != nil:
+			summary.Operation = dns.BulkOperationConsistency
+		case result.Query != nil && result.Query.Query.RecordType == dns.RecordTypePTR:
+			summary.Operation = dns.BulkOperationPTR
+		default:
+			continue
+		}
+		break
+	}
+	return summary, nil
+}
+
+// bulkResultFromLine is the inverse of FormatBulkResultLine
+func bulkResultFromLine(entry bulkResultLine) dns.BulkResult {
+	result := dns.BulkResult{
+		Domain:      entry.Domain,
+		Success:     entry.Success,
+		EndTime:     time.Time{}.Add(entry.Duration),
+		TraceID:     entry.TraceID,
+		Query:       entry.Query,
+		Propagation: entry.Propagation,
+		Consistency: entry.Consistency,
+	}
+	if entry.Error != "" {
+		result.Error = errors.New(entry.Error)
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+// =============================================================================
+// internal/output/sort.go - Row ordering shared by every output format
+// =============================================================================
+package output
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bryanCE/sysadmin/internal/dns"
+	"github.com/bryanCE/sysadmin/internal/network"
+)
+
+// Sort keys accepted by SetSort for each kind of result. Names and addresses
+// sort A-Z, TTLs, priorities and latency lowest first, and open-port counts
+// and durations largest first.
+var (
+	PingSortKeys   = []string{"ip", "hostname", "latency"}
+	ScanSortKeys   = []string{"ip", "hostname", "open-ports", "latency", "port", "service", "state"}
+	PortSortKeys   = []string{"port", "service", "state"}
+	RecordSortKeys = []string{"ttl", "type", "value", "priority"}
+	BulkSortKeys   = []string{"domain", "duration", "status"}
+)
+
+// CheckSortKey reports an error when key is set and not one of keys
+func CheckSortKey(key string, keys []string) error {
+	if key == "" || slices.Contains(keys, key) {
+		return nil
+	}
+	return fmt.Errorf("invalid sort key %q (use %s)", key, strings.Join(keys, ", "))
+}
+
+// SetSort reorders scan, port, record and bulk results by key before they are
+// written, so table, JSON, CSV and XML output share one order. The results
+// passed to the Format methods are sorted in place; an empty key keeps the
+// order they came in.
+func (f *Formatter) SetSort(key string) {
+	f.sortKey = key
+}
+
+// sortScanResult orders hosts by the host keys, or the ports of every host by
+// the port keys
+func sortScanResult(result *network.ScanResult, key string) {
+	hosts := result.Hosts
+	switch key {
+	case "ip":
+		sort.SliceStable(hosts, func(i, j int) bool { return lessAddr(hosts[i].IP, hosts[j].IP) })
+	case "hostname":
+		sort.SliceStable(hosts, func(i, j int) bool { return hosts[i].Hostname < hosts[j].Hostname })
+	case "open-ports":
+		sort.SliceStable(hosts, func(i, j int) bool { return openPortCount(hosts[i]) > openPortCount(hosts[j]) })
+	case "latency":
+		sort.SliceStable(hosts, func(i, j int) bool { return hosts[i].Latency < hosts[j].Latency })
+	default:
+		for i := range hosts {
+			sortPorts(hosts[i].Ports, key)
+		}
+	}
+}
+
+// sortPorts orders port results by port number, service name or state
+func sortPorts(ports []network.PortResult, key string) {
+	switch key {
+	case "port":
+		sort.SliceStable(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+	case "service":
+		sort.SliceStable(ports, func(i, j int) bool {
+			return strings.ToLower(serviceLabel(ports[i])) < strings.ToLower(serviceLabel(ports[j]))
+		})
+	case "state":
+		sort.SliceStable(ports, func(i, j int) bool { return ports[i].State < ports[j].State })
+	}
+}
+
+// sortRecords orders DNS records by TTL, type, value or priority
+func sortRecords(records []dns.DNSRecord, key string) {
+	switch key {
+	case "ttl":
+		sort.SliceStable(records, func(i, j int) bool { return records[i].TTL < records[j].TTL })
+	case "type":
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Type < records[j].Type })
+	case "value":
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Value < records[j].Value })
+	case "priority":
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Priority < records[j].Priority })
+	}
+}
+
+// sortBulkResults orders bulk results by domain, slowest first, or failures first
+func sortBulkResults(results []dns.BulkResult, key string) {
+	switch key {
+	case "domain":
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Domain < results[j].Domain })
+	case "duration":
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].EndTime.Sub(results[i].StartTime) > results[j].EndTime.Sub(results[j].StartTime)
+		})
+	case "status":
+		sort.SliceStable(results, func(i, j int) bool { return !results[i].Success && results[j].Success })
+	}
+}
+
+// openPortCount counts the ports of a host that answered
+func openPortCount(host network.HostResult) int {
+	open := 0
+	for _, port := range host.Ports {
+		if port.Open {
+			open++
+		}
+	}
+	return open
+}
+
+// lessAddr compares IP addresses numerically, placing unparsable ones last
+func lessAddr(a, b string) bool {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	switch {
+	case errA != nil || errB != nil:
+		return errA == nil && errB != nil
+	default:
+		return addrA.Less(addrB)
+	}
+}
@@ -13,6 +13,7 @@ import (
 type Table struct {
 	headers []string
 	rows    [][]string
+	details map[int][]string // row index -> full-width lines printed beneath it
 	widths  []int
 }
 
@@ -26,6 +27,7 @@ func NewTable(headers []string) *Table {
 	return &Table{
 		headers: headers,
 		rows:    make([][]string, 0),
+		details: make(map[int][]string),
 		widths:  widths,
 	}
 }
@@ -52,6 +54,37 @@ func (t *Table) AddRow(row []string) {
 	t.rows = append(t.rows, row)
 }
 
+// AddDetail adds a line of text beneath the most recently added row, spanning all columns and
+// wrapped to the table width
+func (t *Table) AddDetail(text string) {
+	if len(t.rows) == 0 || text == "" {
+		return
+	}
+	last := len(t.rows) - 1
+	t.details[last] = append(t.details[last], text)
+}
+
+// wrapText breaks text into lines of at most width characters at word boundaries
+func wrapText(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 // Render renders the table to the writer
 func (t *Table) Render(writer io.Writer) error {
 	if len(t.headers) == 0 {
@@ -82,7 +115,7 @@ func (t *Table) Render(writer io.Writer) error {
 	fmt.Fprintf(writer, "├%s┤\n", strings.Repeat("─", totalWidth))
 
 	// Print rows
-	for _, row := range t.rows {
+	for r, row := range t.rows {
 		fmt.Fprint(writer, "│")
 		for i, cell := range row {
 			fmt.Fprintf(writer, " %-*s ", t.widths[i], cell)
@@ -91,6 +124,14 @@ func (t *Table) Render(writer io.Writer) error {
 			}
 		}
 		fmt.Fprintf(writer, "│\n")
+
+		// Detail lines span the row's full inner width
+		detailWidth := totalWidth
+		for _, detail := range t.details[r] {
+			for _, line := range wrapText(detail, detailWidth-4) {
+				fmt.Fprintf(writer, "│   %-*s │\n", detailWidth-4, line)
+			}
+		}
 	}
 
 	// Print bottom border
@@ -1,37 +1,211 @@
 package ssl
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/xml"
 	"fmt"
 	"net"
+	"sort"
+	"sync"
 	"time"
 )
 
 // CertInfo contains SSL certificate details
 type CertInfo struct {
-	Domain       string
-	Issuer       string
-	CommonName   string
-	DNSNames     []string
-	NotBefore    time.Time
-	NotAfter     time.Time
-	ExpiresIn    int
-	IsValid      bool
-	SerialNumber string
-	SignatureAlg string
+	Domain        string        `json:"domain"`
+	Issuer        string        `json:"issuer"`
+	CommonName    string        `json:"common_name"`
+	DNSNames      []string      `json:"dns_names"`
+	NotBefore     time.Time     `json:"not_before"`
+	NotAfter      time.Time     `json:"not_after"`
+	ExpiresIn     int           `json:"expires_in_days"`
+	IsValid       bool          `json:"is_valid"`
+	SerialNumber  string        `json:"serial_number"`
+	SignatureAlg  string        `json:"signature_algorithm"`
+	HandshakeTime time.Duration `json:"handshake_time"`
+	IsSelfSigned  bool          `json:"is_self_signed"`
+	IsWildcard    bool          `json:"is_wildcard"`
+	MatchesDomain bool          `json:"matches_domain"` // Queried domain is covered by the CN or a SAN
+	SANCount      int           `json:"san_count"`
+
+	HostnameCovered bool   `json:"hostname_covered"`        // Queried domain is covered by a SAN, as TLS clients require
+	HostnameNote    string `json:"hostname_note,omitempty"` // Why the domain isn't covered, listing the SANs
+
+	SHA256Fingerprint string    `json:"sha256_fingerprint"`
+	SPKIFingerprint   string    `json:"spki_fingerprint"`         // SHA-256 of the SubjectPublicKeyInfo
+	PubliclyTrusted   bool      `json:"publicly_trusted"`         // Chain verifies against the system root store
+	SCTs              []SCTInfo `json:"scts"`                     // Signed Certificate Timestamps from all sources
+	CTLogEntries      *int      `json:"ct_log_entries,omitempty"` // crt.sh entry count; nil unless looked up
+
+	ClientCertRequested bool     `json:"client_cert_requested"`     // Server sent a CertificateRequest
+	ClientCertSent      bool     `json:"client_cert_sent"`          // A client certificate was presented in response
+	ClientCertCAs       []string `json:"client_cert_cas,omitempty"` // CA names advertised in the CertificateRequest
+
+	Warnings []string `json:"warnings"`
+}
+
+// IPCertResult holds the certificate check outcome for a single address
+type IPCertResult struct {
+	IP    string    `json:"ip"`
+	Cert  *CertInfo `json:"cert,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// MultiIPResult contains certificate checks for every address behind a hostname
+type MultiIPResult struct {
+	Domain     string                   `json:"domain"`
+	Port       string                   `json:"port"`
+	Results    map[string]*IPCertResult `json:"results"` // IP -> result
+	Reachable  int                      `json:"reachable"`
+	Failed     int                      `json:"failed"`
+	Consistent bool                     `json:"consistent"`
+	Verdict    string                   `json:"verdict"`
+	Warnings   []string                 `json:"warnings"`
+	Timestamp  time.Time                `json:"timestamp"`
 }
 
 // CheckCertificate validates an SSL certificate for a given domain
-func CheckCertificate(domain string, port string) (*CertInfo, error) {
-	address := net.JoinHostPort(domain, port)
-	conn, err := tls.Dial("tcp", address, &tls.Config{
-		InsecureSkipVerify: true, // We'll validate manually
-	})
+func CheckCertificate(ctx context.Context, domain string, port string, dialCfg DialConfig) (*CertInfo, error) {
+	return checkCertificate(ctx, domain, net.JoinHostPort(domain, port), dialCfg)
+}
+
+// CheckCertificateAt validates the certificate served by a specific IP address,
+// presenting domain as the SNI server name
+func CheckCertificateAt(ctx context.Context, domain, ip, port string, dialCfg DialConfig) (*CertInfo, error) {
+	return checkCertificate(ctx, domain, net.JoinHostPort(ip, port), dialCfg)
+}
+
+// CheckAllIPs checks the certificate served by each of the given addresses for domain.
+// Unreachable addresses are recorded as individual failures rather than aborting the run.
+func CheckAllIPs(ctx context.Context, domain, port string, ips []string, dialCfg DialConfig) *MultiIPResult {
+	result := &MultiIPResult{
+		Domain:    domain,
+		Port:      port,
+		Results:   make(map[string]*IPCertResult),
+		Timestamp: time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for _, ip := range ips {
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+
+			ipResult := &IPCertResult{IP: ip}
+			info, err := CheckCertificateAt(ctx, domain, ip, port, dialCfg)
+			if err != nil {
+				ipResult.Error = err.Error()
+			} else {
+				ipResult.Cert = info
+			}
+
+			mu.Lock()
+			result.Results[ip] = ipResult
+			mu.Unlock()
+		}(ip)
+	}
+	wg.Wait()
+
+	summarizeMultiIPResult(result)
+	return result
+}
+
+// SortedIPs returns the checked addresses in a stable display order
+func (r *MultiIPResult) SortedIPs() []string {
+	ips := make([]string, 0, len(r.Results))
+	for ip := range r.Results {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+// MarshalXML encodes Results as a list of result elements in SortedIPs order, since
+// encoding/xml cannot encode maps; JSON keeps them keyed by IP
+func (r MultiIPResult) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain MultiIPResult
+	results := make([]*IPCertResult, 0, len(r.Results))
+	for _, ip := range r.SortedIPs() {
+		results = append(results, r.Results[ip])
+	}
+	return e.EncodeElement(struct {
+		plain
+		Results []*IPCertResult `xml:"Results>Result"`
+	}{plain(r), results}, start)
+}
+
+// summarizeMultiIPResult computes counts and the consolidated verdict
+func summarizeMultiIPResult(result *MultiIPResult) {
+	serials := make(map[string][]string)
+	for _, ip := range result.SortedIPs() {
+		ipResult := result.Results[ip]
+		if ipResult.Cert == nil {
+			result.Failed++
+			continue
+		}
+		result.Reachable++
+		serials[ipResult.Cert.SerialNumber] = append(serials[ipResult.Cert.SerialNumber], ip)
+	}
+
+	result.Consistent = len(serials) <= 1
+	if !result.Consistent {
+		for serial, ips := range serials {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("serial %s served by %v", serial, ips))
+		}
+		sort.Strings(result.Warnings)
+	}
+
+	switch {
+	case result.Reachable == 0:
+		result.Verdict = "failed: no address could be checked"
+	case !result.Consistent:
+		result.Verdict = "warning: serial numbers differ across IPs"
+	case result.Failed > 0:
+		result.Verdict = fmt.Sprintf("warning: %d of %d addresses unreachable", result.Failed, len(result.Results))
+	default:
+		result.Verdict = "ok: all addresses serve the same certificate"
+	}
+}
+
+// checkCertificate connects to address and inspects the leaf certificate, using domain for SNI
+func checkCertificate(ctx context.Context, domain, address string, dialCfg DialConfig) (*CertInfo, error) {
+	timeout := dialCfg.timeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	rawConn, err := dialCfg.dial(ctx, address)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect: %v", err)
+		return nil, connectionError(ctx, "failed to connect", timeout, err)
 	}
+
+	clientAuth := &clientAuthState{cert: dialCfg.ClientCertificate}
+	conn := tls.Client(rawConn, &tls.Config{
+		ServerName:           domain,
+		InsecureSkipVerify:   true, // We'll validate manually
+		GetClientCertificate: clientAuth.getClientCertificate,
+	})
 	defer conn.Close()
 
+	handshakeStart := time.Now()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		if rejected := clientAuth.rejectionError(err); rejected != nil {
+			return nil, rejected
+		}
+		return nil, connectionError(ctx, "TLS handshake failed", timeout, err)
+	}
+	handshakeTime := time.Since(handshakeStart)
+
+	if err := clientAuth.probe(conn); err != nil {
+		return nil, err
+	}
+
 	state := conn.ConnectionState()
 	if len(state.PeerCertificates) == 0 {
 		return nil, fmt.Errorf("no certificates presented")
@@ -39,20 +213,55 @@ func CheckCertificate(domain string, port string) (*CertInfo, error) {
 
 	cert := state.PeerCertificates[0]
 	now := time.Now()
+	fingerprint := sha256.Sum256(cert.Raw)
+	spkiFingerprint := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
 	expiresIn := int(cert.NotAfter.Sub(now).Hours() / 24)
 
 	info := &CertInfo{
-		Domain:       domain,
-		Issuer:       cert.Issuer.String(),
-		CommonName:   cert.Subject.CommonName,
-		DNSNames:     cert.DNSNames,
-		NotBefore:    cert.NotBefore,
-		NotAfter:     cert.NotAfter,
-		ExpiresIn:    expiresIn,
-		IsValid:      now.After(cert.NotBefore) && now.Before(cert.NotAfter),
-		SerialNumber: cert.SerialNumber.String(),
-		SignatureAlg: cert.SignatureAlgorithm.String(),
+		Domain:        domain,
+		Issuer:        cert.Issuer.String(),
+		CommonName:    cert.Subject.CommonName,
+		DNSNames:      cert.DNSNames,
+		NotBefore:     cert.NotBefore,
+		NotAfter:      cert.NotAfter,
+		ExpiresIn:     expiresIn,
+		IsValid:       now.After(cert.NotBefore) && now.Before(cert.NotAfter),
+		SerialNumber:  cert.SerialNumber.String(),
+		SignatureAlg:  cert.SignatureAlgorithm.String(),
+		HandshakeTime: handshakeTime,
+		IsSelfSigned:  isSelfSigned(cert),
+		IsWildcard:    isWildcard(cert),
+		MatchesDomain: matchesDomain(cert, domain),
+		SANCount:      len(cert.DNSNames),
+
+		SHA256Fingerprint: hex.EncodeToString(fingerprint[:]),
+		SPKIFingerprint:   hex.EncodeToString(spkiFingerprint[:]),
+		PubliclyTrusted:   isPubliclyTrusted(state.PeerCertificates),
+		SCTs:              collectSCTs(state.SignedCertificateTimestamps, cert, state.OCSPResponse),
+
+		ClientCertRequested: clientAuth.requested,
+		ClientCertSent:      clientAuth.sent,
+		ClientCertCAs:       clientAuth.acceptableCAs,
+	}
+
+	info.HostnameCovered = coversHostname(cert, domain)
+	if !info.HostnameCovered {
+		info.HostnameNote = hostnameNote(cert, domain, info.IsValid)
+	}
+
+	if info.PubliclyTrusted && len(info.SCTs) == 0 {
+		info.Warnings = append(info.Warnings, "publicly trusted certificate has no SCTs")
 	}
 
 	return info, nil
 }
+
+// isPubliclyTrusted reports whether the presented chain verifies against the system roots
+func isPubliclyTrusted(chain []*x509.Certificate) bool {
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{Intermediates: intermediates})
+	return err == nil
+}
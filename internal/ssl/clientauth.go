@@ -0,0 +1,123 @@
+// =============================================================================
+// internal/ssl/clientauth.go - Client certificate (mutual TLS) handling
+// =============================================================================
+package ssl
+
+import (
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrClientCertRejected is returned when the server aborts the handshake over the client certificate
+var ErrClientCertRejected = errors.New("client certificate rejected by server")
+
+// clientCertProbeWindow is how long to wait for a post-handshake rejection under TLS 1.3,
+// where the server verifies the client certificate only after the client finishes
+const clientCertProbeWindow = 500 * time.Millisecond
+
+// Remote alerts a server sends when it refuses the client certificate
+var clientCertAlerts = []string{
+	"tls: bad certificate",
+	"tls: unsupported certificate",
+	"tls: revoked certificate",
+	"tls: expired certificate",
+	"tls: unknown certificate",
+	"tls: unknown certificate authority",
+	"tls: access denied",
+	"tls: certificate required",
+	"tls: handshake failure",
+}
+
+// LoadClientCertificate loads a PEM-encoded certificate and private key for mutual TLS
+func LoadClientCertificate(certFile, keyFile string) (*tls.Certificate, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("both a client certificate and key are required")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+// clientAuthState records what the server asked for in its CertificateRequest
+type clientAuthState struct {
+	cert          *tls.Certificate
+	requested     bool
+	sent          bool
+	acceptableCAs []string
+}
+
+// getClientCertificate is installed as tls.Config.GetClientCertificate
+func (s *clientAuthState) getClientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	s.requested = true
+	for _, der := range info.AcceptableCAs {
+		s.acceptableCAs = append(s.acceptableCAs, distinguishedName(der))
+	}
+
+	if s.cert == nil {
+		// An empty certificate tells the server we have none to offer
+		return &tls.Certificate{}, nil
+	}
+	s.sent = true
+	return s.cert, nil
+}
+
+// rejectionError wraps err in ErrClientCertRejected when it is a client-certificate alert
+// from a server that requested one; otherwise it returns nil
+func (s *clientAuthState) rejectionError(err error) error {
+	if !s.requested || !isClientCertAlert(err) {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrClientCertRejected, err)
+}
+
+// probe waits briefly for a TLS 1.3 post-handshake alert rejecting the client certificate
+func (s *clientAuthState) probe(conn *tls.Conn) error {
+	if !s.requested || conn.ConnectionState().Version != tls.VersionTLS13 {
+		return nil
+	}
+
+	conn.SetReadDeadline(time.Now().Add(clientCertProbeWindow))
+	defer conn.SetReadDeadline(time.Time{})
+
+	_, err := conn.Read(make([]byte, 1))
+	return s.rejectionError(err)
+}
+
+func isClientCertAlert(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "remote error" {
+		return false
+	}
+
+	message := opErr.Err.Error()
+	for _, alert := range clientCertAlerts {
+		if message == alert {
+			return true
+		}
+	}
+	return false
+}
+
+// distinguishedName renders a DER-encoded X.501 name, falling back to a hex marker
+func distinguishedName(der []byte) string {
+	var rdn pkix.RDNSequence
+	if _, err := asn1.Unmarshal(der, &rdn); err != nil {
+		return fmt.Sprintf("<unparseable name %x>", der[:min(len(der), 8)])
+	}
+
+	var name pkix.Name
+	name.FillFromRDNSequence(&rdn)
+	if s := name.String(); strings.TrimSpace(s) != "" {
+		return s
+	}
+	return "<empty name>"
+}
@@ -0,0 +1,131 @@
+// =============================================================================
+// internal/ssl/compare.go - Field-by-field comparison of two endpoints' certificates
+// =============================================================================
+package ssl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CertField is one compared certificate attribute
+type CertField struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Match bool   `json:"match"`
+}
+
+// CertDifference names a field whose values differ between the two endpoints
+type CertDifference struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// CertComparison is the result of comparing the certificates served by two endpoints
+type CertComparison struct {
+	EndpointA   string           `json:"endpoint_a"`
+	EndpointB   string           `json:"endpoint_b"`
+	CertA       *CertInfo        `json:"cert_a"`
+	CertB       *CertInfo        `json:"cert_b"`
+	Identical   bool             `json:"identical"`
+	Fields      []CertField      `json:"fields"`
+	Differences []CertDifference `json:"differences"`
+	Timestamp   time.Time        `json:"timestamp"`
+}
+
+// CompareCertificates checks both endpoints concurrently and diffs their leaf certificates
+func CompareCertificates(ctx context.Context, hostA, portA, hostB, portB string, dialCfg DialConfig) (*CertComparison, error) {
+	var (
+		wg           sync.WaitGroup
+		certA, certB *CertInfo
+		errA, errB   error
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		certA, errA = CheckCertificate(ctx, hostA, portA, dialCfg)
+	}()
+	go func() {
+		defer wg.Done()
+		certB, errB = CheckCertificate(ctx, hostB, portB, dialCfg)
+	}()
+	wg.Wait()
+
+	if errA != nil {
+		return nil, fmt.Errorf("%s: %w", net.JoinHostPort(hostA, portA), errA)
+	}
+	if errB != nil {
+		return nil, fmt.Errorf("%s: %w", net.JoinHostPort(hostB, portB), errB)
+	}
+
+	comparison := &CertComparison{
+		EndpointA:   net.JoinHostPort(hostA, portA),
+		EndpointB:   net.JoinHostPort(hostB, portB),
+		CertA:       certA,
+		CertB:       certB,
+		Differences: []CertDifference{},
+		Timestamp:   time.Now(),
+	}
+
+	for _, field := range compareFields(certA, certB) {
+		comparison.Fields = append(comparison.Fields, field)
+		if !field.Match {
+			comparison.Differences = append(comparison.Differences, CertDifference{
+				Field: field.Field,
+				A:     field.A,
+				B:     field.B,
+			})
+		}
+	}
+	comparison.Identical = len(comparison.Differences) == 0
+
+	return comparison, nil
+}
+
+// compareFields lists the compared attributes in display order
+func compareFields(a, b *CertInfo) []CertField {
+	const timeFormat = "2006-01-02 15:04:05"
+
+	pairs := []struct {
+		field string
+		a, b  string
+	}{
+		{"serial_number", a.SerialNumber, b.SerialNumber},
+		{"sha256_fingerprint", a.SHA256Fingerprint, b.SHA256Fingerprint},
+		{"spki_fingerprint", a.SPKIFingerprint, b.SPKIFingerprint},
+		{"issuer", a.Issuer, b.Issuer},
+		{"common_name", a.CommonName, b.CommonName},
+		{"sans", sortedNames(a.DNSNames), sortedNames(b.DNSNames)},
+		{"not_before", a.NotBefore.UTC().Format(timeFormat), b.NotBefore.UTC().Format(timeFormat)},
+		{"not_after", a.NotAfter.UTC().Format(timeFormat), b.NotAfter.UTC().Format(timeFormat)},
+	}
+
+	fields := make([]CertField, 0, len(pairs))
+	for _, pair := range pairs {
+		fields = append(fields, CertField{
+			Field: pair.field,
+			A:     pair.a,
+			B:     pair.b,
+			Match: pair.a == pair.b,
+		})
+	}
+	return fields
+}
+
+// sortedNames renders SANs order-independently
+func sortedNames(names []string) string {
+	sorted := make([]string, len(names))
+	for i, name := range names {
+		sorted[i] = normalizeHostname(name)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,215 @@
+// =============================================================================
+// internal/ssl/ct.go - Certificate Transparency (SCT) parsing and log lookups
+// =============================================================================
+package ssl
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SCT sources
+const (
+	SCTSourceTLSExtension = "tls_extension"
+	SCTSourceEmbedded     = "embedded"
+	SCTSourceOCSP         = "ocsp"
+)
+
+// SCTInfo describes a single Signed Certificate Timestamp
+type SCTInfo struct {
+	Version   int       `json:"version"`
+	LogID     string    `json:"log_id"` // base64-encoded SHA-256 of the log's public key
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+}
+
+var (
+	oidEmbeddedSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+	oidOCSPSCTList     = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 5}
+	oidOCSPBasic       = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+)
+
+// crtShURL is the Certificate Transparency search endpoint used by LookupCTLogEntries
+const crtShURL = "https://crt.sh/"
+
+// collectSCTs gathers SCTs delivered via the TLS extension, embedded in the leaf, and stapled in OCSP
+func collectSCTs(tlsSCTs [][]byte, leaf *x509.Certificate, ocspResponse []byte) []SCTInfo {
+	var scts []SCTInfo
+
+	for _, raw := range tlsSCTs {
+		if sct, err := parseSCT(raw, SCTSourceTLSExtension); err == nil {
+			scts = append(scts, sct)
+		}
+	}
+
+	for _, ext := range leaf.Extensions {
+		if ext.Id.Equal(oidEmbeddedSCTList) {
+			scts = append(scts, parseSCTExtension(ext.Value, SCTSourceEmbedded)...)
+		}
+	}
+
+	for _, ext := range ocspSingleExtensions(ocspResponse) {
+		if ext.Id.Equal(oidOCSPSCTList) {
+			scts = append(scts, parseSCTExtension(ext.Value, SCTSourceOCSP)...)
+		}
+	}
+
+	return scts
+}
+
+// parseSCTExtension decodes an X.509/OCSP extension value: an OCTET STRING wrapping a TLS-encoded SCT list
+func parseSCTExtension(value []byte, source string) []SCTInfo {
+	var list []byte
+	if _, err := asn1.Unmarshal(value, &list); err != nil {
+		return nil
+	}
+	return parseSCTList(list, source)
+}
+
+// parseSCTList decodes a SignedCertificateTimestampList (RFC 6962 section 3.3)
+func parseSCTList(data []byte, source string) []SCTInfo {
+	if len(data) < 2 {
+		return nil
+	}
+	total := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+	if total > len(data) {
+		return nil
+	}
+	data = data[:total]
+
+	var scts []SCTInfo
+	for len(data) >= 2 {
+		size := int(binary.BigEndian.Uint16(data))
+		data = data[2:]
+		if size > len(data) {
+			break
+		}
+		if sct, err := parseSCT(data[:size], source); err == nil {
+			scts = append(scts, sct)
+		}
+		data = data[size:]
+	}
+
+	return scts
+}
+
+// parseSCT decodes the fixed-layout prefix of a serialized SCT (version, log ID, timestamp)
+func parseSCT(data []byte, source string) (SCTInfo, error) {
+	const headerLen = 1 + 32 + 8
+	if len(data) < headerLen {
+		return SCTInfo{}, fmt.Errorf("SCT too short")
+	}
+
+	millis := binary.BigEndian.Uint64(data[33:41])
+	return SCTInfo{
+		Version:   int(data[0]) + 1, // v1 is encoded as 0
+		LogID:     base64.StdEncoding.EncodeToString(data[1:33]),
+		Timestamp: time.UnixMilli(int64(millis)).UTC(),
+		Source:    source,
+	}, nil
+}
+
+// Minimal OCSP structures (RFC 6960), enough to reach the single-response extensions
+type ocspResponse struct {
+	Status        asn1.Enumerated
+	ResponseBytes ocspResponseBytes `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseBytes struct {
+	ResponseType asn1.ObjectIdentifier
+	Response     []byte
+}
+
+type ocspBasicResponse struct {
+	TBSResponseData    ocspResponseData
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseData struct {
+	Version     int `asn1:"optional,default:0,explicit,tag:0"`
+	ResponderID asn1.RawValue
+	ProducedAt  time.Time `asn1:"generalized"`
+	Responses   []ocspSingleResponse
+}
+
+type ocspSingleResponse struct {
+	CertID           asn1.RawValue
+	CertStatus       asn1.RawValue
+	ThisUpdate       time.Time        `asn1:"generalized"`
+	NextUpdate       time.Time        `asn1:"generalized,explicit,tag:0,optional"`
+	SingleExtensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+// ocspSingleExtensions returns the singleExtensions of a stapled OCSP response, if any
+func ocspSingleExtensions(der []byte) []pkix.Extension {
+	if len(der) == 0 {
+		return nil
+	}
+
+	var resp ocspResponse
+	if _, err := asn1.Unmarshal(der, &resp); err != nil || !resp.ResponseBytes.ResponseType.Equal(oidOCSPBasic) {
+		return nil
+	}
+
+	var basic ocspBasicResponse
+	if _, err := asn1.Unmarshal(resp.ResponseBytes.Response, &basic); err != nil {
+		return nil
+	}
+
+	var exts []pkix.Extension
+	for _, single := range basic.TBSResponseData.Responses {
+		exts = append(exts, single.SingleExtensions...)
+	}
+	return exts
+}
+
+// LookupCTLogEntries queries crt.sh for log entries matching the leaf certificate's SHA-256
+// fingerprint and records the count on info
+func LookupCTLogEntries(ctx context.Context, info *CertInfo) error {
+	if info.SHA256Fingerprint == "" {
+		return fmt.Errorf("certificate fingerprint not available")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	query := url.Values{"q": {info.SHA256Fingerprint}, "output": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, crtShURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("crt.sh lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("crt.sh lookup failed: %s", resp.Status)
+	}
+
+	var entries []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return fmt.Errorf("invalid crt.sh response: %w", err)
+	}
+
+	count := len(entries)
+	info.CTLogEntries = &count
+	if count == 0 && info.PubliclyTrusted {
+		info.Warnings = append(info.Warnings, "certificate not found in CT logs (crt.sh)")
+	}
+	return nil
+}
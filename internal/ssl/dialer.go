@@ -0,0 +1,155 @@
+// =============================================================================
+// internal/ssl/dialer.go - Connection setup (timeouts and proxies) for TLS checks
+// =============================================================================
+package ssl
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// ErrCancelled is returned when a check is interrupted by context cancellation
+var ErrCancelled = errors.New("cancelled")
+
+// DialConfig controls how certificate checks connect to their target
+type DialConfig struct {
+	Timeout time.Duration // Connect + handshake timeout; zero means DefaultTimeout
+	Proxy   string        // socks5://, socks5h:// or http:// proxy URL; empty falls back to the environment
+
+	ClientCertificate *tls.Certificate // Presented when the server requests a client certificate
+}
+
+// DefaultTimeout is used when DialConfig.Timeout is not set
+const DefaultTimeout = 10 * time.Second
+
+func (c DialConfig) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// dial opens a TCP connection to address, directly or through the configured proxy
+func (c DialConfig) dial(ctx context.Context, address string) (net.Conn, error) {
+	proxyURL, err := c.proxyURL(address)
+	if err != nil {
+		return nil, err
+	}
+
+	direct := &net.Dialer{Timeout: c.timeout()}
+	if proxyURL == nil {
+		return direct.DialContext(ctx, "tcp", address)
+	}
+
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if proxyURL.User != nil {
+			password, _ := proxyURL.User.Password()
+			auth = &proxy.Auth{User: proxyURL.User.Username(), Password: password}
+		}
+		dialer, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, direct)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SOCKS5 proxy: %w", err)
+		}
+		return dialer.(proxy.ContextDialer).DialContext(ctx, "tcp", address)
+	case "http":
+		return dialHTTPConnect(ctx, direct, proxyURL, address)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %s", proxyURL.Scheme)
+	}
+}
+
+// proxyURL resolves the proxy to use for address, honoring HTTPS_PROXY, ALL_PROXY and NO_PROXY
+func (c DialConfig) proxyURL(address string) (*url.URL, error) {
+	if c.Proxy != "" {
+		u, err := url.Parse(c.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL: %s", c.Proxy)
+		}
+		return u, nil
+	}
+
+	cfg := httpproxy.FromEnvironment()
+	if cfg.HTTPSProxy == "" {
+		cfg.HTTPSProxy = firstEnv("ALL_PROXY", "all_proxy")
+	}
+	return cfg.ProxyFunc()(&url.URL{Scheme: "https", Host: address})
+}
+
+// dialHTTPConnect tunnels a connection to address through an HTTP CONNECT proxy
+func dialHTTPConnect(ctx context.Context, dialer *net.Dialer, proxyURL *url.URL, address string) (net.Conn, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach proxy: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed: %w", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT failed: %s", resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// connectionError maps context failures to clearer errors than the underlying network error
+func connectionError(ctx context.Context, stage string, timeout time.Duration, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		return ErrCancelled
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%s: timed out after %v", stage, timeout)
+	default:
+		return fmt.Errorf("%s: %v", stage, err)
+	}
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
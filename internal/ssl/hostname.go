@@ -0,0 +1,125 @@
+// =============================================================================
+// internal/ssl/hostname.go - Certificate name and identity checks
+// =============================================================================
+package ssl
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// isSelfSigned reports whether the certificate is issued by itself and carries a valid self-signature
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return false
+	}
+	return cert.CheckSignatureFrom(cert) == nil
+}
+
+// isWildcard reports whether the CN or any SAN is a wildcard name
+func isWildcard(cert *x509.Certificate) bool {
+	for _, name := range certNames(cert) {
+		if strings.HasPrefix(name, "*.") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesDomain reports whether domain is covered by the certificate's CN or SANs (IP SANs for
+// address targets), allowing a wildcard to stand in for exactly one left-most label
+func matchesDomain(cert *x509.Certificate, domain string) bool {
+	if ip := net.ParseIP(domain); ip != nil {
+		for _, certIP := range cert.IPAddresses {
+			if certIP.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	host := normalizeHostname(domain)
+	for _, name := range certNames(cert) {
+		if matchHostname(normalizeHostname(name), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// coversHostname reports whether domain is covered by the certificate's SANs alone: DNS SANs
+// for names, IP SANs for addresses. This is the check TLS clients make; unlike matchesDomain,
+// a CN naming the domain doesn't count.
+func coversHostname(cert *x509.Certificate, domain string) bool {
+	if ip := net.ParseIP(domain); ip != nil {
+		for _, certIP := range cert.IPAddresses {
+			if certIP.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	host := normalizeHostname(domain)
+	for _, name := range cert.DNSNames {
+		if matchHostname(normalizeHostname(name), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostnameNote explains that domain isn't covered by the certificate's SANs and lists the
+// names it does cover, so a valid certificate served for the wrong name is obvious
+func hostnameNote(cert *x509.Certificate, domain string, valid bool) string {
+	prefix := "certificate does not cover " + domain
+	if valid {
+		prefix = "certificate is valid but does not cover " + domain
+	}
+
+	sans := append([]string(nil), cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	var note string
+	if len(sans) == 0 {
+		note = prefix + "; it has no SANs"
+	} else {
+		note = fmt.Sprintf("%s; its SANs are %s", prefix, strings.Join(sans, ", "))
+	}
+	if cert.Subject.CommonName != "" && matchHostname(normalizeHostname(cert.Subject.CommonName), normalizeHostname(domain)) {
+		note += " (only the CN names it, which clients ignore)"
+	}
+	return note
+}
+
+// certNames returns the CN followed by the DNS SANs
+func certNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, len(cert.DNSNames)+1)
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return append(names, cert.DNSNames...)
+}
+
+// matchHostname compares a single certificate name against a host
+func matchHostname(pattern, host string) bool {
+	if pattern == "" || host == "" {
+		return false
+	}
+	if !strings.HasPrefix(pattern, "*.") {
+		return pattern == host
+	}
+
+	// "*.example.com" matches "www.example.com" but not "example.com" or "a.b.example.com"
+	label, rest, found := strings.Cut(host, ".")
+	return found && label != "" && rest == pattern[2:]
+}
+
+func normalizeHostname(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
@@ -0,0 +1,203 @@
+// =============================================================================
+// internal/whois/whois.go - WHOIS lookups with IANA and registrar referrals
+// =============================================================================
+package whois
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// IANAServer knows the registry WHOIS server for every TLD
+	IANAServer = "whois.iana.org"
+
+	// DefaultTimeout bounds each WHOIS connection
+	DefaultTimeout = 10 * time.Second
+
+	maxResponseSize = 1 << 20
+	maxReferrals    = 3
+)
+
+// Result holds the raw WHOIS response and the fields parsed from it
+type Result struct {
+	Domain      string    `json:"domain"`
+	Server      string    `json:"server"`              // Server that produced Raw
+	Referrals   []string  `json:"referrals,omitempty"` // Servers consulted, in order
+	Registrar   string    `json:"registrar,omitempty"`
+	CreatedAt   string    `json:"created_at,omitempty"`
+	UpdatedAt   string    `json:"updated_at,omitempty"`
+	ExpiresAt   string    `json:"expires_at,omitempty"`
+	Nameservers []string  `json:"nameservers,omitempty"`
+	Status      []string  `json:"status,omitempty"`
+	Errors      []string  `json:"errors,omitempty"` // Referral failures that did not stop the lookup
+	Raw         string    `json:"raw"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Lookup resolves the registry WHOIS server for domain's TLD via IANA (unless server is given),
+// queries it, and follows a registrar referral if the registry is thin. A failing referral
+// keeps the last good response and is recorded in Errors.
+func Lookup(ctx context.Context, domain, server string, timeout time.Duration) (*Result, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return nil, fmt.Errorf("empty domain")
+	}
+
+	result := &Result{Domain: domain, Timestamp: time.Now()}
+
+	if server == "" {
+		labels := strings.Split(domain, ".")
+		tld := labels[len(labels)-1]
+
+		response, err := query(ctx, IANAServer, tld, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("IANA lookup for .%s failed: %w", tld, err)
+		}
+		result.Referrals = append(result.Referrals, IANAServer)
+
+		server = referral(response, "refer", "whois")
+		if server == "" {
+			return nil, fmt.Errorf("IANA has no WHOIS server for .%s", tld)
+		}
+	}
+
+	for hop := 0; server != "" && hop < maxReferrals; hop++ {
+		response, err := query(ctx, server, domain, timeout)
+		if err != nil {
+			if result.Raw == "" {
+				return nil, fmt.Errorf("WHOIS query to %s failed: %w", server, err)
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("referral to %s failed: %v", server, err))
+			break
+		}
+
+		result.Referrals = append(result.Referrals, server)
+		result.Server = server
+		result.Raw = response
+		parse(result, response)
+
+		next := referral(response, "registrar whois server", "whois server", "referralserver")
+		if next == "" || strings.EqualFold(next, server) {
+			break
+		}
+		server = next
+	}
+
+	return result, nil
+}
+
+// query sends one WHOIS request over TCP port 43 and returns the full response
+func query(ctx context.Context, server, request string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	address := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		address = net.JoinHostPort(server, "43")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := io.WriteString(conn, request+"\r\n"); err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(conn, maxResponseSize))
+	if err != nil && len(data) == 0 {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// referral returns the host named by the first matching "key: value" line, stripping any
+// whois:// scheme
+func referral(response string, keys ...string) string {
+	for _, key := range keys {
+		if values := fieldValues(response, key); len(values) > 0 {
+			host := strings.TrimPrefix(strings.TrimPrefix(values[0], "rwhois://"), "whois://")
+			return strings.TrimSuffix(strings.ToLower(host), "/")
+		}
+	}
+	return ""
+}
+
+// Field names used by common registries for each summary value
+var (
+	registrarKeys  = []string{"registrar", "sponsoring registrar", "registrar name"}
+	createdKeys    = []string{"creation date", "created", "created on", "registered on", "domain registration date", "registration time"}
+	updatedKeys    = []string{"updated date", "last updated", "last modified", "changed"}
+	expiresKeys    = []string{"registry expiry date", "registrar registration expiration date", "expiry date", "expiration date", "expires", "expires on", "paid-till"}
+	nameserverKeys = []string{"name server", "nserver", "nameservers", "name servers"}
+	statusKeys     = []string{"domain status", "status"}
+)
+
+// parse fills the summary fields of result from response. Values found in a later (registrar)
+// response replace those from the registry; fields it omits keep the registry's values.
+func parse(result *Result, response string) {
+	first := func(current string, keys []string) string {
+		for _, key := range keys {
+			if values := fieldValues(response, key); len(values) > 0 {
+				return values[0]
+			}
+		}
+		return current
+	}
+	all := func(current []string, keys []string, lower bool) []string {
+		seen := make(map[string]bool)
+		var values []string
+		for _, key := range keys {
+			for _, value := range fieldValues(response, key) {
+				// Status lines often carry an explanatory URL after the code
+				value = strings.Fields(value)[0]
+				if lower {
+					value = strings.TrimSuffix(strings.ToLower(value), ".")
+				}
+				if !seen[value] {
+					seen[value] = true
+					values = append(values, value)
+				}
+			}
+		}
+		if len(values) == 0 {
+			return current
+		}
+		return values
+	}
+
+	result.Registrar = first(result.Registrar, registrarKeys)
+	result.CreatedAt = first(result.CreatedAt, createdKeys)
+	result.UpdatedAt = first(result.UpdatedAt, updatedKeys)
+	result.ExpiresAt = first(result.ExpiresAt, expiresKeys)
+	result.Nameservers = all(result.Nameservers, nameserverKeys, true)
+	result.Status = all(result.Status, statusKeys, false)
+}
+
+// fieldValues returns the non-empty values of every "key: value" line whose key matches,
+// ignoring case and leading whitespace
+func fieldValues(response, key string) []string {
+	var values []string
+	for _, line := range strings.Split(response, "\n") {
+		name, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found || !strings.EqualFold(strings.TrimSpace(name), key) {
+			continue
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
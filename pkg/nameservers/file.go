@@ -0,0 +1,87 @@
+package nameservers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadFile reads nameservers from a file with one server per line in the form
+// address[,location[,provider]], e.g. "8.8.8.8,US-East,Google". The address may carry a
+// port ("192.0.2.1:5353", "[2001:db8::1]:53"). Blank lines and lines starting with # are ignored.
+func LoadFile(filename string) ([]Nameserver, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open nameserver file: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads nameservers in the LoadFile format
+func Parse(reader io.Reader) ([]Nameserver, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	var servers []Nameserver
+	for {
+		fields, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse nameserver file: %w", err)
+		}
+		line, _ := csvReader.FieldPos(0)
+
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected address[,location[,provider]], got %d fields", line, len(fields))
+		}
+		server, err := parseAddress(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(fields) > 1 {
+			server.Location = strings.TrimSpace(fields[1])
+		}
+		if len(fields) > 2 {
+			server.Provider = strings.TrimSpace(fields[2])
+		}
+		server.Name = server.Address()
+		servers = append(servers, server)
+	}
+
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no nameservers found in file")
+	}
+	return servers, nil
+}
+
+// parseAddress accepts an IP address with an optional port
+func parseAddress(address string) (Nameserver, error) {
+	if ip := net.ParseIP(address); ip != nil {
+		return Nameserver{IP: ip, Port: 53}, nil
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return Nameserver{}, fmt.Errorf("invalid nameserver address %q", address)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return Nameserver{}, fmt.Errorf("invalid nameserver IP %q", host)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return Nameserver{}, fmt.Errorf("invalid port %q for nameserver %s", portStr, host)
+	}
+	return Nameserver{IP: ip, Port: port}, nil
+}
@@ -1,20 +1,24 @@
 package nameservers
 
-import "net"
+import (
+	"net"
+	"sort"
+	"strconv"
+)
 
 // CommonNameservers provides lists of well-known public DNS servers
 var CommonNameservers = map[string][]Nameserver{
 	"google": {
-		{Name: "google-dns1", IP: net.ParseIP("8.8.8.8"), Port: 53, Provider: "Google"},
-		{Name: "google-dns2", IP: net.ParseIP("8.8.4.4"), Port: 53, Provider: "Google"},
+		{Name: "google-dns1", IP: net.ParseIP("8.8.8.8"), Port: 53, Provider: "Google", DoHURL: "https://dns.google/dns-query"},
+		{Name: "google-dns2", IP: net.ParseIP("8.8.4.4"), Port: 53, Provider: "Google", DoHURL: "https://dns.google/dns-query"},
 	},
 	"cloudflare": {
-		{Name: "cloudflare-dns1", IP: net.ParseIP("1.1.1.1"), Port: 53, Provider: "Cloudflare"},
-		{Name: "cloudflare-dns2", IP: net.ParseIP("1.0.0.1"), Port: 53, Provider: "Cloudflare"},
+		{Name: "cloudflare-dns1", IP: net.ParseIP("1.1.1.1"), Port: 53, Provider: "Cloudflare", DoHURL: "https://cloudflare-dns.com/dns-query"},
+		{Name: "cloudflare-dns2", IP: net.ParseIP("1.0.0.1"), Port: 53, Provider: "Cloudflare", DoHURL: "https://cloudflare-dns.com/dns-query"},
 	},
 	"quad9": {
-		{Name: "quad9-dns1", IP: net.ParseIP("9.9.9.9"), Port: 53, Provider: "Quad9"},
-		{Name: "quad9-dns2", IP: net.ParseIP("149.112.112.112"), Port: 53, Provider: "Quad9"},
+		{Name: "quad9-dns1", IP: net.ParseIP("9.9.9.9"), Port: 53, Provider: "Quad9", DoHURL: "https://dns.quad9.net/dns-query"},
+		{Name: "quad9-dns2", IP: net.ParseIP("149.112.112.112"), Port: 53, Provider: "Quad9", DoHURL: "https://dns.quad9.net/dns-query"},
 	},
 	"opendns": {
 		{Name: "opendns1", IP: net.ParseIP("208.67.222.222"), Port: 53, Provider: "OpenDNS"},
@@ -67,6 +71,8 @@ type Nameserver struct {
 	IP       net.IP `json:"ip"`
 	Port     int    `json:"port"`
 	Provider string `json:"provider"`
+	DoHURL   string `json:"doh_url,omitempty"`  // DNS-over-HTTPS endpoint, if the provider offers one
+	Location string `json:"location,omitempty"` // Geographic label from a nameserver file, e.g. "US-East"
 }
 
 // GetAllNameservers returns all nameservers from all providers
@@ -86,6 +92,34 @@ func GetProviderNameservers(provider string) []Nameserver {
 	return nil
 }
 
+// ProviderNames returns the sorted keys accepted by GetProviderNameservers
+func ProviderNames() []string {
+	names := make([]string, 0, len(CommonNameservers))
+	for name := range CommonNameservers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Address returns the server in the form the resolver queries: the bare IP on port 53,
+// otherwise IP and port
+func (n Nameserver) Address() string {
+	if (n.Port == 0 || n.Port == 53) && n.IP.To4() != nil {
+		return n.IP.String()
+	}
+	port := n.Port
+	if port == 0 {
+		port = 53
+	}
+	return net.JoinHostPort(n.IP.String(), strconv.Itoa(port))
+}
+
+// HasDoH reports whether the nameserver can be queried over DNS-over-HTTPS
+func (n Nameserver) HasDoH() bool {
+	return n.DoHURL != ""
+}
+
 // GetDefaultNameservers returns a default set of reliable nameservers
 func GetDefaultNameservers() []Nameserver {
 	return []Nameserver{